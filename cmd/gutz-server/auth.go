@@ -0,0 +1,277 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/codeGROOVE-dev/guTZ/pkg/apikey"
+	"github.com/codeGROOVE-dev/guTZ/pkg/github"
+)
+
+const (
+	oauthStateCookie = "gutz_oauth_state"
+	meCookie         = "gutz_me"
+	meTTL            = time.Hour // How long a signed-in user can fetch their own result
+)
+
+// randomToken returns an unguessable hex token for OAuth states and result sessions.
+func randomToken() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// detectScopes returns the scopes a detection needs.
+func detectScopes(refresh bool) []apikey.Scope {
+	if refresh {
+		return []apikey.Scope{apikey.ScopeDetect, apikey.ScopeRefresh}
+	}
+	return []apikey.Scope{apikey.ScopeDetect}
+}
+
+// authorize admits a request that needs scopes, answering refused requests itself. The --admin-token
+// may do anything. A request with an API key is charged to that key's quotas. Anonymous requests may
+// detect within the per-IP rate limit, and refresh within the smaller refresh limit, but not administer.
+func (s *server) authorize(writer http.ResponseWriter, request *http.Request, scopes ...apikey.Scope) bool {
+	requestID := writer.Header().Get("X-Request-ID")
+	clientIP := strings.Split(request.RemoteAddr, ":")[0]
+	if s.isAdmin(request) {
+		return true
+	}
+
+	token, ok := strings.CutPrefix(request.Header.Get("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		switch {
+		case slices.Contains(scopes, apikey.ScopeAdmin):
+			s.logger.Warn("Unauthorized admin request", "request_id", requestID, "path", request.URL.Path, "client_ip", clientIP)
+			http.Error(writer, "Unauthorized", http.StatusUnauthorized)
+		case !s.limiter.allow(clientIP):
+			s.logger.Error("Rate limit exceeded", "request_id", requestID, "client_ip", clientIP)
+			http.Error(writer, "Rate limit exceeded", http.StatusTooManyRequests)
+		case slices.Contains(scopes, apikey.ScopeRefresh) && !s.refreshes.allow(clientIP):
+			s.logger.Error("Refresh rate limit exceeded", "request_id", requestID, "client_ip", clientIP)
+			http.Error(writer, "Refresh rate limit exceeded", http.StatusTooManyRequests)
+		default:
+			return true
+		}
+		return false
+	}
+
+	name, err := s.keys.Allow(token, scopes...)
+	var quotaErr *apikey.QuotaError
+	switch {
+	case err == nil:
+		s.logger.Debug("API key request", "request_id", requestID, "key", name, "path", request.URL.Path)
+		return true
+	case errors.As(err, &quotaErr):
+		s.logger.Warn("API key quota exceeded", "request_id", requestID, "key", name, "quota", quotaErr.Quota)
+		writer.Header().Set("Retry-After", strconv.Itoa(max(1, int(math.Ceil(time.Until(quotaErr.Reset).Seconds())))))
+		http.Error(writer, "API key quota exceeded", http.StatusTooManyRequests)
+	case errors.Is(err, apikey.ErrScope):
+		s.logger.Warn("API key out of scope", "request_id", requestID, "key", name, "path", request.URL.Path, "error", err)
+		http.Error(writer, "API key not allowed here", http.StatusForbidden)
+	default:
+		s.logger.Warn("Invalid API key", "request_id", requestID, "path", request.URL.Path, "client_ip", clientIP)
+		http.Error(writer, "Invalid API key", http.StatusUnauthorized)
+	}
+	return false
+}
+
+// keyName returns the name of the API key a request carries, or "" for the admin token and anonymous requests.
+func (s *server) keyName(request *http.Request) string {
+	token, ok := strings.CutPrefix(request.Header.Get("Authorization"), "Bearer ")
+	if !ok || s.isAdmin(request) {
+		return ""
+	}
+	name, _ := s.keys.Name(token) //nolint:errcheck // unknown keys have no name
+	return name
+}
+
+// handleLogin explains what signing in grants and lets the user choose between their public activity
+// (?access=public, the read:user scope) and their private contributions too (?access=private, which
+// needs the repo scope), then sends them to GitHub to authorize it.
+func (s *server) handleLogin(writer http.ResponseWriter, request *http.Request) {
+	requestID := writer.Header().Get("X-Request-ID")
+	if s.oauthClientID == "" {
+		http.Error(writer, "Sign-in is not enabled on this server", http.StatusNotFound)
+		return
+	}
+	var scope string
+	switch access := request.URL.Query().Get("access"); access {
+	case "":
+		s.renderSignIn(writer, requestID)
+		return
+	case "public":
+		scope = github.ScopeReadUser
+	case "private":
+		scope = github.ScopePrivateRepos
+	default:
+		http.Error(writer, fmt.Sprintf("Invalid access %q (want public or private)", access), http.StatusBadRequest)
+		return
+	}
+
+	state, err := randomToken()
+	if err != nil {
+		s.logger.Error("Failed to generate OAuth state", "request_id", requestID, "error", err)
+		http.Error(writer, "Sign-in failed", http.StatusInternalServerError)
+		return
+	}
+	http.SetCookie(writer, &http.Cookie{
+		Name: oauthStateCookie, Value: state, Path: "/auth/", MaxAge: 600,
+		HttpOnly: true, Secure: true, SameSite: http.SameSiteLaxMode,
+	})
+	writer.Header().Set("Cache-Control", "no-store")
+	http.Redirect(writer, request, github.AuthorizeURL(s.githubURL, s.oauthClientID, state, scope), http.StatusFound)
+}
+
+func (s *server) renderSignIn(writer http.ResponseWriter, requestID string) {
+	tmpl, err := template.New("signin").Parse(signInTemplate)
+	if err != nil {
+		s.logger.Error("Template parsing failed", "request_id", requestID, "error", err)
+		http.Error(writer, "Template error", http.StatusInternalServerError)
+		return
+	}
+	writer.Header().Set("Content-Type", "text/html")
+	if err := tmpl.Execute(writer, struct{ GitHubURL string }{s.githubURL}); err != nil {
+		s.logger.Error("Template execution failed", "request_id", requestID, "error", err)
+	}
+}
+
+// revokeGrant withdraws the authorization a signed-in user gave this server, once their token has
+// served its one detection.
+func (s *server) revokeGrant(ctx context.Context, requestID, token string) {
+	// Revoke even when the sign-in failed or the client went away
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+	defer cancel()
+	if err := github.RevokeOAuthGrant(ctx, s.oauthHTTP, s.githubAPI, s.oauthClientID, s.oauthClientSecret, token); err != nil {
+		s.logger.Warn("Failed to revoke OAuth grant", "request_id", requestID, "error", err)
+	}
+}
+
+// handleCallback completes the GitHub sign-in and detects the signed-in user, private contributions
+// included when they granted the repo scope. The user's token only fetches their activity, and the grant
+// is revoked right after. The result is never cached, stored in history or shared: it is kept in memory
+// for meTTL under a session only the user's browser holds, and served by GET /api/v1/me.
+func (s *server) handleCallback(writer http.ResponseWriter, request *http.Request) {
+	start := time.Now()
+	clientIP := strings.Split(request.RemoteAddr, ":")[0]
+	requestID := writer.Header().Get("X-Request-ID")
+	writer.Header().Set("Cache-Control", "no-store")
+
+	if s.oauthClientID == "" {
+		http.Error(writer, "Sign-in is not enabled on this server", http.StatusNotFound)
+		return
+	}
+	if !s.limiter.allow(clientIP) {
+		s.logger.Error("Rate limit exceeded", "request_id", requestID, "client_ip", clientIP)
+		http.Error(writer, "Rate limit exceeded", http.StatusTooManyRequests)
+		return
+	}
+
+	query := request.URL.Query()
+	cookie, err := request.Cookie(oauthStateCookie)
+	if err != nil || query.Get("state") == "" || subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(query.Get("state"))) != 1 {
+		s.logger.Warn("OAuth state mismatch", "request_id", requestID, "client_ip", clientIP)
+		http.Error(writer, "Sign-in expired or was not started here; please sign in again", http.StatusBadRequest)
+		return
+	}
+	http.SetCookie(writer, &http.Cookie{
+		Name: oauthStateCookie, Path: "/auth/", MaxAge: -1,
+		HttpOnly: true, Secure: true, SameSite: http.SameSiteLaxMode,
+	})
+	if reason := query.Get("error"); reason != "" {
+		s.logger.Info("OAuth sign-in declined", "request_id", requestID, "reason", reason)
+		http.Error(writer, "Sign-in was not authorized", http.StatusForbidden)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(request.Context(), 30*time.Second)
+	defer cancel()
+	token, err := github.ExchangeOAuthCode(ctx, s.oauthHTTP, s.githubURL, s.oauthClientID, s.oauthClientSecret, query.Get("code"))
+	if err != nil {
+		s.logger.Error("OAuth code exchange failed", "request_id", requestID, "error", err)
+		http.Error(writer, "Sign-in failed", http.StatusBadGateway)
+		return
+	}
+	detectCtx, username, err := s.detector.ViewerContext(ctx, token)
+	s.revokeGrant(ctx, requestID, token)
+	if err != nil {
+		s.logger.Error("Failed to fetch signed-in user's activity", "request_id", requestID, "error", err)
+		http.Error(writer, "Failed to read your GitHub activity", http.StatusBadGateway)
+		return
+	}
+
+	result, err := s.detector.Detect(detectCtx, username)
+	if err != nil {
+		statusCode, errorResponse, logMsg := classifyDetectError(err, username)
+		s.logger.Error(logMsg,
+			"request_id", requestID,
+			"username", username,
+			"error", err,
+			"duration_ms", time.Since(start).Milliseconds())
+		s.writeJSON(writer, statusCode, errorResponse)
+		return
+	}
+	if !*verbose {
+		result.GeminiPrompt = ""
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		s.logger.Error("JSON encoding failed", "request_id", requestID, "username", username, "error", err)
+		http.Error(writer, "Encoding failed", http.StatusInternalServerError)
+		return
+	}
+
+	session, err := randomToken()
+	if err != nil {
+		s.logger.Error("Failed to generate session", "request_id", requestID, "error", err)
+		http.Error(writer, "Sign-in failed", http.StatusInternalServerError)
+		return
+	}
+	s.viewerResults.Set(session, data)
+	http.SetCookie(writer, &http.Cookie{
+		Name: meCookie, Value: session, Path: "/api/v1/me", MaxAge: int(meTTL.Seconds()),
+		HttpOnly: true, Secure: true, SameSite: http.SameSiteLaxMode,
+	})
+	s.logger.Info("Signed-in detection completed",
+		"request_id", requestID,
+		"username", username,
+		"timezone", result.Timezone,
+		"duration_ms", time.Since(start).Milliseconds())
+	http.Redirect(writer, request, "/api/v1/me", http.StatusSeeOther)
+}
+
+// handleMe returns the detection of the signed-in user made by the OAuth callback.
+func (s *server) handleMe(writer http.ResponseWriter, request *http.Request) {
+	requestID := writer.Header().Get("X-Request-ID")
+	var data []byte
+	if cookie, err := request.Cookie(meCookie); err == nil {
+		data, _ = s.viewerResults.Get(cookie.Value)
+	}
+	if data == nil {
+		s.writeJSON(writer, http.StatusUnauthorized, detectErrorResponse{
+			Error:   "Not signed in",
+			Details: "Sign in with GitHub at /auth/login to see your own working hours; results are kept for an hour.",
+			Code:    "NOT_SIGNED_IN",
+		})
+		return
+	}
+	writer.Header().Set("Content-Type", "application/json")
+	if _, err := writer.Write(data); err != nil {
+		s.logger.Error("Failed to write response", "request_id", requestID, "error", err)
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/codeGROOVE-dev/guTZ/pkg/apikey"
+	"github.com/codeGROOVE-dev/guTZ/pkg/gutz"
+)
+
+func TestAuthorize(t *testing.T) {
+	keys := []apikey.Key{
+		{Name: "reader", SHA256: apikey.Hash("reader-secret"), Scopes: []apikey.Scope{apikey.ScopeDetect}, PerMinute: 1},
+	}
+	s, srv := newTestServer(t, keys)
+	// Answered from the cache, so only authorization is under test
+	if _, err := s.storeResult("octocat", &gutz.Result{Username: "octocat", Timezone: "UTC-7"}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		path   string
+		token  string
+		status int
+	}{
+		{"refresh out of scope", "/api/v1/detect/stream?username=octocat&refresh=true", "reader-secret", http.StatusForbidden},
+		{"within quota", "/api/v1/detect/stream?username=octocat", "reader-secret", http.StatusOK},
+		{"over the per-minute quota", "/api/v1/detect/stream?username=octocat", "reader-secret", http.StatusTooManyRequests},
+		{"unknown key", "/api/v1/detect/stream?username=octocat", "wrong-secret", http.StatusUnauthorized},
+		{"admin token", "/api/v1/detect/stream?username=octocat", testAdminToken, http.StatusOK},
+		{"admin endpoint with a detect key", "/_/x-keys", "reader-secret", http.StatusForbidden},
+		{"admin endpoint anonymously", "/_/x-keys", "", http.StatusUnauthorized},
+		{"admin endpoint with the admin token", "/_/x-keys", testAdminToken, http.StatusOK},
+	}
+	for _, tt := range tests {
+		resp := do(t, srv, http.MethodGet, tt.path, tt.token, nil)
+		if resp.StatusCode != tt.status {
+			t.Errorf("%s: status = %d, want %d", tt.name, resp.StatusCode, tt.status)
+		}
+		if resp.StatusCode == http.StatusTooManyRequests && resp.Header.Get("Retry-After") == "" {
+			t.Errorf("%s: no Retry-After", tt.name)
+		}
+	}
+}
+
+func TestOAuthState(t *testing.T) {
+	s, srv := newTestServer(t, nil)
+	if resp := do(t, srv, http.MethodGet, "/auth/login?access=public", "", nil); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("login without a client ID status = %d, want 404", resp.StatusCode)
+	}
+	s.oauthClientID = "client-id"
+
+	if resp := do(t, srv, http.MethodGet, "/auth/login?access=everything", "", nil); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("unknown access status = %d, want 400", resp.StatusCode)
+	}
+	resp := do(t, srv, http.MethodGet, "/auth/login?access=public", "", nil)
+	if resp.StatusCode != http.StatusFound {
+		t.Fatalf("login status = %d, want 302", resp.StatusCode)
+	}
+	location, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	state := location.Query().Get("state")
+	if location.Query().Get("scope") != "read:user" || location.Query().Get("client_id") != "client-id" || state == "" {
+		t.Errorf("authorize URL = %s, want the read:user scope, the client ID and a state", location)
+	}
+	var cookie *http.Cookie
+	for _, c := range resp.Cookies() {
+		if c.Name == oauthStateCookie {
+			cookie = c
+		}
+	}
+	if cookie == nil || cookie.Value != state || !cookie.HttpOnly || !cookie.Secure {
+		t.Fatalf("state cookie = %+v, want a secure HttpOnly cookie holding %q", cookie, state)
+	}
+
+	callback := func(query, cookieValue string) int {
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, srv.URL+"/auth/callback?"+query, http.NoBody)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cookieValue != "" {
+			req.AddCookie(&http.Cookie{Name: oauthStateCookie, Value: cookieValue})
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close() //nolint:errcheck // only the status matters
+		return resp.StatusCode
+	}
+	if got := callback("code=c&state="+state, ""); got != http.StatusBadRequest {
+		t.Errorf("callback without the state cookie = %d, want 400", got)
+	}
+	if got := callback("code=c&state=forged", state); got != http.StatusBadRequest {
+		t.Errorf("callback with a forged state = %d, want 400", got)
+	}
+	if got := callback("code=c", state); got != http.StatusBadRequest {
+		t.Errorf("callback without a state = %d, want 400", got)
+	}
+	// A matching state gets past the check; the user then declined, so nothing is exchanged
+	if got := callback("error=access_denied&state="+state, state); got != http.StatusForbidden {
+		t.Errorf("declined callback with a matching state = %d, want 403", got)
+	}
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/codeGROOVE-dev/guTZ/pkg/apikey"
+	"github.com/codeGROOVE-dev/guTZ/pkg/gutz"
+	"github.com/codeGROOVE-dev/guTZ/pkg/jobs"
+)
+
+// detectForJob detects one user of a bulk job, answering from the caches unless the job refreshes.
+// Each user is charged to the job's API key, just as a request to /api/v1/detect would be.
+func (s *server) detectForJob(ctx context.Context, job jobs.Job, username string) ([]byte, error) {
+	if err := s.chargeJob(ctx, job); err != nil {
+		return nil, err
+	}
+	if data, _ := s.cachedResult(username); !job.Refresh && data != nil {
+		return data, nil
+	}
+	// Wait out an exhausted GitHub budget before the per-user deadline starts ticking
+	if err := s.detector.WaitForRateLimit(ctx); err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	if job.Refresh {
+		ctx = s.invalidate(ctx, job.ID, username)
+	}
+
+	result, err := s.detector.Detect(ctx, username)
+	if err != nil {
+		_, errorResponse, logMsg := classifyDetectError(err, username)
+		s.logger.Warn(logMsg, "job_id", job.ID, "username", username, "error", err)
+		return nil, &jobs.Failure{Message: errorResponse.Error, Code: errorResponse.Code}
+	}
+	return s.storeResult(username, result)
+}
+
+// chargeJob charges one user of job to its API key, waiting out the key's per-minute quota. A spent
+// daily quota, or a key that was removed or lost its scopes, fails the user.
+func (s *server) chargeJob(ctx context.Context, job jobs.Job) error {
+	if job.Key == "" {
+		return nil // Submitted with the admin token
+	}
+	for {
+		err := s.keys.Charge(job.Key, detectScopes(job.Refresh)...)
+		var quotaErr *apikey.QuotaError
+		switch {
+		case err == nil:
+			return nil
+		case errors.As(err, &quotaErr) && quotaErr.Quota == "minute":
+			select {
+			case <-time.After(time.Until(quotaErr.Reset)):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		case errors.Is(err, apikey.ErrQuota):
+			return &jobs.Failure{Message: "API key quota exceeded", Code: "API_KEY_QUOTA"}
+		default:
+			s.logger.Warn("Job key refused", "job_id", job.ID, "key", job.Key, "error", err)
+			return &jobs.Failure{Message: "API key no longer allowed to detect", Code: "API_KEY_REFUSED"}
+		}
+	}
+}
+
+// handleCreateJob queues a bulk detection of a list of users, the public members of an organization,
+// or both, and answers with the job to poll. Jobs need an API key with the detect scope, and each user
+// is charged to the key's quotas as the job runs.
+func (s *server) handleCreateJob(writer http.ResponseWriter, request *http.Request) {
+	requestID := writer.Header().Get("X-Request-ID")
+	var req struct {
+		Org       string   `json:"org"`
+		Usernames []string `json:"usernames"`
+		Refresh   bool     `json:"refresh"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(writer, request.Body, 1<<20)).Decode(&req); err != nil {
+		http.Error(writer, "Invalid request", http.StatusBadRequest)
+		return
+	}
+	for i, u := range req.Usernames {
+		req.Usernames[i] = strings.TrimSpace(u)
+		if !gutz.IsValidGitHubUsername(req.Usernames[i]) {
+			http.Error(writer, fmt.Sprintf("Invalid username %q", u), http.StatusBadRequest)
+			return
+		}
+	}
+	req.Org = strings.TrimSpace(req.Org)
+	if req.Org != "" && !gutz.IsValidGitHubUsername(req.Org) {
+		http.Error(writer, "Invalid organization", http.StatusBadRequest)
+		return
+	}
+	if len(req.Usernames) == 0 && req.Org == "" {
+		http.Error(writer, "Give usernames, an org, or both", http.StatusBadRequest)
+		return
+	}
+
+	// The anonymous tier's per-IP limit counts requests, not users, so bulk detection needs a key
+	if token, ok := strings.CutPrefix(request.Header.Get("Authorization"), "Bearer "); !ok || token == "" {
+		http.Error(writer, "Jobs need an API key", http.StatusUnauthorized)
+		return
+	}
+	if !s.authorize(writer, request, detectScopes(req.Refresh)...) {
+		return
+	}
+	keyName := s.keyName(request)
+
+	if req.Org != "" {
+		ctx, cancel := context.WithTimeout(request.Context(), 20*time.Second)
+		defer cancel()
+		members, err := s.detector.OrganizationMembers(ctx, req.Org, jobs.MaxUsers)
+		if err != nil {
+			s.logger.Warn("Failed to fetch organization members", "request_id", requestID, "org", req.Org, "error", err)
+			status := http.StatusBadGateway
+			if strings.Contains(err.Error(), "not found") {
+				status = http.StatusNotFound
+			}
+			http.Error(writer, "Failed to fetch organization members", status)
+			return
+		}
+		req.Usernames = append(req.Usernames, members...)
+	}
+
+	// Refuse up front what the key's daily quota could never finish today
+	if remaining := s.keys.RemainingToday(keyName); keyName != "" && remaining >= 0 && len(req.Usernames) > remaining {
+		y, m, d := time.Now().UTC().Date()
+		writer.Header().Set("Retry-After", strconv.Itoa(max(1, int(time.Until(time.Date(y, m, d+1, 0, 0, 0, 0, time.UTC)).Seconds()))))
+		http.Error(writer, fmt.Sprintf("Job of %d users exceeds the %d requests left in the API key's daily quota", len(req.Usernames), remaining),
+			http.StatusTooManyRequests)
+		return
+	}
+
+	job, err := s.jobs.Submit(jobs.Job{Usernames: req.Usernames, Key: keyName, Org: req.Org, Refresh: req.Refresh})
+	switch {
+	case errors.Is(err, jobs.ErrBusy):
+		writer.Header().Set("Retry-After", "60")
+		http.Error(writer, "Too many pending jobs", http.StatusServiceUnavailable)
+		return
+	case err != nil:
+		s.logger.Warn("Job refused", "request_id", requestID, "error", err)
+		http.Error(writer, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.logger.Info("Job queued", "request_id", requestID, "job_id", job.ID, "org", job.Org, "users", len(job.Usernames))
+	writer.Header().Set("Location", "/api/v1/jobs/"+job.ID)
+	s.writeJSON(writer, http.StatusAccepted, job)
+}
+
+// handleJob reports a job's status and progress. The job ID is unguessable, so it is all a client needs.
+func (s *server) handleJob(writer http.ResponseWriter, request *http.Request) {
+	job, err := s.jobs.Get(request.PathValue("id"))
+	if err != nil {
+		http.Error(writer, "Job not found", http.StatusNotFound)
+		return
+	}
+	s.writeJSON(writer, http.StatusOK, job)
+}
+
+// handleJobResults streams the results a job has so far as NDJSON: one detection, or one
+// {"username", "error", "code"} failure, per line. Users who opted out since are left out.
+func (s *server) handleJobResults(writer http.ResponseWriter, request *http.Request) {
+	requestID := writer.Header().Get("X-Request-ID")
+	id := request.PathValue("id")
+	job, err := s.jobs.Get(id)
+	if err != nil {
+		http.Error(writer, "Job not found", http.StatusNotFound)
+		return
+	}
+
+	writer.Header().Set("Content-Type", "application/x-ndjson")
+	writer.Header().Set("X-Job-Status", string(job.Status))
+	err = s.jobs.Results(id, func(username string, line []byte) error {
+		if s.detector.OptedOut(username) {
+			return nil
+		}
+		_, err := writer.Write(line)
+		return err
+	})
+	if err != nil {
+		s.logger.Error("Failed to write job results", "request_id", requestID, "job_id", id, "error", err)
+	}
+}
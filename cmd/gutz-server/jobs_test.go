@@ -0,0 +1,83 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/codeGROOVE-dev/guTZ/pkg/apikey"
+	"github.com/codeGROOVE-dev/guTZ/pkg/jobs"
+)
+
+// waitJob polls the job at location until it is done.
+func waitJob(t *testing.T, srv *httptest.Server, location string) jobs.Job {
+	t.Helper()
+	if !strings.HasPrefix(location, "/api/v1/jobs/") {
+		t.Fatalf("Location = %q, want a job URL", location)
+	}
+	deadline := time.Now().Add(30 * time.Second)
+	for time.Now().Before(deadline) {
+		resp := do(t, srv, http.MethodGet, location, "", nil)
+		var job jobs.Job
+		if err := json.NewDecoder(resp.Body).Decode(&job); err != nil {
+			t.Fatalf("decoding job: %v", err)
+		}
+		if job.Status == jobs.StatusDone {
+			return job
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatalf("job %s did not finish", location)
+	return jobs.Job{}
+}
+
+func TestJobs(t *testing.T) {
+	keys := []apikey.Key{
+		{Name: "bulk", SHA256: apikey.Hash("bulk-secret"), Scopes: []apikey.Scope{apikey.ScopeDetect}},
+		{Name: "small", SHA256: apikey.Hash("small-secret"), Scopes: []apikey.Scope{apikey.ScopeDetect}, PerDay: 2},
+	}
+	_, srv := newTestServer(t, keys, replayDetector(t)...)
+
+	users := map[string]any{"usernames": []string{"octocat"}}
+	if resp := do(t, srv, http.MethodPost, "/api/v1/jobs", "", users); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("anonymous job status = %d, want 401", resp.StatusCode)
+	}
+	if resp := do(t, srv, http.MethodPost, "/api/v1/jobs", "bulk-secret", map[string]any{"usernames": []string{"-bad-"}}); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("invalid username status = %d, want 400", resp.StatusCode)
+	}
+	// Authorizing the job spends one of the two, which leaves too few for three users
+	resp := do(t, srv, http.MethodPost, "/api/v1/jobs", "small-secret", map[string]any{"usernames": []string{"a", "b", "c"}})
+	if resp.StatusCode != http.StatusTooManyRequests || resp.Header.Get("Retry-After") == "" {
+		t.Errorf("job over the daily quota = %d, Retry-After %q; want 429 with Retry-After", resp.StatusCode, resp.Header.Get("Retry-After"))
+	}
+
+	resp = do(t, srv, http.MethodPost, "/api/v1/jobs", "bulk-secret", users)
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("create status = %d, want 202", resp.StatusCode)
+	}
+	job := waitJob(t, srv, resp.Header.Get("Location"))
+	if job.Key != "bulk" || job.Done != 1 || job.Failed != 0 {
+		t.Errorf("job = %+v, want octocat detected and charged to bulk", job)
+	}
+
+	resp = do(t, srv, http.MethodGet, "/api/v1/jobs/"+job.ID+"/results", "", nil)
+	if resp.Header.Get("Content-Type") != "application/x-ndjson" || resp.Header.Get("X-Job-Status") != string(jobs.StatusDone) {
+		t.Errorf("results headers = %v, want NDJSON of a done job", resp.Header)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(body)), "\n")
+	if len(lines) != 1 || !strings.Contains(lines[0], `"timezone":"UTC-7"`) {
+		t.Errorf("results = %s, want one UTC-7 line", body)
+	}
+
+	if resp := do(t, srv, http.MethodGet, "/api/v1/jobs/0123456789abcdef", "", nil); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("unknown job status = %d, want 404", resp.StatusCode)
+	}
+}
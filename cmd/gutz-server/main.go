@@ -3,8 +3,10 @@ package main
 
 import (
 	"bytes"
+	"cmp"
 	"compress/gzip"
 	"context"
+	"crypto/subtle"
 	"embed"
 	"encoding/json"
 	"errors"
@@ -13,47 +15,81 @@ import (
 	"html/template"
 	"io"
 	"log/slog"
+	"math"
 	"net/http"
 	"os"
 	"os/exec"
 	"os/signal"
 	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
 	"sync"
 	"syscall"
 	"time"
 
+	"github.com/codeGROOVE-dev/guTZ/pkg/apikey"
+	"github.com/codeGROOVE-dev/guTZ/pkg/github"
 	"github.com/codeGROOVE-dev/guTZ/pkg/gutz"
+	"github.com/codeGROOVE-dev/guTZ/pkg/history"
+	"github.com/codeGROOVE-dev/guTZ/pkg/jobs"
+	"github.com/codeGROOVE-dev/guTZ/pkg/llm"
+	"github.com/codeGROOVE-dev/guTZ/pkg/optout"
 	"github.com/maypok86/otter"
 )
 
 //go:embed templates/home.html
 var homeTemplate string
 
+//go:embed templates/signin.html
+var signInTemplate string
+
 //go:embed static/*
 var staticFiles embed.FS
 
 var (
 	port         = flag.String("port", "8080", "Port for web server")
 	githubToken  = flag.String("github-token", "", "GitHub API token (or set GITHUB_TOKEN)")
+	githubTokens = flag.String("github-tokens", "", "Comma-separated pool of extra GitHub tokens to spread requests over (or set GITHUB_TOKENS)")
+	tokensFile   = flag.String("github-tokens-file", "", "File with one pooled GitHub token per line (or set GITHUB_TOKENS_FILE)")
+	adminToken   = flag.String("admin-token", "", "Bearer token with every scope and no quota; unset disables it (or set ADMIN_TOKEN)")
+	apiKeysFile  = flag.String("api-keys", "", "JSON file of API keys with their scopes and quotas (or set API_KEYS_FILE)")
+	githubURL    = flag.String("github-url", "", "GitHub Enterprise Server URL, e.g. https://ghe.example.com (or set GITHUB_URL)")
+	githubAPI    = flag.String("github-api-url", "", "GitHub REST API base URL, overriding --github-url (or set GITHUB_API_URL)")
+	githubGQL    = flag.String("github-graphql-url", "", "GitHub GraphQL endpoint, overriding --github-url (or set GITHUB_GRAPHQL_URL)")
 	geminiAPIKey = flag.String("gemini-key", "", "Gemini API key (or set GEMINI_API_KEY)")
 	geminiModel  = flag.String("gemini-model", "gemini-2.5-flash-lite", "Gemini model to use")
 	mapsAPIKey   = flag.String("maps-key", "", "Google Maps API key (or set GOOGLE_MAPS_API_KEY)")
 	gcpProject   = flag.String("gcp-project", "", "GCP project ID (or set GCP_PROJECT)")
 	cacheDir     = flag.String("cache-dir", "", "Cache directory (or set CACHE_DIR)")
+	cacheStore   = flag.String("cache-store", "", "Shared HTTP cache store: bolt:PATH, sqlite:PATH or redis://HOST:PORT/DB (or set CACHE_STORE)")
+	llmBackend   = flag.String("llm", "", "LLM backend: gemini, openai, ollama, or none (or set LLM_BACKEND)")
+	llmURL       = flag.String("llm-url", "", "Base URL for the openai or ollama backend (or set LLM_URL)")
+	llmModel     = flag.String("llm-model", "", "Model for the openai or ollama backend (or set LLM_MODEL)")
+	llmAPIKey    = flag.String("llm-key", "", "API key for the openai backend (or set LLM_API_KEY)")
+	rateLimit    = flag.String("rate-limit", "", "When the GitHub budget runs out: wait (within the request timeout) or fail (or set RATE_LIMIT_POLICY)")
+	optOutFile   = flag.String("opt-out-file", "", "Opt-out registry, default optout.json in the cache directory (or set OPT_OUT_FILE)")
+	privacy      = flag.String("privacy", "", "What results reveal: full, coarse (default) or timezone-only (or set PRIVACY_LEVEL)")
+	oauthID      = flag.String("oauth-client-id", "", "GitHub OAuth app client ID; enables /auth/login for self-detection (or set GITHUB_OAUTH_CLIENT_ID)")
+	oauthSecret  = flag.String("oauth-client-secret", "", "GitHub OAuth app client secret (or set GITHUB_OAUTH_CLIENT_SECRET)")
+	jobWorkers   = flag.Int("job-workers", 4, "Users detected in parallel across all bulk jobs")
 	verbose      = flag.Bool("verbose", false, "Enable verbose logging")
 	version      = flag.Bool("version", false, "Show version")
 )
 
 type rateLimiter struct {
 	requests map[string][]time.Time
+	window   time.Duration
+	limit    int
 	mu       sync.Mutex
 }
 
-func newRateLimiter() *rateLimiter {
+// newRateLimiter allows each IP limit requests per window.
+func newRateLimiter(limit int, window time.Duration) *rateLimiter {
 	return &rateLimiter{
 		requests: make(map[string][]time.Time),
+		window:   window,
+		limit:    limit,
 	}
 }
 
@@ -62,7 +98,7 @@ func (rl *rateLimiter) allow(ip string) bool {
 	defer rl.mu.Unlock()
 
 	now := time.Now()
-	cutoff := now.Add(-time.Minute)
+	cutoff := now.Add(-rl.window)
 
 	var valid []time.Time
 	for _, t := range rl.requests[ip] {
@@ -71,8 +107,7 @@ func (rl *rateLimiter) allow(ip string) bool {
 		}
 	}
 
-	// Rate limit: 15 requests per minute per IP
-	if len(valid) >= 15 {
+	if len(valid) >= rl.limit {
 		rl.requests[ip] = valid
 		return false
 	}
@@ -117,25 +152,100 @@ func main() {
 	if *cacheDir == "" {
 		*cacheDir = os.Getenv("CACHE_DIR")
 	}
+	*cacheStore = cmp.Or(*cacheStore, os.Getenv("CACHE_STORE"))
+	*githubURL = cmp.Or(*githubURL, os.Getenv("GITHUB_URL"))
+	*githubAPI = cmp.Or(*githubAPI, os.Getenv("GITHUB_API_URL"))
+	*githubGQL = cmp.Or(*githubGQL, os.Getenv("GITHUB_GRAPHQL_URL"))
+	*llmBackend = cmp.Or(*llmBackend, os.Getenv("LLM_BACKEND"))
+	*llmURL = cmp.Or(*llmURL, os.Getenv("LLM_URL"))
+	*llmModel = cmp.Or(*llmModel, os.Getenv("LLM_MODEL"))
+	*llmAPIKey = cmp.Or(*llmAPIKey, os.Getenv("LLM_API_KEY"))
+
+	backend, err := llm.ParseBackend(*llmBackend)
+	if err != nil {
+		logger.Error("Invalid LLM configuration", "error", err)
+		return
+	}
+	tokenPool := github.ParseTokens(cmp.Or(*githubTokens, os.Getenv("GITHUB_TOKENS")))
+	if path := cmp.Or(*tokensFile, os.Getenv("GITHUB_TOKENS_FILE")); path != "" {
+		fileTokens, err := github.LoadTokens(path)
+		if err != nil {
+			logger.Error("Failed to load GitHub tokens", "error", err)
+			return
+		}
+		tokenPool = append(tokenPool, fileTokens...)
+	}
+	*adminToken = cmp.Or(*adminToken, os.Getenv("ADMIN_TOKEN"))
+	// Requests without a key fall into the anonymous tier, limited per IP
+	keys, err := apikey.New()
+	if path := cmp.Or(*apiKeysFile, os.Getenv("API_KEYS_FILE")); path != "" {
+		if keys, err = apikey.Load(path); err != nil {
+			logger.Error("Failed to load API keys", "error", err)
+			return
+		}
+	}
+	*oauthID = cmp.Or(*oauthID, os.Getenv("GITHUB_OAUTH_CLIENT_ID"))
+	*oauthSecret = cmp.Or(*oauthSecret, os.Getenv("GITHUB_OAUTH_CLIENT_SECRET"))
+	rateLimitPolicy, err := github.ParseRateLimitPolicy(cmp.Or(*rateLimit, os.Getenv("RATE_LIMIT_POLICY")))
+	if err != nil {
+		logger.Error("Invalid rate limit policy", "error", err)
+		return
+	}
+	// Results are public, so unlike the CLI the server keeps prompts, reasoning and exact coordinates to itself
+	privacyLevel, err := gutz.ParsePrivacyLevel(cmp.Or(*privacy, os.Getenv("PRIVACY_LEVEL"), string(gutz.PrivacyCoarse)))
+	if err != nil {
+		logger.Error("Invalid privacy level", "error", err)
+		return
+	}
+
+	optOutPath := cmp.Or(*optOutFile, os.Getenv("OPT_OUT_FILE"))
+	if optOutPath == "" && *cacheDir != "" {
+		optOutPath = filepath.Join(*cacheDir, "optout.json")
+	}
+	if optOutPath == "" {
+		logger.Warn("Opt-outs are kept in memory and lost on restart; set --opt-out-file or --cache-dir")
+	}
+	optOuts, err := optout.Open(optOutPath)
+	if err != nil {
+		logger.Error("Failed to open opt-out registry", "error", err)
+		return
+	}
 
 	// Log configuration (without exposing sensitive keys)
 	logger.Info("Server configuration",
 		"port", *port,
 		"verbose", *verbose,
 		"cache_dir", *cacheDir,
+		"opt_out_file", optOutPath,
+		"has_cache_store", *cacheStore != "", // The spec may carry a Redis password
+		"llm_backend", backend,
+		"rate_limit_policy", rateLimitPolicy,
+		"privacy", privacyLevel,
 		"gemini_model", *geminiModel,
+		"github_api_url", github.NewBaseURLs(*githubURL, *githubAPI, *githubGQL).API,
 		"has_github_token", *githubToken != "",
+		"oauth_sign_in", *oauthID != "",
+		"pooled_github_tokens", len(tokenPool),
+		"api_keys", keys.Len(),
 		"has_gemini_key", *geminiAPIKey != "",
 		"has_maps_key", *mapsAPIKey != "",
 		"has_gcp_project", *gcpProject != "")
 
+	githubURLs := github.NewBaseURLs(*githubURL, *githubAPI, *githubGQL)
 	detector := gutz.NewWithLogger(context.Background(), logger,
 		gutz.WithGitHubToken(*githubToken),
+		gutz.WithGitHubTokens(tokenPool...),
+		gutz.WithGitHubBaseURLs(githubURLs),
 		gutz.WithGeminiAPIKey(*geminiAPIKey),
 		gutz.WithGeminiModel(*geminiModel),
 		gutz.WithMapsAPIKey(*mapsAPIKey),
 		gutz.WithGCPProject(*gcpProject),
+		gutz.WithLLM(llm.Config{Backend: backend, BaseURL: *llmURL, Model: *llmModel, APIKey: *llmAPIKey}),
 		gutz.WithMemoryOnlyCache(),
+		gutz.WithOptOutRegistry(optOuts),
+		gutz.WithCacheStore(*cacheStore),
+		gutz.WithRateLimitPolicy(rateLimitPolicy),
+		gutz.WithPrivacy(privacyLevel),
 	)
 	defer func() {
 		if err := detector.Close(); err != nil {
@@ -150,25 +260,61 @@ func main() {
 		logger.Error("Failed to build cache", "error", err)
 		return
 	}
+	viewerResults, err := otter.MustBuilder[string, []byte](1_000).
+		WithTTL(meTTL).
+		Build()
+	if err != nil {
+		logger.Error("Failed to build cache", "error", err)
+		return
+	}
 
 	var diskCache *diskCacheHandler
+	var historyStore *history.Store
 	if *cacheDir != "" {
-		diskCache = &diskCacheHandler{dir: *cacheDir, logger: logger}
+		diskCache = &diskCacheHandler{dir: *cacheDir, logger: logger, privacy: privacyLevel}
+		if historyStore, err = history.Open(filepath.Join(*cacheDir, "history")); err != nil {
+			logger.Warn("History disabled", "error", err)
+		}
 	}
 
 	server := &server{
-		detector:  detector,
-		cache:     cache,
-		diskCache: diskCache,
-		limiter:   newRateLimiter(),
-		logger:    logger,
+		detector:   detector,
+		cache:      cache,
+		diskCache:  diskCache,
+		history:    historyStore,
+		optOuts:    optOuts,
+		limiter:    newRateLimiter(15, time.Minute),
+		refreshes:  newRateLimiter(2, 10*time.Minute),
+		logger:     logger,
+		githubURL:  githubURLs.Web,
+		githubAPI:  githubURLs.API,
+		adminToken: *adminToken,
+		keys:       keys,
+
+		viewerResults:     viewerResults,
+		oauthHTTP:         &http.Client{Timeout: 10 * time.Second},
+		oauthClientID:     *oauthID,
+		oauthClientSecret: *oauthSecret,
 	}
 
-	mux := http.NewServeMux()
-	mux.HandleFunc("/", server.handleHome)
-	mux.HandleFunc("POST /api/v1/detect", server.handleDetect)
-	mux.HandleFunc("POST /_/x-cleanup", server.handleCleanup)
-	mux.Handle("/static/", http.FileServer(http.FS(staticFiles)))
+	var jobsDir string
+	if *cacheDir != "" {
+		jobsDir = filepath.Join(*cacheDir, "jobs")
+	} else {
+		logger.Warn("Bulk jobs are kept in memory and lost on restart; set --cache-dir")
+	}
+	if server.jobs, err = jobs.Open(jobsDir, *jobWorkers, server.detectForJob, logger); err != nil {
+		logger.Error("Failed to open jobs", "error", err)
+		return
+	}
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	jobsStopped := make(chan struct{})
+	go func() {
+		defer close(jobsStopped)
+		server.jobs.Run(jobsCtx)
+	}()
+
+	mux := server.routes()
 
 	antiCSRF := http.NewCrossOriginProtection()
 	// Add trusted origins if needed for cross-origin access
@@ -201,15 +347,56 @@ func main() {
 	if err := srv.Shutdown(ctx); err != nil {
 		logger.Error("Shutdown failed", "error", err)
 	}
+	// Users interrupted here are detected again when the server restarts
+	stopJobs()
+	<-jobsStopped
 	logger.Info("Server stopped")
 }
 
 type server struct {
-	detector  *gutz.Detector
-	cache     otter.Cache[string, []byte]
-	diskCache *diskCacheHandler
-	limiter   *rateLimiter
-	logger    *slog.Logger
+	detector   *gutz.Detector
+	cache      otter.Cache[string, []byte]
+	diskCache  *diskCacheHandler
+	history    *history.Store // nil without a cache directory
+	optOuts    *optout.Registry
+	keys       *apikey.Keyring
+	jobs       *jobs.Manager
+	limiter    *rateLimiter // Anonymous detections, per IP
+	refreshes  *rateLimiter // Anonymous forced refreshes bypass every cache, so they get a much smaller budget
+	logger     *slog.Logger
+	githubURL  string // Web root that profile and organization links point at
+	githubAPI  string // REST API root, for revoking sign-in grants
+	adminToken string // Bearer token with every scope; empty disables it
+
+	// Self-detection through GitHub sign-in; an empty client ID disables it
+	viewerResults     otter.Cache[string, []byte] // Signed-in users' own results, by session
+	oauthHTTP         *http.Client
+	oauthClientID     string
+	oauthClientSecret string
+}
+
+// routes returns the mux serving every endpoint.
+func (s *server) routes() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", s.handleHome)
+	mux.HandleFunc("POST /api/v1/detect", s.handleDetect)
+	mux.HandleFunc("GET /api/v1/detect/stream", s.handleDetectStream)
+	mux.HandleFunc("GET /api/v1/history/{username}", s.handleHistory)
+	mux.HandleFunc("POST /api/v1/opt-out", s.handleOptOut)
+	mux.HandleFunc("POST /api/v1/opt-out/verify", s.handleOptOutVerify)
+	mux.HandleFunc("GET /api/v1/schema", s.handleSchema)
+	mux.HandleFunc("GET /api/v1/rate-limit", s.handleRateLimit)
+	mux.HandleFunc("GET /api/v1/me", s.handleMe)
+	mux.HandleFunc("POST /api/v1/jobs", s.handleCreateJob)
+	mux.HandleFunc("GET /api/v1/jobs/{id}", s.handleJob)
+	mux.HandleFunc("GET /api/v1/jobs/{id}/results", s.handleJobResults)
+	mux.HandleFunc("GET /auth/login", s.handleLogin)
+	mux.HandleFunc("GET /auth/callback", s.handleCallback)
+	mux.HandleFunc("POST /_/x-cleanup", s.requireAdmin(s.handleCleanup))
+	mux.HandleFunc("GET /_/x-tokens", s.requireAdmin(s.handleTokens))
+	mux.HandleFunc("GET /_/x-keys", s.requireAdmin(s.handleKeys))
+	mux.Handle("/static/", http.FileServer(http.FS(staticFiles)))
+	return mux
 }
 
 func (s *server) wrap(handler http.Handler) http.Handler {
@@ -303,7 +490,10 @@ func (s *server) handleHome(w http.ResponseWriter, r *http.Request) {
 	}
 
 	w.Header().Set("Content-Type", "text/html")
-	if err := tmpl.Execute(w, struct{ Username string }{username}); err != nil {
+	if err := tmpl.Execute(w, struct {
+		Username  string
+		GitHubURL string
+	}{username, s.githubURL}); err != nil {
 		s.logger.Error("Template execution failed",
 			"request_id", requestID,
 			"error", err,
@@ -324,21 +514,10 @@ func (s *server) handleDetect(writer http.ResponseWriter, request *http.Request)
 		"method", request.Method,
 		"path", request.URL.Path)
 
-	// Rate limit (bypass for Precache User-Agent)
-	if userAgent != "Precache" {
-		if !s.limiter.allow(clientIP) {
-			s.logger.Error("Rate limit exceeded",
-				"request_id", requestID,
-				"client_ip", clientIP,
-				"user_agent", userAgent)
-			http.Error(writer, "Rate limit exceeded", http.StatusTooManyRequests)
-			return
-		}
-	}
-
 	// Parse request
 	var req struct {
 		Username string `json:"username"`
+		Refresh  bool   `json:"refresh"`
 	}
 	if err := json.NewDecoder(request.Body).Decode(&req); err != nil {
 		s.logger.Error("Invalid request body",
@@ -361,58 +540,46 @@ func (s *server) handleDetect(writer http.ResponseWriter, request *http.Request)
 		return
 	}
 
+	refresh := req.Refresh || request.URL.Query().Get("refresh") == "true"
+	if !s.authorize(writer, request, detectScopes(refresh)...) {
+		return
+	}
+
 	s.logger.Debug("Processing detection request",
 		"request_id", requestID,
 		"username", req.Username,
+		"refresh", refresh,
 		"client_ip", clientIP)
 
-	// Check memory cache
-	cacheKey := "detect:" + req.Username
-	if data, found := s.cache.Get(cacheKey); found {
+	// Check memory and disk caches
+	if data, source := s.cachedResult(req.Username); !refresh && data != nil {
 		writer.Header().Set("Content-Type", "application/json")
-		writer.Header().Set("X-Cache", "memory-hit")
+		writer.Header().Set("X-Cache", source)
 		if _, err := writer.Write(data); err != nil {
 			s.logger.Error("Failed to write cached response",
 				"request_id", requestID,
 				"error", err,
 				"username", req.Username)
 		}
-		s.logger.Info("Detection request completed (memory cache)",
+		s.logger.Info("Detection request completed (cached)",
 			"request_id", requestID,
 			"username", req.Username,
-			"cache", "memory-hit",
+			"cache", source,
 			"duration_ms", time.Since(start).Milliseconds())
 		return
 	}
 
-	// Check disk cache
-	if s.diskCache != nil {
-		if data := s.diskCache.load(req.Username); data != nil {
-			s.cache.Set(cacheKey, data)
-			writer.Header().Set("Content-Type", "application/json")
-			writer.Header().Set("X-Cache", "disk-hit")
-			if _, err := writer.Write(data); err != nil {
-				s.logger.Error("Failed to write disk cached response",
-					"request_id", requestID,
-					"error", err,
-					"username", req.Username)
-			}
-			s.logger.Info("Detection request completed (disk cache)",
-				"request_id", requestID,
-				"username", req.Username,
-				"cache", "disk-hit",
-				"duration_ms", time.Since(start).Milliseconds())
-			return
-		}
-	}
-
 	// Detect timezone
 	ctx, cancel := context.WithTimeout(request.Context(), 30*time.Second)
 	defer cancel()
+	if refresh {
+		ctx = s.invalidate(ctx, requestID, req.Username)
+	}
 
 	s.logger.Info("Starting detection",
 		"request_id", requestID,
 		"username", req.Username,
+		"refresh", refresh,
 		"timeout", "30s")
 
 	detectStart := time.Now()
@@ -420,101 +587,20 @@ func (s *server) handleDetect(writer http.ResponseWriter, request *http.Request)
 	detectDuration := time.Since(detectStart)
 
 	if err != nil {
-		statusCode := http.StatusInternalServerError
-		var errorResponse struct {
-			Error   string `json:"error"`
-			Details string `json:"details,omitempty"`
-			Code    string `json:"code,omitempty"`
-		}
-
-		// Check for specific error types and provide helpful messages
-		switch {
-		case errors.Is(err, context.DeadlineExceeded):
-			statusCode = http.StatusGatewayTimeout
-			errorResponse.Error = "Detection took too long"
-			errorResponse.Details = "The analysis exceeded the 30-second timeout. This usually happens with very active users. Please try again."
-			errorResponse.Code = "TIMEOUT"
-			s.logger.Error("Detection timeout",
-				"request_id", requestID,
-				"username", req.Username,
-				"error", err,
-				"detect_duration_ms", detectDuration.Milliseconds(),
-				"total_duration_ms", time.Since(start).Milliseconds())
-		case errors.Is(err, context.Canceled):
-			statusCode = http.StatusRequestTimeout
-			errorResponse.Error = "Request was canceled"
-			errorResponse.Details = "The request was canceled before completion. Please try again."
-			errorResponse.Code = "CANCELED"
-			s.logger.Error("Detection canceled",
-				"request_id", requestID,
-				"username", req.Username,
-				"error", err,
-				"detect_duration_ms", detectDuration.Milliseconds(),
-				"total_duration_ms", time.Since(start).Milliseconds())
-		case strings.Contains(err.Error(), "rate limit"):
-			statusCode = http.StatusTooManyRequests
-			errorResponse.Error = "GitHub API rate limit exceeded"
-			errorResponse.Details = "We've hit GitHub's rate limit. Please try again in a few minutes, or provide a GitHub token for higher limits."
-			errorResponse.Code = "GITHUB_RATE_LIMIT"
-			s.logger.Error("GitHub rate limit hit",
-				"request_id", requestID,
-				"username", req.Username,
-				"error", err,
-				"detect_duration_ms", detectDuration.Milliseconds(),
-				"total_duration_ms", time.Since(start).Milliseconds())
-		case strings.Contains(err.Error(), "not found"):
-			statusCode = http.StatusNotFound
-			errorResponse.Error = "GitHub user not found"
-			errorResponse.Details = fmt.Sprintf("The username '%s' doesn't exist on GitHub. Please check the spelling.", req.Username)
-			errorResponse.Code = "USER_NOT_FOUND"
-			s.logger.Error("User not found",
-				"request_id", requestID,
-				"username", req.Username,
-				"error", err,
-				"detect_duration_ms", detectDuration.Milliseconds(),
-				"total_duration_ms", time.Since(start).Milliseconds())
-		case strings.Contains(err.Error(), "unable to fetch GitHub profile"):
-			statusCode = http.StatusBadGateway
-			errorResponse.Error = "Unable to fetch GitHub profile"
-			if strings.Contains(err.Error(), "permission") || strings.Contains(err.Error(), "scope") {
-				errorResponse.Details = "The GitHub token doesn't have required permissions. Please ensure the token has 'read:user' scope."
-				errorResponse.Code = "INSUFFICIENT_PERMISSIONS"
-			} else {
-				errorResponse.Details = "GitHub's API is temporarily unavailable. Please try again in a moment."
-				errorResponse.Code = "GITHUB_API_ERROR"
-			}
-			s.logger.Error("GitHub API error",
-				"request_id", requestID,
-				"username", req.Username,
-				"error", err,
-				"detect_duration_ms", detectDuration.Milliseconds(),
-				"total_duration_ms", time.Since(start).Milliseconds())
-		case strings.Contains(err.Error(), "Gemini"):
-			statusCode = http.StatusServiceUnavailable
-			errorResponse.Error = "AI analysis service unavailable"
-			errorResponse.Details = "The Gemini AI service is temporarily unavailable. Detection will use fallback methods."
-			errorResponse.Code = "GEMINI_ERROR"
-			s.logger.Error("Gemini API error",
-				"request_id", requestID,
-				"username", req.Username,
-				"error", err,
-				"detect_duration_ms", detectDuration.Milliseconds(),
-				"total_duration_ms", time.Since(start).Milliseconds())
-		default:
-			errorResponse.Error = "Detection failed"
-			errorResponse.Details = "An unexpected error occurred during timezone detection. Please try again."
-			errorResponse.Code = "INTERNAL_ERROR"
-			s.logger.Error("Detection failed",
-				"request_id", requestID,
-				"username", req.Username,
-				"error", err,
-				"error_type", fmt.Sprintf("%T", err),
-				"detect_duration_ms", detectDuration.Milliseconds(),
-				"total_duration_ms", time.Since(start).Milliseconds())
-		}
+		statusCode, errorResponse, logMsg := classifyDetectError(err, req.Username)
+		s.logger.Error(logMsg,
+			"request_id", requestID,
+			"username", req.Username,
+			"error", err,
+			"error_type", fmt.Sprintf("%T", err),
+			"detect_duration_ms", detectDuration.Milliseconds(),
+			"total_duration_ms", time.Since(start).Milliseconds())
 
 		// Send JSON error response
 		writer.Header().Set("Content-Type", "application/json")
+		if errorResponse.RetryAfter > 0 {
+			writer.Header().Set("Retry-After", strconv.Itoa(errorResponse.RetryAfter))
+		}
 		writer.WriteHeader(statusCode)
 		if err := json.NewEncoder(writer).Encode(errorResponse); err != nil {
 			s.logger.Error("Failed to encode error response",
@@ -530,72 +616,17 @@ func (s *server) handleDetect(writer http.ResponseWriter, request *http.Request)
 		"timezone", result.Timezone,
 		"detect_duration_ms", detectDuration.Milliseconds())
 
-	// Clear sensitive data
-	if !*verbose {
-		result.GeminiPrompt = ""
-	}
-
-	// Convert HalfHourlyActivityUTC map[float64]int to map[string]int for JSON encoding
-	// JSON doesn't support float64 as map keys
-	if result.HalfHourlyActivityUTC != nil {
-		stringKeyMap := make(map[string]int)
-		for k, v := range result.HalfHourlyActivityUTC {
-			// Format as "0.0", "0.5", "1.0", etc.
-			key := fmt.Sprintf("%.1f", k)
-			stringKeyMap[key] = v
-		}
-		// Create a temporary result with string keys for JSON encoding
-		type JSONResult struct {
-			*gutz.Result
-
-			HalfHourlyActivityUTC map[string]int `json:"half_hourly_activity_utc,omitempty"`
-		}
-		jsonResult := JSONResult{
-			Result:                result,
-			HalfHourlyActivityUTC: stringKeyMap,
-		}
-		data, err := json.Marshal(jsonResult)
-		if err != nil {
-			s.logger.Error("JSON encoding failed",
-				"request_id", requestID,
-				"error", err,
-				"username", req.Username,
-				"duration_ms", time.Since(start).Milliseconds())
-			http.Error(writer, "Encoding failed", http.StatusInternalServerError)
-			return
-		}
-		// Cache result
-		s.cache.Set(cacheKey, data)
-		if s.diskCache != nil {
-			go s.diskCache.save(req.Username, data)
-		}
-
-		// Send response
-		writer.Header().Set("Content-Type", "application/json")
-		if _, err := writer.Write(data); err != nil {
-			s.logger.Error("Failed to write response",
-				"request_id", requestID,
-				"error", err,
-				"username", req.Username,
-				"duration_ms", time.Since(start).Milliseconds())
-		}
-		return
-	}
-
-	// Encode response (for cases without HalfHourlyActivityUTC)
-	data, err := json.Marshal(result)
+	data, err := s.storeResult(req.Username, result)
 	if err != nil {
-		s.logger.Error("JSON encoding failed", "error", err, "username", req.Username)
+		s.logger.Error("JSON encoding failed",
+			"request_id", requestID,
+			"error", err,
+			"username", req.Username,
+			"duration_ms", time.Since(start).Milliseconds())
 		http.Error(writer, "Encoding failed", http.StatusInternalServerError)
 		return
 	}
 
-	// Cache result
-	s.cache.Set(cacheKey, data)
-	if s.diskCache != nil {
-		go s.diskCache.save(req.Username, data)
-	}
-
 	// Send response
 	writer.Header().Set("Content-Type", "application/json")
 	writer.Header().Set("X-Cache", "miss")
@@ -616,6 +647,479 @@ func (s *server) handleDetect(writer http.ResponseWriter, request *http.Request)
 	}
 }
 
+// handleDetectStream runs a detection and streams its progress as Server-Sent Events.
+// Each completed phase is sent as a "progress" event, followed by exactly one "result" or "failed" event.
+// ("error" is avoided as an event name because EventSource reserves it for connection failures.)
+func (s *server) handleDetectStream(writer http.ResponseWriter, request *http.Request) {
+	start := time.Now()
+	clientIP := strings.Split(request.RemoteAddr, ":")[0]
+	userAgent := request.Header.Get("User-Agent")
+	requestID := writer.Header().Get("X-Request-ID")
+
+	s.logger.Info("Streaming detection request started",
+		"request_id", requestID,
+		"client_ip", clientIP,
+		"user_agent", userAgent)
+
+	username := strings.TrimSpace(request.URL.Query().Get("username"))
+	if !gutz.IsValidGitHubUsername(username) {
+		s.logger.Error("Invalid username",
+			"request_id", requestID,
+			"username", username,
+			"client_ip", clientIP)
+		http.Error(writer, "Invalid username", http.StatusBadRequest)
+		return
+	}
+
+	rc := http.NewResponseController(writer)
+	// Detection can run up to its own 30s timeout, which would collide with the server's WriteTimeout
+	if err := rc.SetWriteDeadline(time.Now().Add(45 * time.Second)); err != nil {
+		s.logger.Debug("Failed to extend write deadline", "request_id", requestID, "error", err)
+	}
+	refresh := request.URL.Query().Get("refresh") == "true"
+	if !s.authorize(writer, request, detectScopes(refresh)...) {
+		return
+	}
+
+	writer.Header().Set("Content-Type", "text/event-stream")
+	writer.Header().Set("X-Accel-Buffering", "no") // Disable proxy buffering
+
+	var mu sync.Mutex
+	send := func(event string, data []byte) {
+		mu.Lock()
+		defer mu.Unlock()
+		if _, err := fmt.Fprintf(writer, "event: %s\ndata: %s\n\n", event, data); err != nil {
+			s.logger.Debug("Failed to write event", "request_id", requestID, "event", event, "error", err)
+			return
+		}
+		if err := rc.Flush(); err != nil {
+			s.logger.Debug("Failed to flush event", "request_id", requestID, "event", event, "error", err)
+		}
+	}
+
+	if data, source := s.cachedResult(username); !refresh && data != nil {
+		send("result", data)
+		s.logger.Info("Streaming detection completed (cached)",
+			"request_id", requestID,
+			"username", username,
+			"cache", source,
+			"duration_ms", time.Since(start).Milliseconds())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(request.Context(), 30*time.Second)
+	defer cancel()
+	if refresh {
+		ctx = s.invalidate(ctx, requestID, username)
+	}
+
+	result, err := s.detector.DetectWithProgress(ctx, username, func(ev gutz.ProgressEvent) {
+		data, err := json.Marshal(ev)
+		if err != nil {
+			s.logger.Debug("Failed to encode progress event", "request_id", requestID, "phase", ev.Phase, "error", err)
+			return
+		}
+		send("progress", data)
+	})
+	if err != nil {
+		_, errorResponse, logMsg := classifyDetectError(err, username)
+		s.logger.Error(logMsg,
+			"request_id", requestID,
+			"username", username,
+			"error", err,
+			"error_type", fmt.Sprintf("%T", err),
+			"total_duration_ms", time.Since(start).Milliseconds())
+		data, err := json.Marshal(errorResponse)
+		if err != nil {
+			s.logger.Error("Failed to encode error response", "request_id", requestID, "encode_error", err)
+			return
+		}
+		send("failed", data)
+		return
+	}
+
+	data, err := s.storeResult(username, result)
+	if err != nil {
+		s.logger.Error("JSON encoding failed",
+			"request_id", requestID,
+			"error", err,
+			"username", username)
+		send("failed", []byte(`{"error":"Encoding failed","code":"INTERNAL_ERROR"}`))
+		return
+	}
+	send("result", data)
+
+	s.logger.Info("Streaming detection completed",
+		"request_id", requestID,
+		"username", username,
+		"timezone", result.Timezone,
+		"cache", "miss",
+		"duration_ms", time.Since(start).Milliseconds())
+}
+
+// handleHistory returns every recorded detection for a user along with the changes between them.
+func (s *server) handleHistory(writer http.ResponseWriter, request *http.Request) {
+	requestID := writer.Header().Get("X-Request-ID")
+	username := request.PathValue("username")
+	if !gutz.IsValidGitHubUsername(username) {
+		http.Error(writer, "Invalid username", http.StatusBadRequest)
+		return
+	}
+	if s.detector.OptedOut(username) {
+		http.Error(writer, "This user has opted out", http.StatusUnavailableForLegalReasons)
+		return
+	}
+	if s.history == nil {
+		http.Error(writer, "History is not enabled on this server", http.StatusNotFound)
+		return
+	}
+
+	snaps, err := s.history.Snapshots(username)
+	if err != nil {
+		s.logger.Error("Failed to read history",
+			"request_id", requestID,
+			"username", username,
+			"error", err)
+		http.Error(writer, "Failed to read history", http.StatusInternalServerError)
+		return
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(writer).Encode(struct {
+		Username  string             `json:"username"`
+		Snapshots []history.Snapshot `json:"snapshots"`
+		Changes   []history.Change   `json:"changes"`
+	}{username, snaps, history.Changes(snaps)}); err != nil {
+		s.logger.Error("Failed to write history",
+			"request_id", requestID,
+			"username", username,
+			"error", err)
+	}
+}
+
+// handleSchema serves the JSON Schema describing /api/v1/detect responses.
+func (s *server) handleSchema(writer http.ResponseWriter, _ *http.Request) {
+	requestID := writer.Header().Get("X-Request-ID")
+	data, err := gutz.JSONSchema()
+	if err != nil {
+		s.logger.Error("Failed to build schema", "request_id", requestID, "error", err)
+		http.Error(writer, "Failed to build schema", http.StatusInternalServerError)
+		return
+	}
+	writer.Header().Set("Content-Type", "application/schema+json")
+	if _, err := writer.Write(data); err != nil {
+		s.logger.Error("Failed to write schema", "request_id", requestID, "error", err)
+	}
+}
+
+// handleRateLimit reports the server's remaining GitHub budget, so batch clients can pace themselves.
+func (s *server) handleRateLimit(writer http.ResponseWriter, r *http.Request) {
+	requestID := writer.Header().Get("X-Request-ID")
+	budgets, err := s.detector.RateLimits(r.Context())
+	if err != nil {
+		s.logger.Error("Failed to fetch rate limits", "request_id", requestID, "error", err)
+		http.Error(writer, "Failed to fetch rate limits", http.StatusBadGateway)
+		return
+	}
+	writer.Header().Set("Content-Type", "application/json")
+	writer.Header().Set("Cache-Control", "no-store")
+	if err := json.NewEncoder(writer).Encode(struct {
+		Policy  github.RateLimitPolicy `json:"policy"`
+		Budgets []github.RateLimit     `json:"budgets"`
+	}{s.detector.RateLimitPolicy(), budgets}); err != nil {
+		s.logger.Error("Failed to write rate limits", "request_id", requestID, "error", err)
+	}
+}
+
+// readOptOutUsername decodes the {"username": ...} body of the opt-out endpoints, answering bad requests itself.
+func (s *server) readOptOutUsername(writer http.ResponseWriter, request *http.Request) (string, bool) {
+	clientIP := strings.Split(request.RemoteAddr, ":")[0]
+	if !s.limiter.allow(clientIP) {
+		http.Error(writer, "Rate limit exceeded", http.StatusTooManyRequests)
+		return "", false
+	}
+	var req struct {
+		Username string `json:"username"`
+	}
+	if err := json.NewDecoder(request.Body).Decode(&req); err != nil {
+		http.Error(writer, "Invalid request", http.StatusBadRequest)
+		return "", false
+	}
+	username := strings.TrimSpace(req.Username)
+	if !gutz.IsValidGitHubUsername(username) {
+		http.Error(writer, "Invalid username", http.StatusBadRequest)
+		return "", false
+	}
+	return username, true
+}
+
+// writeJSON sends a JSON response with the given status.
+func (s *server) writeJSON(writer http.ResponseWriter, status int, v any) {
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(status)
+	if err := json.NewEncoder(writer).Encode(v); err != nil {
+		s.logger.Error("Failed to encode response", "request_id", writer.Header().Get("X-Request-ID"), "error", err)
+	}
+}
+
+// handleOptOut issues the token a user publishes on their GitHub account to prove they control it.
+// POST /api/v1/opt-out/verify then checks for it.
+func (s *server) handleOptOut(writer http.ResponseWriter, request *http.Request) {
+	requestID := writer.Header().Get("X-Request-ID")
+	username, ok := s.readOptOutUsername(writer, request)
+	if !ok {
+		return
+	}
+	if entry, found := s.optOuts.Entry(username); found {
+		s.writeJSON(writer, http.StatusOK, map[string]any{
+			"status":       "opted_out",
+			"username":     entry.Username,
+			"opted_out_at": entry.OptedOutAt,
+		})
+		return
+	}
+
+	challenge, err := s.optOuts.Challenge(username)
+	if err != nil {
+		s.logger.Error("Failed to issue opt-out challenge", "request_id", requestID, "username", username, "error", err)
+		http.Error(writer, "Failed to issue challenge", http.StatusInternalServerError)
+		return
+	}
+	s.logger.Info("Opt-out challenge issued", "request_id", requestID, "username", username)
+	s.writeJSON(writer, http.StatusOK, map[string]any{
+		"status":     "pending",
+		"username":   username,
+		"token":      challenge.Token,
+		"expires_at": challenge.ExpiresAt,
+		"instructions": fmt.Sprintf("Prove this is your account: create a public gist whose description or file name is %s, "+
+			"or add it anywhere in the README of %s/%s/%s. Then POST {\"username\": %q} to /api/v1/opt-out/verify.",
+			challenge.Token, s.githubURL, username, username, username),
+	})
+}
+
+// handleOptOutVerify adds a user to the opt-out registry once their challenge token is published, and
+// purges every stored detection of them.
+func (s *server) handleOptOutVerify(writer http.ResponseWriter, request *http.Request) {
+	requestID := writer.Header().Get("X-Request-ID")
+	username, ok := s.readOptOutUsername(writer, request)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(request.Context(), 30*time.Second)
+	defer cancel()
+	proof, err := s.detector.VerifyOptOut(ctx, username)
+	switch {
+	case errors.Is(err, optout.ErrNoChallenge):
+		s.writeJSON(writer, http.StatusBadRequest, detectErrorResponse{
+			Error: "No pending opt-out request", Details: "Request a token from POST /api/v1/opt-out first.", Code: "NO_CHALLENGE",
+		})
+		return
+	case errors.Is(err, gutz.ErrProofNotFound):
+		s.logger.Info("Opt-out proof not found", "request_id", requestID, "username", username, "error", err)
+		s.writeJSON(writer, http.StatusUnprocessableEntity, detectErrorResponse{
+			Error: "Token not found", Details: "Publish the token in a public gist or your profile README, then try again.", Code: "PROOF_NOT_FOUND",
+		})
+		return
+	case err != nil:
+		s.logger.Error("Opt-out verification failed", "request_id", requestID, "username", username, "error", err)
+		http.Error(writer, "Verification failed", http.StatusInternalServerError)
+		return
+	}
+
+	s.dropResult(username)
+	if s.history != nil {
+		if err := s.history.Delete(username); err != nil {
+			s.logger.Error("Failed to delete history of opted-out user", "request_id", requestID, "username", username, "error", err)
+		}
+	}
+	if s.jobs != nil {
+		if err := s.jobs.Forget(username); err != nil {
+			s.logger.Error("Failed to purge job results of opted-out user", "request_id", requestID, "username", username, "error", err)
+		}
+	}
+	s.logger.Info("User opted out", "request_id", requestID, "username", username, "proof", proof)
+	s.writeJSON(writer, http.StatusOK, map[string]any{"status": "opted_out", "username": username, "proof": proof})
+}
+
+// detectErrorResponse is the JSON body sent when detection fails.
+type detectErrorResponse struct {
+	ResetAt    *time.Time `json:"reset_at,omitempty"` // When a GitHub rate limit lifts
+	Error      string     `json:"error"`
+	Details    string     `json:"details,omitempty"`
+	Code       string     `json:"code,omitempty"`
+	RetryAfter int        `json:"retry_after,omitempty"` // Seconds until a GitHub rate limit lifts
+}
+
+// classifyDetectError maps a detection error to an HTTP status, a user-facing explanation, and a log message.
+func classifyDetectError(err error, username string) (int, detectErrorResponse, string) {
+	var rlErr *github.RateLimitError
+	switch {
+	case errors.Is(err, gutz.ErrOptedOut):
+		return http.StatusUnavailableForLegalReasons, detectErrorResponse{
+			Error:   "User has opted out",
+			Details: fmt.Sprintf("'%s' has asked not to be profiled, so no detection is available.", username),
+			Code:    "OPTED_OUT",
+		}, "Opted-out user requested"
+	case errors.As(err, &rlErr):
+		reset := rlErr.Reset
+		return http.StatusTooManyRequests, detectErrorResponse{
+			Error:      "GitHub API rate limit exceeded",
+			Details:    fmt.Sprintf("We've used up our GitHub %s budget. It resets at %s UTC.", rlErr.Resource, reset.UTC().Format("15:04")),
+			Code:       "GITHUB_RATE_LIMIT",
+			ResetAt:    &reset,
+			RetryAfter: max(1, int(math.Ceil(time.Until(reset).Seconds()))),
+		}, "GitHub rate limit hit"
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout, detectErrorResponse{
+			Error:   "Detection took too long",
+			Details: "The analysis exceeded the 30-second timeout. This usually happens with very active users. Please try again.",
+			Code:    "TIMEOUT",
+		}, "Detection timeout"
+	case errors.Is(err, context.Canceled):
+		return http.StatusRequestTimeout, detectErrorResponse{
+			Error:   "Request was canceled",
+			Details: "The request was canceled before completion. Please try again.",
+			Code:    "CANCELED",
+		}, "Detection canceled"
+	case strings.Contains(err.Error(), "not found"):
+		return http.StatusNotFound, detectErrorResponse{
+			Error:   "GitHub user not found",
+			Details: fmt.Sprintf("The username '%s' doesn't exist on GitHub. Please check the spelling.", username),
+			Code:    "USER_NOT_FOUND",
+		}, "User not found"
+	case strings.Contains(err.Error(), "unable to fetch GitHub profile"):
+		resp := detectErrorResponse{
+			Error:   "Unable to fetch GitHub profile",
+			Details: "GitHub's API is temporarily unavailable. Please try again in a moment.",
+			Code:    "GITHUB_API_ERROR",
+		}
+		if strings.Contains(err.Error(), "permission") || strings.Contains(err.Error(), "scope") {
+			resp.Details = "The GitHub token doesn't have required permissions. Please ensure the token has 'read:user' scope."
+			resp.Code = "INSUFFICIENT_PERMISSIONS"
+		}
+		return http.StatusBadGateway, resp, "GitHub API error"
+	case strings.Contains(err.Error(), "Gemini"):
+		return http.StatusServiceUnavailable, detectErrorResponse{
+			Error:   "AI analysis service unavailable",
+			Details: "The Gemini AI service is temporarily unavailable. Detection will use fallback methods.",
+			Code:    "GEMINI_ERROR",
+		}, "Gemini API error"
+	default:
+		return http.StatusInternalServerError, detectErrorResponse{
+			Error:   "Detection failed",
+			Details: "An unexpected error occurred during timezone detection. Please try again.",
+			Code:    "INTERNAL_ERROR",
+		}, "Detection failed"
+	}
+}
+
+// storeResult encodes result for the API and saves it to the memory and disk caches and the user's history.
+func (s *server) storeResult(username string, result *gutz.Result) ([]byte, error) {
+	// A user who opted out while this detection ran must not be stored
+	persist := !s.detector.OptedOut(username)
+
+	// Clear sensitive data
+	if !*verbose {
+		result.GeminiPrompt = ""
+	}
+
+	if persist && s.history != nil {
+		if err := s.history.Append(history.FromResult(result)); err != nil {
+			s.logger.Warn("Failed to record history", "username", username, "error", err)
+		}
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+
+	// Cache result
+	if !persist {
+		return data, nil
+	}
+	s.cache.Set("detect:"+username, data)
+	if s.diskCache != nil {
+		go s.diskCache.save(username, data)
+	}
+	return data, nil
+}
+
+// cachedResult returns a previously stored detection for username and where it was found.
+func (s *server) cachedResult(username string) (data []byte, source string) {
+	if s.detector.OptedOut(username) {
+		return nil, ""
+	}
+	cacheKey := "detect:" + username
+	if data, found := s.cache.Get(cacheKey); found {
+		return data, "memory-hit"
+	}
+	if s.diskCache != nil {
+		if data := s.diskCache.load(username); data != nil {
+			s.cache.Set(cacheKey, data)
+			return data, "disk-hit"
+		}
+	}
+	return nil, ""
+}
+
+// invalidate drops every cached result and HTTP response for username and returns a context whose
+// detection fetches everything again.
+func (s *server) invalidate(ctx context.Context, requestID, username string) context.Context {
+	s.dropResult(username)
+	if _, err := s.detector.Invalidate(ctx, username); err != nil {
+		s.logger.Warn("Failed to invalidate cached responses",
+			"request_id", requestID,
+			"username", username,
+			"error", err)
+	}
+	return gutz.WithRefresh(ctx)
+}
+
+// dropResult removes username's stored detection from the memory and disk caches.
+func (s *server) dropResult(username string) {
+	s.cache.Delete("detect:" + username)
+	if s.diskCache != nil {
+		s.diskCache.remove(username)
+	}
+}
+
+// isAdmin reports whether the request carries the --admin-token bearer token.
+func (s *server) isAdmin(r *http.Request) bool {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return s.adminToken != "" && ok && subtle.ConstantTimeCompare([]byte(token), []byte(s.adminToken)) == 1
+}
+
+// requireAdmin guards an admin endpoint with the --admin-token bearer token or an API key with the admin scope.
+func (s *server) requireAdmin(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.authorize(w, r, apikey.ScopeAdmin) {
+			next(w, r)
+		}
+	}
+}
+
+// handleTokens reports per-token usage of the GitHub token pool. Tokens are identified by fingerprint only.
+func (s *server) handleTokens(w http.ResponseWriter, _ *http.Request) {
+	requestID := w.Header().Get("X-Request-ID")
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if err := json.NewEncoder(w).Encode(struct {
+		Tokens []github.TokenUsage `json:"tokens"`
+	}{s.detector.TokenUsage()}); err != nil {
+		s.logger.Error("Failed to encode response", "request_id", requestID, "error", err)
+	}
+}
+
+// handleKeys reports per-key usage of the API keys. Keys are identified by name only.
+func (s *server) handleKeys(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Cache-Control", "no-store")
+	s.writeJSON(w, http.StatusOK, struct {
+		Keys []apikey.Usage `json:"keys"`
+	}{s.keys.Usage()})
+}
+
 func (s *server) handleCleanup(w http.ResponseWriter, r *http.Request) {
 	// Get request ID from header (set by wrap middleware)
 	requestID := w.Header().Get("X-Request-ID")
@@ -645,8 +1149,9 @@ func (s *server) handleCleanup(w http.ResponseWriter, r *http.Request) {
 }
 
 type diskCacheHandler struct {
-	logger *slog.Logger
-	dir    string
+	logger  *slog.Logger
+	dir     string
+	privacy gutz.PrivacyLevel // Results are redacted again on load, in case they were saved under a laxer level
 }
 
 func (d *diskCacheHandler) path(username string) string {
@@ -664,7 +1169,8 @@ func (d *diskCacheHandler) path(username string) string {
 	default:
 		dir2 = "_"
 	}
-	return filepath.Join(d.dir, "v1", dir1, dir2, username+".json.gz")
+	// Results are stored per schema version so a schema change never serves an old encoding
+	return filepath.Join(d.dir, fmt.Sprintf("schema%d", gutz.SchemaVersion), dir1, dir2, username+".json.gz")
 }
 
 func (d *diskCacheHandler) load(username string) []byte {
@@ -692,7 +1198,19 @@ func (d *diskCacheHandler) load(username string) []byte {
 	if err != nil {
 		return nil
 	}
+	if d.privacy == gutz.PrivacyFull {
+		return data
+	}
 
+	var result gutz.Result
+	if err := json.Unmarshal(data, &result); err != nil {
+		d.logger.Debug("Failed to decode cached result", "username", username, "error", err)
+		return nil
+	}
+	data, err = json.Marshal(result.Redact(d.privacy))
+	if err != nil {
+		return nil
+	}
 	return data
 }
 
@@ -719,27 +1237,43 @@ func (d *diskCacheHandler) save(username string, data []byte) {
 	}
 }
 
+func (d *diskCacheHandler) remove(username string) {
+	if err := os.Remove(d.path(username)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		d.logger.Debug("Failed to remove cached result", "username", username, "error", err)
+	}
+}
+
+// cleanup removes cached results older than 28 days, of every schema version. It only walks the
+// schema* result directories: the opt-out registry, history, jobs and HTTP cache share the cache
+// directory and must survive.
 func (d *diskCacheHandler) cleanup() int {
 	count := 0
 	cutoff := time.Now().Add(-28 * 24 * time.Hour)
 
-	if err := filepath.Walk(d.dir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-		if info.IsDir() {
-			return nil
-		}
-		if info.ModTime().Before(cutoff) {
-			if err := os.Remove(path); err != nil {
-				d.logger.Debug("Failed to remove old cache file", "path", path, "error", err)
-			} else {
-				count++
+	roots, err := filepath.Glob(filepath.Join(d.dir, "schema*"))
+	if err != nil {
+		d.logger.Error("Cache cleanup glob failed", "error", err)
+		return 0
+	}
+	for _, root := range roots {
+		if err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
 			}
+			if info.IsDir() || !strings.HasSuffix(path, ".json.gz") {
+				return nil
+			}
+			if info.ModTime().Before(cutoff) {
+				if err := os.Remove(path); err != nil {
+					d.logger.Debug("Failed to remove old cache file", "path", path, "error", err)
+				} else {
+					count++
+				}
+			}
+			return nil
+		}); err != nil {
+			d.logger.Error("Cache cleanup walk failed", "root", root, "error", err)
 		}
-		return nil
-	}); err != nil {
-		d.logger.Error("Cache cleanup walk failed", "error", err)
 	}
 
 	return count
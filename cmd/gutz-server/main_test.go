@@ -0,0 +1,326 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/codeGROOVE-dev/guTZ/pkg/apikey"
+	"github.com/codeGROOVE-dev/guTZ/pkg/github"
+	"github.com/codeGROOVE-dev/guTZ/pkg/gutz"
+	"github.com/codeGROOVE-dev/guTZ/pkg/history"
+	"github.com/codeGROOVE-dev/guTZ/pkg/httpreplay"
+	"github.com/codeGROOVE-dev/guTZ/pkg/jobs"
+	"github.com/codeGROOVE-dev/guTZ/pkg/optout"
+	"github.com/maypok86/otter"
+)
+
+// writeOld creates a file under dir with a modification time well past the cleanup cutoff.
+func writeOld(t *testing.T, dir, name string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("{}"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-60 * 24 * time.Hour)
+	if err := os.Chtimes(path, old, old); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestDiskCacheCleanup(t *testing.T) {
+	dir := t.TempDir()
+	d := &diskCacheHandler{dir: dir, logger: slog.New(slog.DiscardHandler)}
+	stale := []string{
+		writeOld(t, dir, "schema2/al/ic/alice.json.gz"),
+		writeOld(t, dir, "schema1/bo/b/bob.json.gz"),
+	}
+	kept := []string{
+		writeOld(t, dir, "optout.json"),
+		// History exists to compare detections far apart in time, so its age is no reason to drop it
+		writeOld(t, dir, "history/v1/carol.jsonl"),
+		writeOld(t, dir, "jobs/0123456789abcdef.json"),
+		writeOld(t, dir, "jobs/0123456789abcdef.ndjson"),
+	}
+
+	if n := d.cleanup(); n != len(stale) {
+		t.Errorf("cleanup removed %d files, want %d", n, len(stale))
+	}
+	for _, path := range stale {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("stale result %s survived cleanup", path)
+		}
+	}
+	for _, path := range kept {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("cleanup removed %s: %v", path, err)
+		}
+	}
+}
+
+const testAdminToken = "admin-secret"
+
+// newTestServer starts a server whose detector has opts, with keys in its keyring. Every test server
+// has history, a running job manager and an opt-out registry, all in memory or under t.TempDir().
+func newTestServer(t *testing.T, keys []apikey.Key, opts ...gutz.Option) (*server, *httptest.Server) {
+	t.Helper()
+	logger := slog.New(slog.DiscardHandler)
+	optOuts, err := optout.Open("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyring, err := apikey.New(keys...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	historyStore, err := history.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache, err := otter.MustBuilder[string, []byte](100).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	viewerResults, err := otter.MustBuilder[string, []byte](100).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	opts = append([]gutz.Option{gutz.WithMemoryOnlyCache(), gutz.WithOptOutRegistry(optOuts)}, opts...)
+	detector := gutz.NewWithLogger(ctx, logger, opts...)
+	s := &server{
+		detector:      detector,
+		cache:         cache,
+		history:       historyStore,
+		optOuts:       optOuts,
+		keys:          keyring,
+		limiter:       newRateLimiter(15, time.Minute),
+		refreshes:     newRateLimiter(2, 10*time.Minute),
+		logger:        logger,
+		githubURL:     "https://github.example",
+		adminToken:    testAdminToken,
+		viewerResults: viewerResults,
+		oauthHTTP:     http.DefaultClient,
+	}
+	if s.jobs, err = jobs.Open("", 1, s.detectForJob, logger); err != nil {
+		t.Fatal(err)
+	}
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		s.jobs.Run(ctx)
+	}()
+
+	srv := httptest.NewServer(s.wrap(s.routes()))
+	t.Cleanup(func() {
+		srv.Close()
+		cancel()
+		<-stopped
+		if err := detector.Close(); err != nil {
+			t.Errorf("closing detector: %v", err)
+		}
+	})
+	return s, srv
+}
+
+// replayDetector returns detector options that answer from the golden archive, in which octocat works in UTC-7.
+func replayDetector(t *testing.T) []gutz.Option {
+	t.Helper()
+	archive, err := httpreplay.Load("../../pkg/gutz/testdata/replay/mountain.json.gz")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	return []gutz.Option{gutz.WithFixtureArchive(archive), gutz.WithGitHubToken("test-token")}
+}
+
+// do sends a request to srv with an optional bearer token and JSON body, without following redirects.
+func do(t *testing.T, srv *httptest.Server, method, path, token string, body any) *http.Response {
+	t.Helper()
+	var reader io.Reader = http.NoBody
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(context.Background(), method, srv.URL+path, reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resp.Body.Close() }) //nolint:errcheck // the body is fully read or irrelevant
+	return resp
+}
+
+// decode reads resp's JSON body into a map.
+func decode(t *testing.T, resp *http.Response) map[string]any {
+	t.Helper()
+	var got map[string]any
+	if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatalf("decoding %s response: %v", resp.Request.URL.Path, err)
+	}
+	return got
+}
+
+type sseEvent struct {
+	name string
+	data string
+}
+
+// readEvents reads a Server-Sent Events stream until the server closes it.
+func readEvents(t *testing.T, resp *http.Response) []sseEvent {
+	t.Helper()
+	var events []sseEvent
+	var ev sseEvent
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			events = append(events, ev)
+			ev = sseEvent{}
+		case strings.HasPrefix(line, "event: "):
+			ev.name = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			ev.data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("reading event stream: %v", err)
+	}
+	return events
+}
+
+func TestDetectStream(t *testing.T) {
+	s, srv := newTestServer(t, nil, replayDetector(t)...)
+
+	resp := do(t, srv, http.MethodGet, "/api/v1/detect/stream?username=octocat", "", nil)
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("status = %d, content type %q; want an event stream", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	events := readEvents(t, resp)
+	if len(events) < 2 {
+		t.Fatalf("got %d events, want progress followed by a result", len(events))
+	}
+	last := events[len(events)-1]
+	for _, ev := range events[:len(events)-1] {
+		if ev.name != "progress" {
+			t.Errorf("event %q before the end of the stream, want only progress events", ev.name)
+		}
+	}
+	if last.name != "result" || !strings.Contains(last.data, `"timezone":"UTC-7"`) {
+		t.Errorf("last event = %s %s, want the UTC-7 result", last.name, last.data)
+	}
+
+	// A second detection is answered from the cache with the result alone
+	events = readEvents(t, do(t, srv, http.MethodGet, "/api/v1/detect/stream?username=octocat", "", nil))
+	if len(events) != 1 || events[0].name != "result" {
+		t.Errorf("cached stream = %v, want a single result event", events)
+	}
+
+	if err := s.optOuts.Add("hubot", "https://gist.github.example/hubot/1"); err != nil {
+		t.Fatal(err)
+	}
+	events = readEvents(t, do(t, srv, http.MethodGet, "/api/v1/detect/stream?username=hubot", "", nil))
+	if len(events) != 1 || events[0].name != "failed" || !strings.Contains(events[0].data, "OPTED_OUT") {
+		t.Errorf("stream for an opted-out user = %v, want a single failed event", events)
+	}
+
+	if resp := do(t, srv, http.MethodGet, "/api/v1/detect/stream?username=-bad-", "", nil); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("invalid username status = %d, want 400", resp.StatusCode)
+	}
+}
+
+func TestOptOutVerify(t *testing.T) {
+	var mu sync.Mutex
+	var published string // Gist description alice has published
+	gh := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v3/users/alice/gists" {
+			http.NotFound(w, r)
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		gists := []map[string]any{}
+		if published != "" {
+			gists = append(gists, map[string]any{"description": published, "html_url": "https://gist.github.example/alice/1", "public": true})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(gists) //nolint:errcheck // the test fails on a bad body
+	}))
+	defer gh.Close()
+	s, srv := newTestServer(t, nil, gutz.WithGitHubBaseURLs(github.EnterpriseBaseURLs(gh.URL)))
+
+	// Everything the server keeps about alice, to be purged
+	if _, err := s.storeResult("alice", &gutz.Result{Username: "alice", Timezone: "Europe/Berlin"}); err != nil {
+		t.Fatal(err)
+	}
+	resp := do(t, srv, http.MethodPost, "/api/v1/jobs", testAdminToken, map[string]any{"usernames": []string{"alice"}})
+	job := waitJob(t, srv, resp.Header.Get("Location"))
+
+	alice := map[string]string{"username": "alice"}
+	if resp := do(t, srv, http.MethodPost, "/api/v1/opt-out/verify", "", alice); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("verify without a challenge status = %d, want 400", resp.StatusCode)
+	}
+	challenge := decode(t, do(t, srv, http.MethodPost, "/api/v1/opt-out", "", alice))
+	token, _ := challenge["token"].(string) //nolint:errcheck // checked below
+	if challenge["status"] != "pending" || token == "" {
+		t.Fatalf("challenge = %v, want a pending token", challenge)
+	}
+	if resp := do(t, srv, http.MethodPost, "/api/v1/opt-out/verify", "", alice); resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("verify before publishing status = %d, want 422", resp.StatusCode)
+	}
+
+	mu.Lock()
+	published = "gutz opt-out " + token
+	mu.Unlock()
+	resp = do(t, srv, http.MethodPost, "/api/v1/opt-out/verify", "", alice)
+	if got := decode(t, resp); resp.StatusCode != http.StatusOK || got["status"] != "opted_out" || got["proof"] != "https://gist.github.example/alice/1" {
+		t.Fatalf("verify = %d %v, want opted out with the gist as proof", resp.StatusCode, got)
+	}
+
+	if _, found := s.cache.Get("detect:alice"); found {
+		t.Error("cached result of alice survived the opt-out")
+	}
+	if snaps, err := s.history.Snapshots("alice"); err != nil || len(snaps) != 0 {
+		t.Errorf("history of alice = %v, %v; want it deleted", snaps, err)
+	}
+	if resp := do(t, srv, http.MethodGet, "/api/v1/history/alice", "", nil); resp.StatusCode != http.StatusUnavailableForLegalReasons {
+		t.Errorf("history status = %d, want 451", resp.StatusCode)
+	}
+	body, err := io.ReadAll(do(t, srv, http.MethodGet, "/api/v1/jobs/"+job.ID+"/results", "", nil).Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(body), "alice") || strings.Contains(string(body), "Europe/Berlin") {
+		t.Errorf("job results = %s, want alice left out", body)
+	}
+	if got := decode(t, do(t, srv, http.MethodPost, "/api/v1/opt-out", "", alice)); got["status"] != "opted_out" {
+		t.Errorf("opt-out after verifying = %v, want opted_out", got)
+	}
+}
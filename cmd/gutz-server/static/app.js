@@ -1,5 +1,6 @@
 let currentUsername = '';
 let currentMap = null; // Track current map instance
+const githubURL = document.body.dataset.githubUrl || 'https://github.com'; // GitHub Enterprise Server when configured
 
 window.addEventListener('load', function() {
     // Check if username is already filled (from server-side template)
@@ -45,35 +46,8 @@ async function detectUser(username) {
     resultDiv.classList.remove('show');
 
     try {
-        const response = await fetch('/api/v1/detect', {
-            method: 'POST',
-            headers: { 'Content-Type': 'application/json' },
-            body: JSON.stringify({username})
-        });
-
-        if (!response.ok) {
-            let errorMessage = 'Detection failed';
-            let errorDetails = '';
-            
-            try {
-                const errorData = await response.json();
-                errorMessage = errorData.error || errorMessage;
-                errorDetails = errorData.details || '';
-            } catch (e) {
-                // Fallback if response isn't JSON
-                const errorText = await response.text();
-                if (errorText) {
-                    errorMessage = errorText;
-                }
-            }
-            
-            const error = new Error(errorMessage);
-            error.details = errorDetails;
-            error.status = response.status;
-            throw error;
-        }
-
-        const data = await response.json();
+        const streaming = window.ReadableStream && window.TextDecoder;
+        const data = streaming ? await detectViaStream(username) : await detectViaPost(username);
         updateURL(username);
         displayResults(data);
 
@@ -109,6 +83,138 @@ async function detectUser(username) {
     }
 }
 
+// detectViaStream runs detection over Server-Sent Events so the loading message can show real progress.
+// The stream is read with fetch rather than EventSource so that HTTP errors such as rate limiting are
+// reported instead of retried. Only a server without the stream endpoint (404) falls back to POST;
+// a dropped stream is reported as an error rather than starting a second detection.
+async function detectViaStream(username) {
+    const response = await fetch('/api/v1/detect/stream?username=' + encodeURIComponent(username), {
+        headers: { 'Accept': 'text/event-stream' }
+    });
+    if (response.status === 404) {
+        return detectViaPost(username);
+    }
+    if (!response.ok) {
+        throw await responseError(response);
+    }
+
+    const reader = response.body.getReader();
+    const decoder = new TextDecoder();
+    let buffer = '';
+    for (;;) {
+        const { value, done } = await reader.read();
+        if (done) {
+            break;
+        }
+        buffer += decoder.decode(value, { stream: true });
+
+        let end;
+        while ((end = buffer.indexOf('\n\n')) >= 0) {
+            const event = parseStreamEvent(buffer.slice(0, end));
+            buffer = buffer.slice(end + 2);
+
+            if (event.name === 'progress') {
+                try {
+                    progressMessage = progressLabel(JSON.parse(event.data));
+                } catch (err) {
+                    // Ignore malformed progress; the result event is what matters
+                }
+            } else if (event.name === 'result') {
+                reader.cancel();
+                try {
+                    return JSON.parse(event.data);
+                } catch (err) {
+                    throw new Error('Invalid response from server');
+                }
+            } else if (event.name === 'failed') {
+                reader.cancel();
+                throw streamError(event.data);
+            }
+        }
+    }
+    throw new Error('Lost connection to server');
+}
+
+// parseStreamEvent splits one Server-Sent Events block into its event name and data.
+function parseStreamEvent(block) {
+    const event = { name: 'message', data: '' };
+    const data = [];
+    for (const line of block.split('\n')) {
+        if (line.startsWith('event:')) {
+            event.name = line.slice(6).trim();
+        } else if (line.startsWith('data:')) {
+            data.push(line.slice(5).trimStart());
+        }
+    }
+    event.data = data.join('\n');
+    return event;
+}
+
+// streamError converts a "failed" event payload into an Error shaped like the POST endpoint's errors.
+function streamError(payload) {
+    let errorData = {};
+    try {
+        errorData = JSON.parse(payload);
+    } catch (err) {
+        // Fall through with a generic message
+    }
+    const error = new Error(errorData.error || 'Detection failed');
+    error.details = errorData.details || '';
+    if (errorData.code === 'USER_NOT_FOUND') error.status = 404;
+    if (errorData.code === 'GITHUB_RATE_LIMIT') error.status = 429;
+    if (errorData.code === 'TIMEOUT') error.status = 504;
+    return error;
+}
+
+// responseError builds an Error from a non-OK HTTP response, keeping its status for display.
+async function responseError(response) {
+    let errorMessage = 'Detection failed';
+    let errorDetails = '';
+
+    const body = await response.text();
+    try {
+        const errorData = JSON.parse(body);
+        errorMessage = errorData.error || errorMessage;
+        errorDetails = errorData.details || '';
+    } catch (e) {
+        // Fallback if response isn't JSON
+        if (body.trim()) {
+            errorMessage = body.trim();
+        }
+    }
+
+    const error = new Error(errorMessage);
+    error.details = errorDetails;
+    error.status = response.status;
+    return error;
+}
+
+async function detectViaPost(username) {
+    const response = await fetch('/api/v1/detect', {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json' },
+        body: JSON.stringify({username})
+    });
+
+    if (!response.ok) {
+        throw await responseError(response);
+    }
+
+    return response.json();
+}
+
+function progressLabel(progress) {
+    switch (progress.phase) {
+        case 'profile_fetched': return '📥 ' + progress.message;
+        case 'timeline_built': return '🧵 ' + progress.message;
+        case 'candidates_ready': return '🌍 ' + progress.message;
+        case 'location_geocoded': return '📍 ' + progress.message;
+        case 'llm_verdict': return '🤖 ' + progress.message;
+        case 'verification': return '✅ ' + progress.message;
+        default: return progress.message;
+    }
+}
+
 document.getElementById('detectForm').addEventListener('submit', async (e) => {
     e.preventDefault();
     const username = document.getElementById('username').value.trim();
@@ -150,10 +256,10 @@ function displayResults(data) {
     
     if (data.name) {
         // Apple-style: "Full Name (username)" with GitHub link on username
-        userDisplay = `${data.name} (<a href="https://github.com/${data.username}" target="_blank" style="color: inherit; text-decoration: none;">${data.username}</a>)`;
+        userDisplay = `${data.name} (<a href="${githubURL}/${data.username}" target="_blank" style="color: inherit; text-decoration: none;">${data.username}</a>)`;
     } else {
         // Just username with GitHub link if no name available
-        userDisplay = `<a href="https://github.com/${data.username}" target="_blank" style="color: inherit; text-decoration: none;">${data.username}</a>`;
+        userDisplay = `<a href="${githubURL}/${data.username}" target="_blank" style="color: inherit; text-decoration: none;">${data.username}</a>`;
     }
     userElement.innerHTML = userDisplay;
     
@@ -306,7 +412,7 @@ function displayResults(data) {
             
             // Create a link to the GitHub organization
             const orgLink = document.createElement('a');
-            orgLink.href = `https://github.com/${org.name}`;
+            orgLink.href = `${githubURL}/${org.name}`;
             orgLink.target = '_blank';
             orgLink.style.color = 'inherit';
             orgLink.style.textDecoration = 'none';
@@ -1388,6 +1494,7 @@ document.addEventListener('keydown', (e) => {
 let rotatingInterval = null;
 let messageIndex = 0;
 let startTime = 0;
+let progressMessage = null; // Latest phase reported by the streaming endpoint
 
 const funkyMessages = [
     '🔍 Stalking GitHub profiles...',
@@ -1435,6 +1542,7 @@ const funkyMessages = [
 function startRotatingMessages(loadingEl) {
     startTime = Date.now();
     messageIndex = 0;
+    progressMessage = null;
     
     // Show initial message
     updateMessage(loadingEl);
@@ -1448,8 +1556,11 @@ function startRotatingMessages(loadingEl) {
 function updateMessage(loadingEl) {
     const elapsed = Math.floor((Date.now() - startTime) / 1000);
     
-    // After 10 seconds, show rate limit message
-    if (elapsed >= 10) {
+    // Real progress from the server beats the funky messages
+    if (progressMessage) {
+        loadingEl.textContent = `${progressMessage} (${elapsed}s)`;
+    } else if (elapsed >= 10) {
+        // After 10 seconds without progress, show rate limit message
         loadingEl.innerHTML = `Sorry, we probably got GitHub rate limited ... (${elapsed}s)`;
     } else {
         const currentMessage = funkyMessages[messageIndex % funkyMessages.length];
@@ -0,0 +1,302 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/codeGROOVE-dev/guTZ/pkg/gutz"
+	"github.com/codeGROOVE-dev/guTZ/pkg/history"
+	"github.com/codeGROOVE-dev/guTZ/pkg/httpreplay"
+)
+
+// batchResult holds the outcome of detecting a single user in batch mode.
+type batchResult struct {
+	err      error
+	result   *gutz.Result
+	username string
+	duration time.Duration
+}
+
+// batchDetector is the part of *gutz.Detector that batch detection uses.
+type batchDetector interface {
+	DetectWithProgress(ctx context.Context, username string, fn gutz.ProgressFunc) (*gutz.Result, error)
+	Invalidate(ctx context.Context, username string) (int, error)
+	WaitForRateLimit(ctx context.Context) error
+}
+
+// collectUsernames merges usernames from the command line with those read from a file
+// (or stdin when path is "-"), preserving order and dropping duplicates.
+func collectUsernames(args []string, path string) ([]string, error) {
+	names := append([]string{}, args...)
+
+	if path != "" {
+		var r io.Reader
+		if path == "-" {
+			r = os.Stdin
+		} else {
+			f, err := os.Open(path)
+			if err != nil {
+				return nil, fmt.Errorf("opening username file: %w", err)
+			}
+			defer func() {
+				if err := f.Close(); err != nil {
+					slog.Debug("failed to close username file", "error", err)
+				}
+			}()
+			r = f
+		}
+
+		fileNames, err := readUsernames(r)
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, fileNames...)
+	}
+
+	seen := make(map[string]bool, len(names))
+	var unique []string
+	for _, name := range names {
+		key := strings.ToLower(name)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		unique = append(unique, name)
+	}
+	return unique, nil
+}
+
+// readUsernames reads whitespace- or comma-separated usernames, ignoring blank lines and # comments.
+func readUsernames(r io.Reader) ([]string, error) {
+	var names []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if idx := strings.Index(line, "#"); idx >= 0 {
+			line = line[:idx]
+		}
+		for _, field := range strings.FieldsFunc(line, func(r rune) bool {
+			return r == ',' || r == ' ' || r == '\t'
+		}) {
+			names = append(names, strings.TrimPrefix(strings.TrimSpace(field), "@"))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading usernames: %w", err)
+	}
+	return names, nil
+}
+
+// linkIdentities splits identities such as "alice gitlab:alice bob" into the GitHub users to detect
+// and, for each, the accounts on other forges listed after it.
+func linkIdentities(names []string) (usernames []string, links map[string][]gutz.Identity, err error) {
+	links = make(map[string][]gutz.Identity)
+	for _, name := range names {
+		id, err := gutz.ParseIdentity(name)
+		if err != nil {
+			return nil, nil, err
+		}
+		if id.Forge == gutz.ForgeGitHub {
+			usernames = append(usernames, id.Username)
+			continue
+		}
+		if len(usernames) == 0 {
+			return nil, nil, fmt.Errorf("%s must follow the GitHub user it belongs to, e.g. github:alice %s", id, id)
+		}
+		owner := usernames[len(usernames)-1]
+		links[owner] = append(links[owner], id)
+	}
+	return usernames, links, nil
+}
+
+// runBatch detects timezones for many users with bounded concurrency, sharing a single detector
+// (and therefore a single HTTP cache). Results are returned in the same order as usernames.
+// progress is called as each user finishes; phase, if non-nil, is called for each detection phase.
+func runBatch(ctx context.Context, detector batchDetector, usernames []string, concurrency int,
+	timeout time.Duration, progress func(batchResult), phase func(username string, ev gutz.ProgressEvent),
+) []batchResult {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	results := make([]batchResult, len(usernames))
+	sem := make(chan struct{}, concurrency)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for i, username := range usernames {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				results[i] = batchResult{username: username, err: ctx.Err()}
+				return
+			}
+
+			// Wait out an exhausted GitHub budget before the per-user deadline starts ticking
+			if err := detector.WaitForRateLimit(ctx); err != nil {
+				results[i] = batchResult{username: username, err: err}
+				return
+			}
+
+			userCtx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			var onProgress gutz.ProgressFunc
+			if phase != nil {
+				onProgress = func(ev gutz.ProgressEvent) {
+					mu.Lock()
+					defer mu.Unlock()
+					phase(username, ev)
+				}
+			}
+
+			start := time.Now()
+			results[i] = detectOne(refreshContext(gutz.WithLinkedIdentities(userCtx, linked[username]...), detector, username), detector, username, onProgress)
+			results[i].duration = time.Since(start)
+
+			if progress != nil {
+				mu.Lock()
+				progress(results[i])
+				mu.Unlock()
+			}
+		}()
+	}
+
+	wg.Wait()
+	return results
+}
+
+// detectOne runs a single detection, converting a panic into an error so one bad user
+// cannot take down the rest of the batch.
+func detectOne(ctx context.Context, detector batchDetector, username string, onProgress gutz.ProgressFunc) (br batchResult) {
+	br.username = username
+	defer func() {
+		if r := recover(); r != nil {
+			br.result = nil
+			br.err = fmt.Errorf("detection panicked: %v", r)
+		}
+	}()
+	br.result, br.err = detector.DetectWithProgress(ctx, username, onProgress)
+	return br
+}
+
+// phaseReporter returns a per-user progress printer when --progress is set, or nil.
+func phaseReporter() func(string, gutz.ProgressEvent) {
+	if !*progress {
+		return nil
+	}
+	return func(username string, ev gutz.ProgressEvent) {
+		fmt.Fprintf(os.Stderr, "⏳ %s: %s\n", username, ev.Message)
+	}
+}
+
+// runBatchMode runs detection for every username and prints a summary table.
+// It returns the process exit code: non-zero if any detection failed.
+func runBatchMode(logger *slog.Logger, opts []gutz.Option, archive *httpreplay.Archive, store *history.Store, usernames []string) int {
+	ctx, stop := context.WithCancel(context.Background())
+	defer stop()
+
+	detector := gutz.NewWithLogger(ctx, logger, opts...)
+	defer func() {
+		if err := detector.Close(); err != nil {
+			logger.Error("Failed to close detector", "error", err)
+		}
+	}()
+	if *showBudget {
+		defer printRateLimits(os.Stderr, detector)
+	}
+
+	fmt.Fprintf(os.Stderr, "🔎 Detecting %d users (concurrency %d)\n", len(usernames), *concurrency)
+	done := 0
+	results := runBatch(ctx, detector, usernames, *concurrency, 30*time.Second, func(r batchResult) {
+		done++
+		status := "ok"
+		if r.err != nil {
+			status = "failed"
+		}
+		fmt.Fprintf(os.Stderr, "   [%d/%d] %s: %s (%s)\n", done, len(usernames), r.username, status, r.duration.Round(time.Millisecond))
+	}, phaseReporter())
+	saveRecording(logger, archive)
+	for _, r := range results {
+		if r.err == nil {
+			recordHistory(logger, store, r.result)
+		}
+	}
+
+	if *outputFormat != outputText {
+		for i := range results {
+			if results[i].result != nil && !*verbose {
+				results[i].result.GeminiPrompt = ""
+			}
+		}
+		if err := writeResults(os.Stdout, *outputFormat, results); err != nil {
+			logger.Error("Failed to write output", "error", err)
+			return 1
+		}
+		for _, r := range results {
+			if r.err != nil || r.result == nil {
+				return 1
+			}
+		}
+		return 0
+	}
+
+	return printBatchSummary(os.Stdout, results)
+}
+
+// printBatchSummary prints one row per user followed by a list of failures.
+// It returns 1 if any detection failed, 0 otherwise.
+func printBatchSummary(w io.Writer, results []batchResult) int {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "\nUSERNAME\tTIMEZONE\tLOCATION\tCONFIDENCE\tMETHOD\tDURATION")
+
+	var failures []batchResult
+	for _, r := range results {
+		if r.err != nil || r.result == nil {
+			failures = append(failures, r)
+			fmt.Fprintf(tw, "%s\t-\t-\t-\tfailed\t%s\n", r.username, r.duration.Round(time.Millisecond))
+			continue
+		}
+		location := locationSummary(r.result)
+		if location == "" {
+			location = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%.0f%%\t%s\t%s\n",
+			r.username,
+			r.result.Timezone,
+			location,
+			displayConfidence(r.result),
+			formatMethodName(r.result.Method),
+			r.duration.Round(time.Millisecond))
+	}
+	if err := tw.Flush(); err != nil {
+		slog.Debug("failed to flush summary table", "error", err)
+	}
+
+	fmt.Fprintf(w, "\n✅ %d succeeded, ❌ %d failed\n", len(results)-len(failures), len(failures))
+	if len(failures) == 0 {
+		return 0
+	}
+
+	for _, r := range failures {
+		reason := "no result"
+		if r.err != nil {
+			reason = r.err.Error()
+		}
+		fmt.Fprintf(w, "   • %s: %s\n", r.username, reason)
+	}
+	return 1
+}
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/codeGROOVE-dev/guTZ/pkg/gutz"
+)
+
+func TestReadUsernames(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want []string
+	}{
+		{"one per line", "alice\nbob\n", []string{"alice", "bob"}},
+		{"commas and spaces", "alice, bob\tcarol,,dave", []string{"alice", "bob", "carol", "dave"}},
+		{"comments and blanks", "# team\n\nalice # lead\n   \nbob", []string{"alice", "bob"}},
+		{"mentions", "@alice @bob", []string{"alice", "bob"}},
+		{"identities", "alice gitlab:alice", []string{"alice", "gitlab:alice"}},
+		{"empty", "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readUsernames(strings.NewReader(tt.in))
+			if err != nil {
+				t.Fatalf("readUsernames: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("readUsernames(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCollectUsernames(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "team.txt")
+	if err := os.WriteFile(file, []byte("bob\nAlice\ncarol # new\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	stdin := filepath.Join(dir, "stdin.txt")
+	if err := os.WriteFile(stdin, []byte("dave, alice\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		path    string
+		want    []string
+		wantErr bool
+	}{
+		{name: "args only", args: []string{"alice", "bob"}, want: []string{"alice", "bob"}},
+		{name: "args then file, case-insensitive dedup", args: []string{"alice"}, path: file, want: []string{"alice", "bob", "carol"}},
+		{name: "stdin", args: []string{"erin"}, path: "-", want: []string{"erin", "dave", "alice"}},
+		{name: "missing file", path: filepath.Join(dir, "missing.txt"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.path == "-" {
+				f, err := os.Open(stdin)
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer f.Close() //nolint:errcheck // test
+				defer func(old *os.File) { os.Stdin = old }(os.Stdin)
+				os.Stdin = f
+			}
+			got, err := collectUsernames(tt.args, tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("collectUsernames error = %v, want error %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("collectUsernames = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// fakeDetector stands in for *gutz.Detector: "slow" runs until its deadline, "boom" panics, and
+// everyone else is detected in UTC.
+type fakeDetector struct {
+	inFlight    atomic.Int32
+	maxInFlight atomic.Int32
+}
+
+func (f *fakeDetector) DetectWithProgress(ctx context.Context, username string, fn gutz.ProgressFunc) (*gutz.Result, error) {
+	n := f.inFlight.Add(1)
+	defer f.inFlight.Add(-1)
+	for {
+		if m := f.maxInFlight.Load(); n <= m || f.maxInFlight.CompareAndSwap(m, n) {
+			break
+		}
+	}
+	if fn != nil {
+		fn(gutz.ProgressEvent{Username: username, Message: "Fetching"})
+	}
+	switch username {
+	case "slow":
+		<-ctx.Done()
+		return nil, ctx.Err()
+	case "boom":
+		panic("bad data")
+	}
+	time.Sleep(5 * time.Millisecond) // Long enough for detections to overlap
+	return &gutz.Result{Username: username, Timezone: "UTC"}, nil
+}
+
+func (*fakeDetector) Invalidate(context.Context, string) (int, error) { return 0, nil }
+
+func (*fakeDetector) WaitForRateLimit(context.Context) error { return nil }
+
+func TestRunBatch(t *testing.T) {
+	usernames := []string{"alice", "slow", "bob", "boom", "carol", "dave", "erin"}
+	detector := &fakeDetector{}
+	var mu sync.Mutex
+	var finished []string
+	phases := map[string]int{}
+
+	results := runBatch(context.Background(), detector, usernames, 2, 50*time.Millisecond,
+		func(r batchResult) { finished = append(finished, r.username) },
+		func(username string, _ gutz.ProgressEvent) {
+			mu.Lock()
+			defer mu.Unlock()
+			phases[username]++
+		})
+
+	if got := detector.maxInFlight.Load(); got > 2 {
+		t.Errorf("%d detections ran at once, want at most 2", got)
+	}
+	if len(results) != len(usernames) || len(finished) != len(usernames) {
+		t.Fatalf("got %d results and %d progress calls, want %d", len(results), len(finished), len(usernames))
+	}
+	for i, r := range results {
+		if r.username != usernames[i] {
+			t.Errorf("results[%d] = %s, want %s: results must keep input order", i, r.username, usernames[i])
+		}
+		if phases[r.username] != 1 {
+			t.Errorf("%s reported %d phases, want 1", r.username, phases[r.username])
+		}
+		switch r.username {
+		case "slow":
+			if !errors.Is(r.err, context.DeadlineExceeded) {
+				t.Errorf("slow: err = %v, want the per-user timeout", r.err)
+			}
+		case "boom":
+			if r.result != nil || r.err == nil || !strings.Contains(r.err.Error(), "panicked: bad data") {
+				t.Errorf("boom: result = %v, err = %v; want the panic as an error", r.result, r.err)
+			}
+		default:
+			if r.err != nil || r.result == nil || r.result.Timezone != "UTC" {
+				t.Errorf("%s: result = %v, err = %v", r.username, r.result, r.err)
+			}
+		}
+	}
+
+	// Zero concurrency still makes progress, one user at a time
+	detector = &fakeDetector{}
+	if results := runBatch(context.Background(), detector, []string{"alice", "bob"}, 0, time.Second, nil, nil); results[1].err != nil {
+		t.Errorf("concurrency 0: %v", results[1].err)
+	}
+	if got := detector.maxInFlight.Load(); got != 1 {
+		t.Errorf("concurrency 0 ran %d detections at once, want 1", got)
+	}
+
+	// A canceled batch fails every user, whether waiting for a slot or detecting
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for _, r := range runBatch(ctx, &fakeDetector{}, []string{"slow", "slow"}, 1, time.Second, nil, nil) {
+		if !errors.Is(r.err, context.Canceled) {
+			t.Errorf("canceled batch: err = %v, want context.Canceled", r.err)
+		}
+	}
+}
+
+func TestPrintBatchSummary(t *testing.T) {
+	tests := []struct {
+		name     string
+		results  []batchResult
+		wantCode int
+		want     []string
+	}{
+		{
+			name: "all succeeded",
+			results: []batchResult{
+				{username: "alice", result: sampleResult(), duration: 1500 * time.Millisecond},
+			},
+			want: []string{"alice", "America/New_York", "40.700, -74.000", "Activity Pattern Analysis", "1.5s", "1 succeeded, ❌ 0 failed"},
+		},
+		{
+			name: "failures listed",
+			results: []batchResult{
+				{username: "alice", result: sampleResult()},
+				{username: "ghost", err: errors.New("user not found")},
+				{username: "empty"},
+			},
+			wantCode: 1,
+			want:     []string{"1 succeeded, ❌ 2 failed", "• ghost: user not found", "• empty: no result"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if code := printBatchSummary(&out, tt.results); code != tt.wantCode {
+				t.Errorf("exit code = %d, want %d", code, tt.wantCode)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("summary lacks %q:\n%s", want, out.String())
+				}
+			}
+		})
+	}
+}
+
+func TestLinkIdentities(t *testing.T) {
+	usernames, links, err := linkIdentities([]string{"github:alice", "gitlab:alice", "codeberg:ali", "bob"})
+	if err != nil {
+		t.Fatalf("linkIdentities: %v", err)
+	}
+	if !reflect.DeepEqual(usernames, []string{"alice", "bob"}) {
+		t.Errorf("usernames = %v, want [alice bob]", usernames)
+	}
+	want := []gutz.Identity{{Forge: gutz.ForgeGitLab, Username: "alice"}, {Forge: gutz.ForgeCodeberg, Username: "ali"}}
+	if !reflect.DeepEqual(links["alice"], want) || len(links["bob"]) != 0 {
+		t.Errorf("links = %v, want alice linked to %v", links, want)
+	}
+
+	if _, _, err := linkIdentities([]string{"gitlab:alice", "alice"}); err == nil {
+		t.Error("a forge identity before any GitHub user was accepted")
+	}
+}
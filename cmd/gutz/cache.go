@@ -0,0 +1,338 @@
+package main
+
+import (
+	"cmp"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/codeGROOVE-dev/guTZ/pkg/gutz"
+	"github.com/codeGROOVE-dev/guTZ/pkg/httpcache"
+	"github.com/codeGROOVE-dev/guTZ/pkg/optout"
+)
+
+// cacheTTL matches the lifetime the detector gives entries in its disk cache.
+const cacheTTL = 14 * 24 * time.Hour
+
+// cacheDirectory returns the directory holding the HTTP cache and history, or "" if there is none.
+func cacheDirectory(logger *slog.Logger) string {
+	if *cacheDir != "" {
+		return *cacheDir
+	}
+	userCacheDir, err := os.UserCacheDir()
+	if err != nil {
+		logger.Debug("No user cache directory", "error", err)
+		return ""
+	}
+	return filepath.Join(userCacheDir, "gutz")
+}
+
+// openCache opens the HTTP cache the detector uses: --cache-store if set, else the cache directory.
+func openCache(ctx context.Context, logger *slog.Logger) (*httpcache.OtterCache, error) {
+	if *noCache {
+		return nil, errors.New("caching is disabled by --no-cache")
+	}
+	if *cacheStore != "" {
+		store, err := httpcache.OpenStorage(*cacheStore)
+		if err != nil {
+			return nil, err
+		}
+		return httpcache.NewCacheWithStorage(store, cacheTTL, logger), nil
+	}
+	dir := cacheDirectory(logger)
+	if dir == "" {
+		return nil, errors.New("no cache directory; set --cache-dir")
+	}
+	return httpcache.NewOtterCache(ctx, dir, cacheTTL, logger)
+}
+
+// openOptOuts opens the opt-out registry in the cache directory, which a gutz-server sharing the
+// directory fills, or returns nil if there is none.
+func openOptOuts(logger *slog.Logger) *optout.Registry {
+	dir := cacheDirectory(logger)
+	if dir == "" {
+		return nil
+	}
+	reg, err := optout.Open(filepath.Join(dir, "optout.json"))
+	if err != nil {
+		logger.Warn("Opt-out registry unavailable", "error", err)
+		return nil
+	}
+	return reg
+}
+
+// refreshContext honors --refresh: it drops what the cache holds for username and returns a context
+// whose detection fetches everything again.
+func refreshContext(ctx context.Context, detector batchDetector, username string) context.Context {
+	if !*refresh {
+		return ctx
+	}
+	if _, err := detector.Invalidate(ctx, username); err != nil {
+		slog.Warn("Failed to invalidate cache", "username", username, "error", err)
+	}
+	return gutz.WithRefresh(ctx)
+}
+
+// runCache implements "gutz cache stats|ls|purge|export|import".
+func runCache(logger *slog.Logger, args []string) int {
+	usage := func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [flags] cache stats\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s [flags] cache ls [<url-prefix>]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s [flags] cache purge [--user NAME] [--url PATTERN] [--older-than AGE] [--all]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s [flags] cache export [<file>[.gz]]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s [flags] cache import [<file>[.gz]]\n", os.Args[0])
+	}
+	if len(args) == 0 {
+		usage()
+		return 1
+	}
+
+	ctx := context.Background()
+	cache, err := openCache(ctx, logger)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	defer func() {
+		if err := cache.Close(); err != nil {
+			logger.Error("Failed to close cache", "error", err)
+		}
+	}()
+
+	action, args := args[0], args[1:]
+	switch action {
+	case "stats":
+		err = cacheStats(ctx, cache)
+	case "ls":
+		err = cacheList(ctx, cache, args)
+	case "purge":
+		err = cachePurge(ctx, cache, args)
+	case "export":
+		err = cacheExport(ctx, cache, args)
+	case "import":
+		err = cacheImport(ctx, cache, args)
+	default:
+		usage()
+		return 1
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+func cacheStats(ctx context.Context, cache *httpcache.OtterCache) error {
+	stats, err := cache.Inspect(ctx)
+	if err != nil {
+		return err
+	}
+	if *outputFormat == outputJSON || *outputFormat == outputNDJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.SetEscapeHTML(false) // Age labels such as "<1h"
+		return enc.Encode(struct {
+			httpcache.Stats
+			HitRate float64 `json:"hit_rate"`
+		}{stats, stats.HitRate()})
+	}
+	printCacheStats(os.Stdout, stats)
+	return nil
+}
+
+// printCacheStats renders cache statistics as a human-readable report.
+func printCacheStats(w io.Writer, stats httpcache.Stats) {
+	fmt.Fprintln(w, "\n🗄️  HTTP cache")
+	fmt.Fprintln(w, strings.Repeat("─", 50))
+	fmt.Fprintf(w, "Entries:     %d (%d fresh, %d awaiting revalidation)\n", stats.Entries, stats.Fresh, stats.Stale)
+	fmt.Fprintf(w, "Size:        %s\n", formatBytes(stats.Bytes))
+	fmt.Fprintf(w, "Hit rate:    %.1f%% (%d hits, %d misses, %d revalidated)\n",
+		100*stats.HitRate(), stats.Hits, stats.Misses, stats.Revalidated)
+
+	fmt.Fprintln(w, "\nAge")
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, b := range stats.Ages {
+		bar := ""
+		if stats.Entries > 0 {
+			bar = strings.Repeat("█", (b.Entries*30+stats.Entries-1)/stats.Entries)
+		}
+		fmt.Fprintf(tw, "   %s\t%d\t%s\t%s\n", b.Label, b.Entries, formatBytes(b.Bytes), bar)
+	}
+	if err := tw.Flush(); err != nil {
+		slog.Debug("failed to flush cache stats table", "error", err)
+	}
+}
+
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// cacheListing is one entry of "gutz cache ls".
+type cacheListing struct {
+	StoredAt  time.Time `json:"stored_at,omitzero"`
+	ExpiresAt time.Time `json:"expires_at"`
+	Key       string    `json:"key"`
+	URL       string    `json:"url,omitempty"`
+	ETag      string    `json:"etag,omitempty"`
+	Bytes     int       `json:"bytes"`
+}
+
+func cacheList(ctx context.Context, cache *httpcache.OtterCache, args []string) error {
+	if len(args) > 1 {
+		return errors.New("ls takes at most one URL prefix")
+	}
+	prefix := ""
+	if len(args) == 1 {
+		prefix = args[0]
+	}
+
+	var listings []cacheListing
+	if err := cache.Range(ctx, func(key string, entry httpcache.CacheEntry) bool {
+		if strings.HasPrefix(entry.URL, prefix) {
+			listings = append(listings, cacheListing{
+				StoredAt: entry.StoredAt, ExpiresAt: entry.ExpiresAt,
+				Key: key, URL: entry.URL, ETag: entry.ETag, Bytes: len(entry.Data),
+			})
+		}
+		return true
+	}); err != nil {
+		return err
+	}
+	slices.SortFunc(listings, func(a, b cacheListing) int {
+		return cmp.Or(cmp.Compare(a.URL, b.URL), cmp.Compare(a.Key, b.Key))
+	})
+
+	if *outputFormat == outputJSON || *outputFormat == outputNDJSON {
+		enc := json.NewEncoder(os.Stdout)
+		for _, l := range listings {
+			if err := enc.Encode(l); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	now := time.Now()
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "STORED\tSTATE\tSIZE\tURL")
+	for _, l := range listings {
+		stored, state := "-", "fresh"
+		if !l.StoredAt.IsZero() {
+			stored = l.StoredAt.Local().Format("2006-01-02 15:04")
+		}
+		if !now.Before(l.ExpiresAt) {
+			state = "stale"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", stored, state, formatBytes(int64(l.Bytes)), cmp.Or(l.URL, l.Key))
+	}
+	return tw.Flush()
+}
+
+func cachePurge(ctx context.Context, cache *httpcache.OtterCache, args []string) error {
+	fs := flag.NewFlagSet("cache purge", flag.ContinueOnError)
+	user := fs.String("user", "", "Remove entries fetched for this GitHub user")
+	pattern := fs.String("url", "", "Remove entries whose URL matches this pattern (* matches anything)")
+	olderThan := fs.Duration("older-than", 0, "Remove entries stored longer ago than this, e.g. 72h")
+	all := fs.Bool("all", false, "Remove every entry")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected argument %q", fs.Arg(0))
+	}
+
+	var matchers []httpcache.Matcher
+	if *user != "" {
+		matchers = append(matchers, httpcache.MatchUser(*user))
+	}
+	if *pattern != "" {
+		matchers = append(matchers, httpcache.MatchURL(*pattern))
+	}
+	if *olderThan > 0 {
+		matchers = append(matchers, cache.OlderThan(*olderThan))
+	}
+	if len(matchers) == 0 && !*all {
+		return errors.New("purge needs --user, --url, --older-than or --all")
+	}
+
+	n, err := cache.Purge(ctx, httpcache.MatchAll(matchers...))
+	fmt.Fprintf(os.Stderr, "🧹 Purged %d entries\n", n)
+	return err
+}
+
+func cacheExport(ctx context.Context, cache *httpcache.OtterCache, args []string) (err error) {
+	w := io.Writer(os.Stdout)
+	if path := cacheFileArg(args); path != "" {
+		f, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			err = errors.Join(err, f.Close())
+		}()
+		w = f
+		if strings.HasSuffix(path, ".gz") {
+			gz := gzip.NewWriter(f)
+			defer func() {
+				err = errors.Join(err, gz.Close())
+			}()
+			w = gz
+		}
+	}
+
+	n, err := cache.Export(ctx, w)
+	fmt.Fprintf(os.Stderr, "📦 Exported %d entries\n", n)
+	return err
+}
+
+func cacheImport(ctx context.Context, cache *httpcache.OtterCache, args []string) error {
+	r := io.Reader(os.Stdin)
+	if path := cacheFileArg(args); path != "" {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close() //nolint:errcheck // read-only
+		r = f
+		if strings.HasSuffix(path, ".gz") {
+			gz, err := gzip.NewReader(f)
+			if err != nil {
+				return fmt.Errorf("reading %s: %w", path, err)
+			}
+			defer gz.Close() //nolint:errcheck // read-only
+			r = gz
+		}
+	}
+
+	n, err := cache.Import(ctx, r)
+	fmt.Fprintf(os.Stderr, "📥 Imported %d entries\n", n)
+	return err
+}
+
+// cacheFileArg returns the file named by export or import, or "" for stdin/stdout.
+func cacheFileArg(args []string) string {
+	if len(args) == 0 || args[0] == "-" {
+		return ""
+	}
+	return args[0]
+}
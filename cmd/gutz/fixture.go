@@ -0,0 +1,36 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/codeGROOVE-dev/guTZ/pkg/httpreplay"
+)
+
+// openFixtureArchive returns the archive selected by --record or --replay, or nil if neither was given.
+func openFixtureArchive() (*httpreplay.Archive, error) {
+	switch {
+	case *recordPath != "" && *replayPath != "":
+		return nil, errors.New("--record and --replay are mutually exclusive")
+	case *recordPath != "":
+		return httpreplay.NewRecorder(), nil
+	case *replayPath != "":
+		return httpreplay.Load(*replayPath)
+	default:
+		return nil, nil //nolint:nilnil // no archive requested
+	}
+}
+
+// saveRecording writes a recording archive to the --record path. It is a no-op when not recording.
+func saveRecording(logger *slog.Logger, archive *httpreplay.Archive) {
+	if archive == nil || archive.Mode() != httpreplay.Record {
+		return
+	}
+	if err := archive.Save(*recordPath); err != nil {
+		logger.Error("Failed to save fixture archive", "error", err)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "📼 Recorded %d HTTP exchanges to %s\n", archive.Len(), *recordPath)
+}
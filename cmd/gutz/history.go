@@ -0,0 +1,127 @@
+package main
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/codeGROOVE-dev/guTZ/pkg/gutz"
+	"github.com/codeGROOVE-dev/guTZ/pkg/history"
+)
+
+// openHistory returns the store of past results, kept next to the HTTP cache.
+// It returns nil when caching is disabled or when replaying fixtures, which must not pollute real history.
+func openHistory(logger *slog.Logger) *history.Store {
+	if *noCache || *replayPath != "" {
+		return nil
+	}
+	dir := cacheDirectory(logger)
+	if dir == "" {
+		return nil
+	}
+	store, err := history.Open(filepath.Join(dir, "history"))
+	if err != nil {
+		logger.Warn("History disabled", "error", err)
+		return nil
+	}
+	return store
+}
+
+// recordHistory appends a successful result to the user's history.
+func recordHistory(logger *slog.Logger, store *history.Store, result *gutz.Result) {
+	if store == nil || result == nil {
+		return
+	}
+	if err := store.Append(history.FromResult(result)); err != nil {
+		logger.Warn("Failed to record history", "username", result.Username, "error", err)
+	}
+}
+
+// runHistory implements "gutz history <username>", which shows past results and how they drifted.
+func runHistory(logger *slog.Logger, store *history.Store, args []string) int {
+	if len(args) != 1 {
+		fmt.Fprintf(os.Stderr, "Usage: %s [flags] history <github-username>\n", os.Args[0])
+		return 1
+	}
+	if store == nil {
+		fmt.Fprintln(os.Stderr, "Error: history is only kept when caching is enabled")
+		return 1
+	}
+	username := args[0]
+
+	snaps, err := store.Snapshots(username)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	changes := history.Changes(snaps)
+
+	switch *outputFormat {
+	case outputJSON, outputNDJSON:
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(struct {
+			Username  string             `json:"username"`
+			Snapshots []history.Snapshot `json:"snapshots"`
+			Changes   []history.Change   `json:"changes"`
+		}{username, snaps, changes}); err != nil {
+			logger.Error("Failed to write output", "error", err)
+			return 1
+		}
+	case outputCSV:
+		fmt.Fprintln(os.Stderr, "Error: history supports text and json output")
+		return 1
+	default:
+		printHistory(os.Stdout, username, snaps, changes)
+	}
+	return 0
+}
+
+// printHistory renders snapshots and detected changes as a human-readable report.
+func printHistory(w io.Writer, username string, snaps []history.Snapshot, changes []history.Change) {
+	fmt.Fprintf(w, "\n📜 History for %s (%d detections)\n", username, len(snaps))
+	fmt.Fprintln(w, strings.Repeat("─", 50))
+	if len(snaps) == 0 {
+		fmt.Fprintf(w, "No detections recorded yet. Run `gutz %s` to record one.\n", username)
+		return
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "DATE\tTIMEZONE\tOFFSET\tSLEEP\tTOP ORGS\tMETHOD\tLINKED")
+	for _, s := range snaps {
+		sleep := cmp.Or(s.MainSleep(), "-")
+		fmt.Fprintf(tw, "%s\t%s\tUTC%+.1f\t%s\t%s\t%s\t%s\n",
+			s.Time.Format("2006-01-02 15:04"), s.Timezone, s.DominantOffset, sleep,
+			strings.Join(s.TopOrganizations, ", "), s.Method, cmp.Or(strings.Join(s.Linked, ", "), "-"))
+	}
+	if err := tw.Flush(); err != nil {
+		slog.Debug("failed to flush history table", "error", err)
+	}
+
+	if len(changes) == 0 {
+		fmt.Fprintln(w, "\n✅ No changes detected")
+		return
+	}
+	fmt.Fprintln(w, "\n🔀 Changes")
+	for _, c := range changes {
+		icon := "•"
+		switch c.Kind {
+		case history.ChangeRelocation:
+			icon = "✈️ "
+		case history.ChangeDSTShift:
+			icon = "🕐"
+		default:
+		}
+		line := fmt.Sprintf("   %s %s %s: %s → %s", icon, c.To.Format("2006-01-02"), c.Kind, c.Before, c.After)
+		if c.Detail != "" {
+			line += " (" + c.Detail + ")"
+		}
+		fmt.Fprintln(w, line)
+	}
+}
@@ -2,18 +2,21 @@
 package main
 
 import (
+	"cmp"
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"log/slog"
 	"math"
 	"os"
 	"os/exec"
-	"strconv"
 	"strings"
 	"time"
 
+	"github.com/codeGROOVE-dev/guTZ/pkg/github"
 	"github.com/codeGROOVE-dev/guTZ/pkg/gutz"
+	"github.com/codeGROOVE-dev/guTZ/pkg/llm"
 	"github.com/codeGROOVE-dev/guTZ/pkg/sleep"
 	"github.com/codeGROOVE-dev/guTZ/pkg/timezone"
 	"github.com/codeGROOVE-dev/guTZ/pkg/tzconvert"
@@ -21,18 +24,45 @@ import (
 
 var (
 	githubToken  = flag.String("github-token", "", "GitHub token for API access (or set GITHUB_TOKEN)")
+	githubURL    = flag.String("github-url", "", "GitHub Enterprise Server URL, e.g. https://ghe.example.com (or set GITHUB_URL)")
+	githubAPI    = flag.String("github-api-url", "", "GitHub REST API base URL, overriding --github-url (or set GITHUB_API_URL)")
+	githubGQL    = flag.String("github-graphql-url", "", "GitHub GraphQL endpoint, overriding --github-url (or set GITHUB_GRAPHQL_URL)")
 	geminiAPIKey = flag.String("gemini-key", "", "Gemini API key (or set GEMINI_API_KEY)")
 	geminiModel  = flag.String("gemini-model", "gemini-2.5-flash-lite", "Gemini model to use (or set GEMINI_MODEL)")
 	mapsAPIKey   = flag.String("maps-key", "", "Google Maps API key (or set GOOGLE_MAPS_API_KEY)")
 	gcpProject   = flag.String("gcp-project", "", "GCP project ID (or set GCP_PROJECT)")
 	cacheDir     = flag.String("cache-dir", "", "Cache directory (or set CACHE_DIR)")
+	cacheStore   = flag.String("cache-store", "", "HTTP cache store: bolt:PATH, sqlite:PATH or redis://HOST:PORT/DB (or set CACHE_STORE)")
+	llmBackend   = flag.String("llm", "", "LLM backend: gemini, openai, ollama, or none (or set LLM_BACKEND)")
+	llmURL       = flag.String("llm-url", "", "Base URL for the openai or ollama backend (or set LLM_URL)")
+	llmModel     = flag.String("llm-model", "", "Model for the openai or ollama backend (or set LLM_MODEL)")
+	llmAPIKey    = flag.String("llm-key", "", "API key for the openai backend (or set LLM_API_KEY)")
 	noCache      = flag.Bool("no-cache", false, "Disable caching")
+	refresh      = flag.Bool("refresh", false, "Drop cached data for the users and detect them afresh")
+	privacy      = flag.String("privacy", "", "What results reveal: full (default), coarse or timezone-only (or set PRIVACY_LEVEL)")
+	me           = flag.Bool("me", false, "Detect the owner of the GitHub token, counting the private contributions only their token can see")
 	verbose      = flag.Bool("verbose", false, "Enable verbose logging")
 	version      = flag.Bool("version", false, "Show version")
-	forceOffset  = flag.Int("force-offset", 99, "Force a specific UTC offset for visualization (-12 to +14)")
+	forceOffset  = flag.Float64("force-offset", 99, "Force a specific UTC offset for visualization (-12 to +14, e.g. 5.5 or 5.75)")
+	usersFile    = flag.String("file", "", "Read usernames from file, one per line ('-' for stdin)")
+	concurrency  = flag.Int("concurrency", 4, "Number of users to detect in parallel in batch mode")
+	outputFormat = flag.String("output", outputText, "Output format: text, json, ndjson, or csv")
+	recordPath   = flag.String("record", "", "Record all HTTP traffic to a fixture archive (.json or .json.gz)")
+	replayPath   = flag.String("replay", "", "Replay HTTP traffic from a fixture archive instead of the network")
+	progress     = flag.Bool("progress", false, "Print detection progress to stderr")
+	rateLimit    = flag.String("rate-limit", "", "When the GitHub budget runs out: wait for the reset or fail (or set RATE_LIMIT_POLICY)")
+	showBudget   = flag.Bool("rate-limit-status", false, "Print the remaining GitHub API budget to stderr (with no usernames, print it and exit)")
+	gitlabURL    = flag.String("gitlab-url", "", "GitLab instance for gitlab: identities, default https://gitlab.com (or set GITLAB_URL)")
+	gitlabToken  = flag.String("gitlab-token", "", "GitLab token for gitlab: identities (or set GITLAB_TOKEN)")
+	giteaURL     = flag.String("gitea-url", "", "Gitea or Forgejo instance for gitea: identities (or set GITEA_URL)")
+	giteaToken   = flag.String("gitea-token", "", "Gitea or Forgejo token for gitea: identities (or set GITEA_TOKEN)")
 )
 
+// linked holds the accounts on other forges linked to each GitHub user on the command line.
+var linked map[string][]gutz.Identity
+
 func main() { //nolint:gocognit,revive,maintidx // Main function orchestrates complex CLI logic
+	flag.Usage = usage
 	flag.Parse()
 
 	if *version {
@@ -40,14 +70,37 @@ func main() { //nolint:gocognit,revive,maintidx // Main function orchestrates co
 		return
 	}
 
-	args := flag.Args()
-	if len(args) != 1 {
-		fmt.Fprintf(os.Stderr, "Usage: %s [flags] <github-username>\n", os.Args[0])
-		flag.PrintDefaults()
+	if !isValidOutputFormat(*outputFormat) {
+		fmt.Fprintf(os.Stderr, "Error: unsupported output format %q (want text, json, ndjson, or csv)\n", *outputFormat)
 		os.Exit(1)
 	}
 
-	username := args[0]
+	args := flag.Args()
+	var subcommand string
+	if len(args) > 0 && isSubcommand(args[0]) {
+		subcommand = args[0]
+		args = args[1:]
+	}
+
+	var usernames []string
+	if subcommand == "" {
+		var err error
+		usernames, err = collectUsernames(args, *usersFile)
+		if err == nil {
+			usernames, linked, err = linkIdentities(usernames)
+		}
+		if err == nil && *me && len(usernames) > 0 {
+			err = errors.New("--me detects the owner of the GitHub token and takes no usernames")
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if len(usernames) == 0 && !*showBudget && !*me {
+			flag.Usage()
+			os.Exit(1)
+		}
+	}
 
 	// Configure logging
 	level := slog.LevelError
@@ -86,23 +139,96 @@ func main() { //nolint:gocognit,revive,maintidx // Main function orchestrates co
 	if *cacheDir == "" {
 		*cacheDir = os.Getenv("CACHE_DIR")
 	}
+	*cacheStore = cmp.Or(*cacheStore, os.Getenv("CACHE_STORE"))
+	*githubURL = cmp.Or(*githubURL, os.Getenv("GITHUB_URL"))
+	*githubAPI = cmp.Or(*githubAPI, os.Getenv("GITHUB_API_URL"))
+	*githubGQL = cmp.Or(*githubGQL, os.Getenv("GITHUB_GRAPHQL_URL"))
+	*llmBackend = cmp.Or(*llmBackend, os.Getenv("LLM_BACKEND"))
+	*llmURL = cmp.Or(*llmURL, os.Getenv("LLM_URL"))
+	*llmModel = cmp.Or(*llmModel, os.Getenv("LLM_MODEL"))
+	*llmAPIKey = cmp.Or(*llmAPIKey, os.Getenv("LLM_API_KEY"))
+	*gitlabURL = cmp.Or(*gitlabURL, os.Getenv("GITLAB_URL"))
+	*gitlabToken = cmp.Or(*gitlabToken, os.Getenv("GITLAB_TOKEN"))
+	*giteaURL = cmp.Or(*giteaURL, os.Getenv("GITEA_URL"))
+	*giteaToken = cmp.Or(*giteaToken, os.Getenv("GITEA_TOKEN"))
+
+	backend, err := llm.ParseBackend(*llmBackend)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	rateLimitPolicy, err := github.ParseRateLimitPolicy(cmp.Or(*rateLimit, os.Getenv("RATE_LIMIT_POLICY")))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	privacyLevel, err := gutz.ParsePrivacyLevel(cmp.Or(*privacy, os.Getenv("PRIVACY_LEVEL")))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
 
 	// Create detector with options
 	detectorOpts := []gutz.Option{
 		gutz.WithGitHubToken(*githubToken),
+		gutz.WithGitHubBaseURLs(github.NewBaseURLs(*githubURL, *githubAPI, *githubGQL)),
 		gutz.WithGeminiAPIKey(*geminiAPIKey),
 		gutz.WithGeminiModel(*geminiModel),
 		gutz.WithMapsAPIKey(*mapsAPIKey),
 		gutz.WithGCPProject(*gcpProject),
+		gutz.WithLLM(llm.Config{Backend: backend, BaseURL: *llmURL, Model: *llmModel, APIKey: *llmAPIKey}),
+		gutz.WithRateLimitPolicy(rateLimitPolicy),
+		gutz.WithPrivacy(privacyLevel),
+		gutz.WithGitLab(*gitlabURL, *gitlabToken),
+		gutz.WithGitea(*giteaURL, *giteaToken),
 	}
 
 	if *noCache {
 		// Explicitly disable all caching
 		detectorOpts = append(detectorOpts, gutz.WithNoCache())
+	} else if *cacheStore != "" {
+		detectorOpts = append(detectorOpts, gutz.WithCacheStore(*cacheStore))
 	} else if *cacheDir != "" {
 		detectorOpts = append(detectorOpts, gutz.WithCacheDir(*cacheDir))
 	}
 
+	archive, err := openFixtureArchive()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if archive != nil {
+		detectorOpts = append(detectorOpts, gutz.WithFixtureArchive(archive))
+	}
+
+	historyStore := openHistory(logger)
+	if optOuts := openOptOuts(logger); optOuts != nil {
+		detectorOpts = append(detectorOpts, gutz.WithOptOutRegistry(optOuts))
+	}
+
+	switch subcommand {
+	case "overlap":
+		os.Exit(runOverlap(logger, detectorOpts, archive, args))
+	case "history":
+		os.Exit(runHistory(logger, historyStore, args))
+	case "cache":
+		os.Exit(runCache(logger, args))
+	default:
+	}
+
+	// --rate-limit-status alone reports the budget without detecting anyone
+	if len(usernames) == 0 && !*me {
+		os.Exit(runRateLimitStatus(logger, detectorOpts))
+	}
+
+	// Several users: run in batch mode with a shared detector and cache
+	if len(usernames) > 1 {
+		if code := runBatchMode(logger, detectorOpts, archive, historyStore, usernames); code != 0 {
+			os.Exit(code)
+		}
+		return
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
@@ -112,8 +238,56 @@ func main() { //nolint:gocognit,revive,maintidx // Main function orchestrates co
 			logger.Error("Failed to close detector", "error", err)
 		}
 	}()
+	if *showBudget {
+		defer printRateLimits(os.Stderr, detector)
+	}
+
+	var onProgress gutz.ProgressFunc
+	if *progress {
+		onProgress = func(ev gutz.ProgressEvent) {
+			fmt.Fprintf(os.Stderr, "⏳ %s\n", ev.Message)
+		}
+	}
+
+	// With --me the username is that of the token's owner
+	detectCtx := ctx
+	var username string
+	if *me {
+		if *githubToken == "" {
+			fmt.Fprintln(os.Stderr, "Error: --me needs your GitHub token (set GITHUB_TOKEN or sign in with gh)")
+			os.Exit(1) //nolint:gocritic // nothing to persist before detection
+		}
+		var err error
+		if detectCtx, username, err = detector.ViewerContext(ctx, *githubToken); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1) //nolint:gocritic // nothing to persist before detection
+		}
+	} else {
+		username = usernames[0]
+	}
+
+	result, err := detector.DetectWithProgress(refreshContext(gutz.WithLinkedIdentities(detectCtx, linked[username]...), detector, username), username, onProgress)
+	saveRecording(logger, archive)
+	// Results counting private activity don't compare with public detections, so they stay out of history
+	if err == nil && !*me {
+		recordHistory(logger, historyStore, result)
+	}
+
+	// Machine-readable output skips the human report entirely
+	if *outputFormat != outputText {
+		if result != nil && !*verbose {
+			result.GeminiPrompt = ""
+		}
+		if writeErr := writeResults(os.Stdout, *outputFormat, []batchResult{{username: username, result: result, err: err}}); writeErr != nil {
+			logger.Error("Failed to write output", "error", writeErr)
+		}
+		if err != nil {
+			cancel()
+			os.Exit(1) //nolint:gocritic // detector has nothing to persist after a failed detection
+		}
+		return
+	}
 
-	result, err := detector.Detect(ctx, username)
 	if err != nil {
 		cancel() // Ensure context is cancelled before exit
 		logger.Error("Detection failed", "error", err)
@@ -140,17 +314,13 @@ func main() { //nolint:gocognit,revive,maintidx // Main function orchestrates co
 
 	if *forceOffset >= -12 && *forceOffset <= 14 && result.HalfHourlyActivityUTC != nil {
 		// Convert forced offset to UTC+/- format
-		if *forceOffset >= 0 {
-			displayTimezone = fmt.Sprintf("UTC+%d", *forceOffset)
-		} else {
-			displayTimezone = fmt.Sprintf("UTC%d", *forceOffset)
-		}
+		displayTimezone = tzconvert.FormatOffset(*forceOffset)
 
 		// Check if this offset matches one of our analyzed candidates
 		foundCandidate := false
 		for i := range result.TimezoneCandidates {
 			candidate := &result.TimezoneCandidates[i]
-			if int(candidate.Offset) != *forceOffset {
+			if candidate.Offset != *forceOffset {
 				continue
 			}
 			// We have data for this timezone! Use the pre-calculated values
@@ -177,10 +347,7 @@ func main() { //nolint:gocognit,revive,maintidx // Main function orchestrates co
 			}
 
 			// Convert active hours from UTC to local time using forced offset
-			modifiedResult.ActiveHoursLocal = struct {
-				Start float64 `json:"start"`
-				End   float64 `json:"end"`
-			}{
+			modifiedResult.ActiveHoursLocal = gutz.ActiveHours{
 				Start: tzconvert.UTCToLocal(result.ActiveHoursUTC.Start, *forceOffset),
 				End:   tzconvert.UTCToLocal(result.ActiveHoursUTC.End, *forceOffset),
 			}
@@ -265,10 +432,7 @@ func main() { //nolint:gocognit,revive,maintidx // Main function orchestrates co
 				break // Only show top 5
 			}
 			candidate := &result.TimezoneCandidates[i]
-			offsetStr := fmt.Sprintf("UTC%+d", int(candidate.Offset))
-			if candidate.Offset == 0 {
-				offsetStr = "UTC+0"
-			}
+			offsetStr := tzconvert.FormatOffset(candidate.Offset)
 
 			fmt.Printf("%d. %s (%.1f%% confidence)\n", i+1, offsetStr, candidate.Confidence)
 			fmt.Printf("   Evening activity: %d events\n", candidate.EveningActivity)
@@ -290,6 +454,30 @@ func main() { //nolint:gocognit,revive,maintidx // Main function orchestrates co
 	}
 }
 
+// usage prints the command lines gutz accepts and its flags, for --help and a missing username.
+func usage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintf(out, "Usage: %s [flags] <github-username> [<github-username>...]\n", os.Args[0])
+	fmt.Fprintf(out, "       %s [flags] --me\n", os.Args[0])
+	fmt.Fprintf(out, "       %s [flags] github:<username> gitlab:<username> codeberg:<username> gitea:<username>\n", os.Args[0])
+	fmt.Fprintf(out, "       %s [flags] overlap <github-username>...\n", os.Args[0])
+	fmt.Fprintf(out, "       %s [flags] history <github-username>\n", os.Args[0])
+	fmt.Fprintf(out, "       %s [flags] cache stats|ls|purge|export|import\n", os.Args[0])
+	fmt.Fprintf(out, "\nTo detect a GitHub user named overlap, history or cache, prefix it with github:, as in %s github:cache.\n\n", os.Args[0])
+	flag.PrintDefaults()
+}
+
+// isSubcommand reports whether arg names a CLI subcommand rather than a username.
+// A GitHub user of the same name is reached with the github: prefix, which never matches.
+func isSubcommand(arg string) bool {
+	switch arg {
+	case "overlap", "history", "cache":
+		return true
+	default:
+		return false
+	}
+}
+
 func printResult(result *gutz.Result) {
 	// Print header
 	fmt.Printf("\n🌍 GitHub User: %s\n", result.Username)
@@ -305,16 +493,23 @@ func printResult(result *gutz.Result) {
 	printDetectionInfo(result)
 }
 
-func printLocation(result *gutz.Result) {
-	var locationStr string
+// locationSummary returns the best available location description for a result.
+func locationSummary(result *gutz.Result) string {
 	switch {
 	case result.GeminiSuggestedLocation != "":
-		locationStr = result.GeminiSuggestedLocation
+		return result.GeminiSuggestedLocation
 	case result.LocationName != "":
-		locationStr = result.LocationName
+		return result.LocationName
 	case result.Location != nil:
-		locationStr = fmt.Sprintf("%.3f, %.3f", result.Location.Latitude, result.Location.Longitude)
+		return fmt.Sprintf("%.3f, %.3f", result.Location.Latitude, result.Location.Longitude)
 	default:
+		return ""
+	}
+}
+
+func printLocation(result *gutz.Result) {
+	locationStr := locationSummary(result)
+	if locationStr == "" {
 		// No detected location, but check if there's a profile location
 		if result.Verification != nil && result.Verification.ProfileLocation != "" {
 			// Show profile location when we have no detected location
@@ -359,31 +554,26 @@ func printLocation(result *gutz.Result) {
 
 func printTimezone(result *gutz.Result) {
 	// Helper function to get current time in a timezone
-	getCurrentTime := func(tz string) (string, int) {
+	getCurrentTime := func(tz string) (string, float64) {
 		if loc, err := time.LoadLocation(tz); err == nil {
 			now := time.Now().In(loc)
 			_, offset := now.Zone()
-			return now.Format("15:04"), offset / 3600
+			return now.Format("15:04"), float64(offset) / 3600
 		}
-		// Try to parse UTC offset format
+		// Try to parse UTC offset format (including fractional offsets like UTC+5.5)
 		if strings.HasPrefix(tz, "UTC") {
-			offsetStr := strings.TrimPrefix(tz, "UTC")
-			if offsetHours, err := strconv.Atoi(strings.TrimPrefix(offsetStr, "+")); err == nil {
-				now := time.Now().UTC().Add(time.Duration(offsetHours) * time.Hour)
-				return now.Format("15:04"), offsetHours
-			} else if offsetHours, err := strconv.Atoi(offsetStr); err == nil {
-				now := time.Now().UTC().Add(time.Duration(offsetHours) * time.Hour)
-				return now.Format("15:04"), offsetHours
-			}
+			offsetHours := tzconvert.ParseTimezoneOffset(tz)
+			now := time.Now().UTC().Add(time.Duration(offsetHours * float64(time.Hour)))
+			return now.Format("15:04"), offsetHours
 		}
 		return "--:--", 0
 	}
 
 	// Helper to calculate hour difference between timezones
-	calcHourDiff := func(tz1, tz2 string) int {
+	calcHourDiff := func(tz1, tz2 string) float64 {
 		_, offset1 := getCurrentTime(tz1)
 		_, offset2 := getCurrentTime(tz2)
-		return abs(offset1 - offset2)
+		return math.Abs(offset1 - offset2)
 	}
 
 	// Helper to format timezone display with offset indicator
@@ -393,7 +583,7 @@ func printTimezone(result *gutz.Result) {
 		}
 
 		localTime, offsetHours := getCurrentTime(tz)
-		utcStr := fmt.Sprintf("UTC%+d", offsetHours)
+		utcStr := tzconvert.FormatOffset(offsetHours)
 
 		if isPrimary {
 			// Primary detected timezone
@@ -405,9 +595,9 @@ func printTimezone(result *gutz.Result) {
 			if hourDiff > 0 {
 				if hourDiff > 4 {
 					// Red warning for >4 hour difference
-					offsetStr = fmt.Sprintf(" \033[31m⚠️ %+d hr\033[0m", hourDiff)
+					offsetStr = fmt.Sprintf(" \033[31m⚠️ %+g hr\033[0m", hourDiff)
 				} else {
-					offsetStr = fmt.Sprintf(" (%+d hr)", hourDiff)
+					offsetStr = fmt.Sprintf(" (%+g hr)", hourDiff)
 				}
 			}
 			fmt.Printf("                  └─ %s: %s (%s, now %s)%s\n", label, tz, utcStr, localTime, offsetStr)
@@ -436,14 +626,6 @@ func printTimezone(result *gutz.Result) {
 	}
 }
 
-// abs returns the absolute value of an integer.
-func abs(n int) int {
-	if n < 0 {
-		return -n
-	}
-	return n
-}
-
 func printWorkSchedule(result *gutz.Result) {
 	// Check if we have multiple activity periods to display
 	switch {
@@ -569,14 +751,36 @@ func printActivitySummary(result *gutz.Result) {
 		}
 		fmt.Println()
 	}
+
+	if s := result.Seasonal; s != nil && s.Regime != timezone.DSTUnknown {
+		var regime string
+		switch s.Regime {
+		case timezone.DSTNone:
+			regime = "no daylight saving"
+		case timezone.DSTUS:
+			regime = "US/Canada daylight saving"
+		case timezone.DSTEU:
+			regime = "European daylight saving"
+		default:
+			regime = "southern hemisphere daylight saving"
+		}
+		if len(s.SuggestedZones) > 0 {
+			regime += " (like " + strings.Join(s.SuggestedZones[:min(2, len(s.SuggestedZones))], ", ") + ")"
+		}
+		fmt.Printf("🕐 DST:           %s\n", regime)
+	}
 }
 
-func printDetectionInfo(result *gutz.Result) {
+// displayConfidence maps the internal confidence score to the percentage shown to users.
+func displayConfidence(result *gutz.Result) float64 {
 	// Our confidence scores are now in a 0-50 range typically
 	// For the winning candidate, show it as 85-95% range
 	// This matches how we display to Gemini
-	displayConfidence := 85.0 + math.Min(10, result.Confidence/4.0)
-	displayConfidence = math.Min(95, displayConfidence)
+	return math.Min(95, 85.0+math.Min(10, result.Confidence/4.0))
+}
+
+func printDetectionInfo(result *gutz.Result) {
+	displayConfidence := displayConfidence(result)
 
 	// Display data sources inline with method if available
 	if len(result.DataSources) > 0 && result.Method == "gemini_analysis" {
@@ -601,24 +805,6 @@ func formatHour(decimalHour float64) string {
 	return fmt.Sprintf("%d:%02d", hour, minutes)
 }
 
-// calculateTimezoneOffset calculates the UTC offset in hours for a given timezone.
-func calculateTimezoneOffset(tz string) int {
-	if strings.HasPrefix(tz, "UTC") {
-		offsetStr := strings.TrimPrefix(tz, "UTC")
-		if offsetStr == "" {
-			return 0
-		}
-		if offset, err := strconv.Atoi(offsetStr); err == nil {
-			return offset
-		}
-	} else if loc, err := time.LoadLocation(tz); err == nil {
-		now := time.Now().In(loc)
-		_, offset := now.Zone()
-		return offset / 3600
-	}
-	return 0
-}
-
 func formatCandidateLunch(candidate timezone.Candidate) string {
 	if candidate.LunchStartUTC == 0 && candidate.LunchEndUTC == 0 {
 		return "Not detected"
@@ -682,8 +868,7 @@ func convertUTCToLocal(utcHour float64, tz string) float64 {
 		return float64(localTime.Hour()) + float64(localTime.Minute())/60.0
 	}
 	// Fallback for UTC+/- format
-	offset := calculateTimezoneOffset(tz)
-	return math.Mod(utcHour+float64(offset)+24, 24)
+	return tzconvert.UTCToLocal(utcHour, tzconvert.ParseTimezoneOffset(tz))
 }
 
 func printRestHours(result *gutz.Result) {
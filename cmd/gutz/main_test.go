@@ -0,0 +1,47 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGitHubPrefixEscapesSubcommands(t *testing.T) {
+	for _, name := range []string{"overlap", "history", "cache"} {
+		if !isSubcommand(name) {
+			t.Errorf("isSubcommand(%q) = false, want the subcommand", name)
+		}
+
+		// gutz github:cache detects the GitHub user named cache
+		arg := "github:" + name
+		if isSubcommand(arg) {
+			t.Errorf("isSubcommand(%q) = true, want a username", arg)
+			continue
+		}
+		usernames, err := collectUsernames([]string{arg}, "")
+		if err == nil {
+			usernames, _, err = linkIdentities(usernames)
+		}
+		if err != nil || len(usernames) != 1 || usernames[0] != name {
+			t.Errorf("%s parsed as %v, %v; want the user %s", arg, usernames, err, name)
+			continue
+		}
+		results := runBatch(context.Background(), &fakeDetector{}, usernames, 1, time.Second, nil, nil)
+		if results[0].err != nil || results[0].result == nil || results[0].result.Username != name {
+			t.Errorf("detecting %s = %+v, want a result for %s", arg, results[0], name)
+		}
+	}
+}
+
+func TestUsageExplainsGitHubPrefix(t *testing.T) {
+	var out bytes.Buffer
+	flag.CommandLine.SetOutput(&out)
+	defer flag.CommandLine.SetOutput(nil)
+	usage()
+	if !strings.Contains(out.String(), "github:cache") {
+		t.Errorf("usage does not explain the github: prefix:\n%s", out.String())
+	}
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/codeGROOVE-dev/guTZ/pkg/tzconvert"
+)
+
+// Output formats supported by the --output flag.
+const (
+	outputText   = "text"
+	outputJSON   = "json"
+	outputNDJSON = "ndjson"
+	outputCSV    = "csv"
+)
+
+// csvCandidateColumns is the number of top timezone candidates flattened into CSV columns.
+const csvCandidateColumns = 3
+
+// isValidOutputFormat reports whether format is a supported --output value.
+func isValidOutputFormat(format string) bool {
+	switch format {
+	case outputText, outputJSON, outputNDJSON, outputCSV:
+		return true
+	default:
+		return false
+	}
+}
+
+// jsonError is emitted in place of a result when detection fails for a user.
+type jsonError struct {
+	Username string `json:"username"`
+	Error    string `json:"error"`
+}
+
+// jsonRecord returns the value to encode for a single batch result.
+func jsonRecord(r batchResult) any {
+	if r.err != nil || r.result == nil {
+		reason := "no result"
+		if r.err != nil {
+			reason = r.err.Error()
+		}
+		return jsonError{Username: r.username, Error: reason}
+	}
+
+	return r.result
+}
+
+// writeResults writes results in the given machine-readable format.
+// JSON output for a single user is pretty-printed; for several users it falls back to NDJSON.
+func writeResults(w io.Writer, format string, results []batchResult) error {
+	switch format {
+	case outputJSON:
+		if len(results) == 1 {
+			enc := json.NewEncoder(w)
+			enc.SetIndent("", "  ")
+			return enc.Encode(jsonRecord(results[0]))
+		}
+		return writeNDJSON(w, results)
+	case outputNDJSON:
+		return writeNDJSON(w, results)
+	case outputCSV:
+		return writeCSV(w, results)
+	default:
+		return fmt.Errorf("unsupported output format: %q", format)
+	}
+}
+
+// writeNDJSON writes one JSON object per line.
+func writeNDJSON(w io.Writer, results []batchResult) error {
+	enc := json.NewEncoder(w)
+	for _, r := range results {
+		if err := enc.Encode(jsonRecord(r)); err != nil {
+			return fmt.Errorf("encoding %s: %w", r.username, err)
+		}
+	}
+	return nil
+}
+
+// csvHeader returns the column names for CSV output.
+func csvHeader() []string {
+	header := []string{
+		"username", "timezone", "confidence", "method", "location", "latitude", "longitude",
+		"active_start_local", "active_end_local", "active_start_utc", "active_end_utc",
+		"lunch_start_local", "lunch_end_local", "lunch_confidence",
+		"sleep_local",
+	}
+	for i := 1; i <= csvCandidateColumns; i++ {
+		header = append(header, fmt.Sprintf("candidate_%d", i), fmt.Sprintf("candidate_%d_confidence", i))
+	}
+	return append(header, "error")
+}
+
+// writeCSV writes a flattened row per user.
+func writeCSV(w io.Writer, results []batchResult) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader()); err != nil {
+		return err
+	}
+	for _, r := range results {
+		if err := cw.Write(csvRow(r)); err != nil {
+			return fmt.Errorf("writing %s: %w", r.username, err)
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// csvRow flattens a batch result into a row matching csvHeader.
+// Values are collected by column name so the row cannot drift out of step with the header.
+func csvRow(r batchResult) []string {
+	values := csvValues(r)
+	header := csvHeader()
+	row := make([]string, len(header))
+	for i, name := range header {
+		row[i] = values[name]
+	}
+	return row
+}
+
+// csvValues returns the CSV column values for a batch result, keyed by header name.
+func csvValues(r batchResult) map[string]string {
+	values := map[string]string{"username": r.username}
+	if r.err != nil || r.result == nil {
+		values["error"] = "no result"
+		if r.err != nil {
+			values["error"] = r.err.Error()
+		}
+		return values
+	}
+
+	res := r.result
+	values["timezone"] = res.Timezone
+	values["confidence"] = formatFloat(res.Confidence)
+	values["method"] = res.Method
+	values["location"] = locationSummary(res)
+	if res.Location != nil {
+		values["latitude"] = formatFloat(res.Location.Latitude)
+		values["longitude"] = formatFloat(res.Location.Longitude)
+	}
+	if res.ActiveHoursUTC.Start != 0 || res.ActiveHoursUTC.End != 0 {
+		values["active_start_local"] = formatFloat(res.ActiveHoursLocal.Start)
+		values["active_end_local"] = formatFloat(res.ActiveHoursLocal.End)
+		values["active_start_utc"] = formatFloat(res.ActiveHoursUTC.Start)
+		values["active_end_utc"] = formatFloat(res.ActiveHoursUTC.End)
+	}
+	if res.LunchHoursUTC.Confidence > 0 {
+		values["lunch_start_local"] = formatFloat(res.LunchHoursLocal.Start)
+		values["lunch_end_local"] = formatFloat(res.LunchHoursLocal.End)
+		values["lunch_confidence"] = formatFloat(res.LunchHoursUTC.Confidence)
+	}
+
+	sleepRanges := make([]string, 0, len(res.SleepRangesLocal))
+	for _, s := range res.SleepRangesLocal {
+		sleepRanges = append(sleepRanges, formatHour(s.Start)+"-"+formatHour(s.End))
+	}
+	values["sleep_local"] = strings.Join(sleepRanges, ";")
+
+	for i := 0; i < csvCandidateColumns && i < len(res.TimezoneCandidates); i++ {
+		candidate := &res.TimezoneCandidates[i]
+		values[fmt.Sprintf("candidate_%d", i+1)] = tzconvert.FormatOffset(candidate.Offset)
+		values[fmt.Sprintf("candidate_%d_confidence", i+1)] = formatFloat(candidate.Confidence)
+	}
+	return values
+}
+
+// formatFloat formats a float with the minimal precision needed.
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
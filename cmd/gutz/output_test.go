@@ -0,0 +1,179 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/codeGROOVE-dev/guTZ/pkg/gutz"
+	"github.com/codeGROOVE-dev/guTZ/pkg/timezone"
+)
+
+func sampleResult() *gutz.Result {
+	return &gutz.Result{
+		Username:         "alice",
+		Timezone:         "America/New_York",
+		Method:           "activity_patterns",
+		Confidence:       20,
+		Location:         &gutz.Location{Latitude: 40.7, Longitude: -74},
+		ActiveHoursUTC:   gutz.ActiveHours{Start: 13, End: 22},
+		ActiveHoursLocal: gutz.ActiveHours{Start: 9, End: 18},
+		LunchHoursUTC:    gutz.LunchBreak{Start: 16, End: 17, Confidence: 0.7},
+		LunchHoursLocal:  gutz.LunchBreak{Start: 12, End: 13, Confidence: 0.7},
+		SleepRangesLocal: []gutz.SleepRange{{Start: 23, End: 7, Duration: 8}},
+		TimezoneCandidates: []timezone.Candidate{
+			{Offset: -4, Confidence: 30},
+			{Offset: -3.5, Confidence: 12.5},
+		},
+		HalfHourlyActivityUTC: map[float64]int{13.5: 4, 14: 7},
+	}
+}
+
+func TestCSVRow(t *testing.T) {
+	tests := []struct {
+		name string
+		in   batchResult
+		want map[string]string
+	}{
+		{
+			name: "success",
+			in:   batchResult{username: "alice", result: sampleResult()},
+			want: map[string]string{
+				"username":               "alice",
+				"timezone":               "America/New_York",
+				"confidence":             "20",
+				"method":                 "activity_patterns",
+				"latitude":               "40.7",
+				"longitude":              "-74",
+				"active_start_local":     "9",
+				"active_end_local":       "18",
+				"active_start_utc":       "13",
+				"active_end_utc":         "22",
+				"lunch_start_local":      "12",
+				"lunch_end_local":        "13",
+				"lunch_confidence":       "0.7",
+				"sleep_local":            "23:00-7:00",
+				"candidate_1":            "UTC-4",
+				"candidate_1_confidence": "30",
+				"candidate_2":            "UTC-3.5",
+				"candidate_2_confidence": "12.5",
+				"candidate_3":            "",
+				"error":                  "",
+			},
+		},
+		{
+			name: "failure",
+			in:   batchResult{username: "bob", err: errors.New("user not found")},
+			want: map[string]string{
+				"username": "bob",
+				"timezone": "",
+				"error":    "user not found",
+			},
+		},
+		{
+			name: "nil result",
+			in:   batchResult{username: "carol"},
+			want: map[string]string{
+				"username": "carol",
+				"error":    "no result",
+			},
+		},
+	}
+
+	header := csvHeader()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			row := csvRow(tt.in)
+			if len(row) != len(header) {
+				t.Fatalf("row has %d columns, header has %d", len(row), len(header))
+			}
+			got := make(map[string]string, len(header))
+			for i, name := range header {
+				got[name] = row[i]
+			}
+			for col, want := range tt.want {
+				if got[col] != want {
+					t.Errorf("column %q = %q, want %q", col, got[col], want)
+				}
+			}
+		})
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	results := []batchResult{
+		{username: "alice", result: sampleResult()},
+		{username: "bob", err: errors.New("boom")},
+	}
+	if err := writeCSV(&buf, results); err != nil {
+		t.Fatalf("writeCSV: %v", err)
+	}
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("reading CSV: %v", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("got %d records, want header plus 2 rows", len(records))
+	}
+	if strings.Join(records[0], ",") != strings.Join(csvHeader(), ",") {
+		t.Errorf("header = %v, want %v", records[0], csvHeader())
+	}
+	if records[2][0] != "bob" || records[2][len(records[2])-1] != "boom" {
+		t.Errorf("failed row = %v", records[2])
+	}
+}
+
+func TestJSONRecordRekeysHalfHourlyActivity(t *testing.T) {
+	data, err := json.Marshal(jsonRecord(batchResult{username: "alice", result: sampleResult()}))
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+
+	var decoded struct {
+		Username              string         `json:"username"`
+		HalfHourlyActivityUTC map[string]int `json:"half_hourly_activity_utc"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if decoded.Username != "alice" {
+		t.Errorf("username = %q, want alice", decoded.Username)
+	}
+	want := map[string]int{"13.5": 4, "14.0": 7}
+	if len(decoded.HalfHourlyActivityUTC) != len(want) {
+		t.Fatalf("half_hourly_activity_utc = %v, want %v", decoded.HalfHourlyActivityUTC, want)
+	}
+	for k, v := range want {
+		if decoded.HalfHourlyActivityUTC[k] != v {
+			t.Errorf("half_hourly_activity_utc[%q] = %d, want %d", k, decoded.HalfHourlyActivityUTC[k], v)
+		}
+	}
+}
+
+func TestWriteNDJSON(t *testing.T) {
+	var buf bytes.Buffer
+	results := []batchResult{
+		{username: "alice", result: sampleResult()},
+		{username: "bob", err: errors.New("boom")},
+	}
+	if err := writeNDJSON(&buf, results); err != nil {
+		t.Fatalf("writeNDJSON: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2", len(lines))
+	}
+	var failed jsonError
+	if err := json.Unmarshal([]byte(lines[1]), &failed); err != nil {
+		t.Fatalf("unmarshal error line: %v", err)
+	}
+	if failed.Username != "bob" || failed.Error != "boom" {
+		t.Errorf("error line = %+v", failed)
+	}
+}
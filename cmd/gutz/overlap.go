@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/codeGROOVE-dev/guTZ/pkg/gutz"
+	"github.com/codeGROOVE-dev/guTZ/pkg/httpreplay"
+	"github.com/codeGROOVE-dev/guTZ/pkg/overlap"
+	"github.com/codeGROOVE-dev/guTZ/pkg/tzconvert"
+)
+
+// runOverlap implements "gutz overlap <username>...", which detects each user and
+// suggests meeting windows that work for the whole group.
+func runOverlap(logger *slog.Logger, opts []gutz.Option, archive *httpreplay.Archive, args []string) int {
+	fs := flag.NewFlagSet("overlap", flag.ExitOnError)
+	length := fs.Duration("length", time.Hour, "Meeting length (rounded up to 30 minutes)")
+	top := fs.Int("top", 3, "Number of meeting windows to suggest")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [flags] overlap [overlap-flags] <github-username>...\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+
+	usernames, err := collectUsernames(fs.Args(), *usersFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	if len(usernames) < 2 {
+		fs.Usage()
+		return 1
+	}
+
+	ctx, stop := context.WithCancel(context.Background())
+	defer stop()
+
+	detector := gutz.NewWithLogger(ctx, logger, opts...)
+	defer func() {
+		if err := detector.Close(); err != nil {
+			logger.Error("Failed to close detector", "error", err)
+		}
+	}()
+
+	fmt.Fprintf(os.Stderr, "🔎 Detecting %d users (concurrency %d)\n", len(usernames), *concurrency)
+	batch := runBatch(ctx, detector, usernames, *concurrency, 30*time.Second, nil, phaseReporter())
+	saveRecording(logger, archive)
+
+	var results []*gutz.Result
+	for _, r := range batch {
+		if r.err != nil || r.result == nil {
+			fmt.Fprintf(os.Stderr, "   ⚠️  skipping %s: %v\n", r.username, r.err)
+			continue
+		}
+		results = append(results, r.result)
+	}
+	if len(results) < 2 {
+		fmt.Fprintln(os.Stderr, "Error: need at least two successful detections to plan a meeting")
+		return 1
+	}
+
+	windowSlots := int((*length + 29*time.Minute) / (30 * time.Minute))
+	plan := overlap.Build(results, overlap.Options{WindowSlots: windowSlots, MaxWindows: *top})
+
+	switch *outputFormat {
+	case outputJSON, outputNDJSON:
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(plan); err != nil {
+			logger.Error("Failed to write output", "error", err)
+			return 1
+		}
+	case outputCSV:
+		fmt.Fprintln(os.Stderr, "Error: overlap supports text and json output")
+		return 1
+	default:
+		printOverlapPlan(os.Stdout, plan, windowSlots)
+	}
+	return 0
+}
+
+// printOverlapPlan renders the plan as a human-readable report.
+func printOverlapPlan(w io.Writer, plan *overlap.Plan, windowSlots int) {
+	nameWidth := 0
+	for _, m := range plan.Members {
+		nameWidth = max(nameWidth, len(m.Username))
+	}
+
+	fmt.Fprintf(w, "\n🤝 Team Overlap (%d people)\n", len(plan.Members))
+	fmt.Fprintln(w, strings.Repeat("─", 50))
+
+	fmt.Fprintf(w, "📅 Best %s meeting windows:\n", (time.Duration(windowSlots) * 30 * time.Minute).String())
+	if len(plan.Windows) == 0 {
+		fmt.Fprintln(w, "   No workable window found")
+	}
+	for i, win := range plan.Windows {
+		fmt.Fprintf(w, "   %d. %s → %s UTC (%.0f%% availability)\n",
+			i+1, formatHour(win.StartUTC), formatHour(win.EndUTC), win.Score*100)
+		for _, m := range plan.Members {
+			fmt.Fprintf(w, "        %-*s %s → %s (%s)\n", nameWidth, m.Username,
+				formatHour(tzconvert.UTCToLocal(win.StartUTC, m.Offset)),
+				formatHour(tzconvert.UTCToLocal(win.EndUTC, m.Offset)),
+				m.Timezone)
+		}
+		if len(win.Asleep) > 0 {
+			fmt.Fprintf(w, "        💤 asleep: %s\n", strings.Join(win.Asleep, ", "))
+		}
+		if len(win.AtLunch) > 0 {
+			fmt.Fprintf(w, "        🍽️  lunch:  %s\n", strings.Join(win.AtLunch, ", "))
+		}
+		if len(win.OffHours) > 0 {
+			fmt.Fprintf(w, "        🌙 off-hours: %s\n", strings.Join(win.OffHours, ", "))
+		}
+	}
+
+	fmt.Fprintln(w, "\n😣 Pain Score (0 = always in working hours, 4 = always asleep):")
+	for _, m := range plan.Members {
+		fmt.Fprintf(w, "   %-*s %.2f\n", nameWidth, m.Username, m.PainScore)
+	}
+
+	fmt.Fprintln(w, "\n🗓️  Day Grid (UTC, one column per 30 minutes):")
+	var header strings.Builder
+	for h := 0; h < 24; h += 3 {
+		header.WriteString(fmt.Sprintf("%-6d", h))
+	}
+	fmt.Fprintf(w, "   %-*s %s\n", nameWidth, "", header.String())
+	symbols := map[overlap.Status]string{
+		overlap.Active: "█",
+		overlap.Off:    "·",
+		overlap.Lunch:  "L",
+		overlap.Asleep: "z",
+	}
+	for _, m := range plan.Members {
+		var row strings.Builder
+		for _, st := range m.Slots {
+			row.WriteString(symbols[st])
+		}
+		fmt.Fprintf(w, "   %-*s %s\n", nameWidth, m.Username, row.String())
+	}
+	fmt.Fprintln(w, "   █ active  · off-hours  L lunch  z asleep")
+	fmt.Fprintln(w)
+}
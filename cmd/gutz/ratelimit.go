@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/codeGROOVE-dev/guTZ/pkg/gutz"
+)
+
+// runRateLimitStatus prints the GitHub budget and returns the process exit code.
+func runRateLimitStatus(logger *slog.Logger, opts []gutz.Option) int {
+	detector := gutz.NewWithLogger(context.Background(), logger, append(opts, gutz.WithNoCache())...)
+	if !printRateLimits(os.Stderr, detector) {
+		return 1
+	}
+	return 0
+}
+
+// printRateLimits writes one line per GitHub budget, reporting whether it could fetch them.
+func printRateLimits(w io.Writer, detector *gutz.Detector) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	budgets, err := detector.RateLimits(ctx)
+	if err != nil {
+		fmt.Fprintf(w, "Error: %v\n", err)
+		return false
+	}
+	fmt.Fprintf(w, "📊 GitHub API budget (%s on exhaustion)\n", detector.RateLimitPolicy())
+	for _, b := range budgets {
+		fmt.Fprintf(w, "   %-22s %5d/%-5d resets %s\n", b.Resource, b.Remaining, b.Limit, b.Reset.Local().Format(time.Kitchen))
+	}
+	return true
+}
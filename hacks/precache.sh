@@ -8,10 +8,13 @@ command -v jq &> /dev/null || { echo "Error: jq not installed"; exit 1; }
 
 TOKEN=$(gh auth token)
 [ -z "$TOKEN" ] && { echo "Error: Not authenticated with GitHub CLI"; exit 1; }
+# An API key with the detect scope and a quota sized for precaching; see --api-keys in gutz-server
+[ -z "$GUTZ_API_KEY" ] && { echo "Error: GUTZ_API_KEY is not set"; exit 1; }
 
 # Temp file for users
 TEMP_USERS=$(mktemp)
-trap "rm -f $TEMP_USERS" EXIT
+TEMP_BODY=$(mktemp)
+trap "rm -f $TEMP_USERS $TEMP_BODY" EXIT
 
 echo "Collecting GitHub users..."
 
@@ -51,25 +54,22 @@ USER_COUNT=$(echo "$UNIQUE_USERS" | wc -l | tr -d ' ')
 
 echo "Found $USER_COUNT unique users"
 
-# Run detection API for each user
-for USER in $UNIQUE_USERS; do
-    echo "$USER: "
-    curl -s 'https://tz.github.robot-army.dev/api/v1/detect' \
+# Submit the users as bulk jobs of at most 1000 (the server's limit) and wait for each to finish
+SERVER='https://tz.github.robot-army.dev'
+echo "$UNIQUE_USERS" | split -l 1000 - "$TEMP_USERS.chunk."
+for CHUNK in "$TEMP_USERS".chunk.*; do
+    JOB=$(jq -R . "$CHUNK" | jq -s '{usernames: .}' | curl -sf "$SERVER/api/v1/jobs" \
         -X POST \
-        -H 'User-Agent: Precache' \
-        -H 'Accept: */*' \
-        -H 'Accept-Language: en-US,en;q=0.5' \
-        -H 'Accept-Encoding: gzip, deflate, br, zstd' \
-        -H 'Referer: https://tz.github.robot-army.dev/' \
+        -H "Authorization: Bearer $GUTZ_API_KEY" \
         -H 'Content-Type: application/json' \
-        -H 'Origin: https://tz.github.robot-army.dev' \
-        -H 'Connection: keep-alive' \
-        -H 'Sec-Fetch-Dest: empty' \
-        -H 'Sec-Fetch-Mode: cors' \
-        -H 'Sec-Fetch-Site: same-origin' \
-        -H 'Priority: u=0' \
-        -H 'TE: trailers' \
-        --data-raw "{\"username\":\"$USER\"}"
-    echo
-    sleep 4
+        --data-binary @- | jq -r '.id')
+    rm -f "$CHUNK"
+    [ -z "$JOB" ] && { echo "Error: job submission failed"; exit 1; }
+    while true; do
+        STATUS=$(curl -sf "$SERVER/api/v1/jobs/$JOB")
+        echo "Job $JOB: $(echo "$STATUS" | jq -r '"\(.status), \(.done)/\(.usernames | length) done, \(.failed) failed"')"
+        [ "$(echo "$STATUS" | jq -r '.status')" = "done" ] && break
+        sleep 30
+    done
+    curl -sf "$SERVER/api/v1/jobs/$JOB/results" | jq -c '{username, timezone, error}'
 done
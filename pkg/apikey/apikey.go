@@ -0,0 +1,253 @@
+// Package apikey authenticates gutz-server clients by API key, and enforces each key's scopes and quotas.
+package apikey
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Scope is something a key is allowed to do.
+type Scope string
+
+const (
+	// ScopeDetect allows detections, streamed or not.
+	ScopeDetect Scope = "detect"
+	// ScopeRefresh allows detections that bypass every cache.
+	ScopeRefresh Scope = "refresh"
+	// ScopeAdmin allows the /_/x-* admin endpoints.
+	ScopeAdmin Scope = "admin"
+)
+
+var (
+	// ErrUnknownKey is returned for a key that is not configured.
+	ErrUnknownKey = errors.New("unknown API key")
+	// ErrScope is returned when a key lacks a scope a request needs.
+	ErrScope = errors.New("API key lacks scope")
+	// ErrQuota matches every *QuotaError with errors.Is.
+	ErrQuota = errors.New("API key quota exceeded")
+)
+
+// QuotaError reports a request refused because its key used up a quota.
+type QuotaError struct {
+	Reset time.Time // When the key may make requests again
+	Name  string
+	Quota string // "minute" or "day"
+}
+
+func (e *QuotaError) Error() string {
+	return fmt.Sprintf("API key %q exceeded its per-%s quota until %s", e.Name, e.Quota, e.Reset.UTC().Format(time.RFC3339))
+}
+
+// Is makes errors.Is(err, ErrQuota) match.
+func (*QuotaError) Is(target error) bool {
+	return target == ErrQuota
+}
+
+// Key is one configured API key. Only the SHA-256 of the secret is kept, so the key file reveals no keys.
+type Key struct {
+	Name      string  `json:"name"`
+	SHA256    string  `json:"sha256"` // Hex SHA-256 of the secret, as printed by sha256sum
+	Scopes    []Scope `json:"scopes"`
+	PerMinute int     `json:"per_minute,omitempty"` // Requests per minute; 0 means unlimited
+	PerDay    int     `json:"per_day,omitempty"`    // Requests per UTC day; 0 means unlimited
+}
+
+// Usage is the accounting of one key since the keyring was loaded.
+type Usage struct {
+	LastUsed time.Time `json:"last_used,omitzero"`
+	Name     string    `json:"name"`
+	Requests int64     `json:"requests"` // Allowed requests
+	Refused  int64     `json:"refused"`  // Requests over quota or out of scope
+	Today    int       `json:"today"`    // Requests allowed in the current UTC day
+}
+
+type entry struct {
+	day    string      // UTC day that today counts, as 2006-01-02
+	recent []time.Time // Allowed requests in the last minute
+	key    Key
+	usage  Usage
+}
+
+// Keyring holds the configured keys and their usage.
+type Keyring struct {
+	now  func() time.Time
+	keys map[string]*entry // By SHA-256
+	mu   sync.Mutex
+}
+
+// Hash returns the hex SHA-256 of a secret, as stored in Key.SHA256.
+func Hash(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// New returns a keyring holding keys.
+func New(keys ...Key) (*Keyring, error) {
+	k := &Keyring{now: time.Now, keys: make(map[string]*entry, len(keys))}
+	names := map[string]bool{}
+	for _, key := range keys {
+		if key.Name == "" || names[key.Name] {
+			return nil, fmt.Errorf("API key names must be unique and non-empty, got %q", key.Name)
+		}
+		names[key.Name] = true
+		key.SHA256 = strings.ToLower(key.SHA256)
+		if raw, err := hex.DecodeString(key.SHA256); err != nil || len(raw) != sha256.Size {
+			return nil, fmt.Errorf("API key %q: sha256 must be 64 hex digits", key.Name)
+		}
+		if _, dup := k.keys[key.SHA256]; dup {
+			return nil, fmt.Errorf("API key %q reuses the secret of another key", key.Name)
+		}
+		for _, s := range key.Scopes {
+			if s != ScopeDetect && s != ScopeRefresh && s != ScopeAdmin {
+				return nil, fmt.Errorf("API key %q: unknown scope %q (want detect, refresh or admin)", key.Name, s)
+			}
+		}
+		k.keys[key.SHA256] = &entry{key: key, usage: Usage{Name: key.Name}}
+	}
+	return k, nil
+}
+
+// Load reads a keyring from a JSON file of the form {"keys": [{"name": ..., "sha256": ..., "scopes": [...]}]}.
+func Load(path string) (*Keyring, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read API keys: %w", err)
+	}
+	var file struct {
+		Keys []Key `json:"keys"`
+	}
+	if err := json.Unmarshal(raw, &file); err != nil {
+		return nil, fmt.Errorf("decode API keys: %w", err)
+	}
+	return New(file.Keys...)
+}
+
+// Len returns the number of keys.
+func (k *Keyring) Len() int {
+	return len(k.keys)
+}
+
+// Allow admits a request made with secret that needs every one of scopes, charging it to the key's
+// quotas. It returns the key's name, and an error wrapping ErrUnknownKey, ErrScope or a *QuotaError
+// when the request is refused.
+func (k *Keyring) Allow(secret string, scopes ...Scope) (string, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	e, ok := k.keys[Hash(secret)]
+	if !ok {
+		return "", ErrUnknownKey
+	}
+	return e.key.Name, k.allow(e, scopes)
+}
+
+// Charge is Allow for a key known by name, for work a key holder queued earlier, such as the users
+// of a bulk job, that is charged as it runs.
+func (k *Keyring) Charge(name string, scopes ...Scope) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	e := k.byName(name)
+	if e == nil {
+		return ErrUnknownKey
+	}
+	return k.allow(e, scopes)
+}
+
+// Name returns the name of the key with secret.
+func (k *Keyring) Name(secret string) (string, bool) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	e, ok := k.keys[Hash(secret)]
+	if !ok {
+		return "", false
+	}
+	return e.key.Name, true
+}
+
+// RemainingToday returns how many more requests the key called name may make in the current UTC
+// day, or -1 if it has no daily quota.
+func (k *Keyring) RemainingToday(name string) int {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	e := k.byName(name)
+	if e == nil {
+		return 0
+	}
+	if e.key.PerDay <= 0 {
+		return -1
+	}
+	if e.day != k.now().UTC().Format(time.DateOnly) {
+		return e.key.PerDay
+	}
+	return max(0, e.key.PerDay-e.usage.Today)
+}
+
+// byName returns the entry of the key called name, or nil. The caller holds k.mu.
+func (k *Keyring) byName(name string) *entry {
+	for _, e := range k.keys {
+		if e.key.Name == name {
+			return e
+		}
+	}
+	return nil
+}
+
+// allow charges a request needing scopes to e. The caller holds k.mu.
+func (k *Keyring) allow(e *entry, scopes []Scope) error {
+	name := e.key.Name
+	for _, s := range scopes {
+		if !slices.Contains(e.key.Scopes, s) {
+			e.usage.Refused++
+			return fmt.Errorf("%w %s", ErrScope, s)
+		}
+	}
+
+	now := k.now()
+	if day := now.UTC().Format(time.DateOnly); day != e.day {
+		e.day, e.usage.Today = day, 0
+	}
+	cutoff := now.Add(-time.Minute)
+	for len(e.recent) > 0 && !e.recent[0].After(cutoff) {
+		e.recent = e.recent[1:]
+	}
+	if e.key.PerMinute > 0 && len(e.recent) >= e.key.PerMinute {
+		e.usage.Refused++
+		return &QuotaError{Name: name, Quota: "minute", Reset: e.recent[0].Add(time.Minute)}
+	}
+	if e.key.PerDay > 0 && e.usage.Today >= e.key.PerDay {
+		e.usage.Refused++
+		y, m, d := now.UTC().Date()
+		return &QuotaError{Name: name, Quota: "day", Reset: time.Date(y, m, d+1, 0, 0, 0, 0, time.UTC)}
+	}
+
+	e.recent = append(e.recent, now)
+	e.usage.Requests++
+	e.usage.Today++
+	e.usage.LastUsed = now
+	return nil
+}
+
+// Usage returns the accounting of every key, by name.
+func (k *Keyring) Usage() []Usage {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	out := make([]Usage, 0, len(k.keys))
+	today := k.now().UTC().Format(time.DateOnly)
+	for _, e := range k.keys {
+		u := e.usage
+		if e.day != today {
+			u.Today = 0
+		}
+		out = append(out, u)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
@@ -0,0 +1,118 @@
+package apikey
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAllow(t *testing.T) {
+	k, err := New(
+		Key{Name: "precache", SHA256: Hash("pre-secret"), Scopes: []Scope{ScopeDetect, ScopeRefresh}, PerMinute: 2, PerDay: 3},
+		Key{Name: "ops", SHA256: Hash("ops-secret"), Scopes: []Scope{ScopeAdmin}},
+	)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	now := time.Date(2025, 3, 4, 23, 58, 0, 0, time.UTC)
+	k.now = func() time.Time { return now }
+
+	if _, err := k.Allow("guess", ScopeDetect); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("unknown key: error = %v, want ErrUnknownKey", err)
+	}
+	if _, err := k.Allow("ops-secret", ScopeDetect); !errors.Is(err, ErrScope) {
+		t.Errorf("out of scope: error = %v, want ErrScope", err)
+	}
+	if name, err := k.Allow("ops-secret", ScopeAdmin); err != nil || name != "ops" {
+		t.Errorf("admin: Allow = %q, %v", name, err)
+	}
+
+	for range 2 {
+		if _, err := k.Allow("pre-secret", ScopeDetect, ScopeRefresh); err != nil {
+			t.Fatalf("within quota: %v", err)
+		}
+	}
+	var qErr *QuotaError
+	if _, err := k.Allow("pre-secret", ScopeDetect); !errors.As(err, &qErr) || qErr.Quota != "minute" || !errors.Is(err, ErrQuota) {
+		t.Fatalf("third request in a minute: error = %v, want the per-minute quota", err)
+	}
+
+	now = now.Add(time.Minute)
+	if _, err := k.Allow("pre-secret", ScopeDetect); err != nil {
+		t.Fatalf("next minute: %v", err)
+	}
+	if _, err := k.Allow("pre-secret", ScopeDetect); !errors.As(err, &qErr) || qErr.Quota != "day" ||
+		!qErr.Reset.Equal(time.Date(2025, 3, 5, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("fourth request in a day: error = %v, want the per-day quota until midnight", err)
+	}
+
+	now = now.Add(2 * time.Minute) // Past midnight UTC
+	if _, err := k.Allow("pre-secret", ScopeDetect); err != nil {
+		t.Fatalf("next day: %v", err)
+	}
+
+	usage := k.Usage()
+	if len(usage) != 2 || usage[1].Name != "precache" || usage[1].Requests != 4 || usage[1].Refused != 2 || usage[1].Today != 1 {
+		t.Errorf("usage = %+v", usage)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.json")
+	data := `{"keys": [{"name": "ci", "sha256": "` + Hash("ci-secret") + `", "scopes": ["detect"], "per_day": 100}]}`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	k, err := Load(path)
+	if err != nil || k.Len() != 1 {
+		t.Fatalf("Load = %v keys, %v", k, err)
+	}
+	if name, err := k.Allow("ci-secret", ScopeDetect); err != nil || name != "ci" {
+		t.Errorf("Allow = %q, %v", name, err)
+	}
+
+	for _, bad := range []Key{
+		{Name: "", SHA256: Hash("x")},
+		{Name: "short", SHA256: "abc"},
+		{Name: "scope", SHA256: Hash("x"), Scopes: []Scope{"delete"}},
+	} {
+		if _, err := New(bad); err == nil {
+			t.Errorf("New(%+v) succeeded", bad)
+		}
+	}
+}
+
+func TestCharge(t *testing.T) {
+	k, err := New(Key{Name: "bulk", SHA256: Hash("bulk-secret"), Scopes: []Scope{ScopeDetect}, PerDay: 3})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if name, ok := k.Name("bulk-secret"); !ok || name != "bulk" {
+		t.Errorf("Name = %q, %v", name, ok)
+	}
+	if got := k.RemainingToday("bulk"); got != 3 {
+		t.Errorf("RemainingToday = %d, want 3", got)
+	}
+	if _, err := k.Allow("bulk-secret", ScopeDetect); err != nil {
+		t.Fatalf("Allow: %v", err)
+	}
+	for range 2 {
+		if err := k.Charge("bulk", ScopeDetect); err != nil {
+			t.Fatalf("Charge: %v", err)
+		}
+	}
+	if got := k.RemainingToday("bulk"); got != 0 {
+		t.Errorf("RemainingToday = %d, want 0", got)
+	}
+	if err := k.Charge("bulk", ScopeDetect); !errors.Is(err, ErrQuota) {
+		t.Errorf("Charge over quota: error = %v, want ErrQuota", err)
+	}
+	if err := k.Charge("bulk", ScopeRefresh); !errors.Is(err, ErrScope) {
+		t.Errorf("Charge out of scope: error = %v, want ErrScope", err)
+	}
+	if err := k.Charge("gone", ScopeDetect); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("Charge of an unknown key: error = %v, want ErrUnknownKey", err)
+	}
+}
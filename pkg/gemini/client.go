@@ -73,13 +73,18 @@ func (c *Client) CallWithSDK(ctx context.Context, prompt string, cache Cache, lo
 	return c.processResponseAndCache(resp, prompt, cache, logger)
 }
 
+// CacheKey returns the key under which the response to prompt is cached.
+func (c *Client) CacheKey(prompt string) string {
+	return fmt.Sprintf("genai:%s:%s", c.model, prompt)
+}
+
 // checkCache checks for cached responses and returns them if valid.
 func (c *Client) checkCache(prompt string, cache Cache, logger Logger) *Response {
 	if cache == nil {
 		return nil
 	}
 
-	cacheKey := fmt.Sprintf("genai:%s:%s", c.model, prompt)
+	cacheKey := c.CacheKey(prompt)
 	cachedData, found := cache.APICall(cacheKey, []byte(prompt))
 	if !found {
 		return nil
@@ -229,6 +234,34 @@ func (*Client) createResponseSchema() *genai.Schema {
 	}
 }
 
+// ResponseJSONSchema returns the structured output schema as a standard JSON Schema object
+// for backends that don't speak genai types.
+func ResponseJSONSchema() map[string]any {
+	return toJSONSchema((*Client)(nil).createResponseSchema())
+}
+
+// toJSONSchema converts a genai schema into its JSON Schema equivalent.
+func toJSONSchema(s *genai.Schema) map[string]any {
+	out := map[string]any{"type": strings.ToLower(string(s.Type))}
+	if s.Description != "" {
+		out["description"] = s.Description
+	}
+	if len(s.Enum) > 0 {
+		out["enum"] = s.Enum
+	}
+	if len(s.Properties) > 0 {
+		props := make(map[string]any, len(s.Properties))
+		for name, prop := range s.Properties {
+			props[name] = toJSONSchema(prop)
+		}
+		out["properties"] = props
+	}
+	if len(s.Required) > 0 {
+		out["required"] = s.Required
+	}
+	return out
+}
+
 // makeAPICallWithRetry executes the API call with retry logic.
 func (c *Client) makeAPICallWithRetry(ctx context.Context, client *genai.Client, modelName string,
 	contents []*genai.Content, config *genai.GenerateContentConfig, logger Logger,
@@ -313,36 +346,9 @@ func (c *Client) processResponseAndCache(resp *genai.GenerateContentResponse, pr
 
 	logger.Debug("Raw Gemini response", "response_text", text)
 
-	var geminiResp Response
-	if err := json.Unmarshal([]byte(text), &geminiResp); err != nil {
-		// Fallback to JSON extraction for older cached responses
-		logger.Debug("Direct JSON parse failed, trying extraction", "error", err)
-		jsonText, extractErr := c.extractJSON(text)
-		if extractErr != nil {
-			logger.Warn("Failed to parse Gemini JSON response", "parse_error", err, "extract_error", extractErr, "response_text", text)
-			return nil, fmt.Errorf("failed to parse Gemini JSON response: %w", err)
-		}
-		if err := json.Unmarshal([]byte(jsonText), &geminiResp); err != nil {
-			logger.Warn("Failed to parse extracted JSON", "error", err, "json_text", jsonText, "response_text", text)
-			return nil, fmt.Errorf("failed to parse Gemini JSON response: %w", err)
-		}
-	}
-
-	c.cleanResponse(&geminiResp)
-
-	// Log the parsed struct values for debugging
-	logger.Debug("Parsed Gemini response struct",
-		"detected_timezone", geminiResp.DetectedTimezone,
-		"detected_location", geminiResp.DetectedLocation,
-		"latitude", geminiResp.Latitude,
-		"longitude", geminiResp.Longitude,
-		"confidence_level", geminiResp.ConfidenceLevel,
-		"detection_reasoning", geminiResp.DetectionReasoning)
-
-	// Validate the response has required fields
-	if geminiResp.DetectedTimezone == "" {
-		logger.Warn("Gemini response missing timezone field", "response", geminiResp)
-		return nil, errors.New("gemini response missing timezone information")
+	geminiResp, err := ParseResponse(text, logger)
+	if err != nil {
+		return nil, err
 	}
 
 	logger.Info("Gemini response received",
@@ -352,7 +358,7 @@ func (c *Client) processResponseAndCache(resp *genai.GenerateContentResponse, pr
 
 	// Cache the response
 	if cache != nil {
-		cacheKey := fmt.Sprintf("genai:%s:%s", c.model, prompt)
+		cacheKey := c.CacheKey(prompt)
 		if respData, err := json.Marshal(geminiResp); err == nil {
 			if err := cache.SetAPICall(cacheKey, []byte(prompt), respData); err != nil {
 				logger.Debug("Failed to cache Gemini response", "error", err)
@@ -362,11 +368,48 @@ func (c *Client) processResponseAndCache(resp *genai.GenerateContentResponse, pr
 		}
 	}
 
-	return &geminiResp, nil
+	return geminiResp, nil
+}
+
+// ParseResponse parses a model's text output into a Response. It accepts bare JSON as well as
+// JSON wrapped in code fences or explanatory text, and rejects verdicts without a timezone.
+func ParseResponse(text string, logger Logger) (*Response, error) {
+	var resp Response
+	if err := json.Unmarshal([]byte(text), &resp); err != nil {
+		// Fallback to JSON extraction for older cached responses and models without structured output
+		logger.Debug("Direct JSON parse failed, trying extraction", "error", err)
+		jsonText, extractErr := extractJSON(text)
+		if extractErr != nil {
+			logger.Warn("Failed to parse Gemini JSON response", "parse_error", err, "extract_error", extractErr, "response_text", text)
+			return nil, fmt.Errorf("failed to parse Gemini JSON response: %w", err)
+		}
+		if err := json.Unmarshal([]byte(jsonText), &resp); err != nil {
+			logger.Warn("Failed to parse extracted JSON", "error", err, "json_text", jsonText, "response_text", text)
+			return nil, fmt.Errorf("failed to parse Gemini JSON response: %w", err)
+		}
+	}
+
+	cleanResponse(&resp)
+
+	// Log the parsed struct values for debugging
+	logger.Debug("Parsed Gemini response struct",
+		"detected_timezone", resp.DetectedTimezone,
+		"detected_location", resp.DetectedLocation,
+		"latitude", resp.Latitude,
+		"longitude", resp.Longitude,
+		"confidence_level", resp.ConfidenceLevel,
+		"detection_reasoning", resp.DetectionReasoning)
+
+	// Validate the response has required fields
+	if resp.DetectedTimezone == "" {
+		logger.Warn("Gemini response missing timezone field", "response", resp)
+		return nil, errors.New("gemini response missing timezone information")
+	}
+	return &resp, nil
 }
 
 // extractJSON extracts JSON content from a response that may contain explanatory text.
-func (*Client) extractJSON(text string) (string, error) {
+func extractJSON(text string) (string, error) {
 	// First try to parse as direct JSON
 	if isValidJSON(text) {
 		return text, nil
@@ -414,7 +457,7 @@ func isValidJSON(s string) bool {
 }
 
 // cleanResponse cleans up response data by removing newlines and extra spaces.
-func (*Client) cleanResponse(resp *Response) {
+func cleanResponse(resp *Response) {
 	resp.DetectedTimezone = strings.TrimSpace(strings.ReplaceAll(resp.DetectedTimezone, "\n", " "))
 	resp.DetectedLocation = strings.TrimSpace(strings.ReplaceAll(resp.DetectedLocation, "\n", " "))
 	resp.DetectionReasoning = strings.TrimSpace(strings.ReplaceAll(resp.DetectionReasoning, "\n", " "))
@@ -0,0 +1,460 @@
+package geocode
+
+import (
+	"bufio"
+	"context"
+	"embed"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+//go:embed data/cities.tsv data/countries.tsv data/regions.tsv
+var tables embed.FS
+
+const (
+	// maxNearestCityKm is how far the nearest gazetteer city may be before TimezoneForCoordinates
+	// falls back to the nautical zone for the longitude (open ocean, polar regions).
+	maxNearestCityKm = 1000
+	// earthRadiusKm is the mean radius of the Earth.
+	earthRadiusKm = 6371
+)
+
+// City is a gazetteer entry.
+type City struct {
+	Name       string
+	Country    string // ISO 3166-1 alpha-2 code
+	Region     string // First-level subdivision code, such as a US state; may be empty
+	Timezone   string // IANA time zone
+	Latitude   float64
+	Longitude  float64
+	Population int
+}
+
+type region struct {
+	country, code string
+}
+
+// Gazetteer is an offline Geocoder backed by an embedded GeoNames-style table of cities.
+// It understands common spellings, abbreviations and nicknames ("SF Bay Area", "Zürich, CH", "NYC"),
+// uses country and state qualifiers to pick between same-named cities, and tolerates small typos.
+// Time zones for coordinates come from the nearest city, which approximates zone boundaries
+// to within the spacing of the table; it lists cities on both sides of the borders that run
+// through populated areas, such as Indiana's and Afghanistan's.
+type Gazetteer struct {
+	byName    map[string][]int    // normalized name or alternate name -> indexes into cities
+	countries map[string]string   // normalized country name or code -> country code
+	regions   map[string][]region // normalized region name or code -> regions
+	cities    []City
+	maxWords  int // longest name, in words
+}
+
+var offline = sync.OnceValues(func() (*Gazetteer, error) {
+	return newGazetteer()
+})
+
+// Offline returns the embedded gazetteer. It is parsed on first use and shared afterwards.
+func Offline() (*Gazetteer, error) {
+	return offline()
+}
+
+func newGazetteer() (*Gazetteer, error) {
+	g := &Gazetteer{
+		byName:    map[string][]int{},
+		countries: map[string]string{},
+		regions:   map[string][]region{},
+	}
+
+	err := readTable("data/cities.tsv", 8, func(f []string) error {
+		lat, err := strconv.ParseFloat(f[4], 64)
+		if err != nil {
+			return fmt.Errorf("latitude: %w", err)
+		}
+		lng, err := strconv.ParseFloat(f[5], 64)
+		if err != nil {
+			return fmt.Errorf("longitude: %w", err)
+		}
+		population, err := strconv.Atoi(f[6])
+		if err != nil {
+			return fmt.Errorf("population: %w", err)
+		}
+		idx := len(g.cities)
+		g.cities = append(g.cities, City{
+			Name: f[0], Country: f[2], Region: f[3], Timezone: f[7],
+			Latitude: lat, Longitude: lng, Population: population,
+		})
+		for _, name := range append([]string{f[0]}, splitList(f[1])...) {
+			key := normalize(name)
+			g.byName[key] = append(g.byName[key], idx)
+			g.maxWords = max(g.maxWords, strings.Count(key, " ")+1)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = readTable("data/countries.tsv", 2, func(f []string) error {
+		g.countries[strings.ToLower(f[0])] = f[0]
+		for _, name := range splitList(f[1]) {
+			g.countries[normalize(name)] = f[0]
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = readTable("data/regions.tsv", 3, func(f []string) error {
+		r := region{country: f[0], code: f[1]}
+		for _, name := range append([]string{f[1]}, splitList(f[2])...) {
+			key := normalize(name)
+			g.regions[key] = append(g.regions[key], r)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// readTable calls fn with the fields of each row of an embedded tab-separated table.
+func readTable(name string, columns int, fn func(fields []string) error) error {
+	f, err := tables.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close() //nolint:errcheck // read-only embedded file
+
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Split(text, "\t")
+		if len(fields) != columns {
+			return fmt.Errorf("%s:%d: got %d columns, want %d", name, line, len(fields), columns)
+		}
+		if err := fn(fields); err != nil {
+			return fmt.Errorf("%s:%d: %w", name, line, err)
+		}
+	}
+	return scanner.Err()
+}
+
+func splitList(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, ",")
+}
+
+// foldings covers letters that Unicode does not decompose into a base letter and a mark.
+var foldings = strings.NewReplacer("ø", "o", "ł", "l", "đ", "d", "ß", "ss", "æ", "ae", "œ", "oe", "ı", "i", "þ", "th")
+
+// normalize lowercases s, strips diacritics and punctuation, and collapses whitespace,
+// so "Zürich", "ZURICH" and "zurich" compare equal, as do "St. Louis" and "St Louis".
+func normalize(s string) string {
+	folded, _, err := transform.String(transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC), strings.ToLower(s))
+	if err != nil {
+		folded = strings.ToLower(s)
+	}
+	folded = foldings.Replace(folded)
+	var b strings.Builder
+	space := false
+	for _, r := range folded {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if space && b.Len() > 0 {
+				b.WriteByte(' ')
+			}
+			space = false
+			b.WriteRune(r)
+		case r == '\'' || r == '’' || r == '.':
+			// Dropped without a break: "Xi'an" -> "xian", "D.C." -> "dc"
+		default:
+			space = true
+		}
+	}
+	return b.String()
+}
+
+// fillerWords are stripped when a location does not match as written, so "Greater Boston Area" finds Boston.
+var fillerWords = map[string]bool{
+	"greater": true, "area": true, "metro": true, "metropolitan": true, "region": true,
+	"downtown": true, "city": true, "of": true, "the": true, "near": true, "based": true, "in": true,
+}
+
+// Lookup returns the city that best matches a free-form location such as "Zürich, CH",
+// "Portland, Oregon" or "SF Bay Area". Country names alone are too vague to pick a time zone and are not matched.
+func (g *Gazetteer) Lookup(location string) (City, bool) {
+	parts := splitLocation(location)
+	keys := make([]string, len(parts))
+	for i, p := range parts {
+		keys[i] = normalize(p)
+	}
+
+	// A part naming a city, as written or without filler words
+	for i, key := range keys {
+		if c, ok := g.best(g.byName[key], keys, i); ok {
+			return c, true
+		}
+		if c, ok := g.best(g.byName[stripFiller(key)], keys, i); ok {
+			return c, true
+		}
+		// A qualifier without a separator: "Zurich Switzerland", "Austin TX"
+		if name, qualifier := g.splitQualifier(key); name != "" {
+			if c, ok := g.best(g.byName[name], append([]string{qualifier}, keys...), i+1); ok {
+				return c, true
+			}
+		}
+	}
+
+	// A city named somewhere inside a part: "Living in Berlin", "Software engineer, Mountain View"
+	for i, part := range parts {
+		if c, ok := g.best(g.mentioned(part), keys, i); ok {
+			return c, true
+		}
+	}
+
+	// A misspelled city: "Amsterdan", "Zurick"
+	for i, key := range keys {
+		if c, ok := g.best(g.misspelled(key), keys, i); ok {
+			return c, true
+		}
+	}
+	return City{}, false
+}
+
+// splitLocation splits a location into its comma-, slash- or dash-separated parts.
+func splitLocation(location string) []string {
+	location = strings.NewReplacer(" - ", ",", " – ", ",", " · ", ",", " | ", ",").Replace(location)
+	fields := strings.FieldsFunc(location, func(r rune) bool {
+		return strings.ContainsRune(",;/|()\n", r)
+	})
+	parts := make([]string, 0, len(fields))
+	for _, f := range fields {
+		if f = strings.TrimSpace(f); f != "" {
+			parts = append(parts, f)
+		}
+	}
+	return parts
+}
+
+func stripFiller(key string) string {
+	words := strings.Fields(key)
+	kept := words[:0]
+	for _, w := range words {
+		if !fillerWords[w] {
+			kept = append(kept, w)
+		}
+	}
+	return strings.Join(kept, " ")
+}
+
+// splitQualifier splits trailing words naming a country or region from key,
+// returning empty strings when there are none.
+func (g *Gazetteer) splitQualifier(key string) (name, qualifier string) {
+	words := strings.Fields(key)
+	for n := min(3, len(words)-1); n >= 1; n-- {
+		suffix := strings.Join(words[len(words)-n:], " ")
+		_, country := g.countries[suffix]
+		_, region := g.regions[suffix]
+		if country || region {
+			return strings.Join(words[:len(words)-n], " "), suffix
+		}
+	}
+	return "", ""
+}
+
+// mentioned returns the cities named by runs of capitalized words within part.
+// Requiring a capital keeps ordinary words ("nice", "split") from matching cities.
+func (g *Gazetteer) mentioned(part string) []int {
+	words := strings.Fields(part)
+	var found []int
+	for start := range words {
+		first, _ := firstRune(words[start])
+		if !unicode.IsUpper(first) && !(unicode.IsLetter(first) && !unicode.IsLower(first)) {
+			continue
+		}
+		for n := min(g.maxWords, len(words)-start); n >= 1; n-- {
+			key := normalize(strings.Join(words[start:start+n], " "))
+			if len(key) < 4 {
+				continue // Too short to tell a city from an abbreviation
+			}
+			if idx, ok := g.byName[key]; ok {
+				found = append(found, idx...)
+				break
+			}
+		}
+	}
+	return found
+}
+
+func firstRune(s string) (rune, bool) {
+	for _, r := range s {
+		return r, true
+	}
+	return 0, false
+}
+
+// misspelled returns the cities whose names are within a small edit distance of key.
+func (g *Gazetteer) misspelled(key string) []int {
+	if len(key) < 5 {
+		return nil
+	}
+	allowed := 1
+	if len(key) >= 8 {
+		allowed = 2
+	}
+	var found []int
+	best := allowed + 1
+	for name, idx := range g.byName {
+		if len(name) < 5 || abs(len(name)-len(key)) > allowed {
+			continue
+		}
+		switch d := editDistance(key, name); {
+		case d > allowed:
+			continue
+		case d < best:
+			best, found = d, append([]int(nil), idx...)
+		case d == best:
+			found = append(found, idx...)
+		default:
+		}
+	}
+	return found
+}
+
+// best picks among candidate cities using the other parts of the location as qualifiers.
+// Qualifiers that name a country or region must agree with the city; otherwise the most populous city wins.
+func (g *Gazetteer) best(candidates []int, keys []string, self int) (City, bool) {
+	if len(candidates) == 0 {
+		return City{}, false
+	}
+	var places []string
+	for i, key := range keys {
+		if i == self {
+			continue
+		}
+		if _, ok := g.countries[key]; ok {
+			places = append(places, key)
+		} else if _, ok := g.regions[key]; ok {
+			places = append(places, key)
+		}
+	}
+
+	bestIdx, bestMatches := -1, 0
+	for _, idx := range candidates {
+		c := g.cities[idx]
+		matches := 0
+		for _, key := range places {
+			if g.qualifies(c, key) {
+				matches++
+			}
+		}
+		if len(places) > 0 && matches == 0 {
+			continue // "Paris, TX" is not Paris, France
+		}
+		if bestIdx < 0 || matches > bestMatches || (matches == bestMatches && c.Population > g.cities[bestIdx].Population) {
+			bestIdx, bestMatches = idx, matches
+		}
+	}
+	if bestIdx < 0 {
+		return City{}, false
+	}
+	return g.cities[bestIdx], true
+}
+
+// qualifies reports whether a normalized country or region name applies to city c.
+func (g *Gazetteer) qualifies(c City, key string) bool {
+	if g.countries[key] == c.Country {
+		return true
+	}
+	for _, r := range g.regions[key] {
+		if r.country == c.Country && r.code == c.Region {
+			return true
+		}
+	}
+	return false
+}
+
+// GeocodeLocation converts a location string to the coordinates of the best matching city.
+func (g *Gazetteer) GeocodeLocation(_ context.Context, location string) (*Location, error) {
+	c, ok := g.Lookup(location)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrNotFound, location)
+	}
+	return &Location{Latitude: c.Latitude, Longitude: c.Longitude}, nil
+}
+
+// TimezoneForCoordinates returns the time zone of the nearest city.
+// Far from any city it returns the nautical zone for the longitude, such as Etc/GMT+10.
+func (g *Gazetteer) TimezoneForCoordinates(_ context.Context, lat, lng float64) (string, error) {
+	if lat < -90 || lat > 90 || lng < -180 || lng > 180 || math.IsNaN(lat) || math.IsNaN(lng) {
+		return "", fmt.Errorf("invalid coordinates %f,%f", lat, lng)
+	}
+	nearest, nearestKm := -1, math.Inf(1)
+	for i, c := range g.cities {
+		if km := distanceKm(lat, lng, c.Latitude, c.Longitude); km < nearestKm {
+			nearest, nearestKm = i, km
+		}
+	}
+	if nearest >= 0 && nearestKm <= maxNearestCityKm {
+		return g.cities[nearest].Timezone, nil
+	}
+	offset := int(math.Round(lng / 15))
+	if offset == 0 {
+		return "Etc/GMT", nil
+	}
+	// Etc zones use POSIX signs: Etc/GMT+10 is ten hours behind UTC
+	return fmt.Sprintf("Etc/GMT%+d", -offset), nil
+}
+
+// distanceKm returns the great-circle distance between two points.
+func distanceKm(lat1, lng1, lat2, lng2 float64) float64 {
+	toRad := math.Pi / 180
+	dLat := (lat2 - lat1) * toRad
+	dLng := (lng2 - lng1) * toRad
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1*toRad)*math.Cos(lat2*toRad)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(a))
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
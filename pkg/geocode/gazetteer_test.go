@@ -0,0 +1,146 @@
+package geocode
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLookup(t *testing.T) {
+	g, err := Offline()
+	if err != nil {
+		t.Fatalf("Offline: %v", err)
+	}
+	tests := []struct {
+		location string
+		want     string // Timezone
+		country  string
+		region   string
+	}{
+		{"SF Bay Area", "America/Los_Angeles", "US", "CA"},
+		{"Zürich, CH", "Europe/Zurich", "CH", ""},
+		{"zurich switzerland", "Europe/Zurich", "CH", ""},
+		{"Portland, OR", "America/Los_Angeles", "US", "OR"},
+		{"Portland, Maine", "America/New_York", "US", "ME"},
+		{"Cambridge, UK", "Europe/London", "GB", ""},
+		{"Cambridge, MA", "America/New_York", "US", "MA"},
+		{"Greater Boston Area", "America/New_York", "US", "MA"},
+		{"NYC", "America/New_York", "US", "NY"},
+		{"München / Germany", "Europe/Berlin", "DE", ""},
+		{"Living in Berlin and loving it", "Europe/Berlin", "DE", ""},
+		{"Amsterdan", "Europe/Amsterdam", "NL", ""},
+		{"Bengaluru, India", "Asia/Kolkata", "IN", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.location, func(t *testing.T) {
+			c, ok := g.Lookup(tt.location)
+			if !ok {
+				t.Fatalf("Lookup(%q) found nothing", tt.location)
+			}
+			if c.Timezone != tt.want || c.Country != tt.country || (tt.region != "" && c.Region != tt.region) {
+				t.Errorf("Lookup(%q) = %s (%s/%s, %s), want %s/%s, %s",
+					tt.location, c.Name, c.Country, c.Region, c.Timezone, tt.country, tt.region, tt.want)
+			}
+		})
+	}
+}
+
+func TestLookupRejectsVagueLocations(t *testing.T) {
+	g, err := Offline()
+	if err != nil {
+		t.Fatalf("Offline: %v", err)
+	}
+	for _, location := range []string{"Germany", "United States", "Earth", "remote", "", "the internet"} {
+		if c, ok := g.Lookup(location); ok {
+			t.Errorf("Lookup(%q) = %s, want no match", location, c.Name)
+		}
+		if _, err := g.GeocodeLocation(context.Background(), location); !errors.Is(err, ErrNotFound) {
+			t.Errorf("GeocodeLocation(%q) error = %v, want ErrNotFound", location, err)
+		}
+	}
+}
+
+func TestTimezoneForCoordinates(t *testing.T) {
+	g, err := Offline()
+	if err != nil {
+		t.Fatalf("Offline: %v", err)
+	}
+	tests := []struct {
+		name     string
+		lat, lng float64
+		want     string
+	}{
+		{"Boulder", 40.015, -105.2705, "America/Denver"},
+		{"Zurich", 47.3769, 8.5417, "Europe/Zurich"},
+		{"Pune", 18.5204, 73.8567, "Asia/Kolkata"},
+		// Near zone borders, where a city across the border used to be the nearest
+		{"Herat airport", 34.2100, 62.2283, "Asia/Kabul"},
+		{"Islam Qala", 34.6600, 61.0700, "Asia/Kabul"},
+		{"Torbat-e Jam", 35.2440, 60.6225, "Asia/Tehran"},
+		{"Kashgar airport", 39.5429, 76.0200, "Asia/Urumqi"},
+		{"Evansville airport", 38.0370, -87.5324, "America/Chicago"},
+		{"Henderson, KY", 37.8362, -87.5900, "America/Chicago"},
+		{"mid-Pacific", 10, -150, "Etc/GMT+10"},
+		{"Indian Ocean", -30, 80, "Etc/GMT-5"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := g.TimezoneForCoordinates(context.Background(), tt.lat, tt.lng)
+			if err != nil {
+				t.Fatalf("TimezoneForCoordinates: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("TimezoneForCoordinates(%v, %v) = %q, want %q", tt.lat, tt.lng, got, tt.want)
+			}
+		})
+	}
+	if _, err := g.TimezoneForCoordinates(context.Background(), 91, 0); err == nil {
+		t.Error("TimezoneForCoordinates accepted latitude 91")
+	}
+}
+
+// TestGazetteerTimezones checks every zone in the table against the system zone database.
+func TestGazetteerTimezones(t *testing.T) {
+	g, err := Offline()
+	if err != nil {
+		t.Fatalf("Offline: %v", err)
+	}
+	for _, c := range g.cities {
+		if _, err := time.LoadLocation(c.Timezone); err != nil {
+			t.Errorf("%s, %s: %v", c.Name, c.Country, err)
+		}
+	}
+}
+
+type stubGeocoder struct {
+	loc *Location
+	tz  string
+	err error
+}
+
+func (s stubGeocoder) GeocodeLocation(context.Context, string) (*Location, error) {
+	return s.loc, s.err
+}
+
+func (s stubGeocoder) TimezoneForCoordinates(context.Context, float64, float64) (string, error) {
+	return s.tz, s.err
+}
+
+func TestChain(t *testing.T) {
+	quota := errors.New("quota exceeded")
+	ctx := context.Background()
+
+	c := Chain(stubGeocoder{err: quota}, stubGeocoder{loc: &Location{Latitude: 1, Longitude: 2}, tz: "Europe/Paris"})
+	if loc, err := c.GeocodeLocation(ctx, "Paris"); err != nil || loc.Latitude != 1 {
+		t.Errorf("GeocodeLocation = %v, %v; want the second geocoder's answer", loc, err)
+	}
+	if tz, err := c.TimezoneForCoordinates(ctx, 1, 2); err != nil || tz != "Europe/Paris" {
+		t.Errorf("TimezoneForCoordinates = %q, %v; want Europe/Paris", tz, err)
+	}
+
+	_, err := Chain(stubGeocoder{err: quota}).GeocodeLocation(ctx, "Paris")
+	if !errors.Is(err, ErrNotFound) || !errors.Is(err, quota) {
+		t.Errorf("error = %v, want ErrNotFound wrapping the geocoder's error", err)
+	}
+}
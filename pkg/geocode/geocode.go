@@ -0,0 +1,57 @@
+// Package geocode turns free-form profile locations into coordinates and coordinates into IANA time zones.
+package geocode
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrNotFound is returned when a location cannot be resolved precisely enough to pick a time zone.
+var ErrNotFound = errors.New("location not found")
+
+// Location represents a geographic location with coordinates.
+type Location struct {
+	Latitude  float64
+	Longitude float64
+}
+
+// Geocoder resolves locations and the time zones at coordinates.
+type Geocoder interface {
+	// GeocodeLocation converts a location string such as "Zürich, CH" to coordinates.
+	GeocodeLocation(ctx context.Context, location string) (*Location, error)
+	// TimezoneForCoordinates returns the IANA time zone at the given coordinates.
+	TimezoneForCoordinates(ctx context.Context, lat, lng float64) (string, error)
+}
+
+// Chain returns a Geocoder that asks each geocoder in turn and returns the first answer.
+// When none answers, the error wraps ErrNotFound and every geocoder's error.
+// Typically an online service comes first, with the offline Gazetteer as a fallback.
+func Chain(geocoders ...Geocoder) Geocoder {
+	return chain(geocoders)
+}
+
+type chain []Geocoder
+
+func (c chain) GeocodeLocation(ctx context.Context, location string) (*Location, error) {
+	errs := []error{ErrNotFound}
+	for _, g := range c {
+		loc, err := g.GeocodeLocation(ctx, location)
+		if err == nil {
+			return loc, nil
+		}
+		errs = append(errs, err)
+	}
+	return nil, errors.Join(errs...)
+}
+
+func (c chain) TimezoneForCoordinates(ctx context.Context, lat, lng float64) (string, error) {
+	errs := []error{ErrNotFound}
+	for _, g := range c {
+		tz, err := g.TimezoneForCoordinates(ctx, lat, lng)
+		if err == nil {
+			return tz, nil
+		}
+		errs = append(errs, err)
+	}
+	return "", errors.Join(errs...)
+}
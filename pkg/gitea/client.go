@@ -0,0 +1,161 @@
+// Package gitea provides a client for the public activity of users on Gitea and Forgejo instances, such as Codeberg.
+package gitea
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// CodebergURL is the instance queried unless another is configured.
+const CodebergURL = "https://codeberg.org"
+
+// ErrUserNotFound is returned when the instance has no user by that name.
+var ErrUserNotFound = errors.New("gitea user not found")
+
+// FeedEntry is an entry of a user's activity feed.
+type FeedEntry struct {
+	Created time.Time `json:"created"`
+	Repo    *struct {
+		FullName string `json:"full_name"`
+		HTMLURL  string `json:"html_url"`
+	} `json:"repo"`
+	Comment *struct {
+		HTMLURL string `json:"html_url"`
+	} `json:"comment"`
+	OpType  string `json:"op_type"` // "commit_repo", "create_pull_request", "comment_issue", ...
+	Content string `json:"content"`
+	RefName string `json:"ref_name"`
+}
+
+// Activity is one timestamped action of a user, as merged into a timeline.
+type Activity struct {
+	Time    time.Time
+	Kind    string // "pull_request", "comment", "push", "issue" or "event"
+	Project string
+	Title   string
+	URL     string
+}
+
+// Client queries the Gitea REST API, which Forgejo shares.
+type Client struct {
+	logger  *slog.Logger
+	do      func(context.Context, *http.Request) (*http.Response, error)
+	baseURL string
+	token   string
+}
+
+// NewClient creates a client for the instance at baseURL, Codeberg if empty. The token is optional;
+// without one only public activity is visible. do performs requests, typically through a cache.
+func NewClient(logger *slog.Logger, baseURL, token string, do func(context.Context, *http.Request) (*http.Response, error)) *Client {
+	return &Client{
+		logger:  logger,
+		do:      do,
+		baseURL: strings.TrimRight(cmp.Or(baseURL, CodebergURL), "/"),
+		token:   token,
+	}
+}
+
+// FetchFeed returns up to 250 of the user's most recent activity feed entries.
+func (c *Client) FetchFeed(ctx context.Context, username string) ([]FeedEntry, error) {
+	const maxPages = 5
+	const perPage = 50
+
+	var all []FeedEntry
+	for page := 1; page <= maxPages; page++ {
+		entries, err := c.fetchFeedPage(ctx, username, page, perPage)
+		if err != nil {
+			if page == 1 {
+				return nil, err
+			}
+			c.logger.Debug("failed to fetch feed page", "page", page, "error", err)
+			break // Return what we have so far
+		}
+		all = append(all, entries...)
+		if len(entries) < perPage {
+			break
+		}
+	}
+	return all, nil
+}
+
+func (c *Client) fetchFeedPage(ctx context.Context, username string, page, perPage int) ([]FeedEntry, error) {
+	apiURL := c.baseURL + fmt.Sprintf("/api/v1/users/%s/activities/feeds?limit=%d&page=%d", url.PathEscape(username), perPage, page)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "token "+c.token)
+	}
+
+	resp, err := c.do(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			c.logger.Debug("failed to close response body", "error", err)
+		}
+	}()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, ErrUserNotFound
+	default:
+		return nil, fmt.Errorf("gitea API returned status %d for %s", resp.StatusCode, apiURL)
+	}
+
+	var entries []FeedEntry
+	if err := json.NewDecoder(resp.Body).Decode(&entries); err != nil {
+		return nil, fmt.Errorf("decoding %s: %w", apiURL, err)
+	}
+	return entries, nil
+}
+
+// FetchActivity returns the user's activity feed as timestamped activity.
+func (c *Client) FetchActivity(ctx context.Context, username string) ([]Activity, error) {
+	feed, err := c.FetchFeed(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	activity := make([]Activity, 0, len(feed))
+	for _, f := range feed {
+		activity = append(activity, f.activity())
+	}
+	c.logger.Debug("fetched gitea activity", "username", username, "entries", len(feed))
+	return activity, nil
+}
+
+// activity classifies a feed entry.
+func (f FeedEntry) activity() Activity {
+	a := Activity{Time: f.Created, Kind: "event"}
+	if f.Repo != nil {
+		a.Project = f.Repo.FullName
+		a.URL = f.Repo.HTMLURL
+	}
+	if f.Comment != nil {
+		a.URL = f.Comment.HTMLURL
+	}
+	switch f.OpType {
+	case "commit_repo", "mirror_sync_push":
+		a.Kind = "push"
+		a.Title = strings.TrimPrefix(f.RefName, "refs/heads/")
+	case "create_pull_request", "merge_pull_request", "auto_merge_pull_request", "approve_pull_request",
+		"reject_pull_request", "close_pull_request", "reopen_pull_request":
+		a.Kind = "pull_request"
+	case "comment_issue", "comment_pull", "pull_review_dismissed":
+		a.Kind = "comment"
+	case "create_issue", "close_issue", "reopen_issue":
+		a.Kind = "issue"
+	}
+	return a
+}
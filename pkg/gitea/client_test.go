@@ -0,0 +1,63 @@
+package gitea
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFetchActivity(t *testing.T) {
+	var pages int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/users/alice/activities/feeds" {
+			http.NotFound(w, r)
+			return
+		}
+		pages++
+		if r.URL.Query().Get("page") != "1" {
+			fmt.Fprint(w, `[]`)
+			return
+		}
+		fmt.Fprint(w, `[
+			{"created": "2025-03-04T09:15:00Z", "op_type": "commit_repo", "ref_name": "refs/heads/main",
+			 "repo": {"full_name": "alice/notes", "html_url": "https://codeberg.org/alice/notes"}},
+			{"created": "2025-03-04T10:00:00Z", "op_type": "comment_pull",
+			 "comment": {"html_url": "https://codeberg.org/forgejo/forgejo/pulls/1#issuecomment-2"}},
+			{"created": "2025-03-04T11:00:00Z", "op_type": "create_pull_request"},
+			{"created": "2025-03-04T12:00:00Z", "op_type": "star_repo"}
+		]`)
+	}))
+	defer server.Close()
+	do := func(_ context.Context, req *http.Request) (*http.Response, error) { return server.Client().Do(req) }
+	c := NewClient(slog.New(slog.DiscardHandler), server.URL, "", do)
+
+	activity, err := c.FetchActivity(context.Background(), "alice")
+	if err != nil {
+		t.Fatalf("FetchActivity: %v", err)
+	}
+	if pages != 1 {
+		t.Errorf("fetched %d pages, want 1 for a short feed", pages)
+	}
+	want := []Activity{
+		{Kind: "push", Project: "alice/notes", Title: "main", URL: "https://codeberg.org/alice/notes"},
+		{Kind: "comment", URL: "https://codeberg.org/forgejo/forgejo/pulls/1#issuecomment-2"},
+		{Kind: "pull_request"},
+		{Kind: "event"},
+	}
+	if len(activity) != len(want) {
+		t.Fatalf("got %d entries, want %d", len(activity), len(want))
+	}
+	for i, a := range activity {
+		if a.Time.IsZero() || a.Kind != want[i].Kind || a.Project != want[i].Project || a.Title != want[i].Title || a.URL != want[i].URL {
+			t.Errorf("activity[%d] = %+v, want %+v", i, a, want[i])
+		}
+	}
+
+	if _, err := c.FetchActivity(context.Background(), "nobody"); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("FetchActivity(nobody) = %v, want ErrUserNotFound", err)
+	}
+}
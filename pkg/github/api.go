@@ -9,7 +9,6 @@ import (
 	"io"
 	"net/http"
 	"net/url"
-	"strings"
 	"sync"
 	"time"
 )
@@ -22,7 +21,7 @@ func (c *Client) FetchPublicEvents(ctx context.Context, username string) ([]Publ
 	var allEvents []PublicEvent
 
 	for page := 1; page <= maxPages; page++ {
-		apiURL := fmt.Sprintf("https://api.github.com/users/%s/events/public?per_page=%d&page=%d", url.PathEscape(username), perPage, page)
+		apiURL := c.baseURLs.API + fmt.Sprintf("/users/%s/events/public?per_page=%d&page=%d", url.PathEscape(username), perPage, page)
 
 		req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, http.NoBody)
 		if err != nil {
@@ -80,7 +79,7 @@ func (c *Client) FetchPublicEvents(ctx context.Context, username string) ([]Publ
 
 // FetchUserGistsDetails fetches full gist objects with descriptions for a user.
 func (c *Client) FetchUserGistsDetails(ctx context.Context, username string) ([]Gist, error) {
-	apiURL := fmt.Sprintf("https://api.github.com/users/%s/gists?per_page=100", url.PathEscape(username))
+	apiURL := c.baseURLs.API + fmt.Sprintf("/users/%s/gists?per_page=100", url.PathEscape(username))
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, http.NoBody)
 	if err != nil {
@@ -117,7 +116,7 @@ func (c *Client) FetchUserGistsDetails(ctx context.Context, username string) ([]
 
 // FetchUserGists fetches gist timestamps for a user.
 func (c *Client) FetchUserGists(ctx context.Context, username string) ([]time.Time, error) {
-	apiURL := fmt.Sprintf("https://api.github.com/users/%s/gists?per_page=100", url.PathEscape(username))
+	apiURL := c.baseURLs.API + fmt.Sprintf("/users/%s/gists?per_page=100", url.PathEscape(username))
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, http.NoBody)
 	if err != nil {
@@ -174,7 +173,7 @@ func (c *Client) FetchPullRequests(ctx context.Context, username string) ([]Pull
 
 // fetchPRPage fetches a single page of pull requests.
 func (c *Client) fetchPRPage(ctx context.Context, username string, page, perPage int) ([]PullRequest, pageResult, error) {
-	apiURL := fmt.Sprintf("https://api.github.com/search/issues?q=author:%s+type:pr&sort=created&order=desc&per_page=%d&page=%d",
+	apiURL := c.baseURLs.API + fmt.Sprintf("/search/issues?q=author:%s+type:pr&sort=created&order=desc&per_page=%d&page=%d",
 		url.QueryEscape(username), perPage, page)
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, http.NoBody)
@@ -231,15 +230,7 @@ func (c *Client) fetchPRPage(ctx context.Context, username string, page, perPage
 	var prs []PullRequest
 	for _, item := range result.Items {
 		// Extract repository from HTML URL (format: https://github.com/owner/repo/pull/123)
-		repo := ""
-		if item.HTMLURL != "" {
-			if strings.HasPrefix(item.HTMLURL, "https://github.com/") {
-				parts := strings.Split(strings.TrimPrefix(item.HTMLURL, "https://github.com/"), "/")
-				if len(parts) >= 2 {
-					repo = parts[0] + "/" + parts[1]
-				}
-			}
-		}
+		repo := RepoFromURL(item.HTMLURL)
 		prs = append(prs, PullRequest{
 			Title:     item.Title,
 			Body:      item.Body,
@@ -281,7 +272,7 @@ func (c *Client) FetchPullRequestsWithLimit(ctx context.Context, username string
 
 // fetchIssuePage fetches a single page of issues.
 func (c *Client) fetchIssuePage(ctx context.Context, username string, page, perPage int) ([]Issue, pageResult, error) {
-	apiURL := fmt.Sprintf("https://api.github.com/search/issues?q=author:%s+type:issue&sort=created&order=desc&per_page=%d&page=%d",
+	apiURL := c.baseURLs.API + fmt.Sprintf("/search/issues?q=author:%s+type:issue&sort=created&order=desc&per_page=%d&page=%d",
 		url.QueryEscape(username), perPage, page)
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, http.NoBody)
@@ -337,15 +328,7 @@ func (c *Client) fetchIssuePage(ctx context.Context, username string, page, perP
 	var issues []Issue
 	for _, item := range result.Items {
 		// Extract repository from HTML URL (format: https://github.com/owner/repo/issues/123)
-		repo := ""
-		if item.HTMLURL != "" {
-			if strings.HasPrefix(item.HTMLURL, "https://github.com/") {
-				parts := strings.Split(strings.TrimPrefix(item.HTMLURL, "https://github.com/"), "/")
-				if len(parts) >= 2 {
-					repo = parts[0] + "/" + parts[1]
-				}
-			}
-		}
+		repo := RepoFromURL(item.HTMLURL)
 		issues = append(issues, Issue{
 			Title:     item.Title,
 			Body:      item.Body,
@@ -438,7 +421,7 @@ func (c *Client) FetchUserComments(ctx context.Context, username string) ([]Comm
 		return nil, fmt.Errorf("marshaling GraphQL query: %w", err)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://api.github.com/graphql", bytes.NewBuffer(jsonBody))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURLs.GraphQL, bytes.NewBuffer(jsonBody))
 	if err != nil {
 		return nil, fmt.Errorf("creating request: %w", err)
 	}
@@ -518,7 +501,7 @@ func (c *Client) FetchUserComments(ctx context.Context, username string) ([]Comm
 		comments = append(comments, Comment{
 			CreatedAt:  node.CreatedAt,
 			Body:       node.Body,
-			HTMLURL:    fmt.Sprintf("https://github.com/%s", node.Repository.NameWithOwner),
+			HTMLURL:    c.baseURLs.Web + "/" + node.Repository.NameWithOwner,
 			Repository: node.Repository.NameWithOwner,
 		})
 	}
@@ -528,7 +511,7 @@ func (c *Client) FetchUserComments(ctx context.Context, username string) ([]Comm
 		comments = append(comments, Comment{
 			CreatedAt:  node.CreatedAt,
 			Body:       node.Body,
-			HTMLURL:    fmt.Sprintf("https://github.com/%s/commit/%s", node.Commit.Repository.NameWithOwner, node.Commit.AbbreviatedOid),
+			HTMLURL:    fmt.Sprintf("%s/%s/commit/%s", c.baseURLs.Web, node.Commit.Repository.NameWithOwner, node.Commit.AbbreviatedOid),
 			Repository: node.Commit.Repository.NameWithOwner,
 		})
 	}
@@ -543,7 +526,7 @@ func (c *Client) FetchUserComments(ctx context.Context, username string) ([]Comm
 
 // FetchOrganizations fetches organizations that a user belongs to.
 func (c *Client) FetchOrganizations(ctx context.Context, username string) ([]Organization, error) {
-	apiURL := fmt.Sprintf("https://api.github.com/users/%s/orgs", url.PathEscape(username))
+	apiURL := c.baseURLs.API + fmt.Sprintf("/users/%s/orgs", url.PathEscape(username))
 	c.logger.Debug("fetching organizations from API", "url", apiURL)
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, http.NoBody)
@@ -602,6 +585,60 @@ func (c *Client) FetchOrganizations(ctx context.Context, username string) ([]Org
 	return orgs, nil
 }
 
+// FetchOrganizationMembers fetches the logins of an organization's public members, up to maxPages pages
+// of 100. Private members are left out even when the token could see them.
+func (c *Client) FetchOrganizationMembers(ctx context.Context, org string, maxPages int) ([]string, error) {
+	const perPage = 100
+
+	var logins []string
+	for page := 1; page <= maxPages; page++ {
+		apiURL := c.baseURLs.API + fmt.Sprintf("/orgs/%s/public_members?per_page=%d&page=%d", url.PathEscape(org), perPage, page)
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, http.NoBody)
+		if err != nil {
+			return nil, fmt.Errorf("creating request: %w", err)
+		}
+		if c.githubToken != "" && c.isValidGitHubToken(c.githubToken) {
+			req.Header.Set("Authorization", "token "+c.githubToken)
+		}
+
+		resp, err := c.cachedHTTPDo(ctx, req)
+		if err != nil {
+			return nil, fmt.Errorf("fetching organization members: %w", err)
+		}
+		var members []struct {
+			Login string `json:"login"`
+		}
+		err = func() error {
+			defer func() {
+				if err := resp.Body.Close(); err != nil {
+					c.logger.Debug("failed to close response body", "error", err)
+				}
+			}()
+			switch resp.StatusCode {
+			case http.StatusOK:
+				return json.NewDecoder(resp.Body).Decode(&members)
+			case http.StatusNotFound:
+				return fmt.Errorf("organization %q not found", org)
+			default:
+				return fmt.Errorf("github API returned status %d", resp.StatusCode)
+			}
+		}()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, m := range members {
+			logins = append(logins, m.Login)
+		}
+		if len(members) < perPage {
+			break
+		}
+	}
+
+	c.logger.Debug("fetched organization members", "org", org, "count", len(logins))
+	return logins, nil
+}
+
 // FetchUserRepositories fetches public repositories owned by a user.
 func (c *Client) FetchUserRepositories(ctx context.Context, username string) ([]Repository, error) {
 	// First try to get pinned repositories using GraphQL
@@ -663,7 +700,7 @@ func (c *Client) FetchPinnedRepositories(ctx context.Context, username string) (
 		return nil, fmt.Errorf("marshaling GraphQL query: %w", err)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://api.github.com/graphql", bytes.NewBuffer(jsonBody))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURLs.GraphQL, bytes.NewBuffer(jsonBody))
 	if err != nil {
 		return nil, fmt.Errorf("creating request: %w", err)
 	}
@@ -742,7 +779,7 @@ func (c *Client) FetchPinnedRepositories(ctx context.Context, username string) (
 // FetchPopularRepositories fetches user's most popular repositories sorted by stars.
 func (c *Client) FetchPopularRepositories(ctx context.Context, username string) ([]Repository, error) {
 	// Fetch all repos (up to 100) to ensure we don't miss important ones
-	apiURL := fmt.Sprintf("https://api.github.com/users/%s/repos?sort=updated&per_page=100", url.PathEscape(username))
+	apiURL := c.baseURLs.API + fmt.Sprintf("/users/%s/repos?sort=updated&per_page=100", url.PathEscape(username))
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, http.NoBody)
 	if err != nil {
@@ -810,7 +847,7 @@ func (c *Client) FetchPopularRepositories(ctx context.Context, username string)
 
 // FetchProfileHTML fetches the raw HTML of a GitHub profile page.
 func (c *Client) FetchProfileHTML(ctx context.Context, username string) string {
-	profileURL := fmt.Sprintf("https://github.com/%s", url.PathEscape(username))
+	profileURL := c.baseURLs.Web + "/" + url.PathEscape(username)
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, profileURL, http.NoBody)
 	if err != nil {
@@ -844,9 +881,47 @@ func (c *Client) FetchProfileHTML(ctx context.Context, username string) string {
 	return string(body)
 }
 
+// FetchProfileREADME returns the raw profile README, the README of the username/username repository,
+// or "" if the user has none.
+func (c *Client) FetchProfileREADME(ctx context.Context, username string) (string, error) {
+	apiURL := c.baseURLs.API + fmt.Sprintf("/repos/%s/%s/readme", url.PathEscape(username), url.PathEscape(username))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, http.NoBody)
+	if err != nil {
+		return "", fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github.raw+json")
+	if c.githubToken != "" && c.isValidGitHubToken(c.githubToken) {
+		req.Header.Set("Authorization", "token "+c.githubToken)
+	}
+
+	resp, err := c.cachedHTTPDo(ctx, req)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			c.logger.Debug("failed to close response body", "error", err)
+		}
+	}()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return "", nil
+	default:
+		return "", fmt.Errorf("github API returned status %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", fmt.Errorf("reading README: %w", err)
+	}
+	return string(body), nil
+}
+
 // FetchSocialFromHTML scrapes GitHub profile HTML for social media links.
 func (c *Client) FetchSocialFromHTML(ctx context.Context, username string) []string {
-	profileURL := fmt.Sprintf("https://github.com/%s", username)
+	profileURL := c.baseURLs.Web + "/" + url.PathEscape(username)
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, profileURL, http.NoBody)
 	if err != nil {
@@ -887,7 +962,7 @@ func (c *Client) FetchSocialFromHTML(ctx context.Context, username string) []str
 
 // FetchStarredRepositories fetches repositories the user has starred for additional timestamp data and repository details.
 func (c *Client) FetchStarredRepositories(ctx context.Context, username string) ([]time.Time, []Repository, error) {
-	apiURL := fmt.Sprintf("https://api.github.com/users/%s/starred?per_page=100", url.PathEscape(username))
+	apiURL := c.baseURLs.API + fmt.Sprintf("/users/%s/starred?per_page=100", url.PathEscape(username))
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, http.NoBody)
 	if err != nil {
@@ -1015,7 +1090,7 @@ func (c *Client) fetchCommitPage(ctx context.Context, username string, page int,
 
 	// Use GitHub Search API to find commits by this user
 	// Note: This requires authentication for better rate limits
-	searchURL := fmt.Sprintf("https://api.github.com/search/commits?q=author:%s&sort=author-date&order=desc&per_page=%d&page=%d",
+	searchURL := c.baseURLs.API + fmt.Sprintf("/search/commits?q=author:%s&sort=author-date&order=desc&per_page=%d&page=%d",
 		url.QueryEscape(username), perPage, page)
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, searchURL, http.NoBody)
@@ -1113,7 +1188,7 @@ func (c *Client) FetchUserCommitActivitiesGraphQL(ctx context.Context, username
 
 	// Use GitHub search API to find commits by the user
 	for page := 1; page <= maxPages; page++ {
-		apiURL := fmt.Sprintf("https://api.github.com/search/commits?q=author:%s&sort=committer-date&order=desc&per_page=100&page=%d",
+		apiURL := c.baseURLs.API + fmt.Sprintf("/search/commits?q=author:%s&sort=committer-date&order=desc&per_page=100&page=%d",
 			url.QueryEscape(username), page)
 
 		req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, http.NoBody)
@@ -1241,7 +1316,7 @@ func (c *Client) fetchCommitActivitiesPage(ctx context.Context, username string,
 	var activities []CommitActivity
 
 	// Use GitHub Search API to find commits by this user
-	searchURL := fmt.Sprintf("https://api.github.com/search/commits?q=author:%s&sort=author-date&order=desc&per_page=%d&page=%d",
+	searchURL := c.baseURLs.API + fmt.Sprintf("/search/commits?q=author:%s&sort=author-date&order=desc&per_page=%d&page=%d",
 		url.QueryEscape(username), perPage, page)
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, searchURL, http.NoBody)
@@ -1328,7 +1403,7 @@ func (c *Client) fetchCommitActivitiesPage(ctx context.Context, username string,
 
 // FetchUserSSHKeys fetches public SSH keys for a user.
 func (c *Client) FetchUserSSHKeys(ctx context.Context, username string) ([]SSHKey, error) {
-	apiURL := fmt.Sprintf("https://api.github.com/users/%s/keys", url.PathEscape(username))
+	apiURL := c.baseURLs.API + fmt.Sprintf("/users/%s/keys", url.PathEscape(username))
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, http.NoBody)
 	if err != nil {
@@ -2,21 +2,67 @@
 package github
 
 import (
+	"cmp"
 	"context"
 	"log/slog"
 	"net/http"
+	"net/url"
 	"strings"
 )
 
+// Endpoints of github.com, used unless BaseURLs says otherwise.
+const (
+	DefaultAPIURL     = "https://api.github.com"
+	DefaultGraphQLURL = "https://api.github.com/graphql"
+	DefaultWebURL     = "https://github.com"
+)
+
+// BaseURLs locates the GitHub instance to query: github.com, or a GitHub Enterprise Server.
+// Empty fields fall back to the github.com endpoints.
+type BaseURLs struct {
+	API     string // REST API root, e.g. https://ghe.example.com/api/v3
+	GraphQL string // GraphQL endpoint, e.g. https://ghe.example.com/api/graphql
+	Web     string // Site root serving profile pages, e.g. https://ghe.example.com
+}
+
+// EnterpriseBaseURLs returns the standard GitHub Enterprise Server endpoints for a site root such as https://ghe.example.com.
+func EnterpriseBaseURLs(webURL string) BaseURLs {
+	web := strings.TrimRight(webURL, "/")
+	return BaseURLs{API: web + "/api/v3", GraphQL: web + "/api/graphql", Web: web}
+}
+
+// NewBaseURLs resolves endpoints from command-line style settings: webURL selects a GitHub Enterprise Server
+// with the standard layout, and apiURL or graphQLURL override individual endpoints. All empty means github.com.
+func NewBaseURLs(webURL, apiURL, graphQLURL string) BaseURLs {
+	var urls BaseURLs
+	if webURL != "" {
+		urls = EnterpriseBaseURLs(webURL)
+	}
+	urls.API = cmp.Or(apiURL, urls.API)
+	urls.GraphQL = cmp.Or(graphQLURL, urls.GraphQL)
+	return urls.withDefaults()
+}
+
+// withDefaults fills unset endpoints with the github.com ones and drops trailing slashes.
+func (b BaseURLs) withDefaults() BaseURLs {
+	return BaseURLs{
+		API:     strings.TrimRight(cmp.Or(b.API, DefaultAPIURL), "/"),
+		GraphQL: strings.TrimRight(cmp.Or(b.GraphQL, DefaultGraphQLURL), "/"),
+		Web:     strings.TrimRight(cmp.Or(b.Web, DefaultWebURL), "/"),
+	}
+}
+
 // Client provides methods for interacting with the GitHub API.
 type Client struct {
 	logger       *slog.Logger
 	httpClient   *http.Client
 	cachedHTTPDo func(context.Context, *http.Request) (*http.Response, error)
+	rateLimiter  *RateLimiter // nil when budgets are not tracked
+	baseURLs     BaseURLs
 	githubToken  string
 }
 
-// NewClient creates a new GitHub API client.
+// NewClient creates a new GitHub API client for github.com.
 func NewClient(logger *slog.Logger, httpClient *http.Client, githubToken string,
 	cachedHTTPDo func(context.Context, *http.Request) (*http.Response, error),
 ) *Client {
@@ -25,7 +71,45 @@ func NewClient(logger *slog.Logger, httpClient *http.Client, githubToken string,
 		httpClient:   httpClient,
 		githubToken:  githubToken,
 		cachedHTTPDo: cachedHTTPDo,
+		baseURLs:     BaseURLs{}.withDefaults(),
+	}
+}
+
+// SetBaseURLs points the client at another GitHub instance, such as GitHub Enterprise Server.
+func (c *Client) SetBaseURLs(urls BaseURLs) {
+	c.baseURLs = urls.withDefaults()
+}
+
+// BaseURLs returns the endpoints the client talks to.
+func (c *Client) BaseURLs() BaseURLs {
+	return c.baseURLs
+}
+
+// SetRateLimiter sets the limiter whose budgets RateLimits reports.
+// The limiter must also be installed in the HTTP client's transport to see responses.
+func (c *Client) SetRateLimiter(l *RateLimiter) {
+	c.rateLimiter = l
+}
+
+// graphQL returns a GraphQL client for the configured instance.
+func (c *Client) graphQL() *GraphQLClient {
+	graphql := NewGraphQLClient(c.githubToken, c.cachedHTTPDo, c.logger)
+	graphql.SetEndpoint(c.baseURLs.GraphQL)
+	return graphql
+}
+
+// RepoFromURL returns "owner/repo" from a web URL such as https://github.com/owner/repo/issues/123.
+// Any host is accepted, so GitHub Enterprise Server URLs work too.
+func RepoFromURL(htmlURL string) string {
+	u, err := url.Parse(htmlURL)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return ""
 	}
+	return parts[0] + "/" + parts[1]
 }
 
 // isValidGitHubToken checks if a token looks valid (basic check).
@@ -3,7 +3,6 @@ package github
 import (
 	"context"
 	"errors"
-	"strings"
 
 	"github.com/codeGROOVE-dev/guTZ/pkg/constants"
 )
@@ -25,7 +24,7 @@ func (c *Client) FetchUserEnhancedGraphQL( //nolint:revive // Multiple return va
 		return nil, nil, nil, nil, ErrNoGitHubToken
 	}
 
-	graphql := NewGraphQLClient(c.githubToken, c.cachedHTTPDo, c.logger)
+	graphql := c.graphQL()
 
 	profile, err := graphql.FetchUserProfile(ctx, username)
 	if err != nil {
@@ -191,7 +190,7 @@ func (c *Client) FetchActivityWithGraphQL(ctx context.Context, username string)
 		return []PullRequest{}, []Issue{}, nil
 	}
 
-	graphql := NewGraphQLClient(c.githubToken, c.cachedHTTPDo, c.logger)
+	graphql := c.graphQL()
 
 	// Fetch first page of activity data (PRs and Issues together)
 	activityData, err := graphql.FetchActivityData(ctx, username, "", "")
@@ -349,37 +348,13 @@ func (c *Client) FetchActivityWithGraphQL(ctx context.Context, username string)
 	return prs, issues, nil
 }
 
-// extractRepoFromURL extracts the repository name from a GitHub URL.
-// Example: https://github.com/owner/repo/issues/123#issuecomment-456 -> "owner/repo".
-func extractRepoFromURL(url string) string {
-	if url == "" {
-		return ""
-	}
-
-	// Remove the GitHub prefix
-	const prefix = "https://github.com/"
-	if !strings.HasPrefix(url, prefix) {
-		return ""
-	}
-
-	path := strings.TrimPrefix(url, prefix)
-
-	// Split by "/" and take the first two parts (owner/repo)
-	parts := strings.Split(path, "/")
-	if len(parts) >= 2 {
-		return parts[0] + "/" + parts[1]
-	}
-
-	return ""
-}
-
 // FetchCommentsWithGraphQL fetches both issue comments (includes PR comments) and commit comments using GraphQL.
 func (c *Client) FetchCommentsWithGraphQL(ctx context.Context, username string) ([]Comment, error) {
 	if c.githubToken == "" {
 		return nil, nil // Can't fetch comments without auth
 	}
 
-	graphql := NewGraphQLClient(c.githubToken, c.cachedHTTPDo, c.logger)
+	graphql := c.graphQL()
 
 	commentData, err := graphql.FetchComments(ctx, username, "", "")
 	if err != nil {
@@ -390,7 +365,7 @@ func (c *Client) FetchCommentsWithGraphQL(ctx context.Context, username string)
 
 	// Process issue comments (includes PR comments)
 	for _, comment := range commentData.User.IssueComments.Nodes {
-		repo := extractRepoFromURL(comment.URL)
+		repo := RepoFromURL(comment.URL)
 		comments = append(comments, Comment{
 			Body:       comment.Body,
 			CreatedAt:  comment.CreatedAt,
@@ -406,7 +381,7 @@ func (c *Client) FetchCommentsWithGraphQL(ctx context.Context, username string)
 		if comment.Commit.URL != "" {
 			url = comment.Commit.URL
 		}
-		repo := extractRepoFromURL(url)
+		repo := RepoFromURL(url)
 		comments = append(comments, Comment{
 			Body:       comment.Body,
 			CreatedAt:  comment.CreatedAt,
@@ -456,7 +431,7 @@ func (c *Client) FetchCommentsWithGraphQL(ctx context.Context, username string)
 
 		// Process additional issue comments
 		for _, comment := range nextData.User.IssueComments.Nodes {
-			repo := extractRepoFromURL(comment.URL)
+			repo := RepoFromURL(comment.URL)
 			comments = append(comments, Comment{
 				Body:       comment.Body,
 				CreatedAt:  comment.CreatedAt,
@@ -472,7 +447,7 @@ func (c *Client) FetchCommentsWithGraphQL(ctx context.Context, username string)
 			if comment.Commit.URL != "" {
 				url = comment.Commit.URL
 			}
-			repo := extractRepoFromURL(url)
+			repo := RepoFromURL(url)
 			comments = append(comments, Comment{
 				Body:       comment.Body,
 				CreatedAt:  comment.CreatedAt,
@@ -0,0 +1,161 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNewBaseURLs(t *testing.T) {
+	tests := []struct {
+		name              string
+		web, api, graphql string
+		want              BaseURLs
+	}{
+		{
+			name: "github.com",
+			want: BaseURLs{API: DefaultAPIURL, GraphQL: DefaultGraphQLURL, Web: DefaultWebURL},
+		},
+		{
+			name: "enterprise server",
+			web:  "https://ghe.example.com/",
+			want: BaseURLs{
+				API:     "https://ghe.example.com/api/v3",
+				GraphQL: "https://ghe.example.com/api/graphql",
+				Web:     "https://ghe.example.com",
+			},
+		},
+		{
+			name:    "enterprise server behind a proxy",
+			web:     "https://ghe.example.com",
+			api:     "https://ghe-api.example.com/v3/",
+			graphql: "https://ghe-api.example.com/graphql",
+			want: BaseURLs{
+				API:     "https://ghe-api.example.com/v3",
+				GraphQL: "https://ghe-api.example.com/graphql",
+				Web:     "https://ghe.example.com",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewBaseURLs(tt.web, tt.api, tt.graphql); got != tt.want {
+				t.Errorf("NewBaseURLs(%q, %q, %q) = %+v, want %+v", tt.web, tt.api, tt.graphql, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRepoFromURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://github.com/owner/repo/issues/123#issuecomment-456", "owner/repo"},
+		{"https://github.com/owner/repo/pull/7", "owner/repo"},
+		{"https://ghe.example.com/team/service/commit/abc123", "team/service"},
+		{"https://github.com/owner", ""},
+		{"owner/repo", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := RepoFromURL(tt.url); got != tt.want {
+			t.Errorf("RepoFromURL(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
+
+// TestEnterpriseEndpoints checks that REST, GraphQL and profile page requests all go to the configured instance.
+func TestEnterpriseEndpoints(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.Method+" "+r.URL.Path)
+		switch r.URL.Path {
+		case "/api/v3/users/octocat/events/public":
+			if err := json.NewEncoder(w).Encode([]PublicEvent{{Type: "PushEvent"}}); err != nil {
+				t.Errorf("encode events: %v", err)
+			}
+		case "/api/graphql":
+			if _, err := w.Write([]byte(`{"data":{}}`)); err != nil {
+				t.Errorf("write graphql: %v", err)
+			}
+		case "/octocat":
+			if _, err := w.Write([]byte(`<html><profile-timezone data-hours-ahead-of-utc="-7.0"></profile-timezone></html>`)); err != nil {
+				t.Errorf("write profile: %v", err)
+			}
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	do := func(_ context.Context, req *http.Request) (*http.Response, error) {
+		return server.Client().Do(req)
+	}
+	client := NewClient(slog.New(slog.DiscardHandler), server.Client(), "", do)
+	client.SetBaseURLs(EnterpriseBaseURLs(server.URL))
+	ctx := context.Background()
+
+	events, err := client.FetchPublicEvents(ctx, "octocat")
+	if err != nil || len(events) != 1 {
+		t.Errorf("FetchPublicEvents = %d events, %v; want 1", len(events), err)
+	}
+	if _, err := client.graphQL().executeQueryOnce(ctx, "query { viewer { login } }", nil); err != nil {
+		t.Errorf("GraphQL query: %v", err)
+	}
+	if html := client.FetchProfileHTML(ctx, "octocat"); html == "" {
+		t.Error("FetchProfileHTML returned nothing from the enterprise profile page")
+	}
+
+	want := []string{"GET /api/v3/users/octocat/events/public", "POST /api/graphql", "GET /octocat"}
+	if len(paths) != len(want) {
+		t.Fatalf("requests = %v, want %v", paths, want)
+	}
+	for i := range want {
+		if paths[i] != want[i] {
+			t.Errorf("request %d = %q, want %q", i, paths[i], want[i])
+		}
+	}
+}
+
+func TestFetchOrganizationMembers(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/orgs/acme/public_members" {
+			http.NotFound(w, r)
+			return
+		}
+		members := []map[string]string{}
+		if r.URL.Query().Get("page") == "1" {
+			for i := range 100 {
+				members = append(members, map[string]string{"login": fmt.Sprintf("user%d", i)})
+			}
+		} else {
+			members = append(members, map[string]string{"login": "last"})
+		}
+		if err := json.NewEncoder(w).Encode(members); err != nil {
+			t.Errorf("encode members: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	do := func(_ context.Context, req *http.Request) (*http.Response, error) {
+		return server.Client().Do(req)
+	}
+	client := NewClient(slog.New(slog.DiscardHandler), server.Client(), "", do)
+	client.SetBaseURLs(NewBaseURLs("", server.URL, ""))
+
+	logins, err := client.FetchOrganizationMembers(context.Background(), "acme", 10)
+	if err != nil || len(logins) != 101 || logins[100] != "last" {
+		t.Errorf("FetchOrganizationMembers = %d logins, %v; want 101 over two pages", len(logins), err)
+	}
+	if logins, err := client.FetchOrganizationMembers(context.Background(), "acme", 1); err != nil || len(logins) != 100 {
+		t.Errorf("FetchOrganizationMembers with one page = %d logins, %v; want 100", len(logins), err)
+	}
+	if _, err := client.FetchOrganizationMembers(context.Background(), "nobody", 10); err == nil {
+		t.Error("FetchOrganizationMembers of a missing organization succeeded")
+	}
+}
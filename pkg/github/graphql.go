@@ -2,12 +2,14 @@ package github
 
 import (
 	"bytes"
+	"cmp"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -19,17 +21,24 @@ type GraphQLClient struct {
 	cachedHTTPDo func(context.Context, *http.Request) (*http.Response, error)
 	logger       *slog.Logger
 	token        string
+	endpoint     string
 }
 
-// NewGraphQLClient creates a new GraphQL client.
+// NewGraphQLClient creates a new GraphQL client for github.com.
 func NewGraphQLClient(token string, cachedHTTPDo func(context.Context, *http.Request) (*http.Response, error), logger *slog.Logger) *GraphQLClient {
 	return &GraphQLClient{
 		token:        token,
 		cachedHTTPDo: cachedHTTPDo,
 		logger:       logger,
+		endpoint:     DefaultGraphQLURL,
 	}
 }
 
+// SetEndpoint sets the GraphQL endpoint, such as https://ghe.example.com/api/graphql for GitHub Enterprise Server.
+func (c *GraphQLClient) SetEndpoint(endpoint string) {
+	c.endpoint = cmp.Or(endpoint, DefaultGraphQLURL)
+}
+
 // GraphQLResponse represents the response from a GraphQL query.
 type GraphQLResponse struct {
 	Data   json.RawMessage `json:"data"`
@@ -544,19 +553,15 @@ func (c *GraphQLClient) executeQuery(ctx context.Context, query string, variable
 		func() error {
 			resp, lastErr = c.executeQueryOnce(retryCtx, query, variables)
 			if lastErr != nil {
-				// Check for secondary rate limit - give up immediately
-				if strings.Contains(lastErr.Error(), "secondary rate limit") {
-					c.logger.Warn("GraphQL secondary rate limit detected, not retrying",
-						"error", lastErr)
-					return retry.Unrecoverable(fmt.Errorf("GitHub secondary rate limit: %w", lastErr))
+				// Rate limits are waited out (or not) by the RateLimiter; retrying here only burns budget
+				if errors.Is(lastErr, ErrRateLimited) {
+					c.logger.Warn("GraphQL rate limit exceeded, not retrying", "error", lastErr)
+					return retry.Unrecoverable(lastErr)
 				}
 
 				// Check if this is a non-transient error
 				errStr := lastErr.Error()
 				if !strings.Contains(errStr, "GraphQL server error (transient)") &&
-					!strings.Contains(errStr, "rate limit") &&
-					!strings.Contains(errStr, "HTTP 403") &&
-					!strings.Contains(errStr, "HTTP 429") &&
 					!strings.Contains(errStr, "HTTP 502") &&
 					!strings.Contains(errStr, "HTTP 503") &&
 					!strings.Contains(errStr, "HTTP 504") {
@@ -600,7 +605,7 @@ func (c *GraphQLClient) executeQueryOnce(ctx context.Context, query string, vari
 		return nil, fmt.Errorf("marshaling query: %w", err)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://api.github.com/graphql", bytes.NewReader(body))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, bytes.NewReader(body))
 	if err != nil {
 		return nil, fmt.Errorf("creating request: %w", err)
 	}
@@ -619,27 +624,23 @@ func (c *GraphQLClient) executeQueryOnce(ctx context.Context, query string, vari
 		}
 	}()
 
-	// Check HTTP status code first
-	if resp.StatusCode == http.StatusForbidden {
-		// Check for secondary rate limit
-		if strings.Contains(resp.Header.Get("X-Ratelimit-Remaining"), "0") ||
-			strings.Contains(resp.Header.Get("Retry-After"), "") {
-			c.logger.Warn("GitHub secondary rate limit detected on GraphQL endpoint",
-				"status", resp.StatusCode,
-				"retry_after", resp.Header.Get("Retry-After"))
-			return nil, errors.New("HTTP 403: secondary rate limit")
+	// Check HTTP status code first. Refusals due to rate limits are normally turned into a *RateLimitError
+	// by the RateLimiter transport; these cover clients without one.
+	if resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusTooManyRequests {
+		if rl, ok := ParseRateLimit(resp.Header); ok && rl.Remaining == 0 {
+			return nil, &RateLimitError{Reset: rl.Reset, Resource: "graphql"}
+		}
+		if resp.Header.Get("Retry-After") != "" || resp.StatusCode == http.StatusTooManyRequests {
+			retryAfter, _ := strconv.Atoi(resp.Header.Get("Retry-After")) //nolint:errcheck // zero when absent
+			return nil, &RateLimitError{
+				Reset:     time.Now().Add(max(time.Duration(retryAfter)*time.Second, secondaryRateLimitPause)),
+				Resource:  "graphql",
+				Secondary: true,
+			}
 		}
 		return nil, errors.New("HTTP 403: forbidden")
 	}
 
-	if resp.StatusCode == http.StatusTooManyRequests {
-		c.logger.Warn("GitHub rate limit exceeded on GraphQL endpoint",
-			"status", resp.StatusCode,
-			"rate_limit_remaining", resp.Header.Get("X-Ratelimit-Remaining"),
-			"rate_limit_reset", resp.Header.Get("X-Ratelimit-Reset"))
-		return nil, errors.New("HTTP 429: rate limit exceeded")
-	}
-
 	if resp.StatusCode == http.StatusBadGateway ||
 		resp.StatusCode == http.StatusServiceUnavailable ||
 		resp.StatusCode == http.StatusGatewayTimeout {
@@ -675,12 +676,15 @@ func (c *GraphQLClient) executeQueryOnce(ctx context.Context, query string, vari
 			// The HTTP layer doesn't see this as an error (200 OK), so we need to handle it here
 			return nil, fmt.Errorf("GraphQL server error (transient): %s", errMsg)
 		}
-		// Check for rate limit errors in GraphQL response
-		if strings.Contains(errMsg, "rate limit") || strings.Contains(errMsg, "API rate limit exceeded") {
-			c.logger.Warn("GraphQL rate limit error",
-				"error", errMsg,
-				"type", graphqlResp.Errors[0].Type)
-			return nil, fmt.Errorf("GraphQL rate limit: %s", errMsg)
+		// GitHub reports an exhausted GraphQL budget in a 200 response
+		if graphqlResp.Errors[0].Type == "RATE_LIMITED" {
+			c.logger.Warn("GraphQL rate limit error", "error", errMsg)
+			rlErr := &RateLimitError{Resource: "graphql", Reset: time.Now().Add(secondaryRateLimitPause)}
+			if rl, ok := ParseRateLimit(resp.Header); ok && !rl.Reset.IsZero() {
+				rlErr.Reset = rl.Reset
+			}
+			noteRateLimit(ctx, rlErr)
+			return nil, rlErr
 		}
 		return nil, fmt.Errorf("GraphQL error: %s", errMsg)
 	}
@@ -0,0 +1,98 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// OAuth scopes requested from users who sign in to detect themselves. ScopeReadUser only identifies
+// them. ScopePrivateRepos also shows their contributions to private repositories, but GitHub has no
+// read-only scope for those: repo grants read and write access to every repository the user can reach.
+const (
+	ScopeReadUser     = "read:user"
+	ScopePrivateRepos = "read:user repo"
+)
+
+// AuthorizeURL returns the page of the instance at webURL where a user grants the OAuth app clientID
+// the given scope on their account. state is passed back to the app's callback unchanged.
+func AuthorizeURL(webURL, clientID, state, scope string) string {
+	q := url.Values{"client_id": {clientID}, "scope": {scope}, "state": {state}}
+	return strings.TrimRight(webURL, "/") + "/login/oauth/authorize?" + q.Encode()
+}
+
+// ExchangeOAuthCode trades the code the instance at webURL passed to an OAuth callback for the user's access token.
+func ExchangeOAuthCode(ctx context.Context, httpClient *http.Client, webURL, clientID, clientSecret, code string) (string, error) {
+	form := url.Values{"client_id": {clientID}, "client_secret": {clientSecret}, "code": {code}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(webURL, "/")+"/login/oauth/access_token",
+		strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("exchanging OAuth code: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close() //nolint:errcheck // best effort close
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("exchanging OAuth code: HTTP %d", resp.StatusCode)
+	}
+
+	// Failures such as an expired code are reported in a 200 response
+	var body struct {
+		AccessToken      string `json:"access_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("decoding OAuth token: %w", err)
+	}
+	if body.Error != "" {
+		return "", fmt.Errorf("exchanging OAuth code: %s: %s", body.Error, body.ErrorDescription)
+	}
+	if body.AccessToken == "" {
+		return "", errors.New("exchanging OAuth code: no access token returned")
+	}
+	return body.AccessToken, nil
+}
+
+// RevokeOAuthGrant deletes the authorization a user gave the OAuth app clientID, through the REST API
+// at apiURL. token, and every other token of the grant, stops working, and signing in again asks the
+// user for their consent again.
+func RevokeOAuthGrant(ctx context.Context, httpClient *http.Client, apiURL, clientID, clientSecret, token string) error {
+	body, err := json.Marshal(map[string]string{"access_token": token})
+	if err != nil {
+		return fmt.Errorf("encoding request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete,
+		strings.TrimRight(apiURL, "/")+"/applications/"+url.PathEscape(clientID)+"/grant", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	req.SetBasicAuth(clientID, clientSecret)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("revoking OAuth grant: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close() //nolint:errcheck // best effort close
+	}()
+	// 404 means the grant is already gone
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("revoking OAuth grant: HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestAuthorizeURL(t *testing.T) {
+	got, err := url.Parse(AuthorizeURL("https://ghe.example.com/", "app-id", "st8", ScopeReadUser))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	q := got.Query()
+	if got.Host != "ghe.example.com" || got.Path != "/login/oauth/authorize" ||
+		q.Get("client_id") != "app-id" || q.Get("state") != "st8" || q.Get("scope") != "read:user" {
+		t.Errorf("AuthorizeURL = %s", got)
+	}
+}
+
+func TestExchangeOAuthCode(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Fatalf("parse form: %v", err)
+		}
+		if r.URL.Path != "/login/oauth/access_token" || r.PostForm.Get("client_secret") != "shh" {
+			t.Errorf("unexpected request %s %v", r.URL.Path, r.PostForm)
+		}
+		if r.PostForm.Get("code") == "expired" {
+			fmt.Fprint(w, `{"error": "bad_verification_code", "error_description": "The code passed is incorrect or expired."}`)
+			return
+		}
+		fmt.Fprint(w, `{"access_token": "gho_user", "token_type": "bearer", "scope": "read:user,repo"}`)
+	}))
+	defer server.Close()
+
+	token, err := ExchangeOAuthCode(context.Background(), server.Client(), server.URL, "app-id", "shh", "good")
+	if err != nil || token != "gho_user" {
+		t.Errorf("ExchangeOAuthCode = %q, %v; want gho_user", token, err)
+	}
+	_, err = ExchangeOAuthCode(context.Background(), server.Client(), server.URL, "app-id", "shh", "expired")
+	if err == nil || !strings.Contains(err.Error(), "bad_verification_code") {
+		t.Errorf("expired code: error = %v, want bad_verification_code", err)
+	}
+}
+
+func TestRevokeOAuthGrant(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, secret, _ := r.BasicAuth()
+		var body struct {
+			AccessToken string `json:"access_token"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("decode body: %v", err)
+		}
+		switch {
+		case r.Method != http.MethodDelete || r.URL.Path != "/api/v3/applications/app-id/grant" || id != "app-id" || secret != "shh":
+			t.Errorf("unexpected request %s %s as %s", r.Method, r.URL.Path, id)
+			w.WriteHeader(http.StatusUnauthorized)
+		case body.AccessToken == "gho_gone":
+			w.WriteHeader(http.StatusNotFound)
+		case body.AccessToken == "gho_user":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusUnprocessableEntity)
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	for token, wantErr := range map[string]bool{"gho_user": false, "gho_gone": false, "gho_bad": true} {
+		if err := RevokeOAuthGrant(ctx, server.Client(), server.URL+"/api/v3/", "app-id", "shh", token); (err != nil) != wantErr {
+			t.Errorf("RevokeOAuthGrant(%s) = %v, want error %v", token, err, wantErr)
+		}
+	}
+}
@@ -0,0 +1,447 @@
+package github
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RateLimitPolicy says what to do when a token's budget is exhausted.
+type RateLimitPolicy string
+
+const (
+	// RateLimitWait sleeps until the budget resets, failing fast only when the reset comes after the context deadline.
+	RateLimitWait RateLimitPolicy = "wait"
+	// RateLimitFail returns a *RateLimitError without waiting.
+	RateLimitFail RateLimitPolicy = "fail"
+)
+
+// ParseRateLimitPolicy parses a policy name; empty means RateLimitWait.
+func ParseRateLimitPolicy(s string) (RateLimitPolicy, error) {
+	switch p := RateLimitPolicy(strings.ToLower(strings.TrimSpace(s))); p {
+	case "":
+		return RateLimitWait, nil
+	case RateLimitWait, RateLimitFail:
+		return p, nil
+	default:
+		return "", fmt.Errorf("unknown rate limit policy %q (want wait or fail)", s)
+	}
+}
+
+// ErrRateLimited matches every *RateLimitError with errors.Is.
+var ErrRateLimited = errors.New("GitHub rate limit exceeded")
+
+// secondaryRateLimitPause is how long to back off after a secondary rate limit that names no Retry-After,
+// per GitHub's guidance to wait at least a minute.
+const secondaryRateLimitPause = time.Minute
+
+// RateLimitError reports a request refused, or not sent, because a GitHub rate limit is exhausted.
+type RateLimitError struct {
+	Reset     time.Time // When requests may resume
+	Resource  string    // Budget that ran out: core, graphql, search, ...
+	Secondary bool      // A secondary (abuse) limit rather than the hourly budget
+}
+
+func (e *RateLimitError) Error() string {
+	kind := "rate limit"
+	if e.Secondary {
+		kind = "secondary rate limit"
+	}
+	return fmt.Sprintf("GitHub %s %s exceeded until %s", e.Resource, kind, e.Reset.UTC().Format(time.RFC3339))
+}
+
+// Is reports whether target is ErrRateLimited.
+func (*RateLimitError) Is(target error) bool {
+	return target == ErrRateLimited
+}
+
+// RateLimit is one token's budget for one resource, as reported by GitHub's X-RateLimit-* headers.
+type RateLimit struct {
+	Reset     time.Time `json:"reset"`
+	Resource  string    `json:"resource"`
+	Token     string    `json:"token"` // Token fingerprint, never the token itself
+	Limit     int       `json:"limit"`
+	Remaining int       `json:"remaining"`
+	Used      int       `json:"used"`
+}
+
+// ParseRateLimit reads a budget from response headers. It reports false if the headers carry none.
+func ParseRateLimit(h http.Header) (RateLimit, bool) {
+	remaining, err := strconv.Atoi(h.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return RateLimit{}, false
+	}
+	limit, _ := strconv.Atoi(h.Get("X-RateLimit-Limit")) //nolint:errcheck // zero when absent
+	used, _ := strconv.Atoi(h.Get("X-RateLimit-Used"))   //nolint:errcheck // zero when absent
+	rl := RateLimit{
+		Resource:  h.Get("X-RateLimit-Resource"),
+		Limit:     limit,
+		Remaining: remaining,
+		Used:      used,
+	}
+	if reset, err := strconv.ParseInt(h.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		rl.Reset = time.Unix(reset, 0)
+	}
+	return rl, true
+}
+
+// RateLimiter tracks the remaining GitHub budget of each token across REST and GraphQL requests,
+// and holds back requests that would be refused. Install it with Transport.
+type RateLimiter struct {
+	logger  *slog.Logger
+	now     func() time.Time
+	budgets map[string]map[string]RateLimit // token fingerprint -> resource -> budget
+	blocked map[string]time.Time            // token fingerprint -> end of a secondary rate limit
+	policy  RateLimitPolicy
+	mu      sync.Mutex
+}
+
+// NewRateLimiter creates a rate limiter with the given policy.
+func NewRateLimiter(policy RateLimitPolicy, logger *slog.Logger) *RateLimiter {
+	if policy == "" {
+		policy = RateLimitWait
+	}
+	return &RateLimiter{
+		logger:  logger,
+		now:     time.Now,
+		budgets: map[string]map[string]RateLimit{},
+		blocked: map[string]time.Time{},
+		policy:  policy,
+	}
+}
+
+// Policy returns the limiter's policy.
+func (l *RateLimiter) Policy() RateLimitPolicy {
+	return l.policy
+}
+
+// Budgets returns the last known budgets of a token, sorted by resource.
+func (l *RateLimiter) Budgets(token string) []RateLimit {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var budgets []RateLimit
+	for _, rl := range l.budgets[fingerprint(token)] {
+		budgets = append(budgets, rl)
+	}
+	slices.SortFunc(budgets, func(a, b RateLimit) int { return strings.Compare(a.Resource, b.Resource) })
+	return budgets
+}
+
+// Record stores budgets for a token, such as those listed by the /rate_limit endpoint.
+func (l *RateLimiter) Record(token string, limits ...RateLimit) {
+	id := fingerprint(token)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.budgets[id] == nil {
+		l.budgets[id] = map[string]RateLimit{}
+	}
+	for _, rl := range limits {
+		rl.Token = id
+		rl.Resource = cmp.Or(rl.Resource, "core")
+		l.budgets[id][rl.Resource] = rl
+	}
+}
+
+// Wait blocks until no budget of token is exhausted, whatever the policy.
+// It returns a *RateLimitError at once if the reset comes after the context deadline.
+func (l *RateLimiter) Wait(ctx context.Context, token string) error {
+	return l.wait(ctx, token, "", RateLimitWait)
+}
+
+// exhausted returns the rate limit that blocks a request for resource, or nil.
+// An empty resource checks every budget of the token.
+func (l *RateLimiter) exhausted(token, resource string) *RateLimitError {
+	id := fingerprint(token)
+	now := l.now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var blocking *RateLimitError
+	if until, ok := l.blocked[id]; ok && now.Before(until) {
+		blocking = &RateLimitError{Reset: until, Resource: cmp.Or(resource, "core"), Secondary: true}
+	}
+	for name, rl := range l.budgets[id] {
+		if resource != "" && name != resource {
+			continue
+		}
+		if rl.Remaining > 0 || !now.Before(rl.Reset) {
+			continue
+		}
+		if blocking == nil || rl.Reset.After(blocking.Reset) {
+			blocking = &RateLimitError{Reset: rl.Reset, Resource: name}
+		}
+	}
+	return blocking
+}
+
+// wait holds a request for resource until its budget resets, or fails fast under RateLimitFail.
+func (l *RateLimiter) wait(ctx context.Context, token, resource string, policy RateLimitPolicy) error {
+	for {
+		rlErr := l.exhausted(token, resource)
+		if rlErr == nil {
+			return nil
+		}
+		if policy == RateLimitFail {
+			return rlErr
+		}
+		if deadline, ok := ctx.Deadline(); ok && deadline.Before(rlErr.Reset) {
+			return rlErr
+		}
+		delay := rlErr.Reset.Sub(l.now()) + time.Second // Resets have one-second granularity
+		l.logger.Warn("waiting for GitHub rate limit reset",
+			"resource", rlErr.Resource, "secondary", rlErr.Secondary, "reset", rlErr.Reset, "wait", delay.Round(time.Second))
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// observe records the budget reported by a response and returns a *RateLimitError if GitHub refused the request.
+// The body of a refused response is consumed and closed.
+func (l *RateLimiter) observe(token, resource string, resp *http.Response) *RateLimitError {
+	rl, ok := ParseRateLimit(resp.Header)
+	if ok {
+		rl.Resource = cmp.Or(rl.Resource, resource, "core")
+		l.Record(token, rl)
+	}
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return nil
+	}
+
+	if ok && rl.Remaining == 0 {
+		closeBody(resp)
+		return &RateLimitError{Reset: rl.Reset, Resource: rl.Resource}
+	}
+	pause := time.Duration(0)
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+		pause = time.Duration(seconds) * time.Second
+	} else if resp.StatusCode == http.StatusTooManyRequests || mentionsSecondaryRateLimit(resp) {
+		pause = secondaryRateLimitPause
+	}
+	if pause == 0 {
+		return nil // A genuine permission error
+	}
+	closeBody(resp)
+	until := l.now().Add(pause)
+	l.mu.Lock()
+	l.blocked[fingerprint(token)] = until
+	l.mu.Unlock()
+	return &RateLimitError{Reset: until, Resource: cmp.Or(resource, "core"), Secondary: true}
+}
+
+// mentionsSecondaryRateLimit checks the message of a 403 that carries no rate limit headers,
+// leaving the body readable for the caller.
+func mentionsSecondaryRateLimit(resp *http.Response) bool {
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if err != nil {
+		return false
+	}
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+	return bytes.Contains(bytes.ToLower(body), []byte("secondary rate limit"))
+}
+
+func closeBody(resp *http.Response) {
+	_, _ = io.Copy(io.Discard, resp.Body) //nolint:errcheck // draining for connection reuse
+	_ = resp.Body.Close()                 //nolint:errcheck // nothing left to read
+}
+
+// Transport returns a RoundTripper that applies the limiter to requests for the REST and GraphQL endpoints in urls.
+// Other requests, such as profile pages, pass straight through to base (http.DefaultTransport if nil).
+// Refused requests are retried after the reset under RateLimitWait; otherwise they fail with a *RateLimitError.
+func (l *RateLimiter) Transport(base http.RoundTripper, urls BaseURLs) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &rateLimitTransport{limiter: l, base: base, urls: urls.withDefaults()}
+}
+
+type rateLimitTransport struct {
+	limiter *RateLimiter
+	base    http.RoundTripper
+	urls    BaseURLs
+}
+
+// apiResource returns the budget a request draws on, or false for requests outside the API
+// and for /rate_limit, which is free.
+func apiResource(urls BaseURLs, req *http.Request) (string, bool) {
+	u := req.URL.Scheme + "://" + req.URL.Host + req.URL.Path
+	switch {
+	case u == urls.GraphQL:
+		return "graphql", true
+	case u == urls.API+"/rate_limit":
+		return "", false
+	case strings.HasPrefix(u, urls.API+"/search/"):
+		return "search", true
+	case strings.HasPrefix(u, urls.API+"/"):
+		return "core", true
+	default:
+		return "", false
+	}
+}
+
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resource, ok := apiResource(t.urls, req)
+	if !ok {
+		return t.base.RoundTrip(req)
+	}
+	ctx := req.Context()
+	token := requestToken(req)
+	for attempt := 1; ; attempt++ {
+		if err := t.limiter.wait(ctx, token, resource, t.limiter.policy); err != nil {
+			var rlErr *RateLimitError
+			if errors.As(err, &rlErr) {
+				noteRateLimit(ctx, rlErr)
+			}
+			return nil, err
+		}
+
+		resp, err := t.base.RoundTrip(req)
+		if err != nil {
+			return nil, err
+		}
+		rlErr := t.limiter.observe(token, resource, resp)
+		if rlErr == nil {
+			return resp, nil
+		}
+		t.limiter.logger.Warn("GitHub refused request: rate limit exceeded",
+			"url", req.URL.String(), "resource", rlErr.Resource, "secondary", rlErr.Secondary, "reset", rlErr.Reset)
+
+		// Under RateLimitWait the next pass sleeps until the reset; a body that cannot be replayed ends it here
+		if t.limiter.policy == RateLimitFail || attempt == 3 || (req.Body != nil && req.Body != http.NoBody && req.GetBody == nil) {
+			noteRateLimit(ctx, rlErr)
+			return nil, rlErr
+		}
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(ctx)
+			req.Body = body
+		}
+	}
+}
+
+// requestToken extracts the token from a request's Authorization header.
+func requestToken(req *http.Request) string {
+	auth := req.Header.Get("Authorization")
+	for _, scheme := range []string{"token ", "Bearer ", "bearer "} {
+		if token, ok := strings.CutPrefix(auth, scheme); ok {
+			return token
+		}
+	}
+	return auth
+}
+
+// fingerprint identifies a token in budgets and logs without revealing it.
+func fingerprint(token string) string {
+	if token == "" {
+		return "anonymous"
+	}
+	sum := sha256.Sum256([]byte(token))
+	return "sha256:" + hex.EncodeToString(sum[:4])
+}
+
+type rateLimitWatchKey struct{}
+
+type rateLimitWatch struct {
+	err *RateLimitError
+	mu  sync.Mutex
+}
+
+// WatchRateLimits returns a context that remembers the first rate limit error of any request made with it,
+// and a function that reports it. Callers that tolerate individual failed fetches use it to avoid
+// presenting results built from data that a rate limit cut short.
+func WatchRateLimits(ctx context.Context) (context.Context, func() *RateLimitError) {
+	w := &rateLimitWatch{}
+	return context.WithValue(ctx, rateLimitWatchKey{}, w), func() *RateLimitError {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		return w.err
+	}
+}
+
+func noteRateLimit(ctx context.Context, rlErr *RateLimitError) {
+	w, ok := ctx.Value(rateLimitWatchKey{}).(*rateLimitWatch)
+	if !ok {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.err == nil {
+		w.err = rlErr
+	}
+}
+
+// RateLimits returns the budgets of the client's token, or of the token set on ctx with WithToken. Budgets not yet seen in a response are fetched
+// from the /rate_limit endpoint, which does not count against them.
+func (c *Client) RateLimits(ctx context.Context) ([]RateLimit, error) {
+	if c.rateLimiter == nil {
+		return nil, errors.New("rate limits are not tracked")
+	}
+	token := cmp.Or(tokenFromContext(ctx), c.githubToken)
+	if budgets := c.rateLimiter.Budgets(token); len(budgets) > 0 {
+		return budgets, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURLs.API+"/rate_limit", http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	if token != "" && c.isValidGitHubToken(token) {
+		req.Header.Set("Authorization", "token "+token)
+	}
+	// Straight to the network: a cached answer would be stale
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetching rate limits: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			c.logger.Debug("failed to close response body", "error", err)
+		}
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("github API returned status %d", resp.StatusCode)
+	}
+
+	var body struct {
+		Resources map[string]struct {
+			Limit     int   `json:"limit"`
+			Remaining int   `json:"remaining"`
+			Used      int   `json:"used"`
+			Reset     int64 `json:"reset"`
+		} `json:"resources"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("decoding rate limits: %w", err)
+	}
+	limits := make([]RateLimit, 0, len(body.Resources))
+	for resource, r := range body.Resources {
+		limits = append(limits, RateLimit{
+			Resource: resource, Limit: r.Limit, Remaining: r.Remaining, Used: r.Used, Reset: time.Unix(r.Reset, 0),
+		})
+	}
+	c.rateLimiter.Record(token, limits...)
+	return c.rateLimiter.Budgets(token), nil
+}
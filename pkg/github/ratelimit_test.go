@@ -0,0 +1,223 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParseRateLimit(t *testing.T) {
+	h := http.Header{}
+	h.Set("X-RateLimit-Limit", "5000")
+	h.Set("X-RateLimit-Remaining", "4321")
+	h.Set("X-RateLimit-Used", "679")
+	h.Set("X-RateLimit-Reset", "1700000000")
+	h.Set("X-RateLimit-Resource", "graphql")
+	got, ok := ParseRateLimit(h)
+	want := RateLimit{Reset: time.Unix(1700000000, 0), Resource: "graphql", Limit: 5000, Remaining: 4321, Used: 679}
+	if !ok || got != want {
+		t.Errorf("ParseRateLimit = %+v, %v; want %+v", got, ok, want)
+	}
+	if _, ok := ParseRateLimit(http.Header{}); ok {
+		t.Error("ParseRateLimit reported a budget for a response without one")
+	}
+}
+
+// budgetServer serves API requests with the given budget headers and counts the requests that reach it.
+func budgetServer(t *testing.T, handler func(w http.ResponseWriter, r *http.Request)) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		handler(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return server, &hits
+}
+
+func setBudget(w http.ResponseWriter, remaining int, reset time.Time) {
+	w.Header().Set("X-RateLimit-Limit", "5000")
+	w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
+	w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+	w.Header().Set("X-RateLimit-Resource", "core")
+}
+
+func get(t *testing.T, client *http.Client, ctx context.Context, url, token string) (*http.Response, error) {
+	t.Helper()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, http.NoBody)
+	if err != nil {
+		t.Fatalf("NewRequest: %v", err)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "token "+token)
+	}
+	resp, err := client.Do(req)
+	if err == nil {
+		if _, err := io.Copy(io.Discard, resp.Body); err != nil {
+			t.Errorf("read body: %v", err)
+		}
+		if err := resp.Body.Close(); err != nil {
+			t.Errorf("close body: %v", err)
+		}
+	}
+	return resp, err
+}
+
+func TestRateLimiterFailsFastOnceExhausted(t *testing.T) {
+	reset := time.Now().Add(time.Hour)
+	server, hits := budgetServer(t, func(w http.ResponseWriter, _ *http.Request) {
+		setBudget(w, 0, reset)
+	})
+	limiter := NewRateLimiter(RateLimitFail, slog.New(slog.DiscardHandler))
+	client := &http.Client{Transport: limiter.Transport(nil, EnterpriseBaseURLs(server.URL))}
+	ctx := context.Background()
+
+	if _, err := get(t, client, ctx, server.URL+"/api/v3/users/a", "tok1"); err != nil {
+		t.Fatalf("first request: %v", err)
+	}
+	_, err := get(t, client, ctx, server.URL+"/api/v3/users/b", "tok1")
+	var rlErr *RateLimitError
+	if !errors.As(err, &rlErr) || !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("second request error = %v, want a *RateLimitError", err)
+	}
+	if rlErr.Resource != "core" || rlErr.Reset.Unix() != reset.Unix() || rlErr.Secondary {
+		t.Errorf("RateLimitError = %+v, want core until %v", rlErr, reset)
+	}
+	if got := hits.Load(); got != 1 {
+		t.Errorf("server saw %d requests, want 1: the exhausted budget should stop the second", got)
+	}
+
+	// Budgets are per token, and other tokens are unaffected
+	if _, err := get(t, client, ctx, server.URL+"/api/v3/users/c", "tok2"); err != nil {
+		t.Errorf("request with another token: %v", err)
+	}
+	budgets := limiter.Budgets("tok1")
+	if len(budgets) != 1 || budgets[0].Remaining != 0 || budgets[0].Limit != 5000 || budgets[0].Token == "tok1" {
+		t.Errorf("Budgets(tok1) = %+v, want one exhausted core budget with a fingerprinted token", budgets)
+	}
+}
+
+func TestRateLimiterWaitRespectsDeadline(t *testing.T) {
+	server, hits := budgetServer(t, func(w http.ResponseWriter, _ *http.Request) {
+		setBudget(w, 0, time.Now().Add(time.Hour))
+		w.WriteHeader(http.StatusForbidden)
+	})
+	limiter := NewRateLimiter(RateLimitWait, slog.New(slog.DiscardHandler))
+	client := &http.Client{Transport: limiter.Transport(nil, EnterpriseBaseURLs(server.URL))}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	_, err := get(t, client, ctx, server.URL+"/api/v3/users/a", "")
+	if !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("error = %v, want ErrRateLimited", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("took %v; a reset after the deadline should fail at once", elapsed)
+	}
+	if got := hits.Load(); got != 1 {
+		t.Errorf("server saw %d requests, want 1", got)
+	}
+}
+
+func TestRateLimiterWaitsOutSecondaryLimit(t *testing.T) {
+	var calls atomic.Int32
+	server, hits := budgetServer(t, func(w http.ResponseWriter, _ *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		setBudget(w, 4999, time.Now().Add(time.Hour))
+	})
+	limiter := NewRateLimiter(RateLimitWait, slog.New(slog.DiscardHandler))
+	client := &http.Client{Transport: limiter.Transport(nil, EnterpriseBaseURLs(server.URL))}
+
+	resp, err := get(t, client, context.Background(), server.URL+"/api/graphql", "tok")
+	if err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("request = %v, %v; want a retried success", resp, err)
+	}
+	if got := hits.Load(); got != 2 {
+		t.Errorf("server saw %d requests, want 2", got)
+	}
+}
+
+func TestRateLimiterPassesOtherResponses(t *testing.T) {
+	server, _ := budgetServer(t, func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		if _, err := w.Write([]byte(`{"message":"Resource not accessible by integration"}`)); err != nil {
+			t.Errorf("write: %v", err)
+		}
+	})
+	limiter := NewRateLimiter(RateLimitFail, slog.New(slog.DiscardHandler))
+	client := &http.Client{Transport: limiter.Transport(nil, EnterpriseBaseURLs(server.URL))}
+
+	for _, path := range []string{"/api/v3/orgs/secret", "/octocat"} {
+		resp, err := get(t, client, context.Background(), server.URL+path, "tok")
+		if err != nil || resp.StatusCode != http.StatusForbidden {
+			t.Errorf("GET %s = %v, %v; want the 403 passed through", path, resp, err)
+		}
+	}
+}
+
+func TestWatchRateLimits(t *testing.T) {
+	server, _ := budgetServer(t, func(w http.ResponseWriter, _ *http.Request) {
+		setBudget(w, 0, time.Now().Add(time.Hour))
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+	limiter := NewRateLimiter(RateLimitFail, slog.New(slog.DiscardHandler))
+	client := &http.Client{Transport: limiter.Transport(nil, EnterpriseBaseURLs(server.URL))}
+
+	ctx, rateLimited := WatchRateLimits(context.Background())
+	if rateLimited() != nil {
+		t.Fatal("rate limit reported before any request")
+	}
+	if _, err := get(t, client, ctx, server.URL+"/api/v3/users/a", ""); err == nil {
+		t.Fatal("request succeeded, want a rate limit error")
+	}
+	if rlErr := rateLimited(); rlErr == nil || rlErr.Resource != "core" {
+		t.Errorf("rateLimited() = %v, want the core limit", rlErr)
+	}
+}
+
+func TestGraphQLRateLimited(t *testing.T) {
+	reset := time.Now().Add(30 * time.Minute)
+	server, _ := budgetServer(t, func(w http.ResponseWriter, _ *http.Request) {
+		setBudget(w, 0, reset)
+		if _, err := w.Write([]byte(`{"errors":[{"type":"RATE_LIMITED","message":"API rate limit exceeded for user ID 1."}]}`)); err != nil {
+			t.Errorf("write: %v", err)
+		}
+	})
+	do := func(_ context.Context, req *http.Request) (*http.Response, error) {
+		return server.Client().Do(req)
+	}
+	client := NewGraphQLClient("tok", do, slog.New(slog.DiscardHandler))
+	client.SetEndpoint(server.URL + "/api/graphql")
+
+	_, err := client.executeQuery(context.Background(), "query { viewer { login } }", nil)
+	var rlErr *RateLimitError
+	if !errors.As(err, &rlErr) {
+		t.Fatalf("error = %v, want a *RateLimitError", err)
+	}
+	if rlErr.Resource != "graphql" || rlErr.Reset.Unix() != reset.Unix() {
+		t.Errorf("RateLimitError = %+v, want graphql until %v", rlErr, reset)
+	}
+}
+
+func TestParseRateLimitPolicy(t *testing.T) {
+	for in, want := range map[string]RateLimitPolicy{"": RateLimitWait, "wait": RateLimitWait, "FAIL": RateLimitFail} {
+		if got, err := ParseRateLimitPolicy(in); err != nil || got != want {
+			t.Errorf("ParseRateLimitPolicy(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseRateLimitPolicy("retry"); err == nil {
+		t.Error("ParseRateLimitPolicy accepted an unknown policy")
+	}
+}
@@ -0,0 +1,324 @@
+package github
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// unknownBudget ranks a token whose budget has not been seen yet: a fresh token has its full hourly quota.
+const unknownBudget = 5000
+
+// retiredRecheck is how long a retired token rests before it is checked against /user again, in case
+// its 401 came from a GitHub hiccup rather than a revocation.
+const retiredRecheck = 15 * time.Minute
+
+type tokenKey struct{}
+
+// WithToken returns a context whose GitHub API requests use token, when a TokenPool transport is installed.
+func WithToken(ctx context.Context, token string) context.Context {
+	return context.WithValue(ctx, tokenKey{}, token)
+}
+
+func tokenFromContext(ctx context.Context) string {
+	token, _ := ctx.Value(tokenKey{}).(string) //nolint:errcheck // empty when unset
+	return token
+}
+
+// ParseTokens splits a comma- or whitespace-separated list of tokens, as found in a GITHUB_TOKENS variable.
+func ParseTokens(list string) []string {
+	return strings.FieldsFunc(list, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t' || r == '\n' || r == '\r'
+	})
+}
+
+// LoadTokens reads tokens from a file with one token per line. Blank lines and lines starting with # are skipped.
+func LoadTokens(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close() //nolint:errcheck // read-only
+
+	var tokens []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		tokens = append(tokens, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	return tokens, nil
+}
+
+// TokenUsage reports how one pooled token has been used.
+type TokenUsage struct {
+	RetiredAt    time.Time   `json:"retired_at,omitzero"`
+	Token        string      `json:"token"` // Token fingerprint, never the token itself
+	Budgets      []RateLimit `json:"budgets"`
+	Requests     int         `json:"requests"`
+	Unauthorized int         `json:"unauthorized"`
+	Retired      bool        `json:"retired"`
+	checkAt      time.Time   // When a retired token is next checked against /user
+}
+
+// TokenPool spreads GitHub API requests over several tokens. Each detection is assigned the token with the
+// most remaining budget, and tokens that GitHub answers with 401 Unauthorized are retired until a check
+// against /user, every retiredRecheck, finds them accepted again.
+type TokenPool struct {
+	limiter *RateLimiter
+	usage   map[string]*TokenUsage // token -> usage
+	tokens  []string
+	next    int // Rotates ties between equally good tokens
+	mu      sync.Mutex
+}
+
+// NewTokenPool creates a pool of distinct tokens whose budgets are read from limiter.
+func NewTokenPool(tokens []string, limiter *RateLimiter) *TokenPool {
+	p := &TokenPool{limiter: limiter, usage: map[string]*TokenUsage{}}
+	for _, token := range tokens {
+		token = strings.TrimSpace(token)
+		if token == "" || p.usage[token] != nil {
+			continue
+		}
+		p.tokens = append(p.tokens, token)
+		p.usage[token] = &TokenUsage{Token: fingerprint(token)}
+	}
+	return p
+}
+
+// Len returns the number of tokens in the pool, including retired ones.
+func (p *TokenPool) Len() int {
+	return len(p.tokens)
+}
+
+// Active returns the tokens that have not been retired.
+func (p *TokenPool) Active() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var active []string
+	for _, token := range p.tokens {
+		if !p.usage[token].Retired {
+			active = append(active, token)
+		}
+	}
+	return active
+}
+
+// Pick returns the active token with the most remaining budget, or "" if every token is retired.
+// A token's budget is the smaller of its core and GraphQL budgets, since a detection needs both.
+func (p *TokenPool) Pick() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.pick("")
+}
+
+// pick chooses a token other than avoid. The caller holds p.mu.
+func (p *TokenPool) pick(avoid string) string {
+	best, bestBudget := "", -1
+	for i := range p.tokens {
+		token := p.tokens[(p.next+i)%len(p.tokens)]
+		if token == avoid || p.usage[token].Retired {
+			continue
+		}
+		if budget := p.remaining(token); budget > bestBudget {
+			best, bestBudget = token, budget
+		}
+	}
+	if len(p.tokens) > 0 {
+		p.next = (p.next + 1) % len(p.tokens)
+	}
+	return best
+}
+
+// remaining returns the usable budget of a token, counting budgets that have since reset as full.
+func (p *TokenPool) remaining(token string) int {
+	now := p.limiter.now()
+	budget := unknownBudget
+	for _, rl := range p.limiter.Budgets(token) {
+		if rl.Resource != "core" && rl.Resource != "graphql" {
+			continue
+		}
+		left := rl.Remaining
+		if !now.Before(rl.Reset) {
+			left = max(rl.Limit, left)
+		}
+		budget = min(budget, left)
+	}
+	return budget
+}
+
+// Assign returns a context whose API requests use the best token at this moment.
+func (p *TokenPool) Assign(ctx context.Context) context.Context {
+	if token := p.Pick(); token != "" {
+		return WithToken(ctx, token)
+	}
+	return ctx
+}
+
+// Retire stops handing out a token, typically because it was revoked.
+func (p *TokenPool) Retire(token string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if u := p.usage[token]; u != nil && !u.Retired {
+		u.Retired, u.RetiredAt = true, p.limiter.now()
+		u.checkAt = u.RetiredAt.Add(retiredRecheck)
+		p.limiter.logger.Error("retiring GitHub token after 401 Unauthorized", "token", u.Token, "recheck_at", u.checkAt)
+	}
+}
+
+// reinstate hands out a retired token again.
+func (p *TokenPool) reinstate(token string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if u := p.usage[token]; u != nil && u.Retired {
+		u.Retired, u.RetiredAt = false, time.Time{}
+		p.limiter.logger.Warn("reinstating retired GitHub token that GitHub accepts again", "token", u.Token)
+	}
+}
+
+// due returns the retired tokens whose rest is over, and starts their next rest so that each is
+// checked once.
+func (p *TokenPool) due() []string {
+	now := p.limiter.now()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var due []string
+	for _, token := range p.tokens {
+		if u := p.usage[token]; u.Retired && !now.Before(u.checkAt) {
+			u.checkAt = now.Add(retiredRecheck)
+			due = append(due, token)
+		}
+	}
+	return due
+}
+
+// Usage reports requests, 401s and the last known budgets of every token in the pool.
+func (p *TokenPool) Usage() []TokenUsage {
+	p.mu.Lock()
+	usage := make([]TokenUsage, 0, len(p.tokens))
+	for _, token := range p.tokens {
+		usage = append(usage, *p.usage[token])
+	}
+	p.mu.Unlock()
+	for i, token := range p.tokens {
+		usage[i].Budgets = slices.Clip(p.limiter.Budgets(token))
+	}
+	return usage
+}
+
+// Transport returns a RoundTripper that sends each API request in urls with a pooled token: the one assigned
+// to the request's context, unless it has been retired or exhausted, or else the best available one.
+// A request answered with 401 retires its token and is retried once with another. Requests also start the
+// background checks of retired tokens that are due.
+// Install it outside the RateLimiter's transport, so that budgets are recorded against the token actually used.
+func (p *TokenPool) Transport(base http.RoundTripper, urls BaseURLs) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &tokenPoolTransport{pool: p, base: base, urls: urls.withDefaults()}
+}
+
+type tokenPoolTransport struct {
+	pool *TokenPool
+	base http.RoundTripper
+	urls BaseURLs
+}
+
+func (t *tokenPoolTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resource, ok := apiResource(t.urls, req)
+	if !ok || len(t.pool.tokens) == 0 {
+		return t.base.RoundTrip(req)
+	}
+	for _, token := range t.pool.due() {
+		go t.recheck(token)
+	}
+	token := t.choose(req.Context(), resource)
+	for attempt := 1; ; attempt++ {
+		out := req.Clone(req.Context())
+		if token != "" {
+			out.Header.Set("Authorization", "bearer "+token)
+		}
+		if attempt > 1 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			out.Body = body
+		}
+
+		resp, err := t.base.RoundTrip(out)
+		t.pool.mu.Lock()
+		if u := t.pool.usage[token]; u != nil {
+			u.Requests++
+			if err == nil && resp.StatusCode == http.StatusUnauthorized {
+				u.Unauthorized++
+			}
+		}
+		t.pool.mu.Unlock()
+		if err != nil || resp.StatusCode != http.StatusUnauthorized || token == "" {
+			return resp, err
+		}
+
+		t.pool.Retire(token)
+		replayable := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+		if attempt > 1 || !replayable {
+			return resp, nil
+		}
+		t.pool.mu.Lock()
+		token = t.pool.pick(token)
+		t.pool.mu.Unlock()
+		if token == "" {
+			return resp, nil
+		}
+		closeBody(resp)
+	}
+}
+
+// recheck reinstates a retired token if GitHub accepts it for /user again.
+func (t *tokenPoolTransport) recheck(token string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, t.urls.API+"/user", http.NoBody)
+	if err != nil {
+		return
+	}
+	req.Header.Set("Authorization", "bearer "+token)
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return // Checked again after the next rest
+	}
+	closeBody(resp)
+	if resp.StatusCode == http.StatusOK {
+		t.pool.reinstate(token)
+	}
+}
+
+// choose returns the token for a request: the context's, unless unusable, else the pool's best.
+func (t *tokenPoolTransport) choose(ctx context.Context, resource string) string {
+	token := tokenFromContext(ctx)
+	t.pool.mu.Lock()
+	defer t.pool.mu.Unlock()
+	u := t.pool.usage[token]
+	usable := u != nil && !u.Retired
+	if usable && t.pool.limiter.exhausted(token, resource) == nil {
+		return token
+	}
+	if best := t.pool.pick(token); best != "" {
+		return best
+	}
+	if usable {
+		return token // Exhausted, but the only one left: the rate limiter decides whether to wait
+	}
+	return ""
+}
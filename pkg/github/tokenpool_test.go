@@ -0,0 +1,179 @@
+package github
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTokenPoolPicksLargestBudget(t *testing.T) {
+	limiter := NewRateLimiter(RateLimitWait, slog.New(slog.DiscardHandler))
+	reset := time.Now().Add(time.Hour)
+	limiter.Record("low", RateLimit{Resource: "core", Limit: 5000, Remaining: 10, Reset: reset})
+	limiter.Record("high", RateLimit{Resource: "core", Limit: 5000, Remaining: 4000, Reset: reset},
+		RateLimit{Resource: "graphql", Limit: 5000, Remaining: 3000, Reset: reset})
+	limiter.Record("reset", RateLimit{Resource: "core", Limit: 5000, Remaining: 0, Reset: time.Now().Add(-time.Minute)})
+
+	pool := NewTokenPool([]string{"low", "high", "high", " ", "reset"}, limiter)
+	if pool.Len() != 3 {
+		t.Errorf("Len() = %d, want 3 distinct tokens", pool.Len())
+	}
+	// "reset" has its full quota again, which beats 3000 of GraphQL budget
+	if got := pool.Pick(); got != "reset" {
+		t.Errorf("Pick() = %q, want the token whose budget has reset", got)
+	}
+	pool.Retire("reset")
+	if got := pool.Pick(); got != "high" {
+		t.Errorf("Pick() = %q, want high", got)
+	}
+	pool.Retire("high")
+	pool.Retire("low")
+	if got := pool.Pick(); got != "" {
+		t.Errorf("Pick() = %q with every token retired, want none", got)
+	}
+}
+
+func TestTokenPoolRotatesFreshTokens(t *testing.T) {
+	pool := NewTokenPool([]string{"a", "b", "c"}, NewRateLimiter(RateLimitWait, slog.New(slog.DiscardHandler)))
+	seen := map[string]bool{}
+	for range 3 {
+		seen[pool.Pick()] = true
+	}
+	if len(seen) != 3 {
+		t.Errorf("picked %v from three fresh tokens, want each once", seen)
+	}
+}
+
+func TestTokenPoolTransportRetiresUnauthorized(t *testing.T) {
+	var mu sync.Mutex
+	var auths []string
+	server, _ := budgetServer(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		auths = append(auths, r.Header.Get("Authorization"))
+		mu.Unlock()
+		if r.Header.Get("Authorization") == "bearer revoked" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		setBudget(w, 4999, time.Now().Add(time.Hour))
+	})
+	limiter := NewRateLimiter(RateLimitWait, slog.New(slog.DiscardHandler))
+	pool := NewTokenPool([]string{"revoked", "good"}, limiter)
+	urls := EnterpriseBaseURLs(server.URL)
+	client := &http.Client{Transport: pool.Transport(limiter.Transport(nil, urls), urls)}
+	ctx := WithToken(context.Background(), "revoked")
+
+	resp, err := get(t, client, ctx, server.URL+"/api/v3/users/octocat", "client-token")
+	if err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("request = %v, %v; want success with the other token", resp, err)
+	}
+	if want := []string{"bearer revoked", "bearer good"}; !slices.Equal(auths, want) {
+		t.Errorf("Authorization headers = %q, want %q", auths, want)
+	}
+
+	// The retired token is not used again, even when assigned
+	if _, err := get(t, client, ctx, server.URL+"/api/v3/users/octocat", ""); err != nil {
+		t.Fatalf("second request: %v", err)
+	}
+	if got := auths[len(auths)-1]; got != "bearer good" {
+		t.Errorf("second request used %q, want bearer good", got)
+	}
+
+	usage := pool.Usage()
+	if len(usage) != 2 {
+		t.Fatalf("Usage() = %+v, want two tokens", usage)
+	}
+	revoked, good := usage[0], usage[1]
+	if !revoked.Retired || revoked.Unauthorized != 1 || revoked.Requests != 1 || revoked.RetiredAt.IsZero() {
+		t.Errorf("revoked usage = %+v, want retired after one 401", revoked)
+	}
+	if good.Retired || good.Requests != 2 || len(good.Budgets) != 1 || good.Budgets[0].Remaining != 4999 {
+		t.Errorf("good usage = %+v, want two requests and its budget", good)
+	}
+	if revoked.Token == "revoked" || good.Token == "good" {
+		t.Error("Usage() exposes raw tokens")
+	}
+}
+
+func TestTokenPoolRechecksRetiredTokens(t *testing.T) {
+	var revoked atomic.Bool
+	revoked.Store(true)
+	server, _ := budgetServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "bearer flaky" && revoked.Load() {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		setBudget(w, 4999, time.Now().Add(time.Hour))
+	})
+	var mu sync.Mutex
+	clock := time.Now()
+	limiter := NewRateLimiter(RateLimitWait, slog.New(slog.DiscardHandler))
+	limiter.now = func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return clock
+	}
+	pool := NewTokenPool([]string{"flaky", "good"}, limiter)
+	urls := EnterpriseBaseURLs(server.URL)
+	client := &http.Client{Transport: pool.Transport(limiter.Transport(nil, urls), urls)}
+	ctx := WithToken(context.Background(), "flaky")
+
+	if _, err := get(t, client, ctx, server.URL+"/api/v3/users/octocat", ""); err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	if got := pool.Active(); !slices.Equal(got, []string{"good"}) {
+		t.Fatalf("Active() = %q after a 401, want flaky retired", got)
+	}
+
+	// GitHub accepts the token again, and its rest is over
+	revoked.Store(false)
+	mu.Lock()
+	clock = clock.Add(retiredRecheck)
+	mu.Unlock()
+	if _, err := get(t, client, ctx, server.URL+"/api/v3/users/octocat", ""); err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for len(pool.Active()) != 2 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if got := pool.Active(); len(got) != 2 {
+		t.Errorf("Active() = %q after a successful recheck, want flaky reinstated", got)
+	}
+}
+
+func TestTokenPoolTransportLeavesOtherHosts(t *testing.T) {
+	var got string
+	server, _ := budgetServer(t, func(_ http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get("Authorization")
+	})
+	pool := NewTokenPool([]string{"pooled"}, NewRateLimiter(RateLimitWait, slog.New(slog.DiscardHandler)))
+	client := &http.Client{Transport: pool.Transport(nil, EnterpriseBaseURLs(server.URL))}
+	if _, err := get(t, client, context.Background(), server.URL+"/octocat", ""); err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	if got != "" {
+		t.Errorf("profile page request carried Authorization %q, want none", got)
+	}
+}
+
+func TestLoadTokens(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tokens")
+	if err := os.WriteFile(path, []byte("# team tokens\nghp_one\n\n  ghp_two  \n"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	tokens, err := LoadTokens(path)
+	if err != nil || !slices.Equal(tokens, []string{"ghp_one", "ghp_two"}) {
+		t.Errorf("LoadTokens = %q, %v", tokens, err)
+	}
+	if got := ParseTokens("ghp_a, ghp_b,,ghp_c"); !slices.Equal(got, []string{"ghp_a", "ghp_b", "ghp_c"}) {
+		t.Errorf("ParseTokens = %q", got)
+	}
+}
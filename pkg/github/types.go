@@ -62,7 +62,10 @@ type Gist struct {
 	ID          string    `json:"id"`
 	Description string    `json:"description"`
 	HTMLURL     string    `json:"html_url"`
-	Public      bool      `json:"public"`
+	Files       map[string]struct {
+		Filename string `json:"filename"`
+	} `json:"files"`
+	Public bool `json:"public"`
 }
 
 // Repository represents a GitHub repository.
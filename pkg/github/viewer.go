@@ -0,0 +1,210 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ViewerContribution is one timestamped contribution of the user a token belongs to.
+type ViewerContribution struct {
+	Time       time.Time
+	Kind       string // "commit", "pull_request", "review" or "issue"
+	Repository string // owner/name
+	Private    bool
+}
+
+// ViewerActivity is the activity of the user a token belongs to, as only their own token reveals it.
+type ViewerActivity struct {
+	Login         string
+	Contributions []ViewerContribution
+}
+
+type viewerRepository struct {
+	NameWithOwner string `json:"nameWithOwner"`
+	IsPrivate     bool   `json:"isPrivate"`
+}
+
+// viewerResponse for the authenticated user's contributions collection.
+type viewerResponse struct {
+	Viewer struct {
+		Login                   string `json:"login"`
+		ID                      string `json:"id"`
+		ContributionsCollection struct {
+			PullRequestContributions struct {
+				Nodes []struct {
+					OccurredAt  time.Time `json:"occurredAt"`
+					PullRequest struct {
+						Repository viewerRepository `json:"repository"`
+					} `json:"pullRequest"`
+				} `json:"nodes"`
+			} `json:"pullRequestContributions"`
+			PullRequestReviewContributions struct {
+				Nodes []struct {
+					OccurredAt time.Time        `json:"occurredAt"`
+					Repository viewerRepository `json:"repository"`
+				} `json:"nodes"`
+			} `json:"pullRequestReviewContributions"`
+			IssueContributions struct {
+				Nodes []struct {
+					OccurredAt time.Time `json:"occurredAt"`
+					Issue      struct {
+						Repository viewerRepository `json:"repository"`
+					} `json:"issue"`
+				} `json:"nodes"`
+			} `json:"issueContributions"`
+			CommitContributionsByRepository []struct {
+				Repository viewerRepository `json:"repository"`
+			} `json:"commitContributionsByRepository"`
+		} `json:"contributionsCollection"`
+	} `json:"viewer"`
+}
+
+// viewerCommitsResponse for the user's commits on a repository's default branch.
+type viewerCommitsResponse struct {
+	Repository struct {
+		DefaultBranchRef *struct {
+			Target struct {
+				History struct {
+					Nodes []struct {
+						CommittedDate time.Time `json:"committedDate"`
+					} `json:"nodes"`
+				} `json:"history"`
+			} `json:"target"`
+		} `json:"defaultBranchRef"`
+	} `json:"repository"`
+}
+
+// FetchViewerActivity returns the pull requests, reviews and issues of the user the client's token
+// belongs to since the given time, which must be less than a year ago, including those in private
+// repositories. Commit contributions only carry a date, so commit times are read from the default
+// branch of each private repository the user committed to; public commits are left to commit search.
+// The responses are specific to the token, so the client must not share a cache with other users.
+func (c *GraphQLClient) FetchViewerActivity(ctx context.Context, since time.Time) (*ViewerActivity, error) {
+	query := `
+	query($from: DateTime!) {
+		viewer {
+			login
+			id
+			contributionsCollection(from: $from) {
+				pullRequestContributions(first: 100) {
+					nodes {
+						occurredAt
+						pullRequest { repository { nameWithOwner isPrivate } }
+					}
+				}
+				pullRequestReviewContributions(first: 100) {
+					nodes {
+						occurredAt
+						repository { nameWithOwner isPrivate }
+					}
+				}
+				issueContributions(first: 100) {
+					nodes {
+						occurredAt
+						issue { repository { nameWithOwner isPrivate } }
+					}
+				}
+				commitContributionsByRepository(maxRepositories: 25) {
+					repository { nameWithOwner isPrivate }
+				}
+			}
+		}
+	}`
+
+	resp, err := c.executeQuery(ctx, query, map[string]any{"from": since.UTC().Format(time.RFC3339)})
+	if err != nil {
+		return nil, err
+	}
+	var data viewerResponse
+	if err := json.Unmarshal(resp.Data, &data); err != nil {
+		return nil, fmt.Errorf("decoding viewer contributions: %w", err)
+	}
+	if data.Viewer.Login == "" {
+		return nil, errors.New("token does not belong to a user")
+	}
+
+	activity := &ViewerActivity{Login: data.Viewer.Login}
+	add := func(at time.Time, kind string, repo viewerRepository) {
+		activity.Contributions = append(activity.Contributions, ViewerContribution{
+			Time: at, Kind: kind, Repository: repo.NameWithOwner, Private: repo.IsPrivate,
+		})
+	}
+	cc := data.Viewer.ContributionsCollection
+	for _, n := range cc.PullRequestContributions.Nodes {
+		add(n.OccurredAt, "pull_request", n.PullRequest.Repository)
+	}
+	for _, n := range cc.PullRequestReviewContributions.Nodes {
+		add(n.OccurredAt, "review", n.Repository)
+	}
+	for _, n := range cc.IssueContributions.Nodes {
+		add(n.OccurredAt, "issue", n.Issue.Repository)
+	}
+
+	for _, r := range cc.CommitContributionsByRepository {
+		if !r.Repository.IsPrivate {
+			continue
+		}
+		times, err := c.fetchViewerCommits(ctx, r.Repository.NameWithOwner, data.Viewer.ID, since)
+		if err != nil {
+			// Keep what we have: one unreadable repository shouldn't void the rest
+			c.logger.Debug("could not list viewer commits", "error", err)
+			continue
+		}
+		for _, t := range times {
+			add(t, "commit", r.Repository)
+		}
+	}
+
+	c.logger.Debug("fetched viewer activity", "username", activity.Login, "contributions", len(activity.Contributions))
+	return activity, nil
+}
+
+// fetchViewerCommits returns the commit times of up to 100 of the author's commits since the given time
+// on the default branch of repo.
+func (c *GraphQLClient) fetchViewerCommits(ctx context.Context, repo, authorID string, since time.Time) ([]time.Time, error) {
+	owner, name, ok := strings.Cut(repo, "/")
+	if !ok {
+		return nil, fmt.Errorf("invalid repository name %q", repo)
+	}
+	query := `
+	query($owner: String!, $name: String!, $author: ID!, $since: GitTimestamp!) {
+		repository(owner: $owner, name: $name) {
+			defaultBranchRef {
+				target {
+					... on Commit {
+						history(first: 100, author: {id: $author}, since: $since) {
+							nodes { committedDate }
+						}
+					}
+				}
+			}
+		}
+	}`
+
+	resp, err := c.executeQuery(ctx, query, map[string]any{
+		"owner":  owner,
+		"name":   name,
+		"author": authorID,
+		"since":  since.UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		return nil, err
+	}
+	var data viewerCommitsResponse
+	if err := json.Unmarshal(resp.Data, &data); err != nil {
+		return nil, fmt.Errorf("decoding commit history: %w", err)
+	}
+	if data.Repository.DefaultBranchRef == nil {
+		return nil, nil // Empty repository
+	}
+	nodes := data.Repository.DefaultBranchRef.Target.History.Nodes
+	times := make([]time.Time, 0, len(nodes))
+	for _, n := range nodes {
+		times = append(times, n.CommittedDate)
+	}
+	return times, nil
+}
@@ -0,0 +1,68 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFetchViewerActivity(t *testing.T) {
+	var commitQueries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer user-token" {
+			t.Errorf("Authorization = %q, want the user's token", r.Header.Get("Authorization"))
+		}
+		var body struct {
+			Query     string         `json:"query"`
+			Variables map[string]any `json:"variables"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		if strings.Contains(body.Query, "history(") {
+			commitQueries = append(commitQueries, fmt.Sprintf("%s/%s by %s", body.Variables["owner"], body.Variables["name"], body.Variables["author"]))
+			fmt.Fprint(w, `{"data": {"repository": {"defaultBranchRef": {"target": {"history": {"nodes": [
+				{"committedDate": "2025-03-04T22:10:00Z"}, {"committedDate": "2025-03-05T06:45:00Z"}]}}}}}}`)
+			return
+		}
+		fmt.Fprint(w, `{"data": {"viewer": {"login": "alice", "id": "U_1", "contributionsCollection": {
+			"pullRequestContributions": {"nodes": [
+				{"occurredAt": "2025-03-03T15:00:00Z", "pullRequest": {"repository": {"nameWithOwner": "acme/secret", "isPrivate": true}}}]},
+			"pullRequestReviewContributions": {"nodes": [
+				{"occurredAt": "2025-03-03T16:00:00Z", "repository": {"nameWithOwner": "alice/dotfiles", "isPrivate": false}}]},
+			"issueContributions": {"nodes": []},
+			"commitContributionsByRepository": [
+				{"repository": {"nameWithOwner": "acme/secret", "isPrivate": true}},
+				{"repository": {"nameWithOwner": "alice/dotfiles", "isPrivate": false}}]}}}}`)
+	}))
+	defer server.Close()
+	do := func(_ context.Context, req *http.Request) (*http.Response, error) { return server.Client().Do(req) }
+	client := NewGraphQLClient("user-token", do, slog.New(slog.DiscardHandler))
+	client.SetEndpoint(server.URL + "/api/graphql")
+
+	activity, err := client.FetchViewerActivity(context.Background(), time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("FetchViewerActivity: %v", err)
+	}
+	if activity.Login != "alice" {
+		t.Errorf("Login = %q, want alice", activity.Login)
+	}
+	kinds := map[string]int{}
+	for _, c := range activity.Contributions {
+		kinds[fmt.Sprintf("%s private=%t", c.Kind, c.Private)]++
+	}
+	want := map[string]int{"pull_request private=true": 1, "review private=false": 1, "commit private=true": 2}
+	if fmt.Sprint(kinds) != fmt.Sprint(want) {
+		t.Errorf("contributions = %v, want %v", kinds, want)
+	}
+	// Public commits are left to commit search
+	if len(commitQueries) != 1 || commitQueries[0] != "acme/secret by U_1" {
+		t.Errorf("commit queries = %v, want only the private repository", commitQueries)
+	}
+}
@@ -0,0 +1,219 @@
+// Package gitlab provides a client for the public activity of GitLab users, on gitlab.com or a self-managed instance.
+package gitlab
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultURL is the instance queried unless another is configured.
+const DefaultURL = "https://gitlab.com"
+
+// ErrUserNotFound is returned when the instance has no user by that name.
+var ErrUserNotFound = errors.New("gitlab user not found")
+
+// User is a GitLab account.
+type User struct {
+	Username string `json:"username"`
+	Name     string `json:"name"`
+	WebURL   string `json:"web_url"`
+	ID       int64  `json:"id"`
+}
+
+// Event is an entry of a user's contribution events.
+type Event struct {
+	CreatedAt   time.Time `json:"created_at"`
+	Note        *Note     `json:"note"`
+	PushData    *PushData `json:"push_data"`
+	ActionName  string    `json:"action_name"` // "opened", "commented on", "pushed to", "accepted", ...
+	TargetType  string    `json:"target_type"` // "MergeRequest", "Issue", "Note", "DiffNote", ...
+	TargetTitle string    `json:"target_title"`
+	ProjectID   int64     `json:"project_id"`
+}
+
+// Note is the comment of a "commented on" event.
+type Note struct {
+	Body         string `json:"body"`
+	NoteableType string `json:"noteable_type"`
+}
+
+// PushData describes the commits of a "pushed to" event.
+type PushData struct {
+	Ref         string `json:"ref"`
+	CommitTitle string `json:"commit_title"`
+	CommitCount int    `json:"commit_count"`
+}
+
+// MergeRequest is a merge request authored by the user.
+type MergeRequest struct {
+	CreatedAt  time.Time `json:"created_at"`
+	Title      string    `json:"title"`
+	WebURL     string    `json:"web_url"`
+	References struct {
+		Full string `json:"full"` // "group/project!12"
+	} `json:"references"`
+}
+
+// Project returns the merge request's project path, such as group/project.
+func (m MergeRequest) Project() string {
+	project, _, _ := strings.Cut(m.References.Full, "!")
+	return project
+}
+
+// Activity is one timestamped action of a user, as merged into a timeline.
+type Activity struct {
+	Time    time.Time
+	Kind    string // "merge_request", "comment", "push", "issue" or "event"
+	Project string
+	Title   string
+	URL     string
+}
+
+// Client queries the GitLab REST API.
+type Client struct {
+	logger  *slog.Logger
+	do      func(context.Context, *http.Request) (*http.Response, error)
+	baseURL string
+	token   string
+}
+
+// NewClient creates a client for the instance at baseURL, gitlab.com if empty. The token is optional;
+// without one only public activity is visible. do performs requests, typically through a cache.
+func NewClient(logger *slog.Logger, baseURL, token string, do func(context.Context, *http.Request) (*http.Response, error)) *Client {
+	return &Client{
+		logger:  logger,
+		do:      do,
+		baseURL: strings.TrimRight(cmp.Or(baseURL, DefaultURL), "/"),
+		token:   token,
+	}
+}
+
+// get decodes the JSON response to an API request for path into v.
+func (c *Client) get(ctx context.Context, path string, v any) error {
+	apiURL := c.baseURL + "/api/v4" + path
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, http.NoBody)
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	if c.token != "" {
+		req.Header.Set("PRIVATE-TOKEN", c.token)
+	}
+
+	resp, err := c.do(ctx, req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			c.logger.Debug("failed to close response body", "error", err)
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("gitlab API returned status %d for %s", resp.StatusCode, apiURL)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("decoding %s: %w", apiURL, err)
+	}
+	return nil
+}
+
+// FetchUser looks up a user by username.
+func (c *Client) FetchUser(ctx context.Context, username string) (*User, error) {
+	var users []User
+	if err := c.get(ctx, "/users?username="+url.QueryEscape(username), &users); err != nil {
+		return nil, err
+	}
+	for i := range users {
+		if strings.EqualFold(users[i].Username, username) {
+			return &users[i], nil
+		}
+	}
+	return nil, ErrUserNotFound
+}
+
+// FetchEvents returns up to 500 of the user's most recent contribution events.
+func (c *Client) FetchEvents(ctx context.Context, userID int64) ([]Event, error) {
+	const maxPages = 5
+	const perPage = 100
+
+	var all []Event
+	for page := 1; page <= maxPages; page++ {
+		var events []Event
+		if err := c.get(ctx, fmt.Sprintf("/users/%d/events?per_page=%d&page=%d", userID, perPage, page), &events); err != nil {
+			if page == 1 {
+				return nil, err
+			}
+			c.logger.Debug("failed to fetch events page", "page", page, "error", err)
+			break // Return what we have so far
+		}
+		all = append(all, events...)
+		if len(events) < perPage {
+			break
+		}
+	}
+	return all, nil
+}
+
+// FetchMergeRequests returns up to 100 merge requests the user authored, most recent first.
+func (c *Client) FetchMergeRequests(ctx context.Context, userID int64) ([]MergeRequest, error) {
+	var mrs []MergeRequest
+	err := c.get(ctx, fmt.Sprintf("/merge_requests?author_id=%d&scope=all&state=all&per_page=100", userID), &mrs)
+	return mrs, err
+}
+
+// FetchActivity returns the user's events and authored merge requests as timestamped activity.
+// Merge requests are best effort, since some instances only list them to signed-in clients.
+func (c *Client) FetchActivity(ctx context.Context, username string) ([]Activity, error) {
+	user, err := c.FetchUser(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	events, err := c.FetchEvents(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	activity := make([]Activity, 0, len(events))
+	for _, e := range events {
+		activity = append(activity, e.activity())
+	}
+
+	mrs, err := c.FetchMergeRequests(ctx, user.ID)
+	if err != nil {
+		c.logger.Debug("merge requests unavailable", "username", username, "error", err)
+	}
+	for _, mr := range mrs {
+		activity = append(activity, Activity{
+			Time: mr.CreatedAt, Kind: "merge_request", Project: mr.Project(), Title: mr.Title, URL: mr.WebURL,
+		})
+	}
+
+	c.logger.Debug("fetched gitlab activity", "username", username, "events", len(events), "merge_requests", len(mrs))
+	return activity, nil
+}
+
+// activity classifies an event.
+func (e Event) activity() Activity {
+	a := Activity{Time: e.CreatedAt, Kind: "event", Title: e.TargetTitle}
+	switch {
+	case e.PushData != nil:
+		a.Kind = "push"
+		a.Title = e.PushData.CommitTitle
+	case e.Note != nil || strings.HasSuffix(e.TargetType, "Note"):
+		a.Kind = "comment"
+	case e.TargetType == "MergeRequest":
+		a.Kind = "merge_request"
+	case e.TargetType == "Issue":
+		a.Kind = "issue"
+	}
+	return a
+}
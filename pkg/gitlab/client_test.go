@@ -0,0 +1,77 @@
+package gitlab
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func testClient(t *testing.T, routes map[string]string) *Client {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("PRIVATE-TOKEN") != "secret" {
+			t.Errorf("request without token: %s", r.URL)
+		}
+		body, ok := routes[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		if _, err := w.Write([]byte(body)); err != nil {
+			t.Errorf("write: %v", err)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return NewClient(slog.New(slog.DiscardHandler), server.URL+"/", "secret", func(_ context.Context, req *http.Request) (*http.Response, error) {
+		return server.Client().Do(req)
+	})
+}
+
+func TestFetchActivity(t *testing.T) {
+	c := testClient(t, map[string]string{
+		"/api/v4/users": `[{"id": 7, "username": "Alice"}]`,
+		"/api/v4/users/7/events": `[
+			{"created_at": "2025-03-04T09:15:00Z", "action_name": "pushed to", "push_data": {"commit_title": "Fix build", "commit_count": 1}},
+			{"created_at": "2025-03-04T10:00:00Z", "action_name": "commented on", "target_type": "DiffNote", "note": {"body": "LGTM"}},
+			{"created_at": "2025-03-04T11:00:00Z", "action_name": "opened", "target_type": "Issue", "target_title": "Crash"},
+			{"created_at": "2025-03-04T12:00:00Z", "action_name": "joined"}
+		]`,
+		"/api/v4/merge_requests": `[{"created_at": "2025-03-05T08:00:00Z", "title": "Add feature",
+			"web_url": "https://gitlab.example.com/group/project/-/merge_requests/12", "references": {"full": "group/project!12"}}]`,
+	})
+
+	activity, err := c.FetchActivity(context.Background(), "alice")
+	if err != nil {
+		t.Fatalf("FetchActivity: %v", err)
+	}
+	var kinds []string
+	for _, a := range activity {
+		kinds = append(kinds, a.Kind)
+	}
+	want := []string{"push", "comment", "issue", "event", "merge_request"}
+	if len(kinds) != len(want) {
+		t.Fatalf("kinds = %v, want %v", kinds, want)
+	}
+	for i := range want {
+		if kinds[i] != want[i] {
+			t.Errorf("kinds = %v, want %v", kinds, want)
+			break
+		}
+	}
+	if mr := activity[4]; mr.Project != "group/project" || mr.Title != "Add feature" {
+		t.Errorf("merge request = %+v", mr)
+	}
+	if activity[0].Title != "Fix build" {
+		t.Errorf("push title = %q, want the commit title", activity[0].Title)
+	}
+}
+
+func TestFetchUserNotFound(t *testing.T) {
+	c := testClient(t, map[string]string{"/api/v4/users": `[]`})
+	if _, err := c.FetchActivity(context.Background(), "nobody"); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("FetchActivity = %v, want ErrUserNotFound", err)
+	}
+}
@@ -11,13 +11,13 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+
+	"github.com/codeGROOVE-dev/guTZ/pkg/geocode"
 )
 
 // Location represents a geographic location with coordinates.
-type Location struct {
-	Latitude  float64
-	Longitude float64
-}
+// It is the geocode package's Location, so a Client satisfies geocode.Geocoder.
+type Location = geocode.Location
 
 // HTTPClient interface for making HTTP requests.
 type HTTPClient interface {
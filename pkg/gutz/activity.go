@@ -79,8 +79,7 @@ func (d *Detector) tryActivityPatternsWithContext(ctx context.Context, userCtx *
 			if tz, err := d.timezoneForCoordinates(ctx, coords.Latitude, coords.Longitude); err == nil {
 				// Convert to UTC offset using current time for DST-aware calculation
 				if loc, err := time.LoadLocation(tz); err == nil {
-					now := time.Now()
-					_, offset := now.In(loc).Zone()
+					_, offset := d.now().In(loc).Zone()
 					offsetHours := offset / 3600
 					switch {
 					case offsetHours == 0:
@@ -101,11 +100,15 @@ func (d *Detector) tryActivityPatternsWithContext(ctx context.Context, userCtx *
 	}
 
 	// Log both profile and profile location timezones for debugging
+	var profileLocation string
+	if userCtx.User != nil {
+		profileLocation = userCtx.User.Location
+	}
 	d.logger.Info("timezone context for activity analysis",
 		"username", userCtx.Username,
 		"profile_timezone", userCtx.GitHubTimezone,
 		"profile_location_timezone", userCtx.ProfileLocationTimezone,
-		"location", userCtx.User.Location)
+		"location", profileLocation)
 
 	// For candidate evaluation, prefer claimed timezone if available, otherwise use implied
 	timezoneForCandidates := userCtx.GitHubTimezone
@@ -119,20 +122,31 @@ func (d *Detector) tryActivityPatternsWithContext(ctx context.Context, userCtx *
 	// Collect all timestamps from various sources, including SSH keys and repositories from userCtx
 	allTimestamps, orgCounts := d.collectActivityTimestampsWithContext(ctx, userCtx)
 
+	sourceCounts := make(map[string]int)
+	for _, entry := range allTimestamps {
+		sourceCounts[entry.Source]++
+	}
+	d.report(ctx, ProgressEvent{
+		Phase:        PhaseTimelineBuilt,
+		Username:     userCtx.Username,
+		Message:      fmt.Sprintf("Built activity timeline from %d timestamps", len(allTimestamps)),
+		SourceCounts: sourceCounts,
+	})
+
 	// Since we have the full UserContext, we don't need to fetch supplemental data again
 	// Just continue with the analysis directly
 	return d.analyzeActivityTimestampsWithoutSupplemental(ctx, userCtx.Username, allTimestamps, orgCounts, timezoneForCandidates)
 }
 
 //nolint:revive // Complex timezone detection logic requires detailed analysis
-func (d *Detector) analyzeActivityTimestampsWithoutSupplemental(ctx context.Context, username string, allTimestamps []timestampEntry, orgCounts map[string]int, claimedTimezone string) *Result {
+func (d *Detector) analyzeActivityTimestampsWithoutSupplemental(ctx context.Context, username string, allTimestamps []TimelineEntry, orgCounts map[string]int, claimedTimezone string) *Result {
 	// Track the oldest event to check data coverage
 	var oldestEventTime time.Time
 	if len(allTimestamps) > 0 {
-		oldestEventTime = allTimestamps[0].time
+		oldestEventTime = allTimestamps[0].Time
 		for _, ts := range allTimestamps {
-			if ts.time.Before(oldestEventTime) {
-				oldestEventTime = ts.time
+			if ts.Time.Before(oldestEventTime) {
+				oldestEventTime = ts.Time
 			}
 		}
 	}
@@ -144,60 +158,66 @@ func (d *Detector) analyzeActivityTimestampsWithoutSupplemental(ctx context.Cont
 }
 
 //nolint:gocognit,revive,maintidx // Complex timezone detection logic requires detailed analysis
-func (d *Detector) analyzeTimestampsCore(ctx context.Context, username string, allTimestamps []timestampEntry, orgCounts map[string]int, claimedTimezone string, _ time.Time) *Result {
+func (d *Detector) analyzeTimestampsCore(ctx context.Context, username string, allTimestamps []TimelineEntry, orgCounts map[string]int, claimedTimezone string, _ time.Time) *Result {
 	// Filter and sort timestamps, then apply progressive time window
-	allTimestamps = filterAndSortTimestamps(allTimestamps, 5)
-	allTimestamps = applyProgressiveTimeWindow(allTimestamps, constants.TargetDataPoints)
+	now := d.now()
+	allTimestamps = filterAndSortTimestamps(allTimestamps, 5, now)
+	allTimestamps = applyProgressiveTimeWindow(allTimestamps, constants.TargetDataPoints, now)
 
 	// Log each timeline item for debugging
 	d.logger.Debug("Final timeline assembled", "username", username, "total_items", len(allTimestamps))
 	for i, entry := range allTimestamps {
 		d.logger.Debug("timeline item",
 			"index", i,
-			"date", entry.time.Format("2006-01-02 15:04:05"),
-			"source", entry.source,
-			"repository", entry.repository,
-			"title", entry.title,
-			"org", entry.org)
+			"date", entry.Time.Format("2006-01-02 15:04:05"),
+			"source", entry.Source,
+			"repository", entry.Repository,
+			"title", entry.Title,
+			"org", entry.Org)
 	}
 
 	// Deduplicate all unique timestamps (no cap with adaptive collection)
+	// Buckets sum source weights (see WithSourceWeight) and are rounded to counts afterwards
 	uniqueTimestamps := make(map[time.Time]bool)
-	hourCounts := make(map[int]int)
-	halfHourCounts := make(map[float64]int)         // 30-minute buckets: 0.0, 0.5, 1.0, 1.5, etc.
+	hourWeights := make(map[int]float64)
+	halfHourWeights := make(map[float64]float64)    // 30-minute buckets: 0.0, 0.5, 1.0, 1.5, etc.
 	hourOrgActivity := make(map[int]map[string]int) // Track org activity by hour
 	duplicates := 0
 
 	for _, entry := range allTimestamps {
-		if !uniqueTimestamps[entry.time] {
-			uniqueTimestamps[entry.time] = true
-			hour := entry.time.UTC().Hour()
-			minute := entry.time.UTC().Minute()
+		if !uniqueTimestamps[entry.Time] {
+			uniqueTimestamps[entry.Time] = true
+			hour := entry.Time.UTC().Hour()
+			minute := entry.Time.UTC().Minute()
+			weight := d.sourceWeight(entry.Source)
 
 			// Traditional hourly counting (keep for backwards compatibility)
-			hourCounts[hour]++
+			hourWeights[hour] += weight
 
 			// 30-minute bucket counting: 0-29 minutes = .0, 30-59 minutes = .5
 			halfHourBucket := float64(hour)
 			if minute >= 30 {
 				halfHourBucket += 0.5
 			}
-			halfHourCounts[halfHourBucket]++
+			halfHourWeights[halfHourBucket] += weight
 
 			// Track organization counts
-			if entry.org != "" {
-				orgCounts[entry.org]++
+			if entry.Org != "" {
+				orgCounts[entry.Org]++
 				// Track hourly organization activity
 				if hourOrgActivity[hour] == nil {
 					hourOrgActivity[hour] = make(map[string]int)
 				}
-				hourOrgActivity[hour][entry.org]++
+				hourOrgActivity[hour][entry.Org]++
 			}
 		} else {
 			duplicates++
 		}
 	}
 
+	hourCounts := weightedCounts(hourWeights)
+	halfHourCounts := weightedCounts(halfHourWeights)
+
 	// Count total unique activities used
 	totalActivity := len(uniqueTimestamps)
 
@@ -220,11 +240,11 @@ func (d *Detector) analyzeTimestampsCore(ctx context.Context, username string, a
 	if oldestActivity.IsZero() && len(allTimestamps) > 0 {
 		// Find oldest and newest from the timestamps
 		for _, ts := range allTimestamps {
-			if oldestActivity.IsZero() || ts.time.Before(oldestActivity) {
-				oldestActivity = ts.time
+			if oldestActivity.IsZero() || ts.Time.Before(oldestActivity) {
+				oldestActivity = ts.Time
 			}
-			if newestActivity.IsZero() || ts.time.After(newestActivity) {
-				newestActivity = ts.time
+			if newestActivity.IsZero() || ts.Time.After(newestActivity) {
+				newestActivity = ts.Time
 			}
 		}
 	}
@@ -568,27 +588,27 @@ func (d *Detector) analyzeTimestampsCore(ctx context.Context, username string, a
 		offsetFromUTC += 24
 	}
 
-	offsetInt := int(math.Round(offsetFromUTC))
+	utcOffset := math.Round(offsetFromUTC)
 
 	d.logger.Debug("calculated timezone offset", "username", username,
 		"sleep_hours", quietHours,
 		"mid_sleep_utc", midQuiet,
 		"offset_calculated", offsetFromUTC,
-		"offset_rounded", offsetInt)
+		"offset_rounded", utcOffset)
 
-	detectedTimezone := timezoneFromOffset(offsetInt)
+	detectedTimezone := timezoneFromOffset(utcOffset)
 	confidence := 0.5 // Default confidence, will be updated if we have candidates
-	d.logger.Debug("Activity-based UTC offset", "username", username, "offset", offsetInt, "timezone", detectedTimezone)
+	d.logger.Debug("Activity-based UTC offset", "username", username, "offset", utcOffset, "timezone", detectedTimezone)
 
 	// Log the detected offset for verification
 	if detectedTimezone != "" {
 		now := time.Now().UTC()
 		// Calculate what the local time would be with this offset
-		localTime := now.Add(time.Duration(offsetInt) * time.Hour)
+		localTime := now.Add(time.Duration(utcOffset * float64(time.Hour)))
 		d.logger.Debug("timezone verification", "username", username, "timezone", detectedTimezone,
 			"utc_time", now.Format("15:04 MST"),
 			"estimated_local_time", localTime.Format("15:04"),
-			"offset_hours", offsetInt)
+			"offset_hours", utcOffset)
 	}
 
 	// Calculate typical active hours in UTC (excluding outliers)
@@ -596,7 +616,7 @@ func (d *Detector) analyzeTimestampsCore(ctx context.Context, username string, a
 	activeStartUTC, activeEndUTC := calculateTypicalActiveHoursUTC(halfHourCounts, quietHours)
 
 	d.logger.Info("active hours calculated", "username", username,
-		"activeStartUTC", activeStartUTC, "activeEndUTC", activeEndUTC, "offsetInt", offsetInt,
+		"activeStartUTC", activeStartUTC, "activeEndUTC", activeEndUTC, "utcOffset", utcOffset,
 		"quietHours", quietHours)
 
 	// STEP 1: Find the best global lunch pattern in UTC (timezone-independent)
@@ -670,11 +690,11 @@ func (d *Detector) analyzeTimestampsCore(ctx context.Context, username string, a
 		// Use the top candidate's offset instead of the initial detection
 		// This gives us better lunch/peak detection
 		if candidates[0].Confidence > confidence {
-			offsetInt = int(candidates[0].Offset)
-			detectedTimezone = timezoneFromOffset(offsetInt)
+			utcOffset = candidates[0].Offset
+			detectedTimezone = timezoneFromOffset(utcOffset)
 			confidence = candidates[0].Confidence
 			d.logger.Info("using top candidate offset", "username", username,
-				"new_offset", offsetInt,
+				"new_offset", utcOffset,
 				"new_timezone", detectedTimezone,
 				"new_confidence", confidence,
 				"initial_offset", int(offsetFromUTC))
@@ -682,10 +702,10 @@ func (d *Detector) analyzeTimestampsCore(ctx context.Context, username string, a
 			// Active hours in UTC don't change with timezone - they're based on the actual activity pattern
 			// We only need to convert them to local time differently
 			d.logger.Info("using winning candidate timezone", "username", username,
-				"activeStartUTC", activeStartUTC, "activeEndUTC", activeEndUTC, "new_offset", offsetInt)
+				"activeStartUTC", activeStartUTC, "activeEndUTC", activeEndUTC, "new_offset", utcOffset)
 		} else {
 			d.logger.Info("keeping initial offset", "username", username,
-				"initial_offset", offsetInt,
+				"initial_offset", utcOffset,
 				"initial_confidence", confidence,
 				"top_candidate_offset", candidates[0].Offset,
 				"top_candidate_confidence", candidates[0].Confidence)
@@ -697,7 +717,7 @@ func (d *Detector) analyzeTimestampsCore(ctx context.Context, username string, a
 	var lunchStart, lunchEnd, lunchConfidence float64
 
 	// Check if we have a winning candidate with lunch data
-	if len(candidates) > 0 && int(candidates[0].Offset) == offsetInt && candidates[0].LunchStartUTC >= 0 {
+	if len(candidates) > 0 && candidates[0].Offset == utcOffset && candidates[0].LunchStartUTC >= 0 {
 		// Reuse the lunch calculation from the winning candidate
 		lunchStart = candidates[0].LunchStartUTC
 		lunchEnd = candidates[0].LunchEndUTC
@@ -706,16 +726,16 @@ func (d *Detector) analyzeTimestampsCore(ctx context.Context, username string, a
 			"lunch_start_utc", lunchStart, "lunch_end_utc", lunchEnd, "confidence", lunchConfidence)
 	} else {
 		// Fall back to calculating lunch for the chosen offset
-		lunchStart, lunchEnd, lunchConfidence = lunch.DetectLunchBreakNoonCentered(halfHourCounts, offsetInt)
+		lunchStart, lunchEnd, lunchConfidence = lunch.DetectLunchBreakNoonCentered(halfHourCounts, utcOffset)
 		d.logger.Debug("calculated new lunch", "username", username,
-			"offset", offsetInt, "lunch_start_utc", lunchStart, "lunch_end_utc", lunchEnd, "confidence", lunchConfidence)
+			"offset", utcOffset, "lunch_start_utc", lunchStart, "lunch_end_utc", lunchEnd, "confidence", lunchConfidence)
 	}
 
 	// Only use global lunch pattern if we don't already have a high-confidence lunch
 	// Global patterns can be misleading - a consistent 2pm drop might be meetings, not lunch
 	if bestGlobalLunch.Confidence > 0.5 && bestGlobalLunch.StartUTC >= 0 && lunchConfidence < 0.7 {
 		// Calculate what local time the global lunch would be
-		globalLunchLocal := math.Mod(bestGlobalLunch.StartUTC+float64(offsetInt)+24, 24)
+		globalLunchLocal := tzconvert.UTCToLocal(bestGlobalLunch.StartUTC, utcOffset)
 
 		// Only use if it's in a more typical lunch range (11:30am-1:30pm)
 		// 2pm is too late and likely represents something else
@@ -735,7 +755,7 @@ func (d *Detector) analyzeTimestampsCore(ctx context.Context, username string, a
 		}
 	}
 	// Detect peak productivity window using 30-minute buckets for better precision
-	peakStart, peakEnd, peakCount := timezone.DetectPeakProductivityWithHalfHours(halfHourCounts, offsetInt)
+	peakStart, peakEnd, peakCount := timezone.DetectPeakProductivityWithHalfHours(halfHourCounts, utcOffset)
 
 	// DISABLED: Work schedule validation corrections were causing more harm than good
 	// The corrections were sometimes moving people further from their actual timezone
@@ -797,7 +817,7 @@ func (d *Detector) analyzeTimestampsCore(ctx context.Context, username string, a
 		}
 	} else {
 		// Check local work start time (convert from UTC to local)
-		localWorkStart := int(activeStartUTC+float64(offsetInt)+24) % 24
+		localWorkStart := int(tzconvert.UTCToLocal(activeStartUTC, utcOffset))
 		if localWorkStart > 11 {
 			// Work starting after 11am local time is unusual (unless part-time)
 			suspiciousWorkHours = true
@@ -859,7 +879,7 @@ func (d *Detector) analyzeTimestampsCore(ctx context.Context, username string, a
 		// If lunch is at 2pm or later, we might be off by 2-3 hours
 		if lunchConfidence >= 0.5 && lunchStartLocal >= 14.0 {
 			// Late lunch detected - check if adjusting timezone would normalize it
-			suggestedOffsetCorrection := 0
+			suggestedOffsetCorrection := 0.0
 
 			// If lunch is at 2pm, shifting 2 hours earlier would make it noon (normal)
 			// If lunch is at 3pm, shifting 3 hours earlier would make it noon
@@ -870,20 +890,20 @@ func (d *Detector) analyzeTimestampsCore(ctx context.Context, username string, a
 			}
 
 			// Apply correction if it results in a valid US timezone
-			potentialOffset := offsetInt + suggestedOffsetCorrection
+			potentialOffset := utcOffset + suggestedOffsetCorrection
 			if suggestedOffsetCorrection != 0 && potentialOffset >= -8 && potentialOffset <= -5 {
 				d.logger.Info("late lunch suggests timezone correction", "username", username,
-					"lunch_start", lunchStartLocal, "current_offset", offsetInt,
+					"lunch_start", lunchStartLocal, "current_offset", utcOffset,
 					"suggested_correction", suggestedOffsetCorrection, "new_offset", potentialOffset)
 
 				// Apply the correction
-				offsetInt = potentialOffset
-				detectedTimezone = timezoneFromOffset(offsetInt)
+				utcOffset = potentialOffset
+				detectedTimezone = timezoneFromOffset(utcOffset)
 
 				// Active hours in UTC don't change - no need to recalculate
 
 				// Recalculate lunch with corrected offset
-				newLunchStart, newLunchEnd, newLunchConfidence := lunch.DetectLunchBreakNoonCentered(halfHourCounts, offsetInt)
+				newLunchStart, newLunchEnd, newLunchConfidence := lunch.DetectLunchBreakNoonCentered(halfHourCounts, utcOffset)
 
 				// If the new lunch time is more reasonable, keep the correction
 				if newLunchStart >= 11.5 && newLunchStart <= 13.0 {
@@ -894,17 +914,17 @@ func (d *Detector) analyzeTimestampsCore(ctx context.Context, username string, a
 					// Boost confidence since lunch correction worked
 					confidence = math.Min(confidence+0.15, 0.85)
 					d.logger.Info("lunch-based timezone correction successful", "username", username,
-						"new_lunch_start", lunchStart, "new_offset", offsetInt, "new_confidence", confidence)
+						"new_lunch_start", lunchStart, "new_offset", utcOffset, "new_confidence", confidence)
 				} else {
 					// Revert if it didn't help
-					offsetInt -= suggestedOffsetCorrection
-					detectedTimezone = timezoneFromOffset(offsetInt)
+					utcOffset -= suggestedOffsetCorrection
+					detectedTimezone = timezoneFromOffset(utcOffset)
 					// Active hours in UTC don't change - no need to recalculate
 					d.logger.Debug("lunch-based correction didn't improve, reverting", "username", username)
 				}
 
 				// Recalculate peak with final offset
-				peakStart, peakEnd, peakCount = timezone.DetectPeakProductivityWithHalfHours(halfHourCounts, offsetInt)
+				peakStart, peakEnd, peakCount = timezone.DetectPeakProductivityWithHalfHours(halfHourCounts, utcOffset)
 			}
 		}
 	}
@@ -912,7 +932,7 @@ func (d *Detector) analyzeTimestampsCore(ctx context.Context, username string, a
 	// If we have suspicious work hours and detected European timezone,
 	// but sleep pattern could fit Asia, consider adjusting to Asia
 	// UNLESS we have strong evidence for Europe (e.g., Polish name)
-	if suspiciousWorkHours && alternativeTimezone == "UTC+8" && offsetInt <= 3 {
+	if suspiciousWorkHours && alternativeTimezone == "UTC+8" && utcOffset <= 3 {
 		// Get user's full name to check for regional indicators
 		user, _, _, _, err := d.githubClient.FetchUserEnhancedGraphQL(ctx, username)
 		if err != nil && !errors.Is(err, github.ErrNoGitHubToken) && !errors.Is(err, github.ErrUserNotFound) {
@@ -952,52 +972,42 @@ func (d *Detector) analyzeTimestampsCore(ctx context.Context, username string, a
 				"username", username, "original", detectedTimezone, "adjusted", alternativeTimezone,
 				"work_start_utc", activeStartUTC)
 			detectedTimezone = alternativeTimezone
-			offsetInt = 8
+			utcOffset = 8
 
 			// Active hours in UTC don't change - no need to recalculate
 
 			// Recalculate lunch with new offset
-			lunchStart, lunchEnd, lunchConfidence = lunch.DetectLunchBreakNoonCentered(halfHourCounts, offsetInt)
+			lunchStart, lunchEnd, lunchConfidence = lunch.DetectLunchBreakNoonCentered(halfHourCounts, utcOffset)
 		}
 
 		// Recalculate peak with new offset
-		peakStart, peakEnd, peakCount = timezone.DetectPeakProductivityWithHalfHours(halfHourCounts, offsetInt)
+		peakStart, peakEnd, peakCount = timezone.DetectPeakProductivityWithHalfHours(halfHourCounts, utcOffset)
 
 		confidence = 0.7 // Moderate confidence after adjustment
 
 		d.logger.Info("recalculated work hours after timezone adjustment", "username", username,
-			"new_work_start_utc", activeStartUTC, "new_work_end_utc", activeEndUTC, "new_offset", offsetInt)
+			"new_work_start_utc", activeStartUTC, "new_work_end_utc", activeEndUTC, "new_offset", utcOffset)
 	}
 
 	// Active hours are already in UTC from calculateTypicalActiveHours
 	// No conversion needed for storage
 
 	// Detect sleep periods using 30-minute resolution with timezone awareness
-	sleepBuckets := sleep.DetectSleepPeriodsWithOffset(halfHourCounts, offsetInt)
+	sleepBuckets := sleep.DetectSleepPeriodsWithOffset(halfHourCounts, utcOffset)
 	d.logger.Info("detected sleep buckets UTC",
 		"username", username,
 		"sleepBuckets", sleepBuckets,
 		"numBuckets", len(sleepBuckets))
 
 	// Log what this translates to in local time for debugging
-	if len(sleepBuckets) > 0 && offsetInt != 0 {
-		localStart := sleepBuckets[0] + float64(offsetInt)
-		if localStart < 0 {
-			localStart += 24
-		} else if localStart >= 24 {
-			localStart -= 24
-		}
-		localEnd := sleepBuckets[len(sleepBuckets)-1] + 0.5 + float64(offsetInt)
-		if localEnd < 0 {
-			localEnd += 24
-		} else if localEnd >= 24 {
-			localEnd -= 24
-		}
+	if len(sleepBuckets) > 0 && utcOffset != 0 {
+		localStart := tzconvert.UTCToLocal(sleepBuckets[0], utcOffset)
+		localEnd := tzconvert.UTCToLocal(sleepBuckets[len(sleepBuckets)-1]+0.5, utcOffset)
 		d.logger.Info("sleep period in local time",
 			"username", username,
 			"local_start", localStart,
 			"local_end", localEnd,
-			"offset", offsetInt)
+			"offset", utcOffset)
 	}
 
 	// Refine sleep hours using the more precise half-hour data
@@ -1008,7 +1018,20 @@ func (d *Detector) analyzeTimestampsCore(ctx context.Context, username string, a
 	sleepRanges := CalculateSleepRangesFromBuckets(sleepBuckets, detectedTimezone)
 
 	// Detect and classify all activity periods
-	activityPeriods := ClassifyActivityPeriods(halfHourCounts, offsetInt)
+	activityPeriods := ClassifyActivityPeriods(halfHourCounts, utcOffset)
+
+	// Score winter and summer separately to tell zones sharing an offset apart by their DST rules
+	uniqueTimes := make([]time.Time, 0, len(uniqueTimestamps))
+	for t := range uniqueTimestamps {
+		uniqueTimes = append(uniqueTimes, t)
+	}
+	seasonal := timezone.EvaluateSeasons(username, uniqueTimes)
+	d.logger.Debug("seasonal analysis", "username", username,
+		"regime", seasonal.Regime,
+		"shift", seasonal.Shift,
+		"winter_events", seasonal.WinterEvents,
+		"summer_events", seasonal.SummerEvents,
+		"suggested_zones", seasonal.SuggestedZones)
 
 	result := &Result{
 		Username:         username,
@@ -1022,8 +1045,8 @@ func (d *Detector) analyzeTimestampsCore(ctx context.Context, username string, a
 			Start float64 `json:"start"`
 			End   float64 `json:"end"`
 		}{
-			Start: tzconvert.UTCToLocal(activeStartUTC, offsetInt),
-			End:   tzconvert.UTCToLocal(activeEndUTC, offsetInt),
+			Start: tzconvert.UTCToLocal(activeStartUTC, utcOffset),
+			End:   tzconvert.UTCToLocal(activeEndUTC, utcOffset),
 		},
 		ActiveHoursUTC: struct {
 			Start float64 `json:"start"`
@@ -1039,6 +1062,7 @@ func (d *Detector) analyzeTimestampsCore(ctx context.Context, username string, a
 		HourlyOrganizationActivity: hourOrgActivity, // Store org-specific activity
 		TimezoneCandidates:         candidates,      // Top 3 timezone candidates with analysis
 		ActivityPeriods:            activityPeriods, // Multiple activity periods throughout the day
+		Seasonal:                   seasonal,
 	}
 
 	// Add activity date range information
@@ -1051,46 +1075,30 @@ func (d *Detector) analyzeTimestampsCore(ctx context.Context, username string, a
 	// so no conversion is needed
 
 	// Store lunch hours in UTC
-	result.LunchHoursUTC = struct {
-		Start      float64 `json:"start"`
-		End        float64 `json:"end"`
-		Confidence float64 `json:"confidence"`
-	}{
+	result.LunchHoursUTC = LunchBreak{
 		Start:      lunchStart, // Already in UTC
 		End:        lunchEnd,   // Already in UTC
 		Confidence: lunchConfidence,
 	}
 
 	// Store lunch hours in Local (converted from UTC)
-	result.LunchHoursLocal = struct {
-		Start      float64 `json:"start"`
-		End        float64 `json:"end"`
-		Confidence float64 `json:"confidence"`
-	}{
-		Start:      tzconvert.UTCToLocal(lunchStart, offsetInt),
-		End:        tzconvert.UTCToLocal(lunchEnd, offsetInt),
+	result.LunchHoursLocal = LunchBreak{
+		Start:      tzconvert.UTCToLocal(lunchStart, utcOffset),
+		End:        tzconvert.UTCToLocal(lunchEnd, utcOffset),
 		Confidence: lunchConfidence,
 	}
 
 	// Store peak productivity window in UTC
-	result.PeakProductivityUTC = struct {
-		Start float64 `json:"start"`
-		End   float64 `json:"end"`
-		Count int     `json:"count"`
-	}{
+	result.PeakProductivityUTC = PeakTime{
 		Start: peakStart, // Already in UTC
 		End:   peakEnd,   // Already in UTC
 		Count: peakCount,
 	}
 
 	// Store peak productivity window in Local (converted from UTC)
-	result.PeakProductivityLocal = struct {
-		Start float64 `json:"start"`
-		End   float64 `json:"end"`
-		Count int     `json:"count"`
-	}{
-		Start: tzconvert.UTCToLocal(peakStart, offsetInt),
-		End:   tzconvert.UTCToLocal(peakEnd, offsetInt),
+	result.PeakProductivityLocal = PeakTime{
+		Start: tzconvert.UTCToLocal(peakStart, utcOffset),
+		End:   tzconvert.UTCToLocal(peakEnd, utcOffset),
 		Count: peakCount,
 	}
 
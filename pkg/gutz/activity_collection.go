@@ -12,20 +12,20 @@ import (
 	"github.com/codeGROOVE-dev/guTZ/pkg/github"
 )
 
-// timestampEntry represents a single activity timestamp with metadata.
-type timestampEntry struct {
-	time       time.Time
-	source     string // "event", "pr", "issue", "comment", "star", "commit"
-	org        string // organization/owner name
-	title      string // PR/issue title, or comment preview
-	repository string // full repository name (owner/repo)
-	url        string // URL to the item (for reference)
+// TimelineEntry is a single public activity timestamp that went into a detection.
+type TimelineEntry struct {
+	Time       time.Time `json:"time"`
+	Source     string    `json:"source"`               // "event", "pr", "issue", "comment", "star", "commit", ...
+	Org        string    `json:"org,omitempty"`        // organization/owner name
+	Title      string    `json:"title,omitempty"`      // PR/issue title, or comment preview
+	Repository string    `json:"repository,omitempty"` // full repository name (owner/repo)
+	URL        string    `json:"url,omitempty"`        // URL to the item (for reference)
 }
 
 // collectActivityTimestampsWithContext gathers all activity timestamps from UserContext.
 func (d *Detector) collectActivityTimestampsWithContext(
 	ctx context.Context, userCtx *UserContext,
-) (timestamps []timestampEntry, orgCounts map[string]int) {
+) (timestamps []TimelineEntry, orgCounts map[string]int) {
 	d.logger.Info("📊 Building unified timeline from UserContext",
 		"username", userCtx.Username,
 		"ssh_keys", len(userCtx.SSHKeys),
@@ -37,8 +37,8 @@ func (d *Detector) collectActivityTimestampsWithContext(
 	gistTimestamps, gistCount := d.processGistsForTimeline(userCtx)
 	allTimestamps = append(allTimestamps, gistTimestamps...)
 	for _, ts := range gistTimestamps {
-		if ts.org != "" {
-			orgCounts[ts.org]++
+		if ts.Org != "" {
+			orgCounts[ts.Org]++
 		}
 	}
 	if gistCount > 0 {
@@ -48,8 +48,8 @@ func (d *Detector) collectActivityTimestampsWithContext(
 	repoTimestamps, repoCount, forkCount := d.processRepositoriesForTimeline(userCtx)
 	allTimestamps = append(allTimestamps, repoTimestamps...)
 	for _, ts := range repoTimestamps {
-		if ts.org != "" {
-			orgCounts[ts.org]++
+		if ts.Org != "" {
+			orgCounts[ts.Org]++
 		}
 	}
 	if repoCount > 0 || forkCount > 0 {
@@ -63,8 +63,8 @@ func (d *Detector) collectActivityTimestampsWithContext(
 	prTimestamps, prCount := d.processPRsForTimeline(userCtx)
 	allTimestamps = append(allTimestamps, prTimestamps...)
 	for _, ts := range prTimestamps {
-		if ts.org != "" {
-			orgCounts[ts.org]++
+		if ts.Org != "" {
+			orgCounts[ts.Org]++
 		}
 	}
 	if prCount > 0 {
@@ -74,8 +74,8 @@ func (d *Detector) collectActivityTimestampsWithContext(
 	issueTimestamps, issueCount := d.processIssuesForTimeline(userCtx)
 	allTimestamps = append(allTimestamps, issueTimestamps...)
 	for _, ts := range issueTimestamps {
-		if ts.org != "" {
-			orgCounts[ts.org]++
+		if ts.Org != "" {
+			orgCounts[ts.Org]++
 		}
 	}
 	if issueCount > 0 {
@@ -86,14 +86,23 @@ func (d *Detector) collectActivityTimestampsWithContext(
 	commentTimestamps, commentCount := d.processCommentsForTimeline(userCtx)
 	allTimestamps = append(allTimestamps, commentTimestamps...)
 	for _, ts := range commentTimestamps {
-		if ts.org != "" {
-			orgCounts[ts.org]++
+		if ts.Org != "" {
+			orgCounts[ts.Org]++
 		}
 	}
 	if commentCount > 0 {
 		d.logger.Info("💬 Added comments to timeline", "username", userCtx.Username, "count", commentCount)
 	}
 
+	// Activity from registered sources outside GitHub
+	for _, ts := range d.collectTimelineSources(ctx, userCtx) {
+		allTimestamps = append(allTimestamps, ts)
+		if ts.Org != "" {
+			orgCounts[ts.Org]++
+		}
+	}
+	allTimestamps = d.dropUnweighted(allTimestamps)
+
 	d.logger.Info("📊 Unified timeline built",
 		"username", userCtx.Username,
 		"total_events", len(allTimestamps))
@@ -102,7 +111,7 @@ func (d *Detector) collectActivityTimestampsWithContext(
 }
 
 // processGistsForTimeline processes gists for the activity timeline.
-func (d *Detector) processGistsForTimeline(userCtx *UserContext) (timestamps []timestampEntry, count int) {
+func (d *Detector) processGistsForTimeline(userCtx *UserContext) (timestamps []TimelineEntry, count int) {
 	gistCount := 0
 
 	d.logger.Info("📝 Processing gists for timeline", "username", userCtx.Username, "total_gists", len(userCtx.Gists))
@@ -116,13 +125,13 @@ func (d *Detector) processGistsForTimeline(userCtx *UserContext) (timestamps []t
 			title = "created gist: " + gist.Description
 		}
 
-		timestamps = append(timestamps, timestampEntry{
-			time:       gist.CreatedAt,
-			source:     "gist",
-			org:        userCtx.Username, // Gists belong to the user
-			title:      title,
-			repository: "",
-			url:        gist.HTMLURL,
+		timestamps = append(timestamps, TimelineEntry{
+			Time:       gist.CreatedAt,
+			Source:     "gist",
+			Org:        userCtx.Username, // Gists belong to the user
+			Title:      title,
+			Repository: "",
+			URL:        gist.HTMLURL,
 		})
 		gistCount++
 	}
@@ -130,7 +139,7 @@ func (d *Detector) processGistsForTimeline(userCtx *UserContext) (timestamps []t
 }
 
 // processRepositoriesForTimeline processes repositories for the activity timeline.
-func (d *Detector) processRepositoriesForTimeline(userCtx *UserContext) (timestamps []timestampEntry, repoCount int, forkCount int) {
+func (d *Detector) processRepositoriesForTimeline(userCtx *UserContext) (timestamps []TimelineEntry, repoCount int, forkCount int) {
 	d.logger.Debug("Processing repositories for timeline", "username", userCtx.Username, "total_repos", len(userCtx.Repositories))
 	for i := range userCtx.Repositories {
 		repo := &userCtx.Repositories[i]
@@ -148,35 +157,35 @@ func (d *Detector) processRepositoriesForTimeline(userCtx *UserContext) (timesta
 			d.logger.Debug("processing forked repo for timestamps", "repo", repo.Name)
 
 			// Add fork timestamp - the CreatedAt for a fork is when the user forked it
-			timestamps = append(timestamps, timestampEntry{
-				time:       repo.CreatedAt,
-				source:     "fork_created",
-				org:        userCtx.Username, // User's own fork
-				title:      "forked repository: " + repo.Name,
-				repository: repo.FullName,
-				url:        repo.HTMLURL,
+			timestamps = append(timestamps, TimelineEntry{
+				Time:       repo.CreatedAt,
+				Source:     "fork_created",
+				Org:        userCtx.Username, // User's own fork
+				Title:      "forked repository: " + repo.Name,
+				Repository: repo.FullName,
+				URL:        repo.HTMLURL,
 			})
 			forkCount++
 
 			// Also check UpdatedAt and PushedAt for fork activity
 			if !repo.UpdatedAt.IsZero() && !repo.UpdatedAt.Equal(repo.CreatedAt) {
-				timestamps = append(timestamps, timestampEntry{
-					time:       repo.UpdatedAt,
-					source:     "fork_updated",
-					org:        userCtx.Username,
-					title:      "updated fork: " + repo.Name,
-					repository: repo.FullName,
-					url:        repo.HTMLURL,
+				timestamps = append(timestamps, TimelineEntry{
+					Time:       repo.UpdatedAt,
+					Source:     "fork_updated",
+					Org:        userCtx.Username,
+					Title:      "updated fork: " + repo.Name,
+					Repository: repo.FullName,
+					URL:        repo.HTMLURL,
 				})
 			}
 			if !repo.PushedAt.IsZero() && !repo.PushedAt.Equal(repo.CreatedAt) {
-				timestamps = append(timestamps, timestampEntry{
-					time:       repo.PushedAt,
-					source:     "fork_pushed",
-					org:        userCtx.Username,
-					title:      "pushed to fork: " + repo.Name,
-					repository: repo.FullName,
-					url:        repo.HTMLURL,
+				timestamps = append(timestamps, TimelineEntry{
+					Time:       repo.PushedAt,
+					Source:     "fork_pushed",
+					Org:        userCtx.Username,
+					Title:      "pushed to fork: " + repo.Name,
+					Repository: repo.FullName,
+					URL:        repo.HTMLURL,
 				})
 			}
 			continue
@@ -190,13 +199,13 @@ func (d *Detector) processRepositoriesForTimeline(userCtx *UserContext) (timesta
 		}
 
 		d.logger.Debug("Adding repo to timeline", "repo", repo.Name, "created_at", repo.CreatedAt)
-		timestamps = append(timestamps, timestampEntry{
-			time:       repo.CreatedAt,
-			source:     "repo_created",
-			org:        userCtx.Username, // User's own repositories
-			title:      title,
-			repository: repo.FullName,
-			url:        repo.HTMLURL,
+		timestamps = append(timestamps, TimelineEntry{
+			Time:       repo.CreatedAt,
+			Source:     "repo_created",
+			Org:        userCtx.Username, // User's own repositories
+			Title:      title,
+			Repository: repo.FullName,
+			URL:        repo.HTMLURL,
 		})
 		repoCount++
 	}
@@ -204,7 +213,7 @@ func (d *Detector) processRepositoriesForTimeline(userCtx *UserContext) (timesta
 }
 
 // processPRsForTimeline processes pull requests for the activity timeline.
-func (d *Detector) processPRsForTimeline(userCtx *UserContext) (timestamps []timestampEntry, count int) {
+func (d *Detector) processPRsForTimeline(userCtx *UserContext) (timestamps []TimelineEntry, count int) {
 	prCount := 0
 
 	d.logger.Debug("Processing PRs for timeline", "username", userCtx.Username, "total_prs", len(userCtx.PullRequests))
@@ -216,13 +225,13 @@ func (d *Detector) processPRsForTimeline(userCtx *UserContext) (timestamps []tim
 		}
 
 		org := extractOrganization(pr.RepoName)
-		timestamps = append(timestamps, timestampEntry{
-			time:       pr.CreatedAt,
-			source:     "pr",
-			org:        org,
-			title:      pr.Title,
-			repository: pr.RepoName,
-			url:        pr.HTMLURL,
+		timestamps = append(timestamps, TimelineEntry{
+			Time:       pr.CreatedAt,
+			Source:     "pr",
+			Org:        org,
+			Title:      pr.Title,
+			Repository: pr.RepoName,
+			URL:        pr.HTMLURL,
 		})
 		prCount++
 	}
@@ -230,7 +239,7 @@ func (d *Detector) processPRsForTimeline(userCtx *UserContext) (timestamps []tim
 }
 
 // processIssuesForTimeline processes issues for the activity timeline.
-func (d *Detector) processIssuesForTimeline(userCtx *UserContext) (timestamps []timestampEntry, count int) {
+func (d *Detector) processIssuesForTimeline(userCtx *UserContext) (timestamps []TimelineEntry, count int) {
 	issueCount := 0
 
 	d.logger.Debug("Processing issues for timeline", "username", userCtx.Username, "total_issues", len(userCtx.Issues))
@@ -242,13 +251,13 @@ func (d *Detector) processIssuesForTimeline(userCtx *UserContext) (timestamps []
 		}
 
 		org := extractOrganization(issue.RepoName)
-		timestamps = append(timestamps, timestampEntry{
-			time:       issue.CreatedAt,
-			source:     "issue",
-			org:        org,
-			title:      issue.Title,
-			repository: issue.RepoName,
-			url:        issue.HTMLURL,
+		timestamps = append(timestamps, TimelineEntry{
+			Time:       issue.CreatedAt,
+			Source:     "issue",
+			Org:        org,
+			Title:      issue.Title,
+			Repository: issue.RepoName,
+			URL:        issue.HTMLURL,
 		})
 		issueCount++
 	}
@@ -256,7 +265,7 @@ func (d *Detector) processIssuesForTimeline(userCtx *UserContext) (timestamps []
 }
 
 // processCommentsForTimeline processes comments for the activity timeline.
-func (d *Detector) processCommentsForTimeline(userCtx *UserContext) (timestamps []timestampEntry, count int) {
+func (d *Detector) processCommentsForTimeline(userCtx *UserContext) (timestamps []TimelineEntry, count int) {
 	commentCount := 0
 
 	d.logger.Debug("Processing comments for timeline", "username", userCtx.Username, "total_comments", len(userCtx.Comments))
@@ -273,13 +282,13 @@ func (d *Detector) processCommentsForTimeline(userCtx *UserContext) (timestamps
 		if len(commentText) > 100 {
 			commentText = commentText[:100] + "..."
 		}
-		timestamps = append(timestamps, timestampEntry{
-			time:       comment.CreatedAt,
-			source:     "comment",
-			org:        org,
-			title:      commentText,
-			repository: comment.Repository,
-			url:        comment.HTMLURL,
+		timestamps = append(timestamps, TimelineEntry{
+			Time:       comment.CreatedAt,
+			Source:     "comment",
+			Org:        org,
+			Title:      commentText,
+			Repository: comment.Repository,
+			URL:        comment.HTMLURL,
 		})
 		commentCount++
 	}
@@ -291,12 +300,12 @@ func (d *Detector) processCommentsForTimeline(userCtx *UserContext) (timestamps
 //nolint:gocognit,revive,maintidx // Complex event processing logic
 func (d *Detector) collectActivityTimestampsWithSSHKeys(_ context.Context, username string,
 	events []github.PublicEvent, sshKeys []github.SSHKey,
-) (timestamps []timestampEntry, orgCounts map[string]int) {
-	allTimestamps := []timestampEntry{}
+) (timestamps []TimelineEntry, orgCounts map[string]int) {
+	allTimestamps := []TimelineEntry{}
 	orgCounts = make(map[string]int)
 
 	// Add events
-	eventOldest := time.Now()
+	eventOldest := d.now()
 	eventNewest := time.Time{}
 	zeroTimeCount := 0
 	for _, event := range events {
@@ -408,13 +417,13 @@ func (d *Detector) collectActivityTimestampsWithSSHKeys(_ context.Context, usern
 			// Keep the default event type for other events
 		}
 
-		allTimestamps = append(allTimestamps, timestampEntry{
-			time:       event.CreatedAt,
-			source:     eventSource,
-			org:        org,
-			title:      eventTitle,
-			repository: event.Repo.Name,
-			url:        event.Repo.URL,
+		allTimestamps = append(allTimestamps, TimelineEntry{
+			Time:       event.CreatedAt,
+			Source:     eventSource,
+			Org:        org,
+			Title:      eventTitle,
+			Repository: event.Repo.Name,
+			URL:        event.Repo.URL,
 		})
 		if event.CreatedAt.Before(eventOldest) {
 			eventOldest = event.CreatedAt
@@ -457,13 +466,13 @@ func (d *Detector) collectActivityTimestampsWithSSHKeys(_ context.Context, usern
 				title = "added SSH key: " + sshKey.Title
 			}
 
-			allTimestamps = append(allTimestamps, timestampEntry{
-				time:       sshKey.CreatedAt,
-				source:     "ssh_key",
-				org:        username, // SSH keys belong to the user
-				title:      title,
-				repository: "",
-				url:        sshKey.URL,
+			allTimestamps = append(allTimestamps, TimelineEntry{
+				Time:       sshKey.CreatedAt,
+				Source:     "ssh_key",
+				Org:        username, // SSH keys belong to the user
+				Title:      title,
+				Repository: "",
+				URL:        sshKey.URL,
 			})
 			sshKeyCount++
 		}
@@ -480,12 +489,12 @@ func (d *Detector) collectActivityTimestampsWithSSHKeys(_ context.Context, usern
 	noOrgCount := 0
 	orgCount := 0
 	for _, ts := range allTimestamps {
-		if ts.org == "" {
+		if ts.Org == "" {
 			noOrgCount++
-			d.logger.Debug("timestamp without org", "source", ts.source, "time", ts.time.Format("2006-01-02 15:04"))
+			d.logger.Debug("timestamp without org", "source", ts.Source, "time", ts.Time.Format("2006-01-02 15:04"))
 		} else {
 			orgCount++
-			orgCounts[ts.org]++
+			orgCounts[ts.Org]++
 		}
 	}
 	d.logger.Info("org association summary", "username", username, "with_org", orgCount, "without_org", noOrgCount, "total", len(allTimestamps))
@@ -504,18 +513,18 @@ func extractOrganization(repository string) string {
 	return ""
 }
 
-// filterAndSortTimestamps filters timestamps by age and sorts them.
-func filterAndSortTimestamps(allTimestamps []timestampEntry, maxYears int) []timestampEntry {
+// filterAndSortTimestamps filters timestamps older than maxYears before now and sorts them.
+func filterAndSortTimestamps(allTimestamps []TimelineEntry, maxYears int, now time.Time) []TimelineEntry {
 	// Sort timestamps by recency (newest first)
 	sort.Slice(allTimestamps, func(i, j int) bool {
-		return allTimestamps[i].time.After(allTimestamps[j].time)
+		return allTimestamps[i].Time.After(allTimestamps[j].Time)
 	})
 
 	// Filter out events older than maxYears to avoid stale patterns
-	cutoffTime := time.Now().AddDate(-maxYears, 0, 0)
-	filtered := []timestampEntry{}
+	cutoffTime := now.AddDate(-maxYears, 0, 0)
+	filtered := []TimelineEntry{}
 	for _, ts := range allTimestamps {
-		if ts.time.After(cutoffTime) {
+		if ts.Time.After(cutoffTime) {
 			filtered = append(filtered, ts)
 		}
 	}
@@ -524,7 +533,8 @@ func filterAndSortTimestamps(allTimestamps []timestampEntry, maxYears int) []tim
 }
 
 // applyProgressiveTimeWindow applies a progressive time window strategy to get sufficient data.
-func applyProgressiveTimeWindow(allTimestamps []timestampEntry, targetMin int) []timestampEntry {
+// Windows are measured back from now.
+func applyProgressiveTimeWindow(allTimestamps []TimelineEntry, targetMin int, now time.Time) []TimelineEntry {
 	const maxTimeWindowDays = 365 * 5 // Maximum 5 years
 	const initialWindowDays = 30      // Start with 30 days for recency preference
 	const minTimeSpanDays = 30        // Minimum time span we want to achieve
@@ -532,24 +542,24 @@ func applyProgressiveTimeWindow(allTimestamps []timestampEntry, targetMin int) [
 
 	// Progressive time window strategy
 	timeWindowDays := float64(initialWindowDays)
-	var filtered []timestampEntry
+	var filtered []TimelineEntry
 
 	for timeWindowDays <= maxTimeWindowDays {
-		cutoffTime := time.Now().AddDate(0, 0, -int(timeWindowDays))
+		cutoffTime := now.AddDate(0, 0, -int(timeWindowDays))
 
 		// Use map to deduplicate timestamps during filtering
-		uniqueTimestamps := make(map[time.Time]timestampEntry)
+		uniqueTimestamps := make(map[time.Time]TimelineEntry)
 		for _, ts := range allTimestamps {
-			if ts.time.After(cutoffTime) {
+			if ts.Time.After(cutoffTime) {
 				// Keep the first occurrence of each timestamp
-				if _, exists := uniqueTimestamps[ts.time]; !exists {
-					uniqueTimestamps[ts.time] = ts
+				if _, exists := uniqueTimestamps[ts.Time]; !exists {
+					uniqueTimestamps[ts.Time] = ts
 				}
 			}
 		}
 
 		// Convert back to slice
-		filtered = []timestampEntry{}
+		filtered = []TimelineEntry{}
 		for _, ts := range uniqueTimestamps {
 			filtered = append(filtered, ts)
 		}
@@ -559,11 +569,11 @@ func applyProgressiveTimeWindow(allTimestamps []timestampEntry, targetMin int) [
 		if len(filtered) > 0 {
 			var oldest, newest time.Time
 			for i, ts := range filtered {
-				if i == 0 || ts.time.Before(oldest) {
-					oldest = ts.time
+				if i == 0 || ts.Time.Before(oldest) {
+					oldest = ts.Time
 				}
-				if i == 0 || ts.time.After(newest) {
-					newest = ts.time
+				if i == 0 || ts.Time.After(newest) {
+					newest = ts.Time
 				}
 			}
 			actualSpanDays = int(newest.Sub(oldest).Hours() / 24)
@@ -572,7 +582,7 @@ func applyProgressiveTimeWindow(allTimestamps []timestampEntry, targetMin int) [
 		// Count data sources in filtered set for debugging
 		sourceCounts := make(map[string]int)
 		for _, ts := range filtered {
-			sourceCounts[ts.source]++
+			sourceCounts[ts.Source]++
 		}
 
 		// Stop if we have enough events AND sufficient time span, or hit max window
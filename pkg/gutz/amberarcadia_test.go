@@ -65,14 +65,14 @@ func TestAmberArcadiaDelawareDetection(t *testing.T) {
 	}
 
 	// Test for UTC-4 (Eastern Daylight Time - Delaware in summer)
-	offset := -4
+	offset := -4.0
 
 	// Detect lunch for this timezone
 	lunchStart, lunchEnd, confidence := lunch.DetectLunchBreakNoonCentered(halfHourCounts, offset)
 
 	// Convert UTC lunch times to local Eastern Time
-	lunchStartLocal := lunchStart + float64(offset)
-	lunchEndLocal := lunchEnd + float64(offset)
+	lunchStartLocal := lunchStart + offset
+	lunchEndLocal := lunchEnd + offset
 
 	// Normalize to 24-hour format
 	if lunchStartLocal < 0 {
@@ -104,7 +104,7 @@ func TestAojeaPortoDetection(t *testing.T) {
 	offset := 0
 
 	// Test lunch detection for UTC+0
-	lunchStart, lunchEnd, confidence := lunch.DetectLunchBreakNoonCentered(halfHourCounts, offset)
+	lunchStart, lunchEnd, confidence := lunch.DetectLunchBreakNoonCentered(halfHourCounts, float64(offset))
 
 	// Convert to local time
 	lunchStartLocal := lunchStart + float64(offset)
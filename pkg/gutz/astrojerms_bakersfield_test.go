@@ -96,14 +96,14 @@ func TestAstrojermsBakersfieldDetection(t *testing.T) {
 	}
 
 	// Test for UTC-8 (Pacific Standard Time - Bakersfield in winter)
-	offset := -8
+	offset := -8.0
 
 	// Detect lunch for this timezone
 	lunchStart, lunchEnd, confidence := lunch.DetectLunchBreakNoonCentered(halfHourCounts, offset)
 
 	// Convert UTC lunch times to local Pacific Time
-	lunchStartLocal := lunchStart + float64(offset)
-	lunchEndLocal := lunchEnd + float64(offset)
+	lunchStartLocal := lunchStart + offset
+	lunchEndLocal := lunchEnd + offset
 
 	// Normalize to 24-hour format
 	if lunchStartLocal < 0 {
@@ -2,6 +2,7 @@ package gutz
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 
 	"github.com/codeGROOVE-dev/guTZ/pkg/httpcache"
@@ -26,3 +27,26 @@ type retryableHTTPClient struct {
 func (r *retryableHTTPClient) Do(req *http.Request) (*http.Response, error) {
 	return r.doFunc(req)
 }
+
+// WithRefresh returns a context whose detections skip cached responses and fetch everything again,
+// storing what they fetch. Use it after Invalidate to force a fresh detection.
+func WithRefresh(ctx context.Context) context.Context {
+	return httpcache.WithBypass(ctx)
+}
+
+// Invalidate drops every cached HTTP response fetched for username, including GraphQL queries that name
+// them, and returns how many entries it dropped. Responses shared with other users, such as organization
+// details, are kept; WithRefresh fetches those again for one detection. It scans the whole cache, one
+// Redis round trip per 500 entries with a shared store, so callers should rate-limit it. Other replicas
+// sharing the store may serve dropped entries from memory for up to a minute.
+func (d *Detector) Invalidate(ctx context.Context, username string) (int, error) {
+	if d.cache == nil {
+		return 0, nil
+	}
+	n, err := d.cache.Purge(ctx, httpcache.MatchUser(username))
+	if err != nil {
+		return n, fmt.Errorf("invalidating cache for %s: %w", username, err)
+	}
+	d.logger.Info("invalidated cached responses", "username", username, "entries", n)
+	return n, nil
+}
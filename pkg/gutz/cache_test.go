@@ -0,0 +1,81 @@
+package gutz
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/codeGROOVE-dev/guTZ/pkg/httpcache"
+)
+
+func TestInvalidateAndRefresh(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		hits.Add(1)
+		if _, err := w.Write([]byte("fresh")); err != nil {
+			t.Errorf("write: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	logger := slog.New(slog.DiscardHandler)
+	cache, err := httpcache.NewMemoryOnlyCache(time.Hour, logger)
+	if err != nil {
+		t.Fatalf("NewMemoryOnlyCache: %v", err)
+	}
+	d := &Detector{logger: logger, cache: cache, httpClient: server.Client()}
+	for url, data := range map[string]string{
+		server.URL + "/users/alice":                   "stale",
+		server.URL + "/search/commits?q=author:alice": "stale",
+		server.URL + "/users/bob":                     "bob",
+		server.URL + "/orgs/kubernetes":               "org",
+	} {
+		if err := cache.Set(url, []byte(data), ""); err != nil {
+			t.Fatalf("Set: %v", err)
+		}
+	}
+	if err := cache.SetAPICall(server.URL+"/graphql", []byte(`{"variables":{"login":"alice"}}`), []byte("stale")); err != nil {
+		t.Fatalf("SetAPICall: %v", err)
+	}
+
+	n, err := d.Invalidate(context.Background(), "Alice")
+	if err != nil || n != 3 {
+		t.Fatalf("Invalidate = %d, %v; want 3", n, err)
+	}
+	if _, _, found := cache.Get(server.URL + "/users/bob"); !found {
+		t.Error("Invalidate dropped another user's entry")
+	}
+
+	// A refreshing detection refetches even shared entries, and stores the result
+	fetch := func(ctx context.Context) string {
+		t.Helper()
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/orgs/kubernetes", http.NoBody)
+		if err != nil {
+			t.Fatalf("NewRequest: %v", err)
+		}
+		resp, err := d.cachedHTTPDo(ctx, req)
+		if err != nil {
+			t.Fatalf("cachedHTTPDo: %v", err)
+		}
+		defer resp.Body.Close() //nolint:errcheck // test
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatalf("read: %v", err)
+		}
+		return string(body)
+	}
+	if got := fetch(context.Background()); got != "org" {
+		t.Errorf("cached fetch = %q, want the cached org", got)
+	}
+	if got := fetch(WithRefresh(context.Background())); got != "fresh" || hits.Load() != 1 {
+		t.Errorf("refreshing fetch = %q after %d requests, want a fresh response", got, hits.Load())
+	}
+	if got := fetch(context.Background()); got != "fresh" || hits.Load() != 1 {
+		t.Errorf("fetch after refresh = %q, want the refreshed entry from cache", got)
+	}
+}
@@ -40,14 +40,14 @@ func TestChewongLunchDetection(t *testing.T) {
 	}
 
 	// Test for UTC-7 (Pacific Daylight Time)
-	offset := -7
+	offset := -7.0
 
 	// Detect lunch for this timezone
 	lunchStart, lunchEnd, confidence := lunch.DetectLunchBreakNoonCentered(halfHourCounts, offset)
 
 	// Convert UTC lunch times to local Pacific Time
-	lunchStartLocal := lunchStart + float64(offset)
-	lunchEndLocal := lunchEnd + float64(offset)
+	lunchStartLocal := lunchStart + offset
+	lunchEndLocal := lunchEnd + offset
 
 	// Normalize to 24-hour format
 	if lunchStartLocal < 0 {
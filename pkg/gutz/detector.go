@@ -2,6 +2,7 @@
 package gutz
 
 import (
+	"cmp"
 	"context"
 	"crypto/tls"
 	"errors"
@@ -14,15 +15,20 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	md "github.com/JohannesKaufmann/html-to-markdown/v2"
+	"github.com/codeGROOVE-dev/guTZ/pkg/geocode"
 	"github.com/codeGROOVE-dev/guTZ/pkg/github"
 	"github.com/codeGROOVE-dev/guTZ/pkg/httpcache"
+	"github.com/codeGROOVE-dev/guTZ/pkg/httpreplay"
+	"github.com/codeGROOVE-dev/guTZ/pkg/llm"
 	"github.com/codeGROOVE-dev/guTZ/pkg/lunch"
+	"github.com/codeGROOVE-dev/guTZ/pkg/optout"
 	"github.com/codeGROOVE-dev/guTZ/pkg/tzconvert"
 	"github.com/codeGROOVE-dev/retry"
 )
@@ -58,19 +64,28 @@ type UserContext struct {
 	Organizations           []github.Organization
 	Events                  []github.PublicEvent
 	SSHKeys                 []github.SSHKey
+	Linked                  []Identity // Accounts on other forges whose activity joins the timeline
 }
 
 // Detector performs timezone detection for GitHub users.
 type Detector struct {
 	logger        *slog.Logger
 	httpClient    *http.Client
+	viewerClient  *http.Client // For requests signed with a user's own token: no cache, no token pool
 	cache         *httpcache.OtterCache
+	archive       *httpreplay.Archive
 	githubClient  *github.Client
+	rateLimiter   *github.RateLimiter
+	tokens        *github.TokenPool // nil without a GitHub token
+	llm           llm.Provider      // nil when AI analysis is disabled
+	progress      ProgressFunc
+	sources       []TimelineSource
+	sourceWeights map[string]float64 // Missing sources weigh 1
+	optOut        *optout.Registry   // nil when opt-outs are not honored
+	privacy       PrivacyLevel       // How much of each Result Detect returns
+	pinnedNow     time.Time          // fixture recording time; zero means use the wall clock
+	geocoder      geocode.Geocoder
 	githubToken   string
-	mapsAPIKey    string
-	geminiAPIKey  string
-	geminiModel   string
-	gcpProject    string
 	forceActivity bool
 }
 
@@ -85,10 +100,28 @@ func NewWithLogger(ctx context.Context, logger *slog.Logger, opts ...Option) *De
 	var cache *httpcache.OtterCache
 
 	switch {
+	case optHolder.archive != nil:
+		// Cache hits would hide exchanges from the recorder and shadow the archive on replay
+		logger.Info("caching disabled while using fixture archive")
+		cache = nil
 	case optHolder.noCache:
 		// Explicitly disable all caching
 		logger.Info("caching disabled by --no-cache flag")
 		cache = nil
+	case optHolder.cacheStore != "":
+		// Use a persistent store, possibly shared with other processes
+		ttl := 14 * 24 * time.Hour
+		if optHolder.memoryOnlyCache {
+			ttl = 12 * time.Hour
+		}
+		store, err := httpcache.OpenStorage(optHolder.cacheStore)
+		if err != nil {
+			logger.Warn("cache storage initialization failed", "error", err)
+			// Cache is optional, continue without it
+			cache = nil
+			break
+		}
+		cache = httpcache.NewCacheWithStorage(store, ttl, logger)
 	case optHolder.memoryOnlyCache:
 		// Use memory-only cache (for web server)
 		var err error
@@ -122,13 +155,15 @@ func NewWithLogger(ctx context.Context, logger *slog.Logger, opts ...Option) *De
 		}
 	}
 
+	// The client's own token enables token-only APIs such as GraphQL; with a pool, requests are re-signed per detection
+	githubToken := optHolder.githubToken
+	if githubToken == "" && len(optHolder.githubTokens) > 0 {
+		githubToken = optHolder.githubTokens[0]
+	}
+
 	detector := &Detector{
-		githubToken:  optHolder.githubToken,
-		mapsAPIKey:   optHolder.mapsAPIKey,
-		geminiAPIKey: optHolder.geminiAPIKey,
-		geminiModel:  optHolder.geminiModel,
-		gcpProject:   optHolder.gcpProject,
-		logger:       logger,
+		githubToken: githubToken,
+		logger:      logger,
 		httpClient: &http.Client{
 			Timeout: 30 * time.Second,
 			Transport: &http.Transport{
@@ -143,18 +178,85 @@ func NewWithLogger(ctx context.Context, logger *slog.Logger, opts ...Option) *De
 		},
 		forceActivity: optHolder.forceActivity,
 		cache:         cache,
+		archive:       optHolder.archive,
+		progress:      optHolder.progress,
+		sourceWeights: optHolder.sourceWeights,
+		optOut:        optHolder.optOut,
+		privacy:       optHolder.privacy,
+	}
+
+	detector.viewerClient = &http.Client{Timeout: 30 * time.Second}
+	if detector.archive != nil {
+		detector.httpClient.Transport = detector.archive.Transport(detector.httpClient.Transport)
+		detector.viewerClient.Transport = detector.archive.Transport(nil)
+		detector.pinnedNow = detector.archive.RecordedAt()
+	}
+
+	detector.llm = optHolder.llmProvider
+	if detector.llm == nil {
+		cfg := optHolder.llmConfig
+		if cfg.Backend == "" || cfg.Backend == llm.BackendGemini {
+			cfg.Backend = llm.BackendGemini
+			cfg.APIKey = cmp.Or(cfg.APIKey, optHolder.geminiAPIKey)
+			cfg.Model = cmp.Or(cfg.Model, optHolder.geminiModel)
+			cfg.GCPProject = cmp.Or(cfg.GCPProject, optHolder.gcpProject)
+		}
+		// Model calls get their own client: they are slow, must verify TLS, and shouldn't share
+		// the GitHub retry budget. With a fixture archive they are still recorded or replayed.
+		var transport http.RoundTripper
+		if detector.archive != nil {
+			transport = detector.archive.Transport(nil)
+		}
+		provider, err := llm.New(cfg, transport)
+		if err != nil {
+			logger.Warn("AI analysis disabled", "error", err)
+		}
+		detector.llm = provider
 	}
 
 	// Create GitHub client with cached HTTP
 	if cache != nil {
-		detector.githubClient = github.NewClient(logger, detector.httpClient, optHolder.githubToken, detector.cachedHTTPDo)
+		detector.githubClient = github.NewClient(logger, detector.httpClient, detector.githubToken, detector.cachedHTTPDo)
 	} else {
-		detector.githubClient = github.NewClient(logger, detector.httpClient, optHolder.githubToken, detector.retryableHTTPDo)
+		detector.githubClient = github.NewClient(logger, detector.httpClient, detector.githubToken, detector.retryableHTTPDo)
+	}
+	detector.githubClient.SetBaseURLs(optHolder.githubBaseURLs)
+
+	// Linked identities on other forges, and the private activity of ViewerContext, are fetched like any other timeline source
+	forgeDo := detector.retryableHTTPDo
+	if cache != nil {
+		forgeDo = detector.cachedHTTPDo
+	}
+	detector.sources = slices.Concat(forgeSources(logger, optHolder, forgeDo), []TimelineSource{viewerSource{}}, optHolder.timelineSources)
+
+	// Track the GitHub budget below the cache, so only requests that reach GitHub are counted
+	detector.rateLimiter = github.NewRateLimiter(optHolder.rateLimitPolicy, logger)
+	detector.httpClient.Transport = detector.rateLimiter.Transport(detector.httpClient.Transport, detector.githubClient.BaseURLs())
+	detector.githubClient.SetRateLimiter(detector.rateLimiter)
+
+	// Spread API requests over every configured token
+	if pool := github.NewTokenPool(slices.Concat([]string{optHolder.githubToken}, optHolder.githubTokens), detector.rateLimiter); pool.Len() > 0 {
+		detector.tokens = pool
+		detector.httpClient.Transport = pool.Transport(detector.httpClient.Transport, detector.githubClient.BaseURLs())
+	}
+
+	detector.geocoder = optHolder.geocoder
+	if detector.geocoder == nil {
+		detector.geocoder = detector.defaultGeocoder(optHolder.mapsAPIKey)
 	}
 
 	return detector
 }
 
+// now returns the time that activity windows are measured back from.
+// With a fixture archive it is the recording time, so replays select the same timeline.
+func (d *Detector) now() time.Time {
+	if !d.pinnedNow.IsZero() {
+		return d.pinnedNow
+	}
+	return time.Now()
+}
+
 // retryableHTTPDo performs an HTTP request with exponential backoff and jitter.
 // The returned response body must be closed by the caller.
 // fetchPersonalWebsite fetches personal websites with minimal retries and ignoring SSL errors.
@@ -229,11 +331,25 @@ func (d *Detector) retryableHTTPDo(ctx context.Context, req *http.Request) (*htt
 				return retry.Unrecoverable(errors.New("timeout after 15 seconds"))
 			}
 
+			// A previous attempt drained the body, so rebuild it for POSTs such as GraphQL queries
+			attempt := req.WithContext(ctx)
+			if req.GetBody != nil {
+				body, err := req.GetBody()
+				if err != nil {
+					return retry.Unrecoverable(fmt.Errorf("rewinding request body: %w", err))
+				}
+				attempt.Body = body
+			}
+
 			var err error
-			resp, err = d.httpClient.Do(req.WithContext(ctx)) //nolint:bodyclose // Body closed on error, returned open on success for caller
+			resp, err = d.httpClient.Do(attempt) //nolint:bodyclose // Body closed on error, returned open on success for caller
 			if err != nil {
-				// Network errors are retryable
 				lastErr = err
+				// A replay miss will never succeed on retry, and the rate limiter has already waited if it was going to
+				if errors.Is(err, httpreplay.ErrNotRecorded) || errors.Is(err, github.ErrRateLimited) {
+					return retry.Unrecoverable(err)
+				}
+				// Network errors are retryable
 				return err
 			}
 
@@ -377,6 +493,7 @@ func (d *Detector) mergeActivityData(result, activityResult *Result) {
 	result.HourlyOrganizationActivity = activityResult.HourlyOrganizationActivity
 	result.TimezoneCandidates = activityResult.TimezoneCandidates
 	result.ActivityDateRange = activityResult.ActivityDateRange
+	result.Seasonal = activityResult.Seasonal
 
 	// Calculate timezone offset for the new timezone (needed for recalculation)
 	newOffset := offsetFromNamedTimezone(result.Timezone)
@@ -401,20 +518,20 @@ func (d *Detector) mergeActivityData(result, activityResult *Result) {
 		// For timezones with DST, check both possible offsets
 		// e.g., America/Los_Angeles could be -7 (PDT) or -8 (PST)
 		// We prefer the current offset (newOffset) first
-		possibleOffsets := []int{newOffset}
+		possibleOffsets := []float64{newOffset}
 		switch result.Timezone {
 		case "America/Los_Angeles":
 			// Currently August, so PDT (-7) is active
-			possibleOffsets = []int{-7, -8}
+			possibleOffsets = []float64{-7, -8}
 		case "America/New_York":
 			// Currently August, so EDT (-4) is active
-			possibleOffsets = []int{-4, -5}
+			possibleOffsets = []float64{-4, -5}
 		case "America/Chicago":
 			// Currently August, so CDT (-5) is active
-			possibleOffsets = []int{-5, -6}
+			possibleOffsets = []float64{-5, -6}
 		case "America/Denver":
 			// Currently August, so MDT (-6) is active
-			possibleOffsets = []int{-6, -7}
+			possibleOffsets = []float64{-6, -7}
 		default:
 			// For other timezones, stick with the calculated offset
 		}
@@ -424,7 +541,7 @@ func (d *Detector) mergeActivityData(result, activityResult *Result) {
 		for _, offset := range possibleOffsets {
 			for i := range result.TimezoneCandidates {
 				candidate := &result.TimezoneCandidates[i]
-				if int(candidate.Offset) == offset && candidate.LunchStartUTC >= 0 {
+				if candidate.Offset == offset && candidate.LunchStartUTC >= 0 {
 					// Reuse the lunch calculation from this candidate
 					d.logger.Debug("reusing lunch from candidate",
 						"timezone", result.Timezone,
@@ -432,21 +549,13 @@ func (d *Detector) mergeActivityData(result, activityResult *Result) {
 						"lunch_start_utc", candidate.LunchStartUTC,
 						"lunch_end_utc", candidate.LunchEndUTC,
 						"lunch_confidence", candidate.LunchConfidence)
-					result.LunchHoursUTC = struct {
-						Start      float64 `json:"start"`
-						End        float64 `json:"end"`
-						Confidence float64 `json:"confidence"`
-					}{
+					result.LunchHoursUTC = LunchBreak{
 						Start:      candidate.LunchStartUTC,
 						End:        candidate.LunchEndUTC,
 						Confidence: candidate.LunchConfidence,
 					}
 					// Convert lunch hours from UTC to local
-					result.LunchHoursLocal = struct {
-						Start      float64 `json:"start"`
-						End        float64 `json:"end"`
-						Confidence float64 `json:"confidence"`
-					}{
+					result.LunchHoursLocal = LunchBreak{
 						Start:      tzconvert.UTCToLocal(candidate.LunchStartUTC, newOffset),
 						End:        tzconvert.UTCToLocal(candidate.LunchEndUTC, newOffset),
 						Confidence: candidate.LunchConfidence,
@@ -466,21 +575,13 @@ func (d *Detector) mergeActivityData(result, activityResult *Result) {
 				"timezone", result.Timezone,
 				"offset", newOffset)
 			lunchStart, lunchEnd, lunchConfidence := lunch.DetectLunchBreakNoonCentered(activityResult.HalfHourlyActivityUTC, newOffset)
-			result.LunchHoursUTC = struct {
-				Start      float64 `json:"start"`
-				End        float64 `json:"end"`
-				Confidence float64 `json:"confidence"`
-			}{
+			result.LunchHoursUTC = LunchBreak{
 				Start:      lunchStart,
 				End:        lunchEnd,
 				Confidence: lunchConfidence,
 			}
 			// Convert lunch hours from UTC to local
-			result.LunchHoursLocal = struct {
-				Start      float64 `json:"start"`
-				End        float64 `json:"end"`
-				Confidence float64 `json:"confidence"`
-			}{
+			result.LunchHoursLocal = LunchBreak{
 				Start:      tzconvert.UTCToLocal(lunchStart, newOffset),
 				End:        tzconvert.UTCToLocal(lunchEnd, newOffset),
 				Confidence: lunchConfidence,
@@ -497,11 +598,7 @@ func (d *Detector) mergeActivityData(result, activityResult *Result) {
 				"offset", newOffset,
 				"lunchStartUTC", result.LunchHoursUTC.Start,
 				"lunchEndUTC", result.LunchHoursUTC.End)
-			result.LunchHoursLocal = struct {
-				Start      float64 `json:"start"`
-				End        float64 `json:"end"`
-				Confidence float64 `json:"confidence"`
-			}{
+			result.LunchHoursLocal = LunchBreak{
 				Start:      tzconvert.UTCToLocal(result.LunchHoursUTC.Start, newOffset),
 				End:        tzconvert.UTCToLocal(result.LunchHoursUTC.End, newOffset),
 				Confidence: result.LunchHoursUTC.Confidence,
@@ -516,10 +613,7 @@ func (d *Detector) mergeActivityData(result, activityResult *Result) {
 			"offset", newOffset,
 			"activeStartUTC", result.ActiveHoursUTC.Start,
 			"activeEndUTC", result.ActiveHoursUTC.End)
-		result.ActiveHoursLocal = struct {
-			Start float64 `json:"start"`
-			End   float64 `json:"end"`
-		}{
+		result.ActiveHoursLocal = ActiveHours{
 			Start: tzconvert.UTCToLocal(result.ActiveHoursUTC.Start, newOffset),
 			End:   tzconvert.UTCToLocal(result.ActiveHoursUTC.End, newOffset),
 		}
@@ -532,11 +626,7 @@ func (d *Detector) mergeActivityData(result, activityResult *Result) {
 			"offset", newOffset,
 			"peakStartUTC", result.PeakProductivityUTC.Start,
 			"peakEndUTC", result.PeakProductivityUTC.End)
-		result.PeakProductivityLocal = struct {
-			Start float64 `json:"start"`
-			End   float64 `json:"end"`
-			Count int     `json:"count"`
-		}{
+		result.PeakProductivityLocal = PeakTime{
 			Start: tzconvert.UTCToLocal(result.PeakProductivityUTC.Start, newOffset),
 			End:   tzconvert.UTCToLocal(result.PeakProductivityUTC.End, newOffset),
 			Count: result.PeakProductivityUTC.Count,
@@ -559,7 +649,9 @@ func (d *Detector) fetchAllUserData(
 	userCtx := &UserContext{
 		Username:  username,
 		FromCache: make(map[string]bool),
+		Linked:    linkedIdentities(ctx),
 	}
+	ctx, rateLimited := github.WatchRateLimits(ctx)
 
 	// STEP 1: First fetch profile HTML to verify username exists
 	d.logger.Debug("checking profile HTML", "username", username)
@@ -739,6 +831,11 @@ func (d *Detector) fetchAllUserData(
 	if criticalErr != nil {
 		return nil, criticalErr
 	}
+	// The fetches above tolerate failures, but a result built around an exhausted budget would be mostly guesswork.
+	// Search has its own small per-minute budget and only supplements the rest.
+	if rlErr := rateLimited(); rlErr != nil && rlErr.Resource != "search" {
+		return nil, fmt.Errorf("fetching GitHub data: %w", rlErr)
+	}
 
 	// Log summary
 	// Note: PRs and Issues are fetched as part of the GraphQL user profile query
@@ -764,16 +861,32 @@ func createdAtFromUser(user *github.User) *time.Time {
 	return &user.CreatedAt
 }
 
-// Detect performs timezone detection for the given GitHub username.
-//
+// Detect performs timezone detection for the given GitHub username. The result is redacted to the WithPrivacy level.
+func (d *Detector) Detect(ctx context.Context, username string) (*Result, error) {
+	result, err := d.detect(ctx, username)
+	if result != nil {
+		result.SchemaVersion = SchemaVersion
+		result.Linked = linkedNames(linkedIdentities(ctx))
+		result = result.Redact(d.privacy)
+	}
+	return result, err
+}
+
 //nolint:gocognit // Main detection orchestration function
-func (d *Detector) Detect(ctx context.Context, username string) (*Result, error) { //nolint:revive,maintidx // Main detection logic
+func (d *Detector) detect(ctx context.Context, username string) (*Result, error) { //nolint:revive,maintidx // Main detection logic
 	// SECURITY: Validate username to prevent injection attacks
 	if !IsValidGitHubUsername(username) {
 		return nil, errors.New("invalid GitHub username format")
 	}
+	if d.OptedOut(username) {
+		d.logger.Info("refusing to detect opted-out user", "username", username)
+		return nil, ErrOptedOut
+	}
 
 	d.logger.Info("detecting timezone", "username", username)
+	if d.tokens != nil {
+		ctx = d.tokens.Assign(ctx)
+	}
 
 	// Fetch ALL data at once to avoid redundant API calls
 	userCtx, err := d.fetchAllUserData(ctx, username)
@@ -782,6 +895,11 @@ func (d *Detector) Detect(ctx context.Context, username string) (*Result, error)
 		d.logger.Error("Failed to fetch user data", "username", username, "error", err)
 		return nil, fmt.Errorf("GitHub API error: %w", err)
 	}
+	d.report(ctx, ProgressEvent{
+		Phase:    PhaseProfileFetched,
+		Username: username,
+		Message:  fmt.Sprintf("Fetched GitHub profile and %d public events", len(userCtx.Events)),
+	})
 
 	// Get the full name from the fetched user
 	var fullName string
@@ -802,6 +920,14 @@ func (d *Detector) Detect(ctx context.Context, username string) (*Result, error)
 			"username", username,
 			"has_events", len(userCtx.Events) > 0,
 			"has_user", userCtx.User != nil)
+	} else {
+		d.report(ctx, ProgressEvent{
+			Phase:      PhaseCandidatesReady,
+			Username:   username,
+			Message:    fmt.Sprintf("Scored %d activity-based timezone candidates", len(activityResult.TimezoneCandidates)),
+			Timezone:   activityResult.Timezone,
+			Candidates: activityResult.TimezoneCandidates,
+		})
 	}
 
 	// Try quick detection methods first
@@ -819,6 +945,21 @@ func (d *Detector) Detect(ctx context.Context, username string) (*Result, error)
 
 	d.logger.Debug("trying location field analysis", "username", username)
 	locationResult := d.tryLocationFieldWithContext(ctx, userCtx)
+	if locationResult != nil {
+		d.report(ctx, ProgressEvent{
+			Phase:    PhaseLocationGeocoded,
+			Username: username,
+			Message:  "Geocoded profile location " + locationResult.LocationName,
+			Timezone: locationResult.Timezone,
+			Location: locationResult.Location,
+		})
+	} else {
+		d.report(ctx, ProgressEvent{
+			Phase:    PhaseLocationGeocoded,
+			Username: username,
+			Message:  "No usable profile location",
+		})
+	}
 	if locationResult != nil { //nolint:nestif // Complex location detection logic
 		d.logger.Info("detected from location field",
 			"username", username,
@@ -865,27 +1006,19 @@ func (d *Detector) Detect(ctx context.Context, username string) (*Result, error)
 			candidateFound := false
 			for i := range locationResult.TimezoneCandidates {
 				candidate := &locationResult.TimezoneCandidates[i]
-				if int(candidate.Offset) == newOffset {
+				if candidate.Offset == newOffset {
 					d.logger.Info("found matching candidate",
 						"offset", newOffset,
 						"lunch_local", candidate.LunchLocalTime)
 
 					// Use the pre-calculated lunch hours from the candidate
 					if candidate.LunchStartUTC > 0 && candidate.LunchEndUTC > 0 {
-						locationResult.LunchHoursUTC = struct {
-							Start      float64 `json:"start"`
-							End        float64 `json:"end"`
-							Confidence float64 `json:"confidence"`
-						}{
+						locationResult.LunchHoursUTC = LunchBreak{
 							Start:      candidate.LunchStartUTC,
 							End:        candidate.LunchEndUTC,
 							Confidence: candidate.LunchConfidence,
 						}
-						locationResult.LunchHoursLocal = struct {
-							Start      float64 `json:"start"`
-							End        float64 `json:"end"`
-							Confidence float64 `json:"confidence"`
-						}{
+						locationResult.LunchHoursLocal = LunchBreak{
 							Start:      tzconvert.UTCToLocal(candidate.LunchStartUTC, newOffset),
 							End:        tzconvert.UTCToLocal(candidate.LunchEndUTC, newOffset),
 							Confidence: candidate.LunchConfidence,
@@ -911,10 +1044,7 @@ func (d *Detector) Detect(ctx context.Context, username string) (*Result, error)
 					"offset", newOffset,
 					"activeStartUTC", locationResult.ActiveHoursUTC.Start,
 					"activeEndUTC", locationResult.ActiveHoursUTC.End)
-				locationResult.ActiveHoursLocal = struct {
-					Start float64 `json:"start"`
-					End   float64 `json:"end"`
-				}{
+				locationResult.ActiveHoursLocal = ActiveHours{
 					Start: tzconvert.UTCToLocal(locationResult.ActiveHoursUTC.Start, newOffset),
 					End:   tzconvert.UTCToLocal(locationResult.ActiveHoursUTC.End, newOffset),
 				}
@@ -927,11 +1057,7 @@ func (d *Detector) Detect(ctx context.Context, username string) (*Result, error)
 					"offset", newOffset,
 					"peakStartUTC", locationResult.PeakProductivityUTC.Start,
 					"peakEndUTC", locationResult.PeakProductivityUTC.End)
-				locationResult.PeakProductivityLocal = struct {
-					Start float64 `json:"start"`
-					End   float64 `json:"end"`
-					Count int     `json:"count"`
-				}{
+				locationResult.PeakProductivityLocal = PeakTime{
 					Start: tzconvert.UTCToLocal(locationResult.PeakProductivityUTC.Start, newOffset),
 					End:   tzconvert.UTCToLocal(locationResult.PeakProductivityUTC.End, newOffset),
 					Count: locationResult.PeakProductivityUTC.Count,
@@ -981,10 +1107,7 @@ func (d *Detector) Detect(ctx context.Context, username string) (*Result, error)
 
 				// Recalculate ActiveHoursLocal
 				if locationResult.ActiveHoursUTC.Start != 0 || locationResult.ActiveHoursUTC.End != 0 {
-					locationResult.ActiveHoursLocal = struct {
-						Start float64 `json:"start"`
-						End   float64 `json:"end"`
-					}{
+					locationResult.ActiveHoursLocal = ActiveHours{
 						Start: tzconvert.UTCToLocal(locationResult.ActiveHoursUTC.Start, newOffset),
 						End:   tzconvert.UTCToLocal(locationResult.ActiveHoursUTC.End, newOffset),
 					}
@@ -992,11 +1115,7 @@ func (d *Detector) Detect(ctx context.Context, username string) (*Result, error)
 
 				// Recalculate PeakProductivityLocal
 				if locationResult.PeakProductivityUTC.Start != 0 || locationResult.PeakProductivityUTC.End != 0 {
-					locationResult.PeakProductivityLocal = struct {
-						Start float64 `json:"start"`
-						End   float64 `json:"end"`
-						Count int     `json:"count"`
-					}{
+					locationResult.PeakProductivityLocal = PeakTime{
 						Start: tzconvert.UTCToLocal(locationResult.PeakProductivityUTC.Start, newOffset),
 						End:   tzconvert.UTCToLocal(locationResult.PeakProductivityUTC.End, newOffset),
 						Count: locationResult.PeakProductivityUTC.Count,
@@ -1005,11 +1124,7 @@ func (d *Detector) Detect(ctx context.Context, username string) (*Result, error)
 
 				// Recalculate LunchHoursLocal
 				if locationResult.LunchHoursUTC.Start != 0 || locationResult.LunchHoursUTC.End != 0 {
-					locationResult.LunchHoursLocal = struct {
-						Start      float64 `json:"start"`
-						End        float64 `json:"end"`
-						Confidence float64 `json:"confidence"`
-					}{
+					locationResult.LunchHoursLocal = LunchBreak{
 						Start:      tzconvert.UTCToLocal(locationResult.LunchHoursUTC.Start, newOffset),
 						End:        tzconvert.UTCToLocal(locationResult.LunchHoursUTC.End, newOffset),
 						Confidence: locationResult.LunchHoursUTC.Confidence,
@@ -1075,6 +1190,11 @@ func (d *Detector) Detect(ctx context.Context, username string) (*Result, error)
 	if activityResult != nil {
 		d.logger.Info("using activity-only result as fallback", "username", username, "timezone", activityResult.Timezone)
 		activityResult.Name = fullName
+		if zone := seasonalZone(activityResult); zone != "" {
+			d.logger.Info("named activity timezone from seasonal DST behavior", "username", username,
+				"offset_timezone", activityResult.Timezone, "timezone", zone, "regime", activityResult.Seasonal.Regime)
+			activityResult.Timezone = zone
+		}
 		// Add verification for activity-only result
 		activityResult.Verification = d.createVerification(ctx, userCtx, activityResult.Timezone,
 			userCtx.ProfileLocationTimezone, activityResult.Timezone, activityResult.Location)
@@ -1149,6 +1269,12 @@ func (d *Detector) createVerification(
 		}
 	}
 
+	d.report(ctx, ProgressEvent{
+		Phase:    PhaseVerification,
+		Username: userCtx.Username,
+		Message:  "Verified " + detectedTimezone + " against profile signals",
+		Timezone: detectedTimezone,
+	})
 	return verification
 }
 
@@ -225,7 +225,7 @@ func TestEveningActivityDetection(t *testing.T) {
 			}
 
 			// Check timezone mapping
-			tz := timezoneFromOffset(offsetInt)
+			tz := timezoneFromOffset(float64(offsetInt))
 			if tz != tt.expectedTimezone {
 				t.Errorf("%s: expected timezone %s, got %s (offset=%d)",
 					tt.name, tt.expectedTimezone, tz, offsetInt)
@@ -426,7 +426,7 @@ func TestWorkScheduleCorrection(t *testing.T) { //nolint:maintidx // Large test
 			}
 
 			// Check final timezone mapping
-			finalTZ := timezoneFromOffset(finalOffset)
+			finalTZ := timezoneFromOffset(float64(finalOffset))
 			if finalTZ != tt.expectedTZ {
 				t.Errorf("%s: expected final timezone %s, got %s (offset=%d)",
 					tt.name, tt.expectedTZ, finalTZ, finalOffset)
@@ -496,7 +496,7 @@ func TestQuietHoursToTimezone(t *testing.T) {
 				offsetFromUTC += 24
 			}
 
-			tz := timezoneFromOffset(int(offsetFromUTC))
+			tz := timezoneFromOffset(float64(int(offsetFromUTC)))
 
 			// Check if the result is one of the expected options
 			found := false
@@ -545,17 +545,17 @@ func TestTstrombergLunchDetection(t *testing.T) {
 	halfHourCounts[22.5] = 9
 
 	// Eastern Time offset
-	utcOffset := -4
+	utcOffset := -4.0
 
 	// Detect lunch using 30-minute buckets
 	lunchStart, lunchEnd, lunchConfidence := lunch.DetectLunchBreakNoonCentered(halfHourCounts, utcOffset)
 
 	// Convert to local time for logging
-	lunchStartLocal := lunchStart + float64(utcOffset)
+	lunchStartLocal := lunchStart + utcOffset
 	if lunchStartLocal < 0 {
 		lunchStartLocal += 24
 	}
-	lunchEndLocal := lunchEnd + float64(utcOffset)
+	lunchEndLocal := lunchEnd + utcOffset
 	if lunchEndLocal < 0 {
 		lunchEndLocal += 24
 	}
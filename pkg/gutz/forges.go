@@ -0,0 +1,172 @@
+package gutz
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/codeGROOVE-dev/guTZ/pkg/gitea"
+	"github.com/codeGROOVE-dev/guTZ/pkg/gitlab"
+)
+
+// Forges an identity can live on.
+const (
+	ForgeGitHub   = "github"
+	ForgeGitLab   = "gitlab"   // gitlab.com, or the instance set with WithGitLab
+	ForgeCodeberg = "codeberg" // codeberg.org
+	ForgeGitea    = "gitea"    // The Gitea or Forgejo instance set with WithGitea
+)
+
+// forgeUsername matches the usernames GitLab, Gitea and Forgejo allow.
+var forgeUsername = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,254}$`)
+
+// Identity is an account on a forge, written forge:username. A bare username is a GitHub account.
+type Identity struct {
+	Forge    string
+	Username string
+}
+
+func (i Identity) String() string {
+	return i.Forge + ":" + i.Username
+}
+
+// ParseIdentity parses an identity such as gitlab:alice, codeberg:alice or plain alice (on GitHub).
+func ParseIdentity(s string) (Identity, error) {
+	forge, username, found := strings.Cut(strings.TrimSpace(s), ":")
+	if !found {
+		forge, username = ForgeGitHub, forge
+	}
+	id := Identity{Forge: strings.ToLower(forge), Username: username}
+	switch id.Forge {
+	case ForgeGitHub:
+		if !IsValidGitHubUsername(username) {
+			return Identity{}, fmt.Errorf("invalid GitHub username %q", username)
+		}
+	case ForgeGitLab, ForgeCodeberg, ForgeGitea:
+		if !forgeUsername.MatchString(username) {
+			return Identity{}, fmt.Errorf("invalid %s username %q", id.Forge, username)
+		}
+	default:
+		return Identity{}, fmt.Errorf("unknown forge %q in %q (want github, gitlab, codeberg or gitea)", forge, s)
+	}
+	return id, nil
+}
+
+type linkedKey struct{}
+
+// WithLinkedIdentities returns a context whose detections merge the activity of the given accounts on
+// other forges into the GitHub user's timeline, for people whose GitHub activity alone is too sparse.
+func WithLinkedIdentities(ctx context.Context, ids ...Identity) context.Context {
+	if len(ids) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, linkedKey{}, ids)
+}
+
+func linkedIdentities(ctx context.Context) []Identity {
+	ids, _ := ctx.Value(linkedKey{}).([]Identity) //nolint:errcheck // nil when nothing is linked
+	return ids
+}
+
+// linkedNames returns ids as sorted forge:username strings, so that results from the same linked
+// accounts compare equal whatever order they were given in.
+func linkedNames(ids []Identity) []string {
+	if len(ids) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(ids))
+	for _, id := range ids {
+		names = append(names, strings.ToLower(id.String()))
+	}
+	slices.Sort(names)
+	return slices.Compact(names)
+}
+
+// WithGitLab points gitlab: identities at a self-managed instance instead of gitlab.com. The token is
+// optional and sent as PRIVATE-TOKEN.
+func WithGitLab(baseURL, token string) Option {
+	return func(o *OptionHolder) {
+		o.gitlabURL = baseURL
+		o.gitlabToken = token
+	}
+}
+
+// WithGitea sets the Gitea or Forgejo instance that gitea: identities live on. The token is optional.
+func WithGitea(baseURL, token string) Option {
+	return func(o *OptionHolder) {
+		o.giteaURL = baseURL
+		o.giteaToken = token
+	}
+}
+
+// forgeSource is the TimelineSource for the linked identities on one forge.
+type forgeSource struct {
+	fetch func(ctx context.Context, username string) ([]TimelineEntry, error)
+	forge string
+}
+
+func (s forgeSource) Name() string { return s.forge }
+
+func (s forgeSource) Timeline(ctx context.Context, userCtx *UserContext) ([]TimelineEntry, error) {
+	var entries []TimelineEntry
+	for _, id := range userCtx.Linked {
+		if id.Forge != s.forge {
+			continue
+		}
+		got, err := s.fetch(ctx, id.Username)
+		if err != nil {
+			return entries, fmt.Errorf("%s: %w", id, err)
+		}
+		entries = append(entries, got...)
+	}
+	return entries, nil
+}
+
+// forgeSources returns a source for each forge identities can be linked on.
+func forgeSources(logger *slog.Logger, o *OptionHolder, do func(context.Context, *http.Request) (*http.Response, error)) []TimelineSource {
+	sources := []TimelineSource{
+		gitlabSource(gitlab.NewClient(logger, o.gitlabURL, o.gitlabToken, do)),
+		giteaSource(ForgeCodeberg, gitea.NewClient(logger, gitea.CodebergURL, "", do)),
+	}
+	if o.giteaURL != "" {
+		sources = append(sources, giteaSource(ForgeGitea, gitea.NewClient(logger, o.giteaURL, o.giteaToken, do)))
+	}
+	return sources
+}
+
+func gitlabSource(client *gitlab.Client) forgeSource {
+	return forgeSource{forge: ForgeGitLab, fetch: func(ctx context.Context, username string) ([]TimelineEntry, error) {
+		activity, err := client.FetchActivity(ctx, username)
+		entries := make([]TimelineEntry, 0, len(activity))
+		for _, a := range activity {
+			entries = append(entries, forgeEntry(ForgeGitLab, a.Time, a.Kind, a.Project, a.Title, a.URL))
+		}
+		return entries, err
+	}}
+}
+
+func giteaSource(forge string, client *gitea.Client) forgeSource {
+	return forgeSource{forge: forge, fetch: func(ctx context.Context, username string) ([]TimelineEntry, error) {
+		activity, err := client.FetchActivity(ctx, username)
+		entries := make([]TimelineEntry, 0, len(activity))
+		for _, a := range activity {
+			entries = append(entries, forgeEntry(forge, a.Time, a.Kind, a.Project, a.Title, a.URL))
+		}
+		return entries, err
+	}}
+}
+
+// forgeEntry builds a timeline entry for forge activity. Entries are attributed to the forge, so that
+// WithSourceWeight applies to all of it, and the kind of activity leads the title. Groups on other forges
+// are not GitHub organizations, so Org stays empty.
+func forgeEntry(forge string, at time.Time, kind, project, title, url string) TimelineEntry {
+	if title != "" {
+		kind += ": " + title
+	}
+	return TimelineEntry{Time: at, Source: forge, Repository: project, Title: kind, URL: url}
+}
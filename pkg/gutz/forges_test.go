@@ -0,0 +1,96 @@
+package gutz
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/codeGROOVE-dev/guTZ/pkg/httpreplay"
+)
+
+func TestParseIdentity(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Identity
+		wantErr bool
+	}{
+		{in: "alice", want: Identity{Forge: ForgeGitHub, Username: "alice"}},
+		{in: "github:alice", want: Identity{Forge: ForgeGitHub, Username: "alice"}},
+		{in: "GitLab:alice.smith", want: Identity{Forge: ForgeGitLab, Username: "alice.smith"}},
+		{in: "codeberg:alice_s", want: Identity{Forge: ForgeCodeberg, Username: "alice_s"}},
+		{in: "gitea:alice", want: Identity{Forge: ForgeGitea, Username: "alice"}},
+		{in: "github:alice.smith", wantErr: true},
+		{in: "gitlab:", wantErr: true},
+		{in: "gitlab:../admin", wantErr: true},
+		{in: "bitbucket:alice", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseIdentity(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseIdentity(%q) = %+v, %v; want %+v, error %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestForgeSourcesFollowLinkedIdentities(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.Path)
+		if r.URL.Query().Get("page") != "1" {
+			fmt.Fprint(w, `[]`)
+			return
+		}
+		fmt.Fprint(w, `[{"created": "2025-03-04T09:15:00Z", "op_type": "create_pull_request",
+			"repo": {"full_name": "team/app", "html_url": "https://git.example.com/team/app"}}]`)
+	}))
+	defer server.Close()
+	do := func(_ context.Context, req *http.Request) (*http.Response, error) { return server.Client().Do(req) }
+
+	logger := slog.New(slog.DiscardHandler)
+	d := &Detector{logger: logger, sources: forgeSources(logger, &OptionHolder{giteaURL: server.URL}, do)}
+
+	ctx := WithLinkedIdentities(context.Background(), Identity{Forge: ForgeGitea, Username: "alice"})
+	userCtx := &UserContext{Username: "alice-gh", Linked: linkedIdentities(ctx)}
+	entries := d.collectTimelineSources(ctx, userCtx)
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries, want 1", len(entries))
+	}
+	if e := entries[0]; e.Source != ForgeGitea || e.Repository != "team/app" || e.Title != "pull_request" || e.Org != "" {
+		t.Errorf("entry = %+v", e)
+	}
+	if len(requests) != 1 || requests[0] != "/api/v1/users/alice/activities/feeds" {
+		t.Errorf("requests = %v, want only alice's gitea feed", requests)
+	}
+
+	// Without linked identities no forge is contacted
+	requests = nil
+	if entries := d.collectTimelineSources(context.Background(), &UserContext{Username: "bob"}); len(entries) != 0 || len(requests) != 0 {
+		t.Errorf("unlinked user got %d entries after %d requests", len(entries), len(requests))
+	}
+}
+
+func TestDetectRecordsLinkedIdentities(t *testing.T) {
+	archive, err := httpreplay.Load(goldenArchive)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	detector := NewWithLogger(ctx, slog.New(slog.DiscardHandler), WithFixtureArchive(archive), WithGitHubToken("test-token"),
+		WithPrivacy(PrivacyTimezoneOnly))
+
+	// The forges were not recorded, so only the linking itself is under test
+	ctx = WithLinkedIdentities(ctx, Identity{Forge: ForgeGitLab, Username: "Octo"}, Identity{Forge: ForgeCodeberg, Username: "octo"})
+	result, err := detector.Detect(ctx, "octocat")
+	if err != nil {
+		t.Fatalf("Detect: %v", err)
+	}
+	if want := []string{"codeberg:octo", "gitlab:octo"}; !slices.Equal(result.Linked, want) {
+		t.Errorf("Linked = %v, want %v", result.Linked, want)
+	}
+}
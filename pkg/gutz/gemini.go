@@ -2,8 +2,10 @@ package gutz
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math"
+	"net/http"
 	"sort"
 	"strconv"
 	"strings"
@@ -11,7 +13,9 @@ import (
 
 	"github.com/codeGROOVE-dev/guTZ/pkg/gemini"
 	"github.com/codeGROOVE-dev/guTZ/pkg/github"
+	"github.com/codeGROOVE-dev/guTZ/pkg/httpreplay"
 	"github.com/codeGROOVE-dev/guTZ/pkg/social"
+	"github.com/codeGROOVE-dev/guTZ/pkg/timezone"
 )
 
 // geminiQueryResult holds the result from a Gemini API query.
@@ -48,11 +52,22 @@ func (d *Detector) queryUnifiedGeminiForTimezone(ctx context.Context, contextDat
 
 	// Verbose prompt display removed - now handled in main CLI
 
-	// Create Gemini client and call API
-	client := gemini.NewClient(d.geminiAPIKey, d.geminiModel, d.gcpProject)
-	resp, err := client.CallWithSDK(ctx, prompt, d.cache, d.logger)
+	if d.llm == nil {
+		return nil, errors.New("AI analysis is disabled")
+	}
+	var cache gemini.Cache = d.cache
+	if d.archive != nil {
+		// The Gemini SDK bypasses our HTTP client, so verdicts are recorded and replayed at the API call level
+		if d.archive.Mode() == httpreplay.Replay {
+			if _, found := d.archive.APICall(d.llm.CacheKey(prompt), nil); !found {
+				return nil, fmt.Errorf("LLM query: %w", httpreplay.ErrNotRecorded)
+			}
+		}
+		cache = d.archive
+	}
+	resp, err := d.llm.Query(ctx, prompt, cache, d.logger)
 	if err != nil {
-		return nil, fmt.Errorf("🚩 Gemini API SDK call failed: %w (prompt_length: %d, has_activity: %t)",
+		return nil, fmt.Errorf("🚩 LLM query failed: %w (prompt_length: %d, has_activity: %t)",
 			err, len(prompt), hasActivityData)
 	}
 
@@ -110,6 +125,10 @@ func (d *Detector) queryUnifiedGeminiForTimezone(ctx context.Context, contextDat
 //
 //nolint:gocognit,nestif,revive,maintidx // Complex AI-based analysis requires comprehensive data processing
 func (d *Detector) tryUnifiedGeminiAnalysisWithContext(ctx context.Context, userCtx *UserContext, activityResult *Result) *Result {
+	if d.llm == nil {
+		d.logger.Debug("AI analysis disabled, skipping", "username", userCtx.Username)
+		return nil
+	}
 	if userCtx.User == nil {
 		d.logger.Warn("🚩 User Profile Unavailable - Proceeding with Gemini analysis using available data", "username", userCtx.Username,
 			"issue", "GitHub user profile fetch failed - likely token scope issues or user not found")
@@ -167,12 +186,16 @@ func (d *Detector) tryUnifiedGeminiAnalysisWithContext(ctx context.Context, user
 			}
 		}
 
+		if activityResult.Seasonal != nil && activityResult.Seasonal.Regime != timezone.DSTUnknown {
+			contextData["seasonal"] = activityResult.Seasonal
+		}
+
 		if activityResult.ActivityTimezone != "" {
 			contextData["activity_timezone"] = activityResult.ActivityTimezone
 
 			if strings.HasPrefix(activityResult.ActivityTimezone, "UTC") {
 				offsetStr := strings.TrimPrefix(activityResult.ActivityTimezone, "UTC")
-				if offset, err := strconv.Atoi(offsetStr); err == nil {
+				if offset, err := strconv.ParseFloat(offsetStr, 64); err == nil {
 					contextData["utc_offset"] = offset
 
 					if activityResult.ActiveHoursUTC.Start > 0 || activityResult.ActiveHoursUTC.End > 0 {
@@ -396,7 +419,11 @@ func (d *Detector) tryUnifiedGeminiAnalysisWithContext(ctx context.Context, user
 
 		// Extract social media data using the social package
 		d.logger.Debug("calling social.Extract", "profiles", socialProfiles)
-		extractedProfiles := social.Extract(ctx, socialProfiles, d.logger)
+		var socialClient *http.Client
+		if d.archive != nil {
+			socialClient = d.httpClient
+		}
+		extractedProfiles := social.ExtractWithClient(ctx, socialClient, socialProfiles, d.logger)
 
 		d.logger.Debug("extracted social profiles", "count", len(extractedProfiles), "profiles", extractedProfiles)
 
@@ -502,6 +529,11 @@ func (d *Detector) tryUnifiedGeminiAnalysisWithContext(ctx context.Context, user
 				return keys
 			}(),
 			"fallback", "using activity-only patterns")
+		d.report(ctx, ProgressEvent{
+			Phase:    PhaseLLMVerdict,
+			Username: userCtx.Username,
+			Message:  "AI analysis failed; falling back to activity patterns",
+		})
 		return nil
 	}
 
@@ -516,6 +548,12 @@ func (d *Detector) tryUnifiedGeminiAnalysisWithContext(ctx context.Context, user
 				return geminiResult.Reasoning[:100] + "..."
 			}(),
 			"threshold", 0.3)
+		d.report(ctx, ProgressEvent{
+			Phase:    PhaseLLMVerdict,
+			Username: userCtx.Username,
+			Message:  fmt.Sprintf("AI verdict %s rejected for low confidence (%.0f%%)", geminiResult.Timezone, geminiResult.Confidence*100),
+			Timezone: geminiResult.Timezone,
+		})
 		return nil
 	}
 
@@ -526,6 +564,12 @@ func (d *Detector) tryUnifiedGeminiAnalysisWithContext(ctx context.Context, user
 		"location", geminiResult.Location,
 		"confidence", geminiResult.Confidence,
 		"data_sources", dataSources)
+	d.report(ctx, ProgressEvent{
+		Phase:    PhaseLLMVerdict,
+		Username: userCtx.Username,
+		Message:  fmt.Sprintf("AI verdict: %s (%.0f%% confidence)", geminiResult.Timezone, geminiResult.Confidence*100),
+		Timezone: geminiResult.Timezone,
+	})
 
 	// Sort data sources alphabetically for consistent display
 	sort.Strings(dataSources)
@@ -654,12 +698,12 @@ func utcOffsetFromTimezone(tzString string) float64 {
 // extractRepositoryContributions aggregates repository contributions from all sources.
 // collectTextSamplesFromTimeline extracts text samples from timeline entries for Gemini analysis.
 // It prioritizes diverse, recent samples that can reveal language, cultural context, and location hints.
-func collectTextSamplesFromTimeline(timeline []timestampEntry, maxSamples int) []string {
+func collectTextSamplesFromTimeline(timeline []TimelineEntry, maxSamples int) []string {
 	// Sort timeline by recency (newest first)
-	sortedTimeline := make([]timestampEntry, len(timeline))
+	sortedTimeline := make([]TimelineEntry, len(timeline))
 	copy(sortedTimeline, timeline)
 	sort.Slice(sortedTimeline, func(i, j int) bool {
-		return sortedTimeline[i].time.After(sortedTimeline[j].time)
+		return sortedTimeline[i].Time.After(sortedTimeline[j].Time)
 	})
 
 	var samples []string
@@ -667,33 +711,33 @@ func collectTextSamplesFromTimeline(timeline []timestampEntry, maxSamples int) [
 
 	for _, entry := range sortedTimeline {
 		// Skip entries without meaningful text
-		if entry.title == "" || entry.title == entry.source {
+		if entry.Title == "" || entry.Title == entry.Source {
 			continue
 		}
 
 		// Skip duplicates
-		if seen[entry.title] {
+		if seen[entry.Title] {
 			continue
 		}
-		seen[entry.title] = true
+		seen[entry.Title] = true
 
 		// Format the sample with source and repository info for context
 		var sample string
-		switch entry.source {
+		switch entry.Source {
 		case "pr":
-			sample = fmt.Sprintf("PR: %q (%s)", entry.title, entry.repository)
+			sample = fmt.Sprintf("PR: %q (%s)", entry.Title, entry.Repository)
 		case "issue":
-			sample = fmt.Sprintf("Issue: %q (%s)", entry.title, entry.repository)
+			sample = fmt.Sprintf("Issue: %q (%s)", entry.Title, entry.Repository)
 		case "comment":
 			// Comments are already truncated in timeline collection
-			sample = fmt.Sprintf("Comment: %q (%s)", entry.title, entry.repository)
+			sample = fmt.Sprintf("Comment: %q (%s)", entry.Title, entry.Repository)
 		case "gist":
-			sample = fmt.Sprintf("Gist: %q", entry.title)
+			sample = fmt.Sprintf("Gist: %q", entry.Title)
 		case "repo_created":
 			continue // Skip repo descriptions, they're listed elsewhere
 		case "commit":
 			// Commits now have actual commit messages extracted from PushEvents
-			sample = fmt.Sprintf("Commit: %q (%s)", entry.title, entry.repository)
+			sample = fmt.Sprintf("Commit: %q (%s)", entry.Title, entry.Repository)
 		case "event":
 			// Skip generic events - we've already extracted meaningful content as commits/comments
 			continue
@@ -731,7 +775,7 @@ func extractRepositoryContributions(userCtx *UserContext) []repoContribution {
 
 	// Extract from Comments (parse repository from HTML URL)
 	for _, comment := range userCtx.Comments {
-		if repoFullName := extractRepoFromGitHubURL(comment.HTMLURL); repoFullName != "" {
+		if repoFullName := github.RepoFromURL(comment.HTMLURL); repoFullName != "" {
 			// Only count external contributions (not user's own repos)
 			if !strings.HasPrefix(repoFullName, userCtx.Username+"/") {
 				contributedRepos[repoFullName]++
@@ -775,47 +819,6 @@ func extractRepositoryContributions(userCtx *UserContext) []repoContribution {
 	return contribs
 }
 
-// extractRepoFromGitHubURL extracts repository owner/name from a GitHub URL
-// Examples:
-//
-//	https://github.com/owner/repo/issues/123 -> "owner/repo"
-//	https://github.com/owner/repo/pull/456#issuecomment-789 -> "owner/repo"
-//	https://github.com/owner/repo/commit/abc123 -> "owner/repo"
-func extractRepoFromGitHubURL(htmlURL string) string {
-	if htmlURL == "" {
-		return ""
-	}
-
-	// Parse the URL to extract the path
-	// Expected format: https://github.com/owner/repo/...
-	// We want to extract "owner/repo"
-
-	// Simple string parsing approach for GitHub URLs
-	const githubPrefix = "https://github.com/"
-	if !strings.HasPrefix(htmlURL, githubPrefix) {
-		return ""
-	}
-
-	// Remove the github.com prefix
-	path := strings.TrimPrefix(htmlURL, githubPrefix)
-
-	// Split by '/' and take first two parts (owner/repo)
-	parts := strings.Split(path, "/")
-	if len(parts) < 2 {
-		return ""
-	}
-
-	owner := parts[0]
-	repo := parts[1]
-
-	// Basic validation - owner and repo should not be empty
-	if owner == "" || repo == "" {
-		return ""
-	}
-
-	return owner + "/" + repo
-}
-
 // extractAndDedupeEmails collects emails from user profile and commits, returning deduplicated list.
 func extractAndDedupeEmails(userCtx *UserContext) []string {
 	emailSet := make(map[string]bool)
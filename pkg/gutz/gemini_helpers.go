@@ -9,6 +9,7 @@ import (
 
 	"github.com/codeGROOVE-dev/guTZ/pkg/github"
 	"github.com/codeGROOVE-dev/guTZ/pkg/timezone"
+	"github.com/codeGROOVE-dev/guTZ/pkg/tzconvert"
 )
 
 // Constants for data limits and thresholds.
@@ -251,7 +252,7 @@ func (d *Detector) formatEvidenceForGemini(contextData map[string]any) string {
 			if i > 0 {
 				sb.WriteString(", ")
 			}
-			fmt.Fprintf(&sb, "UTC%+.0f", candidate.Offset)
+			sb.WriteString(tzconvert.FormatOffset(candidate.Offset))
 		}
 		sb.WriteString("\n")
 
@@ -284,6 +285,12 @@ func (d *Detector) formatEvidenceForGemini(contextData map[string]any) string {
 				}
 			}
 		}
+
+		// Seasonal comparison separates zones that share a standard offset but not DST rules
+		if seasonal, ok := contextData["seasonal"].(*timezone.SeasonalAnalysis); ok {
+			sb.WriteString(seasonalSummary(seasonal))
+			sb.WriteString("\n")
+		}
 		sb.WriteString("\n")
 
 		// Show detailed signals for top candidates (including claimed if not in top 3)
@@ -301,16 +308,16 @@ func (d *Detector) formatEvidenceForGemini(contextData map[string]any) string {
 				break
 			}
 			candidate := &candidates[i]
-			fmt.Fprintf(&sb, "%d. UTC%+.1f (%.0f%% confidence)\n",
-				i+1, candidate.Offset, candidate.Confidence)
+			fmt.Fprintf(&sb, "%d. %s (%.0f%% confidence)\n",
+				i+1, tzconvert.FormatOffset(candidate.Offset), candidate.Confidence)
 
 			// Calculate local times for this candidate
-			offset := int(candidate.Offset)
+			offset := candidate.Offset
 
 			// Work hours - convert UTC to this candidate's local time
 			if workHours, ok := contextData["work_hours_utc"].([]float64); ok && len(workHours) == 2 {
-				localStart := math.Mod(workHours[0]+float64(offset)+24, 24)
-				localEnd := math.Mod(workHours[1]+float64(offset)+24, 24)
+				localStart := tzconvert.UTCToLocal(workHours[0], offset)
+				localEnd := tzconvert.UTCToLocal(workHours[1], offset)
 
 				// Format with minutes if there are any
 				startHour := int(localStart)
@@ -332,8 +339,8 @@ func (d *Detector) formatEvidenceForGemini(contextData map[string]any) string {
 			// Lunch break - use the candidate's own lunch calculation
 			if candidate.LunchStartUTC >= 0 {
 				// Convert candidate's UTC lunch time to local time for this candidate
-				localLunchStart := math.Mod(candidate.LunchStartUTC+float64(offset)+24, 24)
-				localLunchEnd := math.Mod(candidate.LunchEndUTC+float64(offset)+24, 24)
+				localLunchStart := tzconvert.UTCToLocal(candidate.LunchStartUTC, offset)
+				localLunchEnd := tzconvert.UTCToLocal(candidate.LunchEndUTC, offset)
 
 				// Format the times properly
 				startHour := int(localLunchStart)
@@ -383,20 +390,20 @@ func (d *Detector) formatEvidenceForGemini(contextData map[string]any) string {
 				}
 
 				// Calculate local sleep hours
-				localSleepStart := (longestStart + offset + 24) % 24
-				localSleepEnd := ((longestEnd+1)%24 + offset + 24) % 24 // Add 1 to get end of sleep period
+				localSleepStart := tzconvert.UTCToLocal(float64(longestStart), offset)
+				localSleepEnd := tzconvert.UTCToLocal(float64(longestEnd+1), offset) // Add 1 to get end of sleep period
 
 				// Format the sleep display with more detail
-				fmt.Fprintf(&sb, "   Sleep: %02d:00-%02d:00 local (%d hrs)",
-					localSleepStart, localSleepEnd, maxLength)
+				fmt.Fprintf(&sb, "   Sleep: %s-%s local (%d hrs)",
+					clockTime(localSleepStart), clockTime(localSleepEnd), maxLength)
 
 				// Check if sleep is at night - normal sleep starts 8pm-2am and ends 4am-10am
-				nighttimeStart := (localSleepStart >= 20 && localSleepStart <= 23) || (localSleepStart >= 0 && localSleepStart <= 2)
-				nighttimeEnd := (localSleepEnd >= 4 && localSleepEnd <= 10) || (localSleepEnd >= 0 && localSleepEnd <= 3)
+				nighttimeStart := localSleepStart >= 20 || localSleepStart <= 2
+				nighttimeEnd := localSleepEnd <= 10
 				if nighttimeStart && nighttimeEnd {
 					sb.WriteString(" ✓ nighttime")
 				} else {
-					fmt.Fprintf(&sb, " ⚠️ unusual (start:%02d end:%02d)", localSleepStart, localSleepEnd)
+					fmt.Fprintf(&sb, " ⚠️ unusual (start:%s end:%s)", clockTime(localSleepStart), clockTime(localSleepEnd))
 				}
 				sb.WriteString("\n")
 			}
@@ -412,9 +419,9 @@ func (d *Detector) formatEvidenceForGemini(contextData map[string]any) string {
 
 			// Peak productivity
 			if peakHours, ok := contextData["peak_productivity_utc"].([]int); ok && len(peakHours) >= 2 {
-				localPeakStart := (peakHours[0] + offset + 24) % 24
-				localPeakEnd := (peakHours[1] + offset + 24) % 24
-				fmt.Fprintf(&sb, "   Peak productivity: %02d:00-%02d:00 local", localPeakStart, localPeakEnd)
+				localPeakStart := tzconvert.UTCToLocal(float64(peakHours[0]), offset)
+				localPeakEnd := tzconvert.UTCToLocal(float64(peakHours[1]), offset)
+				fmt.Fprintf(&sb, "   Peak productivity: %s-%s local", clockTime(localPeakStart), clockTime(localPeakEnd))
 				if !candidate.PeakTimeReasonable {
 					sb.WriteString(" ⚠️ unusual peak time")
 				}
@@ -756,3 +763,31 @@ func (d *Detector) formatEvidenceForGemini(contextData map[string]any) string {
 
 	return sb.String()
 }
+
+// seasonalSummary describes inferred daylight saving behavior in one line.
+func seasonalSummary(seasonal *timezone.SeasonalAnalysis) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Seasonal analysis (%d winter / %d summer events): ", seasonal.WinterEvents, seasonal.SummerEvents)
+	switch seasonal.Regime {
+	case timezone.DSTNone:
+		sb.WriteString("same UTC routine all year, so no daylight saving time")
+	case timezone.DSTUS:
+		sb.WriteString("routine moves 1h earlier in UTC during summer, matching US/Canada daylight saving")
+	case timezone.DSTEU:
+		sb.WriteString("routine moves 1h earlier in UTC during summer, matching European daylight saving")
+	case timezone.DSTSouthern:
+		sb.WriteString("routine moves 1h earlier in UTC during the northern winter, matching southern hemisphere daylight saving")
+	default:
+		sb.WriteString("inconclusive")
+	}
+	if len(seasonal.SuggestedZones) > 0 {
+		fmt.Fprintf(&sb, " (consistent with %s)", strings.Join(seasonal.SuggestedZones, ", "))
+	}
+	return sb.String()
+}
+
+// clockTime formats a fractional local hour as HH:MM, e.g. 23.5 becomes "23:30".
+func clockTime(hour float64) string {
+	minutes := int(math.Round(hour*60)) % (24 * 60)
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
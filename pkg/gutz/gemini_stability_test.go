@@ -36,10 +36,7 @@ func TestGeminiPromptStability(t *testing.T) {
 	}))
 
 	detector := &Detector{
-		logger:       logger,
-		geminiAPIKey: "test-key",
-		geminiModel:  "test-model",
-		gcpProject:   "test-project",
+		logger: logger,
 	}
 
 	// Create deterministic test data that mirrors real user data
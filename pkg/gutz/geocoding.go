@@ -4,18 +4,32 @@ import (
 	"context"
 	"net/http"
 
+	"github.com/codeGROOVE-dev/guTZ/pkg/geocode"
 	"github.com/codeGROOVE-dev/guTZ/pkg/googlemaps"
 )
 
-// geocodeLocation converts a location string to coordinates using Google Geocoding API.
-func (d *Detector) geocodeLocation(ctx context.Context, location string) (*Location, error) {
-	// Create a custom HTTP client that uses our caching mechanism
-	cachedClient := &cachedHTTPClient{
-		detector: d,
-		doFunc:   func(req *http.Request) (*http.Response, error) { return d.cachedHTTPDo(ctx, req) },
+// defaultGeocoder uses Google Maps when an API key is configured, falling back to the embedded gazetteer.
+func (d *Detector) defaultGeocoder(mapsAPIKey string) geocode.Geocoder {
+	var geocoders []geocode.Geocoder
+	if mapsAPIKey != "" {
+		// Route Google requests through our caching mechanism
+		cachedClient := &cachedHTTPClient{
+			detector: d,
+			doFunc:   func(req *http.Request) (*http.Response, error) { return d.cachedHTTPDo(req.Context(), req) },
+		}
+		geocoders = append(geocoders, googlemaps.NewClient(mapsAPIKey, cachedClient, d.logger))
+	}
+	if offline, err := geocode.Offline(); err == nil {
+		geocoders = append(geocoders, offline)
+	} else {
+		d.logger.Warn("offline gazetteer unavailable", "error", err)
 	}
-	client := googlemaps.NewClient(d.mapsAPIKey, cachedClient, d.logger)
-	geoLoc, err := client.GeocodeLocation(ctx, location)
+	return geocode.Chain(geocoders...)
+}
+
+// geocodeLocation converts a location string to coordinates.
+func (d *Detector) geocodeLocation(ctx context.Context, location string) (*Location, error) {
+	geoLoc, err := d.geocoder.GeocodeLocation(ctx, location)
 	if err != nil {
 		return nil, err
 	}
@@ -25,15 +39,9 @@ func (d *Detector) geocodeLocation(ctx context.Context, location string) (*Locat
 	}, nil
 }
 
-// timezoneForCoordinates gets the timezone for given coordinates using Google Timezone API.
+// timezoneForCoordinates gets the timezone for given coordinates.
 func (d *Detector) timezoneForCoordinates(ctx context.Context, lat, lng float64) (string, error) {
-	// Create a custom HTTP client that uses our caching mechanism
-	cachedClient := &cachedHTTPClient{
-		detector: d,
-		doFunc:   func(req *http.Request) (*http.Response, error) { return d.cachedHTTPDo(ctx, req) },
-	}
-	client := googlemaps.NewClient(d.mapsAPIKey, cachedClient, d.logger)
-	return client.TimezoneForCoordinates(ctx, lat, lng)
+	return d.geocoder.TimezoneForCoordinates(ctx, lat, lng)
 }
 
 // cachedHTTPClient wraps the Detector's cachedHTTPDo method to implement HTTPClient interface.
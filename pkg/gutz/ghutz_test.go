@@ -127,7 +127,7 @@ func TestTimezoneFromOffset(t *testing.T) {
 	// Test that the function returns generic UTC offsets
 	// since we can't determine the specific location without more context
 	tests := []struct {
-		offset   int
+		offset   float64
 		expected string
 	}{
 		{-8, "UTC-8"},
@@ -139,12 +139,15 @@ func TestTimezoneFromOffset(t *testing.T) {
 		{1, "UTC+1"},
 		{2, "UTC+2"},
 		{8, "UTC+8"},
+		{5.5, "UTC+5.5"},
+		{5.75, "UTC+5.75"},
+		{-3.5, "UTC-3.5"},
 	}
 
 	for _, tt := range tests {
 		result := timezoneFromOffset(tt.offset)
 		if result != tt.expected {
-			t.Errorf("timezoneFromOffset(%d) = %v, want %v", tt.offset, result, tt.expected)
+			t.Errorf("timezoneFromOffset(%v) = %v, want %v", tt.offset, result, tt.expected)
 		}
 	}
 }
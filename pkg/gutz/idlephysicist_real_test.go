@@ -81,14 +81,14 @@ func TestIdlePhysicistRealLunchDetection(t *testing.T) {
 	halfHourCounts[20.5] = 6  // 14:30 MST
 
 	// Test for UTC-6 (Mountain Standard Time)
-	offset := -6
+	offset := -6.0
 
 	// Detect lunch for this timezone
 	lunchStart, lunchEnd, confidence := lunch.DetectLunchBreakNoonCentered(halfHourCounts, offset)
 
 	// Convert UTC lunch times to local Mountain Time
-	lunchStartLocal := lunchStart + float64(offset)
-	lunchEndLocal := lunchEnd + float64(offset)
+	lunchStartLocal := lunchStart + offset
+	lunchEndLocal := lunchEnd + offset
 
 	// Normalize to 24-hour format
 	if lunchStartLocal < 0 {
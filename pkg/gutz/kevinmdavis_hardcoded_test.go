@@ -94,14 +94,14 @@ func TestKevinMDavisNashvilleDetection(t *testing.T) {
 	}
 
 	// Test for UTC-6 (Central Standard Time - Nashville in winter)
-	offset := -6
+	offset := -6.0
 
 	// Detect lunch for this timezone
 	lunchStart, lunchEnd, confidence := lunch.DetectLunchBreakNoonCentered(halfHourCounts, offset)
 
 	// Convert UTC lunch times to local Central Time
-	lunchStartLocal := lunchStart + float64(offset)
-	lunchEndLocal := lunchEnd + float64(offset)
+	lunchStartLocal := lunchStart + offset
+	lunchEndLocal := lunchEnd + offset
 
 	// Normalize to 24-hour format
 	if lunchStartLocal < 0 {
@@ -0,0 +1,87 @@
+package gutz
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/codeGROOVE-dev/guTZ/pkg/optout"
+)
+
+// ErrOptedOut is returned when detecting a user who opted out of profiling.
+var ErrOptedOut = errors.New("user has opted out of timezone detection")
+
+// ErrProofNotFound is returned when a user's opt-out challenge token is not on their account yet.
+var ErrProofNotFound = errors.New("challenge token not found in a public gist or the profile README")
+
+// WithOptOutRegistry makes Detect refuse the users in reg, and lets VerifyOptOut add to it.
+func WithOptOutRegistry(reg *optout.Registry) Option {
+	return func(o *OptionHolder) {
+		o.optOut = reg
+	}
+}
+
+// OptedOut reports whether username has opted out of detection.
+func (d *Detector) OptedOut(username string) bool {
+	return d.optOut != nil && d.optOut.Contains(username)
+}
+
+// VerifyOptOut checks that username published their pending challenge token, as the description or a
+// file name of a public gist or anywhere in their profile README, and then adds them to the opt-out
+// registry and drops everything cached about them. It returns where the token was found.
+func (d *Detector) VerifyOptOut(ctx context.Context, username string) (string, error) {
+	if d.optOut == nil {
+		return "", errors.New("no opt-out registry configured")
+	}
+	challenge, err := d.optOut.Pending(username)
+	if err != nil {
+		return "", err
+	}
+
+	// The user just published the token, so cached copies of their gists and README are stale
+	ctx = WithRefresh(ctx)
+	proof, err := d.findOptOutProof(ctx, username, challenge.Token)
+	if err != nil {
+		return "", err
+	}
+	if err := d.optOut.Add(username, proof); err != nil {
+		return "", fmt.Errorf("recording opt-out: %w", err)
+	}
+	d.logger.Info("user opted out", "username", username, "proof", proof)
+
+	if _, err := d.Invalidate(ctx, username); err != nil {
+		d.logger.Warn("failed to drop cached data of opted-out user", "username", username, "error", err)
+	}
+	return proof, nil
+}
+
+// findOptOutProof returns the URL of the gist or README holding token.
+func (d *Detector) findOptOutProof(ctx context.Context, username, token string) (string, error) {
+	gists, err := d.githubClient.FetchUserGistsDetails(ctx, username)
+	if err != nil {
+		d.logger.Debug("could not list gists for opt-out proof", "username", username, "error", err)
+	}
+	for _, g := range gists {
+		if !g.Public {
+			continue
+		}
+		if strings.Contains(g.Description, token) {
+			return g.HTMLURL, nil
+		}
+		for name := range g.Files {
+			if strings.Contains(name, token) {
+				return g.HTMLURL, nil
+			}
+		}
+	}
+
+	readme, readmeErr := d.githubClient.FetchProfileREADME(ctx, username)
+	if strings.Contains(readme, token) {
+		return fmt.Sprintf("%s/%s/%s", d.githubClient.BaseURLs().Web, username, username), nil
+	}
+	if err := errors.Join(err, readmeErr); err != nil {
+		return "", fmt.Errorf("%w (%w)", ErrProofNotFound, err)
+	}
+	return "", ErrProofNotFound
+}
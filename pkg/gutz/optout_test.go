@@ -0,0 +1,64 @@
+package gutz
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/codeGROOVE-dev/guTZ/pkg/github"
+	"github.com/codeGROOVE-dev/guTZ/pkg/optout"
+)
+
+func TestVerifyOptOut(t *testing.T) {
+	reg, err := optout.Open(filepath.Join(t.TempDir(), "optout.json"))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	var readme string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/users/alice/gists":
+			fmt.Fprint(w, `[{"description": "notes", "html_url": "https://gist.example.com/1", "public": true, "files": {"a.md": {"filename": "a.md"}}}]`)
+		case "/repos/alice/alice/readme":
+			fmt.Fprint(w, readme)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	d := NewWithLogger(context.Background(), slog.New(slog.DiscardHandler),
+		WithNoCache(),
+		WithGitHubBaseURLs(github.BaseURLs{API: server.URL, Web: "https://github.example.com"}),
+		WithOptOutRegistry(reg))
+	ctx := context.Background()
+
+	if _, err := d.VerifyOptOut(ctx, "alice"); !errors.Is(err, optout.ErrNoChallenge) {
+		t.Errorf("VerifyOptOut without a challenge = %v, want ErrNoChallenge", err)
+	}
+	challenge, err := reg.Challenge("alice")
+	if err != nil {
+		t.Fatalf("Challenge: %v", err)
+	}
+	readme = "# Hi, I'm Alice"
+	if _, err := d.VerifyOptOut(ctx, "alice"); !errors.Is(err, ErrProofNotFound) {
+		t.Errorf("VerifyOptOut before publishing = %v, want ErrProofNotFound", err)
+	}
+	if d.OptedOut("alice") {
+		t.Fatal("user opted out without proof")
+	}
+
+	readme = "# Hi\n<!-- " + challenge.Token + " -->"
+	proof, err := d.VerifyOptOut(ctx, "alice")
+	if err != nil || proof != "https://github.example.com/alice/alice" {
+		t.Fatalf("VerifyOptOut = %q, %v; want the README as proof", proof, err)
+	}
+	if _, err := d.Detect(ctx, "Alice"); !errors.Is(err, ErrOptedOut) {
+		t.Errorf("Detect = %v, want ErrOptedOut", err)
+	}
+}
@@ -0,0 +1,26 @@
+package gutz
+
+import (
+	"context"
+	"strings"
+)
+
+// OrganizationMembers returns the public members of a GitHub organization, at most limit of them,
+// without the users who opted out.
+func (d *Detector) OrganizationMembers(ctx context.Context, org string, limit int) ([]string, error) {
+	const perPage = 100
+	logins, err := d.githubClient.FetchOrganizationMembers(ctx, strings.TrimSpace(org), (limit+perPage-1)/perPage)
+	if err != nil {
+		return nil, err
+	}
+	members := make([]string, 0, len(logins))
+	for _, login := range logins {
+		if len(members) == limit {
+			break
+		}
+		if !d.OptedOut(login) {
+			members = append(members, login)
+		}
+	}
+	return members, nil
+}
@@ -0,0 +1,95 @@
+package gutz
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// PrivacyLevel says how much of a Result leaves the detector.
+type PrivacyLevel string
+
+const (
+	// PrivacyFull keeps everything, including the LLM prompt with all the evidence it was given.
+	PrivacyFull PrivacyLevel = "full"
+	// PrivacyCoarse drops the LLM prompt and reasoning, which can quote emails and other evidence, and
+	// the per-hour organization activity, and rounds coordinates to the city, about 10 km.
+	PrivacyCoarse PrivacyLevel = "coarse"
+	// PrivacyTimezoneOnly keeps the username, timezone, confidence and method, and nothing else.
+	PrivacyTimezoneOnly PrivacyLevel = "timezone-only"
+)
+
+// ParsePrivacyLevel parses a privacy level name; empty means PrivacyFull.
+func ParsePrivacyLevel(s string) (PrivacyLevel, error) {
+	switch l := PrivacyLevel(strings.ToLower(strings.TrimSpace(s))); l {
+	case "":
+		return PrivacyFull, nil
+	case PrivacyFull, PrivacyCoarse, PrivacyTimezoneOnly:
+		return l, nil
+	default:
+		return "", fmt.Errorf("unknown privacy level %q (want full, coarse or timezone-only)", s)
+	}
+}
+
+// WithPrivacy redacts every Result that Detect returns to level. The default is PrivacyFull.
+func WithPrivacy(level PrivacyLevel) Option {
+	return func(o *OptionHolder) {
+		o.privacy = level
+	}
+}
+
+// Redact returns r with what level does not allow removed. r itself is left untouched, and returned
+// as is for PrivacyFull.
+func (r *Result) Redact(level PrivacyLevel) *Result {
+	switch level {
+	case PrivacyCoarse:
+		out := *r
+		out.GeminiPrompt = ""
+		out.GeminiReasoning = ""
+		out.GeminiMismatchReason = ""
+		out.HourlyOrganizationActivity = nil
+		out.Timeline = nil
+		if r.Location != nil {
+			out.Location = &Location{Latitude: roundCoordinate(r.Location.Latitude), Longitude: roundCoordinate(r.Location.Longitude)}
+		}
+		return &out
+	case PrivacyTimezoneOnly:
+		return &Result{
+			DetectionTime:      r.DetectionTime,
+			Username:           r.Username,
+			Timezone:           r.Timezone,
+			Method:             r.Method,
+			Linked:             r.Linked,
+			SchemaVersion:      r.SchemaVersion,
+			Confidence:         r.Confidence,
+			TimezoneConfidence: r.TimezoneConfidence,
+		}
+	default:
+		return r
+	}
+}
+
+// Redact returns ev with what level does not allow removed, so that progress observers learn no more
+// than the final Result will tell them.
+func (ev ProgressEvent) Redact(level PrivacyLevel) ProgressEvent {
+	switch level {
+	case PrivacyCoarse:
+		if ev.Location != nil {
+			ev.Location = &Location{Latitude: roundCoordinate(ev.Location.Latitude), Longitude: roundCoordinate(ev.Location.Longitude)}
+		}
+	case PrivacyTimezoneOnly:
+		ev.Location = nil
+		ev.Candidates = nil
+		ev.SourceCounts = nil
+		if ev.Phase == PhaseLocationGeocoded {
+			// The message names the profile location
+			ev.Message = "Geocoded profile location"
+		}
+	}
+	return ev
+}
+
+// roundCoordinate rounds a latitude or longitude to one decimal, a cell of about 11 km.
+func roundCoordinate(deg float64) float64 {
+	return math.Round(deg*10) / 10
+}
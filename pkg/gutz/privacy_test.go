@@ -0,0 +1,155 @@
+package gutz
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/codeGROOVE-dev/guTZ/pkg/timezone"
+)
+
+func sensitiveResult() *Result {
+	return &Result{
+		DetectionTime:              time.Date(2025, 3, 4, 12, 0, 0, 0, time.UTC),
+		Username:                   "alice",
+		Timezone:                   "Europe/Berlin",
+		Method:                     "gemini_refined_activity",
+		Confidence:                 0.8,
+		TimezoneConfidence:         0.8,
+		SchemaVersion:              SchemaVersion,
+		GeminiPrompt:               "Emails: alice@secret.example",
+		GeminiReasoning:            "Commits from alice@secret.example at 52.52437 13.41053",
+		GeminiMismatchReason:       "profile says Munich",
+		Location:                   &Location{Latitude: 52.52437, Longitude: 13.41053},
+		LocationName:               "Berlin, Germany",
+		HourlyOrganizationActivity: map[int]map[string]int{9: {"acme-internal": 4}},
+		TopOrganizations:           []OrgActivity{{Name: "acme-internal", Count: 4}},
+		HalfHourlyActivityUTC:      map[float64]int{9: 4},
+		Timeline:                   []TimelineEntry{{Source: "commit", Repository: "acme-internal/secret"}},
+	}
+}
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		level     PrivacyLevel
+		leaks     []string // Must not appear in the JSON
+		keeps     []string // Must still appear
+		wantLat   float64
+		wantHours bool
+	}{
+		{
+			level:     PrivacyFull,
+			keeps:     []string{"alice@secret.example", "52.52437", "hourly_organization_activity", "Berlin, Germany"},
+			wantLat:   52.52437,
+			wantHours: true,
+		},
+		{
+			level:     PrivacyCoarse,
+			leaks:     []string{"secret.example", "52.52437", "13.41053", "Munich", "hourly_organization_activity", "gemini_prompt", "gemini_reasoning"},
+			keeps:     []string{"Europe/Berlin", "Berlin, Germany", "acme-internal", "half_hourly_activity_utc"},
+			wantLat:   52.5,
+			wantHours: true,
+		},
+		{
+			level: PrivacyTimezoneOnly,
+			leaks: []string{"secret.example", "52.5", "Berlin, Germany", "acme-internal", "half_hourly_activity_utc", "location"},
+			keeps: []string{"Europe/Berlin", `"username":"alice"`, `"confidence":0.8`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.level), func(t *testing.T) {
+			orig := sensitiveResult()
+			got := orig.Redact(tt.level)
+			data, err := json.Marshal(got)
+			if err != nil {
+				t.Fatalf("marshal: %v", err)
+			}
+			for _, s := range tt.leaks {
+				if strings.Contains(string(data), s) {
+					t.Errorf("%s leaks %q: %s", tt.level, s, data)
+				}
+			}
+			for _, s := range tt.keeps {
+				if !strings.Contains(string(data), s) {
+					t.Errorf("%s lost %q: %s", tt.level, s, data)
+				}
+			}
+			if tt.wantLat != 0 && (got.Location == nil || got.Location.Latitude != tt.wantLat) {
+				t.Errorf("%s location = %+v, want latitude %v", tt.level, got.Location, tt.wantLat)
+			}
+			if tt.level != PrivacyFull && len(got.Timeline) != 0 {
+				t.Errorf("%s kept the timeline", tt.level)
+			}
+			if orig.GeminiPrompt == "" || orig.Location.Latitude != 52.52437 {
+				t.Errorf("Redact modified the original result")
+			}
+		})
+	}
+}
+
+func TestParsePrivacyLevel(t *testing.T) {
+	for in, want := range map[string]PrivacyLevel{"": PrivacyFull, "Coarse": PrivacyCoarse, "timezone-only": PrivacyTimezoneOnly} {
+		if got, err := ParsePrivacyLevel(in); err != nil || got != want {
+			t.Errorf("ParsePrivacyLevel(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParsePrivacyLevel("none"); err == nil {
+		t.Error("ParsePrivacyLevel(none) succeeded")
+	}
+}
+
+func TestProgressRedacted(t *testing.T) {
+	events := []ProgressEvent{
+		{Phase: PhaseTimelineBuilt, Username: "alice", SourceCounts: map[string]int{"commit": 4}},
+		{
+			Phase: PhaseCandidatesReady, Username: "alice", Timezone: "Europe/Berlin",
+			Candidates: []timezone.Candidate{{Timezone: "UTC+1", ScoringDetails: []string{"lunch at 12:00"}}},
+		},
+		{
+			Phase: PhaseLocationGeocoded, Username: "alice", Timezone: "Europe/Berlin",
+			Message:  "Geocoded profile location Kreuzberg, Berlin",
+			Location: &Location{Latitude: 52.52437, Longitude: 13.41053},
+		},
+	}
+	tests := []struct {
+		level PrivacyLevel
+		leaks []string
+		keeps []string
+	}{
+		{level: PrivacyFull, keeps: []string{"52.52437", "13.41053", "Kreuzberg", "lunch at 12:00", `"commit":4`}},
+		{level: PrivacyCoarse, leaks: []string{"52.52437", "13.41053"}, keeps: []string{"52.5", "13.4", "Europe/Berlin"}},
+		{
+			level: PrivacyTimezoneOnly,
+			leaks: []string{"52.5", "13.4", "Kreuzberg", "lunch at 12:00", `"candidates":`, `"source_counts":`, `"location":`},
+			keeps: []string{"Europe/Berlin", `"username":"alice"`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.level), func(t *testing.T) {
+			var got []ProgressEvent
+			d := &Detector{privacy: tt.level, progress: func(ev ProgressEvent) { got = append(got, ev) }}
+			for _, ev := range events {
+				d.report(context.Background(), ev)
+			}
+			data, err := json.Marshal(got)
+			if err != nil {
+				t.Fatalf("marshal: %v", err)
+			}
+			for _, s := range tt.leaks {
+				if strings.Contains(string(data), s) {
+					t.Errorf("%s progress leaks %q: %s", tt.level, s, data)
+				}
+			}
+			for _, s := range tt.keeps {
+				if !strings.Contains(string(data), s) {
+					t.Errorf("%s progress lost %q: %s", tt.level, s, data)
+				}
+			}
+			if events[2].Location.Latitude != 52.52437 {
+				t.Error("redaction modified the reported event")
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package gutz
+
+import (
+	"context"
+	"time"
+
+	"github.com/codeGROOVE-dev/guTZ/pkg/timezone"
+)
+
+// Phase identifies a step of detection reported to progress observers.
+type Phase string
+
+// Detection phases, in the order they normally occur. Phases may be skipped when an earlier
+// method settles the timezone, e.g. a timezone set on the GitHub profile needs no LLM verdict.
+const (
+	PhaseProfileFetched   Phase = "profile_fetched"   // GitHub profile and activity data downloaded
+	PhaseTimelineBuilt    Phase = "timeline_built"    // Activity timestamps collected, with counts per source
+	PhaseCandidatesReady  Phase = "candidates_ready"  // Activity-based timezone candidates scored
+	PhaseLocationGeocoded Phase = "location_geocoded" // Profile location resolved to coordinates and a timezone
+	PhaseLLMVerdict       Phase = "llm_verdict"       // Language model analysis finished
+	PhaseVerification     Phase = "verification"      // Final timezone cross-checked against other signals
+)
+
+// ProgressEvent reports that a detection phase has completed.
+type ProgressEvent struct {
+	Time         time.Time            `json:"time"`
+	SourceCounts map[string]int       `json:"source_counts,omitempty"`
+	Location     *Location            `json:"location,omitempty"`
+	Phase        Phase                `json:"phase"`
+	Username     string               `json:"username"`
+	Message      string               `json:"message"`
+	Timezone     string               `json:"timezone,omitempty"`
+	Candidates   []timezone.Candidate `json:"candidates,omitempty"`
+}
+
+// ProgressFunc receives progress events. It is called synchronously from the detecting goroutine,
+// so it should return quickly.
+type ProgressFunc func(ProgressEvent)
+
+type progressKey struct{}
+
+// WithProgress registers a detector-wide observer that receives progress events for every detection.
+func WithProgress(fn ProgressFunc) Option {
+	return func(o *OptionHolder) {
+		o.progress = fn
+	}
+}
+
+// DetectWithProgress is like Detect but also reports this detection's progress to fn.
+func (d *Detector) DetectWithProgress(ctx context.Context, username string, fn ProgressFunc) (*Result, error) {
+	if fn != nil {
+		ctx = context.WithValue(ctx, progressKey{}, fn)
+	}
+	return d.Detect(ctx, username)
+}
+
+// report delivers ev, redacted to the detector's privacy level, to the detector-wide observer and to
+// any observer attached to ctx.
+func (d *Detector) report(ctx context.Context, ev ProgressEvent) {
+	fn, _ := ctx.Value(progressKey{}).(ProgressFunc) //nolint:errcheck // absent means no per-call observer
+	if d.progress == nil && fn == nil {
+		return
+	}
+	ev.Time = time.Now()
+	ev = ev.Redact(d.privacy)
+	if d.progress != nil {
+		d.progress(ev)
+	}
+	if fn != nil {
+		fn(ev)
+	}
+}
@@ -0,0 +1,39 @@
+package gutz
+
+import (
+	"context"
+	"testing"
+)
+
+func TestReportDeliversToAllObservers(t *testing.T) {
+	var global, perCall []ProgressEvent
+	holder := &OptionHolder{}
+	WithProgress(func(ev ProgressEvent) { global = append(global, ev) })(holder)
+	d := &Detector{progress: holder.progress}
+
+	d.report(context.Background(), ProgressEvent{Phase: PhaseProfileFetched, Username: "alice"})
+
+	ctx := context.WithValue(context.Background(), progressKey{}, ProgressFunc(func(ev ProgressEvent) {
+		perCall = append(perCall, ev)
+	}))
+	d.report(ctx, ProgressEvent{Phase: PhaseTimelineBuilt, Username: "alice", SourceCounts: map[string]int{"pr": 3}})
+
+	if len(global) != 2 {
+		t.Fatalf("detector-wide observer got %d events, want 2", len(global))
+	}
+	if len(perCall) != 1 || perCall[0].Phase != PhaseTimelineBuilt {
+		t.Fatalf("per-call observer got %+v, want one %s event", perCall, PhaseTimelineBuilt)
+	}
+	if perCall[0].Time.IsZero() {
+		t.Error("event time was not set")
+	}
+	if perCall[0].SourceCounts["pr"] != 3 {
+		t.Errorf("SourceCounts = %v, want pr=3", perCall[0].SourceCounts)
+	}
+}
+
+func TestReportWithoutObservers(t *testing.T) {
+	d := &Detector{}
+	// Must not panic when nobody is listening
+	d.report(context.Background(), ProgressEvent{Phase: PhaseVerification})
+}
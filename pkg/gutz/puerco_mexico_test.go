@@ -39,17 +39,17 @@ func TestPuercoMexicoCityLunchDetection(t *testing.T) {
 	}
 
 	// Test for UTC-6 (Mexico City)
-	offset := -6
+	offset := -6.0
 
 	// Detect lunch
 	lunchStart, lunchEnd, confidence := lunch.DetectLunchBreakNoonCentered(halfHourCounts, offset)
 
 	// Convert to local time
-	lunchStartLocal := lunchStart + float64(offset)
+	lunchStartLocal := lunchStart + offset
 	if lunchStartLocal < 0 {
 		lunchStartLocal += 24
 	}
-	lunchEndLocal := lunchEnd + float64(offset)
+	lunchEndLocal := lunchEnd + offset
 	if lunchEndLocal < 0 {
 		lunchEndLocal += 24
 	}
@@ -0,0 +1,50 @@
+package gutz
+
+import (
+	"context"
+
+	"github.com/codeGROOVE-dev/guTZ/pkg/github"
+)
+
+// RateLimits returns the remaining GitHub budgets of the detector's tokens, one per token and resource
+// (core, graphql, search, ...). Retired tokens are left out.
+func (d *Detector) RateLimits(ctx context.Context) ([]github.RateLimit, error) {
+	if d.tokens == nil {
+		return d.githubClient.RateLimits(ctx)
+	}
+	var budgets []github.RateLimit
+	for _, token := range d.tokens.Active() {
+		b, err := d.githubClient.RateLimits(github.WithToken(ctx, token))
+		if err != nil {
+			return nil, err
+		}
+		budgets = append(budgets, b...)
+	}
+	return budgets, nil
+}
+
+// TokenUsage reports requests, 401s and budgets for each pooled GitHub token, or nil without tokens.
+func (d *Detector) TokenUsage() []github.TokenUsage {
+	if d.tokens == nil {
+		return nil
+	}
+	return d.tokens.Usage()
+}
+
+// RateLimitPolicy returns what the detector does when the GitHub budget runs out.
+func (d *Detector) RateLimitPolicy() github.RateLimitPolicy {
+	return d.rateLimiter.Policy()
+}
+
+// WaitForRateLimit blocks while the GitHub budget is exhausted, under the wait policy.
+// Batch callers use it between users, so that waiting for a reset does not eat into a per-user deadline.
+func (d *Detector) WaitForRateLimit(ctx context.Context) error {
+	if d.rateLimiter.Policy() != github.RateLimitWait {
+		return nil
+	}
+	token := d.githubToken
+	if d.tokens != nil {
+		token = d.tokens.Pick()
+	}
+	return d.rateLimiter.Wait(ctx, token)
+}
@@ -0,0 +1,183 @@
+package gutz
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/codeGROOVE-dev/guTZ/pkg/httpreplay"
+)
+
+// TestDetectReplayIsOffline verifies that a replaying detector never falls through to the network:
+// with an empty archive every fetch fails immediately instead of retrying against live endpoints.
+func TestDetectReplayIsOffline(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "empty.json")
+	if err := httpreplay.NewRecorder().Save(path); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	archive, err := httpreplay.Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	detector := NewWithLogger(ctx, slog.New(slog.DiscardHandler), WithFixtureArchive(archive), WithGitHubToken("test-token"))
+
+	start := time.Now()
+	result, err := detector.Detect(ctx, "octocat")
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Detect took %s with an empty archive; replay misses should not be retried", elapsed)
+	}
+	if err == nil && result != nil && result.Timezone != "" {
+		t.Errorf("Detect found timezone %q with no recorded traffic", result.Timezone)
+	}
+}
+
+var updateFixtures = flag.Bool("update", false, "re-record testdata fixture archives and regenerate docs/result.schema.json")
+
+const goldenArchive = "testdata/replay/mountain.json.gz"
+
+// syntheticGitHub answers GitHub requests for a user who works 9:00-18:00 local time with a noon lunch.
+// It is only used to (re)record the golden archive; the test itself replays the committed file.
+type syntheticGitHub struct {
+	now    time.Time
+	offset int // hours ahead of UTC
+}
+
+func (s syntheticGitHub) RoundTrip(req *http.Request) (*http.Response, error) {
+	respond := func(status int, body any) (*http.Response, error) {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		return &http.Response{
+			StatusCode: status,
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body:       io.NopCloser(bytes.NewReader(data)),
+			Request:    req,
+		}, nil
+	}
+
+	if req.Method != http.MethodPost || req.URL.Path != "/graphql" {
+		return respond(http.StatusNotFound, map[string]string{"message": "Not Found"})
+	}
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.Contains(string(body), "socialAccounts") {
+		return respond(http.StatusNotFound, map[string]string{"message": "Not Found"})
+	}
+
+	type node struct {
+		CreatedAt  time.Time         `json:"createdAt"`
+		UpdatedAt  time.Time         `json:"updatedAt"`
+		Repository map[string]string `json:"repository"`
+		Title      string            `json:"title"`
+		URL        string            `json:"url"`
+		State      string            `json:"state"`
+	}
+	var prs []node
+	day := s.now.UTC().Truncate(24*time.Hour).AddDate(0, 0, -1)
+	for d := range 45 {
+		date := day.AddDate(0, 0, -d)
+		if wd := date.Weekday(); wd == time.Saturday || wd == time.Sunday {
+			continue
+		}
+		// Local minutes past midnight, with nothing between 12:00 and 13:00.
+		for _, local := range []int{540, 570, 600, 630, 645, 660, 690, 780, 810, 825, 840, 870, 900, 930, 960, 990, 1020} {
+			ts := date.Add(time.Duration(local+d%3*5-s.offset*60) * time.Minute)
+			prs = append(prs, node{
+				CreatedAt:  ts,
+				UpdatedAt:  ts,
+				Repository: map[string]string{"nameWithOwner": "octocat/hello-world"},
+				Title:      fmt.Sprintf("Change %d", len(prs)+1),
+				URL:        fmt.Sprintf("https://github.com/octocat/hello-world/pull/%d", len(prs)+1),
+				State:      "MERGED",
+			})
+		}
+	}
+
+	return respond(http.StatusOK, map[string]any{
+		"data": map[string]any{
+			"user": map[string]any{
+				"login":        "octocat",
+				"name":         "The Octocat",
+				"createdAt":    s.now.AddDate(-5, 0, 0),
+				"updatedAt":    s.now,
+				"pullRequests": map[string]any{"nodes": prs, "totalCount": len(prs)},
+			},
+		},
+	})
+}
+
+// recordGoldenArchive records a detection of the synthetic user into path.
+func recordGoldenArchive(t *testing.T, path string) {
+	t.Helper()
+	ctx := context.Background()
+	archive := httpreplay.NewRecorder()
+	detector := NewWithLogger(ctx, slog.New(slog.DiscardHandler), WithFixtureArchive(archive), WithGitHubToken("test-token"))
+	detector.httpClient.Transport = archive.Transport(syntheticGitHub{now: archive.RecordedAt(), offset: -7})
+	if _, err := detector.Detect(ctx, "octocat"); err != nil {
+		t.Fatalf("recording Detect: %v", err)
+	}
+	if err := archive.Save(path); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+}
+
+// TestDetectReplayGolden replays a committed archive through Detect and pins the outcome.
+// The archive's recording time is used as the clock, so the result does not depend on today's date.
+func TestDetectReplayGolden(t *testing.T) {
+	if *updateFixtures {
+		recordGoldenArchive(t, goldenArchive)
+	}
+
+	archive, err := httpreplay.Load(goldenArchive)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if archive.RecordedAt().IsZero() {
+		t.Fatal("golden archive has no recording time")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	detector := NewWithLogger(ctx, slog.New(slog.DiscardHandler), WithFixtureArchive(archive), WithGitHubToken("test-token"))
+
+	result, err := detector.Detect(ctx, "octocat")
+	if err != nil {
+		t.Fatalf("Detect: %v", err)
+	}
+	if result.Timezone != "UTC-7" {
+		t.Errorf("Timezone = %q, want UTC-7", result.Timezone)
+	}
+	if result.SchemaVersion != SchemaVersion {
+		t.Errorf("SchemaVersion = %d, want %d", result.SchemaVersion, SchemaVersion)
+	}
+	if len(result.TimezoneCandidates) < 3 {
+		t.Fatalf("got %d candidates, want at least 3", len(result.TimezoneCandidates))
+	}
+	top := result.TimezoneCandidates[0]
+	if top.Offset != -7 {
+		t.Errorf("top candidate offset = %v, want -7", top.Offset)
+	}
+	if top.LunchLocalTime != 12 {
+		t.Errorf("top candidate lunch = %v, want 12", top.LunchLocalTime)
+	}
+	for _, c := range result.TimezoneCandidates[:3] {
+		if c.Offset < -8 || c.Offset > -6 {
+			t.Errorf("candidate %s (offset %v) is outside UTC-8..UTC-6", c.Timezone, c.Offset)
+		}
+	}
+}
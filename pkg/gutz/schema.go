@@ -0,0 +1,167 @@
+package gutz
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SchemaVersion is the version of the Result JSON encoding, reported in Result.SchemaVersion.
+// It is bumped only when a field is removed, renamed or changes type; new optional fields keep the version.
+const SchemaVersion = 1
+
+// SchemaID identifies the published JSON Schema document for Result (docs/result.schema.json).
+const SchemaID = "https://github.com/codeGROOVE-dev/guTZ/blob/main/docs/result.schema.json"
+
+// resultJSON is Result without its methods, so the custom encoding does not recurse.
+type resultJSON Result
+
+// MarshalJSON encodes the result. JSON has no float map keys, so the half-hourly histogram is keyed "0.0", "0.5", ...
+func (r *Result) MarshalJSON() ([]byte, error) {
+	var halfHourly map[string]int
+	if r.HalfHourlyActivityUTC != nil {
+		halfHourly = make(map[string]int, len(r.HalfHourlyActivityUTC))
+		for bucket, count := range r.HalfHourlyActivityUTC {
+			halfHourly[strconv.FormatFloat(bucket, 'f', 1, 64)] = count
+		}
+	}
+	return json.Marshal(struct {
+		*resultJSON
+
+		HalfHourlyActivityUTC map[string]int `json:"half_hourly_activity_utc,omitempty"`
+	}{(*resultJSON)(r), halfHourly})
+}
+
+// UnmarshalJSON decodes a result encoded by MarshalJSON.
+func (r *Result) UnmarshalJSON(data []byte) error {
+	aux := struct {
+		*resultJSON
+
+		HalfHourlyActivityUTC map[string]int `json:"half_hourly_activity_utc,omitempty"`
+	}{resultJSON: (*resultJSON)(r)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	r.HalfHourlyActivityUTC = nil
+	if aux.HalfHourlyActivityUTC != nil {
+		r.HalfHourlyActivityUTC = make(map[float64]int, len(aux.HalfHourlyActivityUTC))
+		for key, count := range aux.HalfHourlyActivityUTC {
+			bucket, err := strconv.ParseFloat(key, 64)
+			if err != nil {
+				return fmt.Errorf("half_hourly_activity_utc key %q: %w", key, err)
+			}
+			r.HalfHourlyActivityUTC[bucket] = count
+		}
+	}
+	return nil
+}
+
+// JSONSchema returns the JSON Schema (draft 2020-12) for an encoded Result.
+// The published copy lives in docs/result.schema.json and is kept in sync by the package tests.
+func JSONSchema() ([]byte, error) {
+	g := schemaGenerator{defs: map[string]any{}}
+	root := g.object(reflect.TypeFor[Result]())
+	properties, ok := root["properties"].(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("result schema has no properties")
+	}
+	properties["schema_version"] = map[string]any{"type": "integer", "const": SchemaVersion}
+	properties["half_hourly_activity_utc"] = map[string]any{
+		"type":                 "object",
+		"description":          "Events per UTC half hour, keyed \"0.0\" through \"23.5\"",
+		"propertyNames":        map[string]any{"pattern": `^([0-9]|1[0-9]|2[0-3])\.[05]$`},
+		"additionalProperties": map[string]any{"type": "integer"},
+	}
+	root["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	root["$id"] = SchemaID
+	root["title"] = "guTZ detection result"
+	root["$defs"] = g.defs
+	return json.MarshalIndent(root, "", "  ")
+}
+
+// schemaGenerator derives JSON Schema from Go types, following encoding/json's field rules.
+type schemaGenerator struct {
+	defs map[string]any
+}
+
+// schema returns the schema for t. Nullable reports whether a zero value encodes as null.
+func (g *schemaGenerator) schema(t reflect.Type, nullable bool) map[string]any {
+	if t == reflect.TypeFor[time.Time]() {
+		return map[string]any{"type": "string", "format": "date-time"}
+	}
+	var s map[string]any
+	switch t.Kind() {
+	case reflect.Pointer:
+		return g.schema(t.Elem(), nullable)
+	case reflect.Bool:
+		s = map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		s = map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		s = map[string]any{"type": "number"}
+	case reflect.String:
+		s = map[string]any{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		s = map[string]any{"type": "array", "items": g.schema(t.Elem(), false)}
+	case reflect.Map:
+		s = map[string]any{"type": "object", "additionalProperties": g.schema(t.Elem(), false)}
+		switch t.Key().Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			s["propertyNames"] = map[string]any{"pattern": "^-?[0-9]+$"}
+		default:
+		}
+	case reflect.Struct:
+		name := t.Name()
+		if _, ok := g.defs[name]; !ok {
+			g.defs[name] = nil // Reserve the name so recursive types terminate
+			g.defs[name] = g.object(t)
+		}
+		s = map[string]any{"$ref": "#/$defs/" + name}
+	default:
+		s = map[string]any{}
+	}
+	if !nullable {
+		return s
+	}
+	if typ, ok := s["type"].(string); ok {
+		s["type"] = []string{typ, "null"}
+		return s
+	}
+	return map[string]any{"anyOf": []any{s, map[string]any{"type": "null"}}}
+}
+
+// object returns the schema for a struct's encoded fields.
+func (g *schemaGenerator) object(t reflect.Type) map[string]any {
+	properties := map[string]any{}
+	required := []string{}
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = field.Name
+		}
+		omitEmpty := strings.Contains(opts, "omitempty")
+		kind := field.Type.Kind()
+		nullable := !omitEmpty && (kind == reflect.Pointer || kind == reflect.Slice || kind == reflect.Map)
+		properties[name] = g.schema(field.Type, nullable)
+		if !omitEmpty {
+			required = append(required, name)
+		}
+	}
+	s := map[string]any{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		s["required"] = required
+	}
+	return s
+}
@@ -0,0 +1,127 @@
+package gutz
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+const publishedSchema = "../../docs/result.schema.json"
+
+// TestJSONSchemaDocument keeps the published schema in step with Result. Run with -update to regenerate it.
+func TestJSONSchemaDocument(t *testing.T) {
+	got, err := JSONSchema()
+	if err != nil {
+		t.Fatalf("JSONSchema: %v", err)
+	}
+	got = append(got, '\n')
+	if *updateFixtures {
+		if err := os.WriteFile(publishedSchema, got, 0o600); err != nil {
+			t.Fatalf("write schema: %v", err)
+		}
+	}
+	want, err := os.ReadFile(publishedSchema)
+	if err != nil {
+		t.Fatalf("read published schema: %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s is out of date; run go test ./pkg/gutz -run TestJSONSchemaDocument -update", publishedSchema)
+	}
+}
+
+// TestResultV1Compat decodes a frozen version 1 response and re-encodes it.
+// A renamed, removed or retyped field changes the round trip and fails here; such a change needs a new SchemaVersion.
+func TestResultV1Compat(t *testing.T) {
+	data, err := os.ReadFile("testdata/result_v1.json")
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+
+	var result Result
+	if err := json.Unmarshal(data, &result); err != nil {
+		t.Fatalf("decode v1 result: %v", err)
+	}
+	if result.SchemaVersion != 1 {
+		t.Errorf("SchemaVersion = %d, want 1", result.SchemaVersion)
+	}
+	if result.HalfHourlyActivityUTC[15.5] != 3 {
+		t.Errorf("HalfHourlyActivityUTC[15.5] = %d, want 3", result.HalfHourlyActivityUTC[15.5])
+	}
+	if result.LunchHoursLocal.Start != 12 || result.ActiveHoursUTC.End != 1 {
+		t.Errorf("lunch %+v, active %+v", result.LunchHoursLocal, result.ActiveHoursUTC)
+	}
+	if len(result.TimezoneCandidates) != 1 || result.TimezoneCandidates[0].Offset != -7 {
+		t.Errorf("TimezoneCandidates = %+v", result.TimezoneCandidates)
+	}
+
+	encoded, err := json.Marshal(&result)
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	var before, after map[string]any
+	if err := json.Unmarshal(data, &before); err != nil {
+		t.Fatalf("decode fixture: %v", err)
+	}
+	if err := json.Unmarshal(encoded, &after); err != nil {
+		t.Fatalf("decode re-encoded result: %v", err)
+	}
+	for key, want := range before {
+		if got, ok := after[key]; !ok {
+			t.Errorf("field %q is no longer encoded", key)
+		} else if !reflect.DeepEqual(got, want) {
+			t.Errorf("field %q = %v, want %v", key, got, want)
+		}
+	}
+}
+
+// TestResultMatchesSchema checks an encoded result against the top level of the schema:
+// every field is documented and every required field is present.
+func TestResultMatchesSchema(t *testing.T) {
+	data, err := os.ReadFile("testdata/result_v1.json")
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	var result Result
+	if err := json.Unmarshal(data, &result); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	result.SchemaVersion = SchemaVersion
+	encoded, err := json.Marshal(&result)
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(encoded, &fields); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	raw, err := JSONSchema()
+	if err != nil {
+		t.Fatalf("JSONSchema: %v", err)
+	}
+	var schema struct {
+		Properties map[string]json.RawMessage `json:"properties"`
+		Required   []string                   `json:"required"`
+	}
+	if err := json.Unmarshal(raw, &schema); err != nil {
+		t.Fatalf("decode schema: %v", err)
+	}
+	for key := range fields {
+		if _, ok := schema.Properties[key]; !ok {
+			t.Errorf("encoded field %q is missing from the schema", key)
+		}
+	}
+	for _, key := range schema.Required {
+		if _, ok := fields[key]; !ok {
+			t.Errorf("required field %q is not encoded", key)
+		}
+	}
+	for _, key := range []string{"username", "timezone", "method", "confidence", "schema_version"} {
+		if !slices.Contains(schema.Required, key) {
+			t.Errorf("schema does not require %q", key)
+		}
+	}
+}
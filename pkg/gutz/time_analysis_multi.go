@@ -80,13 +80,13 @@ func findAllActivityPeriods(halfHourlyActivityUTC map[float64]int) []ActivityPer
 }
 
 // ClassifyActivityPeriods converts UTC periods to local time and classifies them.
-func ClassifyActivityPeriods(halfHourlyActivityUTC map[float64]int, offsetHours int) []ActivityPeriod {
+func ClassifyActivityPeriods(halfHourlyActivityUTC map[float64]int, offsetHours float64) []ActivityPeriod {
 	periods := findAllActivityPeriods(halfHourlyActivityUTC)
 
 	classified := make([]ActivityPeriod, 0, len(periods))
 	for _, p := range periods {
-		localStart := normalizeHour(p.StartUTC + float64(offsetHours))
-		localEnd := normalizeHour(p.EndUTC + float64(offsetHours))
+		localStart := normalizeHour(p.StartUTC + offsetHours)
+		localEnd := normalizeHour(p.EndUTC + offsetHours)
 
 		// Classify the period based on local time
 		var periodType string
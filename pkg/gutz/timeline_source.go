@@ -0,0 +1,99 @@
+package gutz
+
+import (
+	"context"
+	"math"
+)
+
+// TimelineSource contributes activity timestamps that GitHub does not have, such as reviews on an
+// internal Gerrit, comments in Jira or pushes to a GitLab instance, to every detection's timeline.
+type TimelineSource interface {
+	// Name identifies the source in logs, progress events and weights. Entries returned without a
+	// Source are attributed to it.
+	Name() string
+	// Timeline returns the user's activity. userCtx holds what was already fetched from GitHub, so a
+	// source can map the GitHub account to its own, for instance by the profile's email address.
+	Timeline(ctx context.Context, userCtx *UserContext) ([]TimelineEntry, error)
+}
+
+// WithTimelineSource adds src to the sources every detection collects activity from, counting each of
+// its timestamps weight times in the hourly buckets. See WithSourceWeight.
+func WithTimelineSource(src TimelineSource, weight float64) Option {
+	return func(o *OptionHolder) {
+		o.timelineSources = append(o.timelineSources, src)
+		WithSourceWeight(src.Name(), weight)(o)
+	}
+}
+
+// WithSourceWeight sets how much one timestamp from source counts in the hourly activity buckets;
+// sources default to 1. The source is a TimelineSource name or a built-in one such as "event", "pr",
+// "issue", "comment", "gist", "star" or "commit". A weight of 0 or less leaves the source out.
+func WithSourceWeight(source string, weight float64) Option {
+	return func(o *OptionHolder) {
+		if o.sourceWeights == nil {
+			o.sourceWeights = make(map[string]float64)
+		}
+		o.sourceWeights[source] = weight
+	}
+}
+
+// sourceWeight returns how much one timestamp from source counts.
+func (d *Detector) sourceWeight(source string) float64 {
+	if w, ok := d.sourceWeights[source]; ok {
+		return w
+	}
+	return 1
+}
+
+// collectTimelineSources gathers the activity of every registered TimelineSource. A failing source is
+// logged and skipped so that it never blocks a detection.
+func (d *Detector) collectTimelineSources(ctx context.Context, userCtx *UserContext) []TimelineEntry {
+	var entries []TimelineEntry
+	for _, src := range d.sources {
+		name := src.Name()
+		if d.sourceWeight(name) <= 0 {
+			continue
+		}
+		got, err := src.Timeline(ctx, userCtx)
+		if err != nil {
+			d.logger.Warn("timeline source failed", "username", userCtx.Username, "source", name, "error", err)
+			continue
+		}
+		for i := range got {
+			if got[i].Source == "" {
+				got[i].Source = name
+			}
+		}
+		if len(got) > 0 {
+			d.logger.Info("🧩 Added timeline source", "username", userCtx.Username, "source", name, "count", len(got))
+		}
+		entries = append(entries, got...)
+	}
+	return entries
+}
+
+// dropUnweighted removes entries whose source has a weight of 0 or less.
+func (d *Detector) dropUnweighted(entries []TimelineEntry) []TimelineEntry {
+	if len(d.sourceWeights) == 0 {
+		return entries
+	}
+	kept := entries[:0]
+	for _, e := range entries {
+		if d.sourceWeight(e.Source) > 0 {
+			kept = append(kept, e)
+		}
+	}
+	return kept
+}
+
+// weightedCounts rounds weighted bucket sums to the whole counts the analysis works with.
+// With every weight at 1 the sums are already whole and nothing changes.
+func weightedCounts[K comparable](sums map[K]float64) map[K]int {
+	counts := make(map[K]int, len(sums))
+	for k, v := range sums {
+		if n := int(math.Round(v)); n > 0 {
+			counts[k] = n
+		}
+	}
+	return counts
+}
@@ -0,0 +1,63 @@
+package gutz
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+type fakeSource struct {
+	err     error
+	name    string
+	entries []TimelineEntry
+}
+
+func (f fakeSource) Name() string { return f.name }
+
+func (f fakeSource) Timeline(context.Context, *UserContext) ([]TimelineEntry, error) {
+	return f.entries, f.err
+}
+
+func TestTimelineSources(t *testing.T) {
+	at := time.Date(2025, 3, 4, 9, 15, 0, 0, time.UTC)
+	holder := &OptionHolder{}
+	for _, opt := range []Option{
+		WithTimelineSource(fakeSource{name: "gerrit", entries: []TimelineEntry{
+			{Time: at, Org: "acme"},
+			{Time: at.Add(time.Hour), Source: "gerrit-review"},
+		}}, 2),
+		WithTimelineSource(fakeSource{name: "jira", err: errors.New("unreachable")}, 1),
+		WithTimelineSource(fakeSource{name: "wiki", entries: []TimelineEntry{{Time: at}}}, 0),
+		WithSourceWeight("gerrit-review", 0),
+	} {
+		opt(holder)
+	}
+	d := &Detector{
+		logger:        slog.New(slog.DiscardHandler),
+		sources:       holder.timelineSources,
+		sourceWeights: holder.sourceWeights,
+	}
+
+	entries, orgs := d.collectActivityTimestampsWithContext(context.Background(), &UserContext{Username: "alice"})
+	if len(entries) != 1 || entries[0].Source != "gerrit" || !entries[0].Time.Equal(at) {
+		t.Fatalf("timeline = %+v, want the one weighted gerrit entry attributed to its source", entries)
+	}
+	if orgs["acme"] != 1 {
+		t.Errorf("org counts = %v, want acme counted", orgs)
+	}
+	if w := d.sourceWeight("gerrit"); w != 2 {
+		t.Errorf("gerrit weight = %v, want 2", w)
+	}
+	if w := d.sourceWeight("pr"); w != 1 {
+		t.Errorf("built-in weight = %v, want the default of 1", w)
+	}
+}
+
+func TestWeightedCounts(t *testing.T) {
+	got := weightedCounts(map[int]float64{9: 3, 10: 1.5, 11: 0.25})
+	if len(got) != 2 || got[9] != 3 || got[10] != 2 {
+		t.Errorf("weightedCounts = %v, want map[9:3 10:2]", got)
+	}
+}
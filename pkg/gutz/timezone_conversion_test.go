@@ -3,6 +3,7 @@ package gutz
 import (
 	"testing"
 
+	"github.com/codeGROOVE-dev/guTZ/pkg/timezone"
 	"github.com/codeGROOVE-dev/guTZ/pkg/tzconvert"
 )
 
@@ -12,10 +13,10 @@ import (
 func TestTimezoneConversionConsistency(t *testing.T) {
 	tests := []struct {
 		name                     string
-		activityTimezone         string // Timezone from activity analysis
-		activityOffset           int    // Offset from activity analysis
-		finalTimezone            string // Final detected timezone (e.g., from location)
-		finalOffset              int    // Final timezone offset
+		activityTimezone         string  // Timezone from activity analysis
+		activityOffset           float64 // Offset from activity analysis
+		finalTimezone            string  // Final detected timezone (e.g., from location)
+		finalOffset              float64 // Final timezone offset
 		activeStartUTC           float64
 		activeEndUTC             float64
 		peakStartUTC             float64
@@ -156,3 +157,39 @@ func TestMergeActivityDataRecalculation(t *testing.T) {
 
 	t.Skip("Integration test - requires full Detector setup")
 }
+
+// TestSeasonalZone verifies that activity-only offsets are named only when the seasonal analysis agrees.
+func TestSeasonalZone(t *testing.T) {
+	denver := &timezone.SeasonalAnalysis{
+		Regime: timezone.DSTUS, StandardOffset: -7, ObservesDST: true, Confidence: 1,
+		SuggestedZones: []string{"America/Denver"},
+	}
+	phoenix := &timezone.SeasonalAnalysis{
+		Regime: timezone.DSTNone, StandardOffset: -7, Confidence: 1,
+		SuggestedZones: []string{"America/Phoenix"},
+	}
+	weak := *denver
+	weak.Confidence = 0.2
+
+	tests := []struct {
+		name     string
+		timezone string
+		seasonal *timezone.SeasonalAnalysis
+		want     string
+	}{
+		{"DST observer at standard offset", "UTC-7", denver, "America/Denver"},
+		{"DST observer at daylight offset", "UTC-6", denver, "America/Denver"},
+		{"no DST at standard offset", "UTC-7", phoenix, "America/Phoenix"},
+		{"no DST disagrees with offset", "UTC-6", phoenix, ""},
+		{"weak analysis", "UTC-7", &weak, ""},
+		{"already named", "America/Boise", denver, ""},
+		{"no analysis", "UTC-7", nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := seasonalZone(&Result{Timezone: tt.timezone, Seasonal: tt.seasonal}); got != tt.want {
+				t.Errorf("seasonalZone() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,40 +1,38 @@
 package gutz
 
 import (
-	"fmt"
 	"strings"
-	"time"
-)
-
-// offsetFromNamedTimezone converts IANA timezone name to current UTC offset.
-func offsetFromNamedTimezone(tzName string) int {
-	// Handle UTC offset strings like "UTC-4" or "UTC+8"
-	if strings.HasPrefix(tzName, "UTC") {
-		var offset int
-		if n, err := fmt.Sscanf(tzName, "UTC%d", &offset); n == 1 && err == nil {
-			return offset
-		}
-	}
 
-	// Load the IANA timezone location
-	loc, err := time.LoadLocation(tzName)
-	if err != nil {
-		// Default to 0 if we can't parse the timezone
-		return 0
-	}
+	"github.com/codeGROOVE-dev/guTZ/pkg/tzconvert"
+)
 
-	// Get current offset for this timezone
-	now := time.Now()
-	_, offset := now.In(loc).Zone()
-	return offset / 3600 // Convert seconds to hours
+// offsetFromNamedTimezone converts a timezone name ("UTC-4", "UTC+5.5" or an IANA name)
+// to its current UTC offset in hours.
+func offsetFromNamedTimezone(tzName string) float64 {
+	return tzconvert.ParseTimezoneOffset(tzName)
 }
 
 // timezoneFromOffset converts a UTC offset to a timezone string.
-func timezoneFromOffset(offsetHours int) string {
+func timezoneFromOffset(offsetHours float64) string {
 	// Return generic UTC offset format since we don't know the country at this stage
 	// This is used for activity-only detection where location is unknown
-	if offsetHours >= 0 {
-		return fmt.Sprintf("UTC+%d", offsetHours)
+	return tzconvert.FormatOffset(offsetHours)
+}
+
+// seasonalZone names the IANA zone implied by a result's seasonal DST analysis, or returns "" when the
+// analysis is weak or disagrees with the activity offset. The activity offset comes from year-round data,
+// so for DST observers it may land on either the standard or the daylight offset.
+func seasonalZone(result *Result) string {
+	seasonal := result.Seasonal
+	if seasonal == nil || seasonal.Confidence < 0.5 || len(seasonal.SuggestedZones) == 0 {
+		return ""
+	}
+	if !strings.HasPrefix(result.Timezone, "UTC") {
+		return "" // Already named by a stronger signal
+	}
+	offset := offsetFromNamedTimezone(result.Timezone)
+	if offset == seasonal.StandardOffset || (seasonal.ObservesDST && offset == seasonal.StandardOffset+1) {
+		return seasonal.SuggestedZones[0]
 	}
-	return fmt.Sprintf("UTC%d", offsetHours) // Negative sign is already included
+	return ""
 }
@@ -32,13 +32,13 @@ func TestTstrombergRealData(t *testing.T) {
 	halfHourCounts[17.0] = 2 // Low activity at 1:00pm EDT
 
 	t.Run("Eastern Time (UTC-4 for summer)", func(t *testing.T) {
-		utcOffset := -4
+		utcOffset := -4.0
 
 		// Test lunch detection
 		lunchStart, lunchEnd, lunchConf := lunch.DetectLunchBreakNoonCentered(halfHourCounts, utcOffset)
 
 		// Convert UTC lunch to local time for verification
-		lunchStartLocal := lunchStart + float64(utcOffset)
+		lunchStartLocal := lunchStart + utcOffset
 		if lunchStartLocal < 0 {
 			lunchStartLocal += 24
 		}
@@ -97,7 +97,7 @@ func TestLunchDetectionWithRealPatterns(t *testing.T) {
 		name          string
 		description   string
 		halfHourData  map[float64]int
-		utcOffset     int
+		utcOffset     float64
 		expectedLunch float64 // Expected lunch start in local time
 	}{
 		{
@@ -134,7 +134,7 @@ func TestLunchDetectionWithRealPatterns(t *testing.T) {
 				tt.halfHourData, tt.utcOffset)
 
 			// Convert UTC lunch to local time
-			lunchStartLocal := lunchStart + float64(tt.utcOffset)
+			lunchStartLocal := lunchStart + tt.utcOffset
 			if lunchStartLocal < 0 {
 				lunchStartLocal += 24
 			}
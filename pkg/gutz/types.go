@@ -7,7 +7,11 @@ import (
 	"strings"
 	"time"
 
+	"github.com/codeGROOVE-dev/guTZ/pkg/geocode"
 	"github.com/codeGROOVE-dev/guTZ/pkg/github"
+	"github.com/codeGROOVE-dev/guTZ/pkg/httpreplay"
+	"github.com/codeGROOVE-dev/guTZ/pkg/llm"
+	"github.com/codeGROOVE-dev/guTZ/pkg/optout"
 	"github.com/codeGROOVE-dev/guTZ/pkg/timezone"
 )
 
@@ -21,6 +25,22 @@ func WithGitHubToken(token string) Option {
 	}
 }
 
+// WithGitHubTokens adds tokens to a pool that API requests are spread over. Each detection uses the token
+// with the most remaining budget, and tokens that GitHub rejects with 401 Unauthorized are retired.
+func WithGitHubTokens(tokens ...string) Option {
+	return func(o *OptionHolder) {
+		o.githubTokens = append(o.githubTokens, tokens...)
+	}
+}
+
+// WithGitHubBaseURLs points the Detector at a GitHub Enterprise Server instead of github.com.
+// Use github.EnterpriseBaseURLs for the standard layout; empty fields keep the github.com endpoints.
+func WithGitHubBaseURLs(urls github.BaseURLs) Option {
+	return func(o *OptionHolder) {
+		o.githubBaseURLs = urls
+	}
+}
+
 // WithMapsAPIKey sets the Google Maps API key for geocoding services.
 func WithMapsAPIKey(key string) Option {
 	return func(o *OptionHolder) {
@@ -28,6 +48,22 @@ func WithMapsAPIKey(key string) Option {
 	}
 }
 
+// WithRateLimitPolicy sets what happens when the GitHub token's budget runs out: wait for the reset
+// (the default, bounded by the context deadline) or fail at once with a *github.RateLimitError.
+func WithRateLimitPolicy(policy github.RateLimitPolicy) Option {
+	return func(o *OptionHolder) {
+		o.rateLimitPolicy = policy
+	}
+}
+
+// WithGeocoder replaces the default geocoder, which uses Google Maps when a key is set
+// and falls back to the embedded offline gazetteer.
+func WithGeocoder(g geocode.Geocoder) Option {
+	return func(o *OptionHolder) {
+		o.geocoder = g
+	}
+}
+
 // WithGeminiAPIKey sets the Gemini API key for AI-based timezone detection.
 func WithGeminiAPIKey(key string) Option {
 	return func(o *OptionHolder) {
@@ -49,6 +85,21 @@ func WithGCPProject(projectID string) Option {
 	}
 }
 
+// WithLLM selects the language model backend used for AI-based detection.
+// For the Gemini backend, unset fields fall back to WithGeminiAPIKey, WithGeminiModel and WithGCPProject.
+func WithLLM(cfg llm.Config) Option {
+	return func(o *OptionHolder) {
+		o.llmConfig = cfg
+	}
+}
+
+// WithLLMProvider sets a custom language model provider, overriding WithLLM.
+func WithLLMProvider(provider llm.Provider) Option {
+	return func(o *OptionHolder) {
+		o.llmProvider = provider
+	}
+}
+
 // WithActivityAnalysis enables or disables activity analysis.
 func WithActivityAnalysis(enabled bool) Option {
 	return func(o *OptionHolder) {
@@ -63,6 +114,15 @@ func WithCacheDir(dir string) Option {
 	}
 }
 
+// WithCacheStore persists the HTTP cache in the store described by spec (see httpcache.OpenStorage),
+// such as sqlite:/var/cache/gutz/httpcache.db or redis://cache:6379/0. It takes precedence over
+// WithCacheDir and WithMemoryOnlyCache.
+func WithCacheStore(spec string) Option {
+	return func(o *OptionHolder) {
+		o.cacheStore = spec
+	}
+}
+
 // WithMemoryOnlyCache configures the detector to use memory-only HTTP caching.
 func WithMemoryOnlyCache() Option {
 	return func(o *OptionHolder) {
@@ -77,16 +137,42 @@ func WithNoCache() Option {
 	}
 }
 
+// WithFixtureArchive routes all outbound traffic through a fixture archive.
+// A recording archive captures every exchange; a replaying archive serves them back with no network access.
+// The HTTP cache is bypassed so that recordings are complete and replays are deterministic.
+func WithFixtureArchive(archive *httpreplay.Archive) Option {
+	return func(o *OptionHolder) {
+		o.archive = archive
+	}
+}
+
 // OptionHolder holds configuration options.
 type OptionHolder struct {
 	githubToken     string
+	githubTokens    []string
 	mapsAPIKey      string
 	geminiAPIKey    string
 	geminiModel     string
 	gcpProject      string
 	cacheDir        string
+	cacheStore      string
 	forceActivity   bool
 	memoryOnlyCache bool
+	archive         *httpreplay.Archive
+	llmProvider     llm.Provider
+	geocoder        geocode.Geocoder
+	githubBaseURLs  github.BaseURLs
+	rateLimitPolicy github.RateLimitPolicy
+	llmConfig       llm.Config
+	progress        ProgressFunc
+	timelineSources []TimelineSource
+	sourceWeights   map[string]float64
+	gitlabURL       string
+	gitlabToken     string
+	giteaURL        string
+	giteaToken      string
+	optOut          *optout.Registry
+	privacy         PrivacyLevel
 	noCache         bool // Explicitly disable all caching
 }
 
@@ -343,8 +429,8 @@ func convertUTCToLocalFloat(utcHour float64, tz string) float64 {
 		if offsetStr == "" {
 			return utcHour // UTC+0
 		}
-		if offset, err := strconv.Atoi(offsetStr); err == nil {
-			return math.Mod(utcHour+float64(offset)+24, 24)
+		if offset, err := strconv.ParseFloat(offsetStr, 64); err == nil {
+			return math.Mod(utcHour+offset+24, 24)
 		}
 	}
 	return utcHour // Default to UTC if parsing fails
@@ -397,44 +483,48 @@ type OrgActivity struct {
 }
 
 // Result represents timezone detection results.
+// Its JSON encoding is versioned by SchemaVersion and described by JSONSchema.
 type Result struct {
-	DetectionTime              time.Time              `json:"detection_time"`
-	Verification               *VerificationResult    `json:"verification,omitempty"`
-	CreatedAt                  *time.Time             `json:"created_at,omitempty"`
-	HourlyOrganizationActivity map[int]map[string]int `json:"hourly_organization_activity,omitempty"`
-	HalfHourlyActivityUTC      map[float64]int        `json:"half_hourly_activity_utc,omitempty"`
-	Location                   *Location              `json:"location,omitempty"`
-	Name                       string                 `json:"name,omitempty"`
-	GeminiReasoning            string                 `json:"gemini_reasoning,omitempty"`
-	GeminiSuggestedLocation    string                 `json:"gemini_suggested_location,omitempty"`
-	Username                   string                 `json:"username"`
-	Timezone                   string                 `json:"timezone"`
-	LocationName               string                 `json:"location_name,omitempty"`
-	ActivityTimezone           string                 `json:"activity_timezone,omitempty"`
-	GeminiPrompt               string                 `json:"gemini_prompt,omitempty"`
-	Method                     string                 `json:"method"`
-	GeminiMismatchReason       string                 `json:"gemini_mismatch_reason,omitempty"`
-	ActivityDateRange          DateRange              `json:"activity_date_range,omitempty"`
-	SleepHoursUTC              []int                  `json:"sleep_hours_utc,omitempty"`
-	ActivityPeriods            []ActivityPeriod       `json:"activity_periods,omitempty"`
-	TopOrganizations           []OrgActivity          `json:"top_organizations"`
-	TimezoneCandidates         []timezone.Candidate   `json:"timezone_candidates,omitempty"`
-	DataSources                []string               `json:"data_sources,omitempty"`
-	SleepRangesLocal           []SleepRange           `json:"sleep_ranges_local,omitempty"`
-	SleepBucketsUTC            []float64              `json:"sleep_buckets_utc,omitempty"`
-	Timeline                   []timestampEntry       `json:"-"`
-	PeakProductivityLocal      PeakTime               `json:"peak_productivity_local"`
-	PeakProductivityUTC        PeakTime               `json:"peak_productivity_utc"`
-	LunchHoursUTC              LunchBreak             `json:"lunch_hours_utc,omitempty"`
-	LunchHoursLocal            LunchBreak             `json:"lunch_hours_local,omitempty"`
-	ActiveHoursLocal           ActiveHours            `json:"active_hours_local,omitempty"`
-	ActiveHoursUTC             ActiveHours            `json:"active_hours_utc,omitempty"`
-	LocationConfidence         float64                `json:"location_confidence,omitempty"`
-	TimezoneConfidence         float64                `json:"timezone_confidence,omitempty"`
-	Confidence                 float64                `json:"confidence"`
-	GeminiActivityOffsetHours  float64                `json:"gemini_activity_offset_hours,omitempty"`
-	GeminiActivityMismatch     bool                   `json:"gemini_activity_mismatch,omitempty"`
-	GeminiSuspiciousMismatch   bool                   `json:"gemini_suspicious_mismatch,omitempty"`
+	DetectionTime              time.Time                  `json:"detection_time"`
+	Verification               *VerificationResult        `json:"verification,omitempty"`
+	Seasonal                   *timezone.SeasonalAnalysis `json:"seasonal,omitempty"` // Daylight saving behavior inferred from winter vs summer activity
+	CreatedAt                  *time.Time                 `json:"created_at,omitempty"`
+	HourlyOrganizationActivity map[int]map[string]int     `json:"hourly_organization_activity,omitempty"`
+	HalfHourlyActivityUTC      map[float64]int            `json:"half_hourly_activity_utc,omitempty"`
+	Location                   *Location                  `json:"location,omitempty"`
+	Name                       string                     `json:"name,omitempty"`
+	GeminiReasoning            string                     `json:"gemini_reasoning,omitempty"`
+	GeminiSuggestedLocation    string                     `json:"gemini_suggested_location,omitempty"`
+	Username                   string                     `json:"username"`
+	Timezone                   string                     `json:"timezone"`
+	LocationName               string                     `json:"location_name,omitempty"`
+	ActivityTimezone           string                     `json:"activity_timezone,omitempty"`
+	GeminiPrompt               string                     `json:"gemini_prompt,omitempty"`
+	Method                     string                     `json:"method"`
+	GeminiMismatchReason       string                     `json:"gemini_mismatch_reason,omitempty"`
+	ActivityDateRange          DateRange                  `json:"activity_date_range,omitempty"`
+	SleepHoursUTC              []int                      `json:"sleep_hours_utc,omitempty"`
+	ActivityPeriods            []ActivityPeriod           `json:"activity_periods,omitempty"`
+	TopOrganizations           []OrgActivity              `json:"top_organizations"`
+	TimezoneCandidates         []timezone.Candidate       `json:"timezone_candidates,omitempty"`
+	DataSources                []string                   `json:"data_sources,omitempty"`
+	Linked                     []string                   `json:"linked,omitempty"` // Accounts on other forges, as forge:username, whose activity joined the timeline
+	SleepRangesLocal           []SleepRange               `json:"sleep_ranges_local,omitempty"`
+	SleepBucketsUTC            []float64                  `json:"sleep_buckets_utc,omitempty"`
+	Timeline                   []TimelineEntry            `json:"-"`
+	PeakProductivityLocal      PeakTime                   `json:"peak_productivity_local"`
+	PeakProductivityUTC        PeakTime                   `json:"peak_productivity_utc"`
+	LunchHoursUTC              LunchBreak                 `json:"lunch_hours_utc,omitempty"`
+	LunchHoursLocal            LunchBreak                 `json:"lunch_hours_local,omitempty"`
+	ActiveHoursLocal           ActiveHours                `json:"active_hours_local,omitempty"`
+	ActiveHoursUTC             ActiveHours                `json:"active_hours_utc,omitempty"`
+	SchemaVersion              int                        `json:"schema_version"`
+	LocationConfidence         float64                    `json:"location_confidence,omitempty"`
+	TimezoneConfidence         float64                    `json:"timezone_confidence,omitempty"`
+	Confidence                 float64                    `json:"confidence"`
+	GeminiActivityOffsetHours  float64                    `json:"gemini_activity_offset_hours,omitempty"`
+	GeminiActivityMismatch     bool                       `json:"gemini_activity_mismatch,omitempty"`
+	GeminiSuspiciousMismatch   bool                       `json:"gemini_suspicious_mismatch,omitempty"`
 }
 
 // Location represents geographic coordinates.
@@ -0,0 +1,68 @@
+package gutz
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/codeGROOVE-dev/guTZ/pkg/github"
+)
+
+// SourcePrivate is the timeline source of the private contributions added by ViewerContext. Its
+// entries are bare timestamps; see WithSourceWeight to weigh them.
+const SourcePrivate = "private"
+
+type viewerKey struct{}
+
+// viewerTimeline is the private activity of the user a detection context was made for.
+type viewerTimeline struct {
+	login   string
+	entries []TimelineEntry
+}
+
+// ViewerContext looks up the user token belongs to and returns their username with a context whose
+// detection of them also counts the pull requests, reviews, issues and commits in private repositories
+// that only their own token can see. Private contributions join the timeline as timestamps alone:
+// repository names, titles and URLs are dropped and they count towards no organization. The token is
+// used for these queries only, which skip the shared cache and token pool, and the detected Result
+// must only be shown to its owner.
+func (d *Detector) ViewerContext(ctx context.Context, token string) (context.Context, string, error) {
+	client := github.NewGraphQLClient(token, d.viewerHTTPDo, d.logger)
+	client.SetEndpoint(d.githubClient.BaseURLs().GraphQL)
+
+	// A year is the longest span GitHub reports contributions for
+	activity, err := client.FetchViewerActivity(ctx, d.now().AddDate(-1, 0, 1))
+	if err != nil {
+		return ctx, "", fmt.Errorf("fetching your GitHub activity: %w", err)
+	}
+
+	var entries []TimelineEntry
+	for _, c := range activity.Contributions {
+		// Public contributions are already collected from the public APIs
+		if c.Private {
+			entries = append(entries, TimelineEntry{Time: c.Time, Source: SourcePrivate, Title: c.Kind})
+		}
+	}
+	d.logger.Info("fetched private contributions", "username", activity.Login, "count", len(entries))
+	return context.WithValue(ctx, viewerKey{}, viewerTimeline{login: activity.Login, entries: entries}), activity.Login, nil
+}
+
+// viewerHTTPDo sends a request signed with a user's own token. It goes around the cache, whose keys
+// ignore the token, and around the token pool, which would re-sign the request.
+func (d *Detector) viewerHTTPDo(_ context.Context, req *http.Request) (*http.Response, error) {
+	return d.viewerClient.Do(req)
+}
+
+// viewerSource adds the private activity carried by a ViewerContext to the detection of its user.
+type viewerSource struct{}
+
+func (viewerSource) Name() string { return SourcePrivate }
+
+func (viewerSource) Timeline(ctx context.Context, userCtx *UserContext) ([]TimelineEntry, error) {
+	v, ok := ctx.Value(viewerKey{}).(viewerTimeline)
+	if !ok || !strings.EqualFold(v.login, userCtx.Username) {
+		return nil, nil
+	}
+	return v.entries, nil
+}
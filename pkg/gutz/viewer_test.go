@@ -0,0 +1,51 @@
+package gutz
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/codeGROOVE-dev/guTZ/pkg/github"
+)
+
+func TestViewerContextKeepsPrivateActivityAnonymous(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `{"data": {"viewer": {"login": "Alice", "id": "U_1", "contributionsCollection": {
+			"pullRequestContributions": {"nodes": [
+				{"occurredAt": "2025-03-03T15:00:00Z", "pullRequest": {"repository": {"nameWithOwner": "acme/secret", "isPrivate": true}}},
+				{"occurredAt": "2025-03-03T17:00:00Z", "pullRequest": {"repository": {"nameWithOwner": "alice/public", "isPrivate": false}}}]},
+			"pullRequestReviewContributions": {"nodes": []},
+			"issueContributions": {"nodes": []},
+			"commitContributionsByRepository": []}}}}`)
+	}))
+	defer server.Close()
+
+	logger := slog.New(slog.DiscardHandler)
+	client := github.NewClient(logger, server.Client(), "", nil)
+	client.SetBaseURLs(github.NewBaseURLs("", "", server.URL+"/api/graphql"))
+	d := &Detector{logger: logger, githubClient: client, viewerClient: server.Client(), sources: []TimelineSource{viewerSource{}}}
+
+	ctx, login, err := d.ViewerContext(context.Background(), "user-token")
+	if err != nil {
+		t.Fatalf("ViewerContext: %v", err)
+	}
+	if login != "Alice" {
+		t.Errorf("login = %q, want Alice", login)
+	}
+
+	entries := d.collectTimelineSources(ctx, &UserContext{Username: "alice"})
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries, want the private pull request only", len(entries))
+	}
+	if e := entries[0]; e.Source != SourcePrivate || e.Repository != "" || e.URL != "" || e.Org != "" {
+		t.Errorf("entry = %+v, want an anonymous private entry", e)
+	}
+
+	// The private activity only ever joins its owner's detection
+	if entries := d.collectTimelineSources(ctx, &UserContext{Username: "bob"}); len(entries) != 0 {
+		t.Errorf("another user got %d private entries", len(entries))
+	}
+}
@@ -102,8 +102,8 @@ func convertUTCToLocal(utcHour float64, timezone string) float64 {
 		if offsetStr == "" {
 			return utcHour // UTC+0
 		}
-		if offset, err := strconv.Atoi(offsetStr); err == nil {
-			return math.Mod(utcHour+float64(offset)+24, 24)
+		if offset, err := strconv.ParseFloat(offsetStr, 64); err == nil {
+			return math.Mod(utcHour+offset+24, 24)
 		}
 	}
 	return utcHour // No conversion possible
@@ -0,0 +1,152 @@
+package history
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/codeGROOVE-dev/guTZ/pkg/gutz"
+)
+
+// ChangeKind classifies a difference between two consecutive snapshots.
+type ChangeKind string
+
+// Change kinds.
+const (
+	ChangeTimezone      ChangeKind = "timezone"      // Detected timezone name changed
+	ChangeSleep         ChangeKind = "sleep"         // Main local sleep window moved by an hour or more
+	ChangeOrganizations ChangeKind = "organizations" // Top organizations changed
+	ChangeDSTShift      ChangeKind = "dst_shift"     // Dominant offset moved exactly as the zone's daylight saving rules predict
+	ChangeRelocation    ChangeKind = "relocation"    // Dominant offset moved by an hour or more for no DST reason
+)
+
+// Change describes one difference between two consecutive snapshots.
+type Change struct {
+	From   time.Time  `json:"from"`
+	To     time.Time  `json:"to"`
+	Kind   ChangeKind `json:"kind"`
+	Before string     `json:"before"`
+	After  string     `json:"after"`
+	Detail string     `json:"detail,omitempty"`
+}
+
+// Changes compares each snapshot with the one before it made from the same linked accounts, since merging
+// another forge's activity moves a detection without the user having moved. snaps must be sorted oldest first.
+// The result is never nil, so it encodes as [] when nothing changed.
+func Changes(snaps []Snapshot) []Change {
+	changes := []Change{}
+	for i, cur := range snaps {
+		j := i - 1
+		for j >= 0 && !slices.Equal(snaps[j].Linked, cur.Linked) {
+			j--
+		}
+		if j < 0 {
+			continue
+		}
+		prev := snaps[j]
+		change := func(kind ChangeKind, before, after, detail string) {
+			changes = append(changes, Change{From: prev.Time, To: cur.Time, Kind: kind, Before: before, After: after, Detail: detail})
+		}
+
+		if prev.Timezone != cur.Timezone {
+			change(ChangeTimezone, prev.Timezone, cur.Timezone, "")
+		}
+
+		if delta := cur.DominantOffset - prev.DominantOffset; delta != 0 {
+			before, after := formatOffset(prev.DominantOffset), formatOffset(cur.DominantOffset)
+			switch zone, ok := dstZone(prev, cur, delta); {
+			case ok:
+				change(ChangeDSTShift, before, after, "matches daylight saving change in "+zone)
+			case math.Abs(delta) >= 1:
+				change(ChangeRelocation, before, after, fmt.Sprintf("activity shifted %+.1f hours", delta))
+			default:
+				// Half-hour wobble between runs is scoring noise, not a move
+			}
+		}
+
+		if prevSleep, curSleep := prev.MainSleep(), cur.MainSleep(); prevSleep != "" && curSleep != "" && sleepMoved(prev, cur) {
+			change(ChangeSleep, prevSleep, curSleep, "")
+		}
+
+		if !sameOrgs(prev.TopOrganizations, cur.TopOrganizations) {
+			change(ChangeOrganizations, strings.Join(prev.TopOrganizations, ", "), strings.Join(cur.TopOrganizations, ", "), "")
+		}
+	}
+	return changes
+}
+
+// dstZone reports whether an offset move of delta hours between prev and cur is explained by a
+// daylight saving transition in either snapshot's IANA timezone, returning that zone.
+func dstZone(prev, cur Snapshot, delta float64) (string, bool) {
+	for _, name := range []string{cur.Timezone, prev.Timezone} {
+		if name == "" || strings.HasPrefix(name, "UTC") {
+			continue
+		}
+		loc, err := time.LoadLocation(name)
+		if err != nil {
+			continue
+		}
+		_, before := prev.Time.In(loc).Zone()
+		_, after := cur.Time.In(loc).Zone()
+		if before != after && float64(after-before)/3600 == delta {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// MainSleep formats the longest local sleep range as "HH:MM-HH:MM", or returns "" when there is none.
+func (s Snapshot) MainSleep() string {
+	if len(s.SleepRangesLocal) == 0 {
+		return ""
+	}
+	r := longestSleep(s)
+	return fmt.Sprintf("%s-%s", formatHour(r.Start), formatHour(r.End))
+}
+
+func sleepMoved(prev, cur Snapshot) bool {
+	a, b := longestSleep(prev), longestSleep(cur)
+	return hourDistance(a.Start, b.Start) >= 1 || hourDistance(a.End, b.End) >= 1
+}
+
+func longestSleep(s Snapshot) gutz.SleepRange {
+	var best gutz.SleepRange
+	for i, r := range s.SleepRangesLocal {
+		if i == 0 || r.Duration > best.Duration {
+			best = r
+		}
+	}
+	return best
+}
+
+// hourDistance is the distance between two hours of the day, wrapping at midnight.
+func hourDistance(a, b float64) float64 {
+	d := math.Mod(math.Abs(a-b), 24)
+	return math.Min(d, 24-d)
+}
+
+func sameOrgs(a, b []string) bool {
+	a, b = slices.Clone(a), slices.Clone(b)
+	slices.Sort(a)
+	slices.Sort(b)
+	return slices.Equal(a, b)
+}
+
+func formatHour(h float64) string {
+	h = math.Mod(h+24, 24)
+	return fmt.Sprintf("%02d:%02d", int(h), int(math.Round((h-math.Floor(h))*60)))
+}
+
+func formatOffset(offset float64) string {
+	sign := "+"
+	if offset < 0 {
+		sign = "-"
+	}
+	abs := math.Abs(offset)
+	if abs == math.Floor(abs) {
+		return fmt.Sprintf("UTC%s%d", sign, int(abs))
+	}
+	return fmt.Sprintf("UTC%s%d:%02d", sign, int(abs), int(math.Round((abs-math.Floor(abs))*60)))
+}
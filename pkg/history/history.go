@@ -0,0 +1,167 @@
+// Package history keeps past detection results per user and explains how they changed over time.
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/codeGROOVE-dev/guTZ/pkg/gutz"
+	"github.com/codeGROOVE-dev/guTZ/pkg/tzconvert"
+)
+
+// maxTopOrgs is how many organizations a snapshot keeps.
+const maxTopOrgs = 3
+
+// Snapshot is the part of a detection result worth tracking over time.
+type Snapshot struct {
+	Time             time.Time         `json:"time"`
+	Username         string            `json:"username"`
+	Timezone         string            `json:"timezone"`
+	Method           string            `json:"method"`
+	LocationName     string            `json:"location_name,omitempty"`
+	TopOrganizations []string          `json:"top_organizations,omitempty"`
+	Linked           []string          `json:"linked,omitempty"` // Accounts on other forges merged into the detection, as in gutz.Result
+	SleepRangesLocal []gutz.SleepRange `json:"sleep_ranges_local,omitempty"`
+	DominantOffset   float64           `json:"dominant_offset"` // UTC offset of the top activity candidate, in hours
+	Confidence       float64           `json:"confidence"`
+}
+
+// FromResult condenses a detection result into a snapshot.
+func FromResult(result *gutz.Result) Snapshot {
+	snap := Snapshot{
+		Time:             result.DetectionTime,
+		Username:         result.Username,
+		Timezone:         result.Timezone,
+		Method:           result.Method,
+		LocationName:     result.LocationName,
+		SleepRangesLocal: result.SleepRangesLocal,
+		Confidence:       result.Confidence,
+		Linked:           result.Linked,
+	}
+	if snap.Time.IsZero() {
+		snap.Time = time.Now()
+	}
+	for i, org := range result.TopOrganizations {
+		if i == maxTopOrgs {
+			break
+		}
+		snap.TopOrganizations = append(snap.TopOrganizations, org.Name)
+	}
+	if len(result.TimezoneCandidates) > 0 {
+		snap.DominantOffset = result.TimezoneCandidates[0].Offset
+	} else {
+		snap.DominantOffset = tzconvert.ParseTimezoneOffset(result.Timezone)
+	}
+	return snap
+}
+
+// Store persists snapshots as one append-only JSON-lines file per user.
+type Store struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// Open returns a store rooted at dir, creating it if needed.
+func Open(dir string) (*Store, error) {
+	if dir == "" {
+		return nil, errors.New("history directory is required")
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "v1"), 0o750); err != nil {
+		return nil, fmt.Errorf("create history dir: %w", err)
+	}
+	return &Store{dir: dir}, nil
+}
+
+func (s *Store) path(username string) (string, error) {
+	if !gutz.IsValidGitHubUsername(username) {
+		return "", fmt.Errorf("invalid username %q", username)
+	}
+	// GitHub usernames are case-insensitive
+	return filepath.Join(s.dir, "v1", strings.ToLower(username)+".jsonl"), nil
+}
+
+// Append records a snapshot.
+func (s *Store) Append(snap Snapshot) error {
+	path, err := s.path(snap.Username)
+	if err != nil {
+		return err
+	}
+	line, err := json.Marshal(snap)
+	if err != nil {
+		return fmt.Errorf("encode snapshot: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("open history: %w", err)
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		_ = f.Close() //nolint:errcheck // write error takes precedence
+		return fmt.Errorf("write history: %w", err)
+	}
+	return f.Close()
+}
+
+// Snapshots returns every snapshot recorded for username, oldest first.
+// A user with no history yields an empty, non-nil slice and no error, so it encodes as [] rather than null.
+func (s *Store) Snapshots(username string) ([]Snapshot, error) {
+	path, err := s.path(username)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	data, err := os.ReadFile(path)
+	s.mu.Unlock()
+	if errors.Is(err, os.ErrNotExist) {
+		return []Snapshot{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read history: %w", err)
+	}
+
+	snaps := []Snapshot{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	for scanner.Scan() {
+		var snap Snapshot
+		// Skip lines torn by a crash mid-append rather than losing the whole history
+		if err := json.Unmarshal(scanner.Bytes(), &snap); err != nil {
+			continue
+		}
+		snaps = append(snaps, snap)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("scan history: %w", err)
+	}
+
+	sort.SliceStable(snaps, func(i, j int) bool { return snaps[i].Time.Before(snaps[j].Time) })
+	return snaps, nil
+}
+
+// Delete removes every snapshot recorded for username.
+func (s *Store) Delete(username string) error {
+	path, err := s.path(username)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("delete history: %w", err)
+	}
+	return nil
+}
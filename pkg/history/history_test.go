@@ -0,0 +1,183 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/codeGROOVE-dev/guTZ/pkg/gutz"
+	"github.com/codeGROOVE-dev/guTZ/pkg/timezone"
+)
+
+func TestStoreRoundTrip(t *testing.T) {
+	store, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+
+	older := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+	newer := older.Add(30 * 24 * time.Hour)
+	// Append out of order; Snapshots must sort by time
+	for _, snap := range []Snapshot{
+		{Username: "Alice", Time: newer, Timezone: "Europe/Berlin"},
+		{Username: "alice", Time: older, Timezone: "America/New_York"},
+	} {
+		if err := store.Append(snap); err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+	}
+
+	snaps, err := store.Snapshots("ALICE")
+	if err != nil {
+		t.Fatalf("Snapshots: %v", err)
+	}
+	if len(snaps) != 2 || !snaps[0].Time.Equal(older) || snaps[1].Timezone != "Europe/Berlin" {
+		t.Fatalf("Snapshots = %+v, want both snapshots oldest first", snaps)
+	}
+
+	if snaps, err := store.Snapshots("nobody"); err != nil || snaps == nil || len(snaps) != 0 {
+		t.Errorf("Snapshots(nobody) = %#v, %v; want empty non-nil slice, nil", snaps, err)
+	}
+	if err := store.Append(Snapshot{Username: "../etc/passwd"}); err == nil {
+		t.Error("Append accepted an invalid username")
+	}
+
+	if err := store.Delete("alice"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if snaps, err := store.Snapshots("alice"); err != nil || len(snaps) != 0 {
+		t.Errorf("Snapshots after Delete = %v, %v; want none", snaps, err)
+	}
+	if err := store.Delete("alice"); err != nil {
+		t.Errorf("Delete of a user without history = %v", err)
+	}
+}
+
+func TestStoreSkipsTornLines(t *testing.T) {
+	dir := t.TempDir()
+	store, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if err := store.Append(Snapshot{Username: "bob", Timezone: "UTC"}); err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+	f, err := os.OpenFile(filepath.Join(dir, "v1", "bob.jsonl"), os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if _, err := f.WriteString(`{"username":"bob","time`); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	snaps, err := store.Snapshots("bob")
+	if err != nil || len(snaps) != 1 {
+		t.Errorf("Snapshots = %v, %v; want the one intact snapshot", snaps, err)
+	}
+}
+
+func TestFromResult(t *testing.T) {
+	snap := FromResult(&gutz.Result{
+		Username: "carol",
+		Timezone: "Asia/Kolkata",
+		TopOrganizations: []gutz.OrgActivity{
+			{Name: "a"}, {Name: "b"}, {Name: "c"}, {Name: "d"},
+		},
+		TimezoneCandidates: []timezone.Candidate{{Offset: 5.5}},
+	})
+	if snap.DominantOffset != 5.5 {
+		t.Errorf("DominantOffset = %v, want 5.5", snap.DominantOffset)
+	}
+	if len(snap.TopOrganizations) != maxTopOrgs {
+		t.Errorf("TopOrganizations = %v, want %d entries", snap.TopOrganizations, maxTopOrgs)
+	}
+	if snap.Time.IsZero() {
+		t.Error("Time was not defaulted")
+	}
+}
+
+func TestChanges(t *testing.T) {
+	winter := time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC)
+	summer := time.Date(2025, 7, 15, 12, 0, 0, 0, time.UTC)
+	autumn := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	snaps := []Snapshot{
+		{
+			Time: winter, Timezone: "America/New_York", DominantOffset: -5,
+			TopOrganizations: []string{"kubernetes", "golang"},
+			SleepRangesLocal: []gutz.SleepRange{{Start: 23, End: 7, Duration: 8}},
+		},
+		{
+			// DST: New York moves from UTC-5 to UTC-4
+			Time: summer, Timezone: "America/New_York", DominantOffset: -4,
+			TopOrganizations: []string{"golang", "kubernetes"},
+			SleepRangesLocal: []gutz.SleepRange{{Start: 23.5, End: 7, Duration: 7.5}},
+		},
+		{
+			Time: autumn, Timezone: "Europe/Berlin", DominantOffset: 2,
+			TopOrganizations: []string{"golang", "sigstore"},
+			SleepRangesLocal: []gutz.SleepRange{{Start: 1, End: 9, Duration: 8}},
+		},
+	}
+
+	got := map[ChangeKind][]Change{}
+	for _, c := range Changes(snaps) {
+		got[c.Kind] = append(got[c.Kind], c)
+	}
+
+	if c := got[ChangeDSTShift]; len(c) != 1 || c[0].Before != "UTC-5" || c[0].After != "UTC-4" {
+		t.Errorf("DST shifts = %+v, want UTC-5 to UTC-4", c)
+	}
+	if c := got[ChangeRelocation]; len(c) != 1 || c[0].After != "UTC+2" {
+		t.Errorf("relocations = %+v, want one to UTC+2", c)
+	}
+	if c := got[ChangeTimezone]; len(c) != 1 || c[0].After != "Europe/Berlin" {
+		t.Errorf("timezone changes = %+v, want one to Europe/Berlin", c)
+	}
+	// Reordered organizations are not a change; a new one is
+	if c := got[ChangeOrganizations]; len(c) != 1 || !c[0].To.Equal(autumn) {
+		t.Errorf("organization changes = %+v, want one in autumn", c)
+	}
+	// A half-hour bedtime wobble is not a change; a two-hour move is
+	if c := got[ChangeSleep]; len(c) != 1 || c[0].After != "01:00-09:00" {
+		t.Errorf("sleep changes = %+v, want one to 01:00-09:00", c)
+	}
+}
+
+func TestChangesCompareLinkedAlike(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2025, 3, d, 12, 0, 0, 0, time.UTC) }
+	plain := FromResult(&gutz.Result{DetectionTime: day(1), Username: "alice", Timezone: "UTC-5", TimezoneCandidates: []timezone.Candidate{{Offset: -5}}})
+	merged := FromResult(&gutz.Result{
+		DetectionTime: day(2), Username: "alice", Timezone: "UTC+1", TimezoneCandidates: []timezone.Candidate{{Offset: 1}},
+		Linked: []string{"gitlab:alice"},
+	})
+	if !slices.Equal(merged.Linked, []string{"gitlab:alice"}) {
+		t.Fatalf("snapshot linked = %v, want the result's", merged.Linked)
+	}
+	later := plain
+	later.Time = day(3)
+
+	// Merging GitLab activity moved the detection, but alice did not move
+	if got := Changes([]Snapshot{plain, merged, later}); len(got) != 0 {
+		t.Errorf("changes = %+v, want none between detections with different linked accounts", got)
+	}
+	merged2 := merged
+	merged2.Time, merged2.Timezone, merged2.DominantOffset = day(4), "UTC+3", 3
+	got := Changes([]Snapshot{plain, merged, later, merged2})
+	if len(got) != 2 || !got[0].From.Equal(day(2)) || !got[0].To.Equal(day(4)) {
+		t.Errorf("changes = %+v, want the two merged detections compared", got)
+	}
+}
+
+func TestFormatOffset(t *testing.T) {
+	for offset, want := range map[float64]string{0: "UTC+0", -5: "UTC-5", 5.5: "UTC+5:30", 5.75: "UTC+5:45", -3.5: "UTC-3:30"} {
+		if got := formatOffset(offset); got != want {
+			t.Errorf("formatOffset(%v) = %q, want %q", offset, got, want)
+		}
+	}
+}
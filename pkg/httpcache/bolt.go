@@ -0,0 +1,201 @@
+package httpcache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+	berrors "go.etcd.io/bbolt/errors"
+)
+
+// boltLockTimeout bounds how long an operation waits for another process holding the database.
+const boltLockTimeout = 10 * time.Second
+
+// boltIdleClose is how long the database stays open after its last operation. Operations in the
+// meantime share one handle; other processes get their turn once it closes.
+const boltIdleClose = 250 * time.Millisecond
+
+var boltBucket = []byte("httpcache")
+
+// BoltStorage stores entries in an embedded bbolt database.
+// bbolt allows one process at a time, so the database is held open only while this process is using it:
+// the operations of a busy process share one handle, and several gutz processes sharing a cache directory
+// take turns, each closing the database once idle, instead of overwriting each other's entries.
+type BoltStorage struct {
+	db    *bolt.DB      // Open while operations run or recently ran
+	idle  *time.Timer   // Closes db once no operation has used it for boltIdleClose
+	lock  chan struct{} // Guards db, idle and users; a channel so that waiting for it honors ctx
+	path  string
+	users int // Operations using db
+}
+
+// NewBoltStorage creates or opens a bbolt database at path.
+func NewBoltStorage(path string) (*BoltStorage, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return nil, fmt.Errorf("creating cache directory: %w", err)
+	}
+	s := &BoltStorage{path: path, lock: make(chan struct{}, 1)}
+	if err := s.update(context.Background(), func(*bolt.Bucket) error { return nil }); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// openBolt opens the database at path, waiting up to boltLockTimeout for another process to release it.
+func openBolt(ctx context.Context, path string) (*bolt.DB, error) {
+	deadline := time.Now().Add(boltLockTimeout)
+	for {
+		db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: 50 * time.Millisecond})
+		if err == nil {
+			return db, nil
+		}
+		if !errors.Is(err, berrors.ErrTimeout) || time.Now().After(deadline) {
+			return nil, fmt.Errorf("opening %s: %w", path, err)
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("opening %s: %w", path, err)
+		}
+	}
+}
+
+// acquire returns the open database, opening it if needed. Each call must be paired with release.
+func (s *BoltStorage) acquire(ctx context.Context) (*bolt.DB, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	select {
+	case s.lock <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	defer func() { <-s.lock }()
+	if s.idle != nil {
+		s.idle.Stop()
+	}
+	if s.db == nil {
+		db, err := openBolt(ctx, s.path)
+		if err != nil {
+			return nil, err
+		}
+		s.db = db
+	}
+	s.users++
+	return s.db, nil
+}
+
+// release ends an operation, closing the database once it has been idle for boltIdleClose.
+func (s *BoltStorage) release() {
+	s.lock <- struct{}{}
+	defer func() { <-s.lock }()
+	s.users--
+	if s.users == 0 {
+		s.idle = time.AfterFunc(boltIdleClose, s.closeIdle)
+	}
+}
+
+func (s *BoltStorage) closeIdle() {
+	s.lock <- struct{}{}
+	defer func() { <-s.lock }()
+	// An operation may have started since the timer fired
+	if s.users == 0 && s.db != nil {
+		_ = s.db.Close() //nolint:errcheck // nothing is pending once idle, and the next operation reopens it
+		s.db = nil
+	}
+}
+
+func (s *BoltStorage) view(ctx context.Context, fn func(*bolt.Bucket) error) error {
+	db, err := s.acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.release()
+	return db.View(func(tx *bolt.Tx) error {
+		return fn(tx.Bucket(boltBucket))
+	})
+}
+
+func (s *BoltStorage) update(ctx context.Context, fn func(*bolt.Bucket) error) error {
+	db, err := s.acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.release()
+	return db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists(boltBucket)
+		if err != nil {
+			return err
+		}
+		return fn(b)
+	})
+}
+
+// Get returns the entry stored under key.
+func (s *BoltStorage) Get(ctx context.Context, key string) (entry CacheEntry, found bool, err error) {
+	err = s.view(ctx, func(b *bolt.Bucket) error {
+		data := b.Get([]byte(key))
+		if data == nil {
+			return nil
+		}
+		entry, err = decodeEntry(data)
+		found = err == nil
+		return err
+	})
+	return entry, found, err
+}
+
+// Set stores entry under key.
+func (s *BoltStorage) Set(ctx context.Context, key string, entry CacheEntry) error {
+	data, err := encodeEntry(entry)
+	if err != nil {
+		return err
+	}
+	return s.update(ctx, func(b *bolt.Bucket) error {
+		return b.Put([]byte(key), data)
+	})
+}
+
+// Delete removes the entry stored under key.
+func (s *BoltStorage) Delete(ctx context.Context, key string) error {
+	return s.update(ctx, func(b *bolt.Bucket) error {
+		return b.Delete([]byte(key))
+	})
+}
+
+// Range calls fn for each stored entry until fn returns false.
+func (s *BoltStorage) Range(ctx context.Context, fn func(key string, entry CacheEntry) bool) error {
+	return s.view(ctx, func(b *bolt.Bucket) error {
+		c := b.Cursor()
+		for k, v := c.First(); k != nil; k, v = c.Next() {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			entry, err := decodeEntry(v)
+			if err != nil {
+				return fmt.Errorf("key %s: %w", k, err)
+			}
+			if !fn(string(k), entry) {
+				return nil
+			}
+		}
+		return nil
+	})
+}
+
+// Close closes the database, for other processes to use.
+func (s *BoltStorage) Close() error {
+	s.lock <- struct{}{}
+	defer func() { <-s.lock }()
+	if s.idle != nil {
+		s.idle.Stop()
+	}
+	if s.db == nil {
+		return nil
+	}
+	err := s.db.Close()
+	s.db = nil
+	return err
+}
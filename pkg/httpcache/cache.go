@@ -3,95 +3,230 @@ package httpcache
 
 import (
 	"bytes"
+	"cmp"
 	"context"
 	"crypto/sha256"
 	"encoding/gob"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
 	"os"
 	"path/filepath"
-	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/maypok86/otter/v2"
 )
 
+// revalidateWindow is how long an expired entry with an ETag or Last-Modified validator is kept, so that it can be
+// revalidated with a conditional request instead of being fetched again.
+const revalidateWindow = 30 * 24 * time.Hour
+
+// storageTimeout bounds each read or write of the persistent store.
+const storageTimeout = 5 * time.Second
+
+// memoryRecheck is how long a cache with a persistent store serves an entry from memory before reading
+// the store again, so that entries another process or replica purged or replaced stop being served.
+var memoryRecheck = time.Minute
+
 // CacheEntry represents a cached HTTP response with expiration and ETag support.
 type CacheEntry struct {
-	ExpiresAt time.Time `json:"expires_at"`
-	ETag      string    `json:"etag,omitempty"`
-	Data      []byte    `json:"data"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	StoredAt     time.Time `json:"stored_at,omitzero"`
+	URL          string    `json:"url,omitempty"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	Request      []byte    `json:"request,omitempty"` // Request body of a cached API call, such as a GraphQL query
+	Data         []byte    `json:"data"`
+}
+
+// revalidatable reports whether the origin can confirm the entry is unchanged with a 304 Not Modified.
+func (e CacheEntry) revalidatable() bool {
+	return e.ETag != "" || e.LastModified != ""
+}
+
+// retainUntil returns when the entry is dropped: at expiry, or once it is too old to revalidate.
+func (e CacheEntry) retainUntil() time.Time {
+	if e.revalidatable() {
+		return e.ExpiresAt.Add(revalidateWindow)
+	}
+	return e.ExpiresAt
+}
+
+// expiry evicts entries once they can no longer be served or revalidated, and, when the cache is backed
+// by a store, once they were last read from or written to it memoryRecheck ago.
+func expiry(backed bool) otter.ExpiryCalculator[string, CacheEntry] {
+	recheck := memoryRecheck
+	return otter.ExpiryWritingFunc(func(e otter.Entry[string, CacheEntry]) time.Duration {
+		ttl := time.Until(e.Value.retainUntil())
+		if backed {
+			ttl = min(ttl, recheck)
+		}
+		return ttl
+	})
 }
 
-// OtterCache implements an HTTP cache using the Otter library for efficient in-memory caching.
+// cacheKey hashes a URL, plus the request body for API calls, into a cache key.
+func cacheKey(url string, requestBody []byte) string {
+	h := sha256.New()
+	h.Write([]byte(url))
+	h.Write(requestBody)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// OtterCache implements an HTTP cache using the Otter library for efficient in-memory caching,
+// optionally backed by a persistent Storage that entries are written through to and read back from.
 type OtterCache struct {
-	cache      otter.Cache[string, CacheEntry]
-	logger     *slog.Logger
-	saveCancel context.CancelFunc // Cancel func for lifecycle management, not embedded context
-	dir        string
-	saveWg     sync.WaitGroup
-	ttl        time.Duration
-	mu         sync.RWMutex
+	cache       otter.Cache[string, CacheEntry]
+	logger      *slog.Logger
+	store       Storage // nil for a memory-only cache
+	ttl         time.Duration
+	hits        atomic.Int64
+	misses      atomic.Int64
+	revalidated atomic.Int64 // Expired entries refreshed by a 304 Not Modified
+	flushed     counters     // Counters already added to the store's totals
 }
 
-// NewMemoryOnlyCache creates an in-memory only cache with no disk persistence.
-// This is ideal for web servers where response caching is handled separately.
-func NewMemoryOnlyCache(ttl time.Duration, logger *slog.Logger) (*OtterCache, error) {
+func newOtterCache(store Storage, ttl time.Duration, logger *slog.Logger) *OtterCache {
 	// Create otter cache with 100k capacity using v2 API
 	cache := otter.Must(&otter.Options[string, CacheEntry]{
 		MaximumSize:      100_000,
 		InitialCapacity:  10_000,
-		ExpiryCalculator: otter.ExpiryWriting[string, CacheEntry](ttl),
+		ExpiryCalculator: expiry(store != nil),
 	})
 
-	c := &OtterCache{
+	return &OtterCache{
 		cache:  *cache, // Dereference the pointer
-		dir:    "",     // No directory for memory-only cache
+		store:  store,
 		ttl:    ttl,
 		logger: logger,
 	}
+}
 
+// NewMemoryOnlyCache creates an in-memory only cache with no disk persistence.
+// This is ideal for web servers where response caching is handled separately.
+func NewMemoryOnlyCache(ttl time.Duration, logger *slog.Logger) (*OtterCache, error) {
+	c := newOtterCache(nil, ttl, logger)
 	logger.Info("memory-only HTTP cache initialized", "ttl", ttl, "capacity", 100_000)
-
-	// No disk loading or periodic save for memory-only cache
 	return c, nil
 }
 
+// NewCacheWithStorage creates a cache whose entries persist in store, such as one from OpenStorage.
+// The cache takes ownership of store and closes it on Close.
+func NewCacheWithStorage(store Storage, ttl time.Duration, logger *slog.Logger) *OtterCache {
+	c := newOtterCache(store, ttl, logger)
+	logger.Info("HTTP cache initialized", "ttl", ttl, "storage", fmt.Sprintf("%T", store))
+	return c
+}
+
 // NewOtterCache creates a new OtterCache instance with the specified directory, TTL, and logger.
+// Entries persist in a bbolt database in dir; an otter-cache.gob file left by older versions is imported once.
 func NewOtterCache(ctx context.Context, dir string, ttl time.Duration, logger *slog.Logger) (*OtterCache, error) {
 	// Create cache directory if it doesn't exist
 	if err := os.MkdirAll(dir, 0o750); err != nil {
 		return nil, fmt.Errorf("creating cache directory: %w", err)
 	}
 
-	// Create otter cache with 100k capacity using v2 API
-	cache := otter.Must(&otter.Options[string, CacheEntry]{
-		MaximumSize:      100_000,
-		InitialCapacity:  10_000,
-		ExpiryCalculator: otter.ExpiryWriting[string, CacheEntry](ttl),
-	})
+	store, err := NewBoltStorage(filepath.Join(dir, "httpcache.db"))
+	if err != nil {
+		return nil, err
+	}
+	c := newOtterCache(store, ttl, logger)
 
-	c := &OtterCache{
-		cache:  *cache, // Dereference the pointer
-		dir:    dir,
-		ttl:    ttl,
-		logger: logger,
+	if err := c.importLegacy(ctx, filepath.Join(dir, "otter-cache.gob")); err != nil {
+		logger.Warn("failed to import legacy cache file", "error", err)
+	}
+	logger.Info("cache initialized", "dir", dir)
+
+	return c, nil
+}
+
+// importLegacy moves the entries of a gob snapshot written by older versions into the store, then removes it.
+func (c *OtterCache) importLegacy(ctx context.Context, path string) error {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("opening cache file: %w", err)
+	}
+	var entries map[string]CacheEntry
+	err = gob.NewDecoder(file).Decode(&entries)
+	if closeErr := file.Close(); closeErr != nil {
+		c.logger.Debug("Failed to close cache file", "error", closeErr)
+	}
+	if err != nil {
+		return fmt.Errorf("decoding cache file: %w", err)
 	}
 
-	// Load existing cache from disk
-	if err := c.loadFromDisk(); err != nil {
-		logger.Warn("failed to load cache from disk", "error", err)
+	// Import entries, filtering out ones that can neither be served nor revalidated
+	now := time.Now()
+	imported := 0
+	for key, entry := range entries {
+		if !now.Before(entry.retainUntil()) {
+			continue
+		}
+		if err := c.store.Set(ctx, key, entry); err != nil {
+			return fmt.Errorf("importing %s: %w", path, err)
+		}
+		imported++
 	}
-	// Log final cache state after loading
-	logger.Info("cache initialized", "dir", dir, "entries_loaded", c.cache.EstimatedSize())
+	c.logger.Info("imported legacy cache file", "path", path, "total_entries", len(entries), "valid_entries", imported)
+	return os.Remove(path)
+}
 
-	// Start periodic save goroutine
-	c.startPeriodicSave(ctx)
+// lookup returns the entry for key from memory, falling back to the store.
+func (c *OtterCache) lookup(key string) (CacheEntry, bool) {
+	if entry, found := c.cache.GetIfPresent(key); found || c.store == nil {
+		return entry, found
+	}
 
-	return c, nil
+	ctx, cancel := context.WithTimeout(context.Background(), storageTimeout)
+	defer cancel()
+	entry, found, err := c.store.Get(ctx, key)
+	if err != nil {
+		c.logger.Warn("cache storage read failed", "error", err)
+		return CacheEntry{}, false
+	}
+	if !found {
+		return CacheEntry{}, false
+	}
+	if !time.Now().Before(entry.retainUntil()) {
+		c.drop(key)
+		return CacheEntry{}, false
+	}
+	c.cache.Set(key, entry)
+	return entry, true
+}
+
+// put stores the entry in memory and writes it through to the store.
+func (c *OtterCache) put(key string, entry CacheEntry) error {
+	c.cache.Set(key, entry)
+	if c.store == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), storageTimeout)
+	defer cancel()
+	if err := c.store.Set(ctx, key, entry); err != nil {
+		return fmt.Errorf("cache storage write: %w", err)
+	}
+	return nil
+}
+
+// drop removes the entry from memory and the store.
+func (c *OtterCache) drop(key string) {
+	c.cache.Invalidate(key)
+	if c.store == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), storageTimeout)
+	defer cancel()
+	if err := c.store.Delete(ctx, key); err != nil {
+		c.logger.Debug("cache storage delete failed", "error", err)
+	}
 }
 
 // Get retrieves cached data for the given URL, returning data, etag, and found status.
@@ -100,47 +235,80 @@ func (c *OtterCache) Get(url string) (data []byte, etag string, found bool) {
 		return nil, "", false
 	}
 
-	// Generate cache key from URL
-	h := sha256.New()
-	h.Write([]byte(url))
-	key := hex.EncodeToString(h.Sum(nil))
-
-	entry, found := c.cache.GetIfPresent(key)
+	key := cacheKey(url, nil)
+	entry, found := c.lookup(key)
 	if !found {
+		c.misses.Add(1)
 		c.logger.Info("🤦 cache miss", "url", url, "reason", "not_found")
 		return nil, "", false
 	}
 
-	// Check if expired (otter should handle this, but double-check for safety)
+	// Check if expired: revalidatable entries are kept past expiry for Stale
 	if time.Now().After(entry.ExpiresAt) {
-		c.logger.Info("🤦 cache miss", "url", url, "reason", "expired", "expired_at", entry.ExpiresAt)
-		c.cache.Invalidate(key)
+		c.logger.Info("🤦 cache miss", "url", url, "reason", "expired", "expired_at", entry.ExpiresAt,
+			"revalidatable", entry.revalidatable())
+		c.misses.Add(1)
+		if !entry.revalidatable() {
+			c.drop(key)
+		}
 		return nil, "", false
 	}
 
+	c.hits.Add(1)
 	return entry.Data, entry.ETag, true
 }
 
+// Stale returns an expired entry for the given URL that can still be revalidated with a conditional request.
+func (c *OtterCache) Stale(url string) (CacheEntry, bool) {
+	if c == nil {
+		return CacheEntry{}, false
+	}
+
+	entry, found := c.lookup(cacheKey(url, nil))
+	if !found || !entry.revalidatable() || !time.Now().Before(entry.retainUntil()) {
+		return CacheEntry{}, false
+	}
+	return entry, true
+}
+
+// Refresh renews an entry that the origin confirmed unchanged with a 304 Not Modified,
+// adopting any validators the 304 carried.
+func (c *OtterCache) Refresh(url string, entry CacheEntry, etag, lastModified string) {
+	if c == nil {
+		return
+	}
+
+	c.revalidated.Add(1)
+	if err := c.SetWithValidators(url, entry.Data, cmp.Or(etag, entry.ETag), cmp.Or(lastModified, entry.LastModified)); err != nil {
+		c.logger.Debug("cache refresh failed", "url", url, "error", err)
+	}
+	c.logger.Debug("cache revalidated", "url", url)
+}
+
 // Set stores data in the cache with the given URL and etag.
 func (c *OtterCache) Set(url string, data []byte, etag string) error {
+	return c.SetWithValidators(url, data, etag, "")
+}
+
+// SetWithValidators stores data in the cache with the given URL and the ETag and Last-Modified
+// validators used to revalidate it once it expires.
+func (c *OtterCache) SetWithValidators(url string, data []byte, etag, lastModified string) error {
 	if c == nil {
 		return nil
 	}
 
-	// Generate cache key from URL
-	h := sha256.New()
-	h.Write([]byte(url))
-	key := hex.EncodeToString(h.Sum(nil))
-
+	now := time.Now()
 	entry := CacheEntry{
-		Data:      data,
-		ExpiresAt: time.Now().Add(c.ttl),
-		ETag:      etag,
+		Data:         data,
+		StoredAt:     now,
+		ExpiresAt:    now.Add(c.ttl),
+		URL:          url,
+		ETag:         etag,
+		LastModified: lastModified,
 	}
 
-	c.cache.Set(key, entry)
 	c.logger.Debug("cache set", "url", url, "expires_at", entry.ExpiresAt, "size", len(data))
-	return nil
+	return c.put(cacheKey(url, nil), entry)
 }
 
 // SetAPICall stores API call data in the cache with URL and request body as key.
@@ -149,21 +317,18 @@ func (c *OtterCache) SetAPICall(url string, requestBody []byte, data []byte) err
 		return nil
 	}
 
-	// Generate cache key from URL and request body
-	h := sha256.New()
-	h.Write([]byte(url))
-	h.Write(requestBody)
-	key := hex.EncodeToString(h.Sum(nil))
-
+	now := time.Now()
 	entry := CacheEntry{
 		Data:      data,
-		ExpiresAt: time.Now().Add(c.ttl),
+		StoredAt:  now,
+		ExpiresAt: now.Add(c.ttl),
+		URL:       url,
+		Request:   requestBody,
 		ETag:      "", // API calls don't typically use ETags
 	}
 
-	c.cache.Set(key, entry)
 	c.logger.Debug("API cache set", "url", url, "expires_at", entry.ExpiresAt, "size", len(data))
-	return nil
+	return c.put(cacheKey(url, requestBody), entry)
 }
 
 // APICall retrieves cached API call data for the given URL and request body.
@@ -172,190 +337,70 @@ func (c *OtterCache) APICall(url string, requestBody []byte) ([]byte, bool) {
 		return nil, false
 	}
 
-	// Generate cache key from URL and request body
-	h := sha256.New()
-	h.Write([]byte(url))
-	h.Write(requestBody)
-	key := hex.EncodeToString(h.Sum(nil))
-
-	entry, found := c.cache.GetIfPresent(key)
+	key := cacheKey(url, requestBody)
+	entry, found := c.lookup(key)
 	if !found {
+		c.misses.Add(1)
 		c.logger.Info("🤦 API cache miss", "url", url, "reason", "not_found")
 		return nil, false
 	}
 
 	// Check if expired (otter should handle this, but double-check for safety)
 	if time.Now().After(entry.ExpiresAt) {
+		c.misses.Add(1)
 		c.logger.Info("🤦 API cache miss", "url", url, "reason", "expired", "expired_at", entry.ExpiresAt)
-		c.cache.Invalidate(key)
+		c.drop(key)
 		return nil, false
 	}
 
+	c.hits.Add(1)
 	return entry.Data, true
 }
 
-func (c *OtterCache) loadFromDisk() error {
-	cachePath := filepath.Join(c.dir, "otter-cache.gob")
-
-	file, err := os.Open(cachePath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			c.logger.Info("no existing cache file found", "path", cachePath)
-			return nil // No existing cache file
-		}
-		return fmt.Errorf("opening cache file: %w", err)
-	}
-	defer func() {
-		if closeErr := file.Close(); closeErr != nil {
-			c.logger.Debug("Failed to close cache file", "error", closeErr)
-		}
-	}()
-
-	decoder := gob.NewDecoder(file)
-
-	var entries map[string]CacheEntry
-	if err := decoder.Decode(&entries); err != nil {
-		return fmt.Errorf("decoding cache file: %w", err)
-	}
-
-	// Load entries into cache, filtering out expired ones
-	now := time.Now()
-	validEntries := 0
-	for key, entry := range entries {
-		if now.Before(entry.ExpiresAt) {
-			c.cache.Set(key, entry)
-			validEntries++
-		}
-	}
-
-	c.logger.Info("successfully loaded cache from disk",
-		"path", cachePath,
-		"total_entries", len(entries),
-		"valid_entries", validEntries,
-		"expired_entries", len(entries)-validEntries)
-
-	return nil
-}
-
-func (c *OtterCache) saveToDisk() error {
-	c.logger.Info("cache: SAVE TO DISK")
-
-	c.mu.Lock()
-	defer c.mu.Unlock()
-
-	cachePath := filepath.Join(c.dir, "otter-cache.gob")
-
-	// Create temporary file
-	tempPath := cachePath + ".tmp"
-	file, err := os.Create(tempPath)
-	if err != nil {
-		return fmt.Errorf("creating temp cache file: %w", err)
-	}
-	defer func() {
-		// Only try to remove temp file if it still exists
-		if removeErr := os.Remove(tempPath); removeErr != nil && !os.IsNotExist(removeErr) {
-			c.logger.Debug("Failed to remove temp file", "error", removeErr)
-		}
-	}()
-
-	// Collect all cache entries
-	entries := make(map[string]CacheEntry)
-	now := time.Now()
-
-	// Use iterator to iterate over all entries in otter v2
-	c.cache.All()(func(key string, entry CacheEntry) bool {
-		// Only save non-expired entries
-		if now.Before(entry.ExpiresAt) {
-			entries[key] = entry
-		}
-		return true // Continue iteration
-	})
-
-	// Encode to gob format
-	encoder := gob.NewEncoder(file)
-	if err := encoder.Encode(entries); err != nil {
-		return fmt.Errorf("encoding cache to file: %w", err)
-	}
-
-	if err := file.Sync(); err != nil {
-		return fmt.Errorf("syncing cache file: %w", err)
+// Close shuts down the cache and releases its storage. Entries were written through as they were set.
+func (c *OtterCache) Close() error {
+	if c.store == nil {
+		c.logger.Debug("closing memory-only HTTP cache")
+		// Otter v2 doesn't require explicit clearing
+		return nil
 	}
 
-	if err := file.Close(); err != nil {
-		return fmt.Errorf("closing cache file: %w", err)
+	ctx, cancel := context.WithTimeout(context.Background(), storageTimeout)
+	defer cancel()
+	if err := c.flushCounters(ctx); err != nil {
+		c.logger.Debug("saving cache counters failed", "error", err)
 	}
-
-	// Atomically replace the old cache file
-	if err := os.Rename(tempPath, cachePath); err != nil {
-		return fmt.Errorf("replacing cache file: %w", err)
+	if err := c.store.Close(); err != nil {
+		c.logger.Error("closing cache storage failed", "error", err)
+		return err
 	}
 
-	c.logger.Info("cache: SAVED TO DISK", "entries", len(entries), "path", cachePath)
+	c.logger.Info("cache closed")
 	return nil
 }
 
-func (c *OtterCache) startPeriodicSave(ctx context.Context) {
-	// Skip for memory-only cache
-	if c.dir == "" {
-		return
+// Stats returns this process's hit, miss and revalidation counts and the number of entries held in memory.
+// Inspect also covers the persistent store and earlier processes.
+func (c *OtterCache) Stats() Stats {
+	return Stats{
+		Entries:     c.cache.EstimatedSize(),
+		Hits:        c.hits.Load(),
+		Misses:      c.misses.Load(),
+		Revalidated: c.revalidated.Load(),
 	}
-
-	saveCtx, cancel := context.WithCancel(ctx)
-	c.saveCancel = cancel
-
-	c.saveWg.Add(1)
-	go func() {
-		defer c.saveWg.Done()
-
-		ticker := time.NewTicker(10 * time.Minute)
-		defer ticker.Stop()
-
-		for {
-			select {
-			case <-saveCtx.Done():
-				return
-			case <-ticker.C:
-				if err := c.saveToDisk(); err != nil {
-					c.logger.Error("periodic cache save failed", "error", err)
-				}
-			}
-		}
-	}()
 }
 
-// Close shuts down the cache and saves all pending data.
-func (c *OtterCache) Close() error {
-	// Stop periodic saving (if configured)
-	if c.saveCancel != nil {
-		c.saveCancel()
-		c.saveWg.Wait()
-	}
-
-	// Skip disk operations for memory-only cache
-	if c.dir == "" {
-		c.logger.Debug("closing memory-only HTTP cache")
-		// Otter v2 doesn't require explicit clearing
-		return nil
-	}
-
-	// Final save before closing (for disk-backed cache)
-	if err := c.saveToDisk(); err != nil {
-		c.logger.Error("final cache save failed", "error", err)
-		return err
-	}
-
-	// Otter v2 doesn't require explicit closing
+type bypassKey struct{}
 
-	c.logger.Info("cache closed and saved to disk")
-	return nil
+// WithBypass returns a context whose requests skip cached responses and revalidation. Fresh responses
+// are still stored, so a bypassed fetch refreshes the cache.
+func WithBypass(ctx context.Context) context.Context {
+	return context.WithValue(ctx, bypassKey{}, true)
 }
 
-// Stats returns cache statistics.
-func (c *OtterCache) Stats() map[string]any {
-	// Return basic stats since otter v2 doesn't expose detailed stats in the same way
-	return map[string]any{
-		"size": c.cache.EstimatedSize(),
-	}
+func bypassed(ctx context.Context) bool {
+	b, _ := ctx.Value(bypassKey{}).(bool) //nolint:errcheck // false when unset
+	return b
 }
 
 // HTTPClient interface for making HTTP requests.
@@ -387,6 +432,7 @@ func (c *CachedHTTPClient) Do(ctx context.Context, req *http.Request) (*http.Res
 	}
 
 	url := req.URL.String()
+	bypass := bypassed(ctx)
 
 	// Handle POST requests (like GraphQL) with request body as part of cache key
 	//nolint:nestif // POST request handling requires specific logic
@@ -404,7 +450,11 @@ func (c *CachedHTTPClient) Do(ctx context.Context, req *http.Request) (*http.Res
 		}
 
 		// Check cache for POST requests
-		cachedData, found := c.cache.APICall(url, requestBody)
+		var cachedData []byte
+		var found bool
+		if !bypass {
+			cachedData, found = c.cache.APICall(url, requestBody)
+		}
 		if found {
 			// Create a response from cached data
 			resp := &http.Response{
@@ -452,7 +502,12 @@ func (c *CachedHTTPClient) Do(ctx context.Context, req *http.Request) (*http.Res
 	}
 
 	// Check cache for GET requests
-	cachedData, etag, found := c.cache.Get(url)
+	var cachedData []byte
+	var etag string
+	var found bool
+	if !bypass {
+		cachedData, etag, found = c.cache.Get(url)
+	}
 	if found {
 		// Create a response from cached data
 		resp := &http.Response{
@@ -468,12 +523,47 @@ func (c *CachedHTTPClient) Do(ctx context.Context, req *http.Request) (*http.Res
 		return resp, nil
 	}
 
+	// Revalidate an expired entry with a conditional request, unless the caller made its own.
+	// GitHub does not count 304 Not Modified responses against the rate limit.
+	var stale CacheEntry
+	var revalidate bool
+	if !bypass {
+		stale, revalidate = c.cache.Stale(url)
+	}
+	if revalidate && req.Header.Get("If-None-Match") == "" && req.Header.Get("If-Modified-Since") == "" {
+		req = req.Clone(ctx)
+		if stale.ETag != "" {
+			req.Header.Set("If-None-Match", stale.ETag)
+		}
+		if stale.LastModified != "" {
+			req.Header.Set("If-Modified-Since", stale.LastModified)
+		}
+	} else {
+		revalidate = false
+	}
+
 	// Make the actual request
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		return nil, err
 	}
 
+	if revalidate && resp.StatusCode == http.StatusNotModified {
+		if closeErr := resp.Body.Close(); closeErr != nil {
+			c.logger.Debug("failed to close response body", "error", closeErr)
+		}
+		c.cache.Refresh(url, stale, resp.Header.Get("ETag"), resp.Header.Get("Last-Modified"))
+
+		// Answer with the cached body, keeping the 304's headers (such as rate limits)
+		resp.StatusCode = http.StatusOK
+		resp.Status = http.StatusText(http.StatusOK)
+		resp.Body = io.NopCloser(bytes.NewReader(stale.Data))
+		resp.ContentLength = int64(len(stale.Data))
+		resp.Header.Set("X-From-Cache", "true")
+		resp.Header.Set("ETag", cmp.Or(resp.Header.Get("ETag"), stale.ETag))
+		return resp, nil
+	}
+
 	// Only cache successful responses
 	if resp.StatusCode == http.StatusOK {
 		// Read the response body
@@ -487,7 +577,7 @@ func (c *CachedHTTPClient) Do(ctx context.Context, req *http.Request) (*http.Res
 
 		// Cache the response
 		etag := resp.Header.Get("ETag")
-		if err := c.cache.Set(url, body, etag); err != nil {
+		if err := c.cache.SetWithValidators(url, body, etag, resp.Header.Get("Last-Modified")); err != nil {
 			c.logger.Debug("cache set failed", "url", url, "error", err)
 		}
 
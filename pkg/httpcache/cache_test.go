@@ -0,0 +1,116 @@
+package httpcache
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func fetch(t *testing.T, client *CachedHTTPClient, url string) (status int, body, fromCache string) {
+	t.Helper()
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, url, http.NoBody)
+	if err != nil {
+		t.Fatalf("NewRequest: %v", err)
+	}
+	resp, err := client.Do(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	defer resp.Body.Close() //nolint:errcheck // test
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("read body: %v", err)
+	}
+	return resp.StatusCode, string(data), resp.Header.Get("X-From-Cache")
+}
+
+func TestCachedHTTPClientRevalidates(t *testing.T) {
+	tests := []struct {
+		name      string
+		validator string // Response header carrying the validator
+		value     string
+		condition string // Request header expected on revalidation
+	}{
+		{name: "etag", validator: "ETag", value: `"abc123"`, condition: "If-None-Match"},
+		{name: "last-modified", validator: "Last-Modified", value: "Wed, 21 Oct 2015 07:28:00 GMT", condition: "If-Modified-Since"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var full, notModified atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get(tt.condition) == tt.value {
+					notModified.Add(1)
+					w.WriteHeader(http.StatusNotModified)
+					return
+				}
+				full.Add(1)
+				w.Header().Set(tt.validator, tt.value)
+				if _, err := w.Write([]byte("profile")); err != nil {
+					t.Errorf("write: %v", err)
+				}
+			}))
+			defer server.Close()
+
+			cache, err := NewMemoryOnlyCache(50*time.Millisecond, slog.New(slog.DiscardHandler))
+			if err != nil {
+				t.Fatalf("NewMemoryOnlyCache: %v", err)
+			}
+			client := NewCachedHTTPClient(cache, server.Client(), slog.New(slog.DiscardHandler))
+
+			if status, body, _ := fetch(t, client, server.URL); status != http.StatusOK || body != "profile" {
+				t.Fatalf("first fetch = %d %q", status, body)
+			}
+			if _, _, fromCache := fetch(t, client, server.URL); fromCache != "true" {
+				t.Error("fresh entry was not served from cache")
+			}
+
+			time.Sleep(100 * time.Millisecond)
+			status, body, fromCache := fetch(t, client, server.URL)
+			if status != http.StatusOK || body != "profile" || fromCache != "true" {
+				t.Errorf("revalidated fetch = %d %q cache=%q, want the cached body", status, body, fromCache)
+			}
+			if full.Load() != 1 || notModified.Load() != 1 {
+				t.Errorf("server sent %d full responses and %d 304s, want 1 and 1", full.Load(), notModified.Load())
+			}
+
+			// The 304 renewed the entry
+			if _, _, fromCache := fetch(t, client, server.URL); fromCache != "true" || notModified.Load() != 1 {
+				t.Error("revalidated entry was not fresh again")
+			}
+			if stats := cache.Stats(); stats.Revalidated != 1 || stats.Hits != 2 {
+				t.Errorf("Stats() = %+v, want 1 revalidation and 2 hits", stats)
+			}
+		})
+	}
+}
+
+func TestCachedHTTPClientDropsExpiredWithoutValidator(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		if r.Header.Get("If-None-Match") != "" || r.Header.Get("If-Modified-Since") != "" {
+			t.Error("conditional request sent for an entry without validators")
+		}
+	}))
+	defer server.Close()
+
+	cache, err := NewMemoryOnlyCache(20*time.Millisecond, slog.New(slog.DiscardHandler))
+	if err != nil {
+		t.Fatalf("NewMemoryOnlyCache: %v", err)
+	}
+	client := NewCachedHTTPClient(cache, server.Client(), slog.New(slog.DiscardHandler))
+	fetch(t, client, server.URL)
+	time.Sleep(50 * time.Millisecond)
+	if _, ok := cache.Stale(server.URL); ok {
+		t.Error("Stale returned an entry that cannot be revalidated")
+	}
+	fetch(t, client, server.URL)
+	if hits.Load() != 2 {
+		t.Errorf("server saw %d requests, want 2", hits.Load())
+	}
+}
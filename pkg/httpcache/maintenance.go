@@ -0,0 +1,307 @@
+package httpcache
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// countersKey stores hit, miss and revalidation totals across processes. Entry keys are hex hashes, so it cannot clash.
+const countersKey = "counters"
+
+// Stats describes the cache's contents and effectiveness.
+type Stats struct {
+	Ages        []AgeBucket `json:"ages,omitempty"`
+	Bytes       int64       `json:"bytes"`
+	Hits        int64       `json:"hits"`
+	Misses      int64       `json:"misses"`
+	Revalidated int64       `json:"revalidated"`
+	Entries     int         `json:"entries"`
+	Fresh       int         `json:"fresh"`
+	Stale       int         `json:"stale"` // Expired, but kept for revalidation
+}
+
+// HitRate returns the share of lookups answered from the cache, or 0 before any lookup.
+func (s Stats) HitRate() float64 {
+	if total := s.Hits + s.Misses; total > 0 {
+		return float64(s.Hits) / float64(total)
+	}
+	return 0
+}
+
+// AgeBucket counts the entries stored less than MaxAge ago, and more than the previous bucket's MaxAge.
+// The last bucket has no MaxAge and holds everything older.
+type AgeBucket struct {
+	Label   string        `json:"label"`
+	MaxAge  time.Duration `json:"-"`
+	Entries int           `json:"entries"`
+	Bytes   int64         `json:"bytes"`
+}
+
+// ageBuckets returns empty buckets for the age histogram.
+func ageBuckets() []AgeBucket {
+	return []AgeBucket{
+		{Label: "<1h", MaxAge: time.Hour},
+		{Label: "<1d", MaxAge: 24 * time.Hour},
+		{Label: "<7d", MaxAge: 7 * 24 * time.Hour},
+		{Label: "<30d", MaxAge: 30 * 24 * time.Hour},
+		{Label: "older"},
+	}
+}
+
+type counters struct {
+	Hits        int64 `json:"hits"`
+	Misses      int64 `json:"misses"`
+	Revalidated int64 `json:"revalidated"`
+}
+
+// storedCounters returns the totals saved by earlier processes.
+func (c *OtterCache) storedCounters(ctx context.Context) (counters, error) {
+	var total counters
+	if c.store == nil {
+		return total, nil
+	}
+	entry, found, err := c.store.Get(ctx, countersKey)
+	if err != nil || !found {
+		return total, err
+	}
+	if err := json.Unmarshal(entry.Data, &total); err != nil {
+		return counters{}, fmt.Errorf("decoding cache counters: %w", err)
+	}
+	return total, nil
+}
+
+// flushCounters adds what this process counted since the last flush to the store's totals.
+func (c *OtterCache) flushCounters(ctx context.Context) error {
+	now := counters{Hits: c.hits.Load(), Misses: c.misses.Load(), Revalidated: c.revalidated.Load()}
+	if now == c.flushed {
+		return nil
+	}
+	total, err := c.storedCounters(ctx)
+	if err != nil {
+		return err
+	}
+	total.Hits += now.Hits - c.flushed.Hits
+	total.Misses += now.Misses - c.flushed.Misses
+	total.Revalidated += now.Revalidated - c.flushed.Revalidated
+	data, err := json.Marshal(total)
+	if err != nil {
+		return err
+	}
+	// Counters never expire; a far-off expiry keeps Redis from dropping them
+	entry := CacheEntry{ExpiresAt: time.Now().AddDate(100, 0, 0), Data: data}
+	if err := c.store.Set(ctx, countersKey, entry); err != nil {
+		return err
+	}
+	c.flushed = now
+	return nil
+}
+
+// Range calls fn for each entry, from the persistent store if there is one, else from memory.
+// Entries that can no longer be served or revalidated are skipped.
+func (c *OtterCache) Range(ctx context.Context, fn func(key string, entry CacheEntry) bool) error {
+	now := time.Now()
+	visit := func(key string, entry CacheEntry) bool {
+		if key == countersKey || !now.Before(entry.retainUntil()) {
+			return true
+		}
+		return fn(key, entry)
+	}
+	if c.store != nil {
+		return c.store.Range(ctx, visit)
+	}
+	for key, entry := range c.cache.All() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if !visit(key, entry) {
+			break
+		}
+	}
+	return nil
+}
+
+// age returns how long ago the entry was stored. Entries from before StoredAt was recorded are dated from
+// their expiry.
+func (c *OtterCache) age(entry CacheEntry, now time.Time) time.Duration {
+	if !entry.StoredAt.IsZero() {
+		return now.Sub(entry.StoredAt)
+	}
+	return now.Sub(entry.ExpiresAt.Add(-c.ttl))
+}
+
+// Inspect scans every entry and reports sizes, freshness and the age histogram, with hit counts
+// that include earlier processes sharing the store.
+func (c *OtterCache) Inspect(ctx context.Context) (Stats, error) {
+	stats := c.Stats()
+	stats.Entries = 0
+	stats.Ages = ageBuckets()
+
+	total, err := c.storedCounters(ctx)
+	if err != nil {
+		return Stats{}, err
+	}
+	stats.Hits += total.Hits - c.flushed.Hits
+	stats.Misses += total.Misses - c.flushed.Misses
+	stats.Revalidated += total.Revalidated - c.flushed.Revalidated
+
+	now := time.Now()
+	err = c.Range(ctx, func(_ string, entry CacheEntry) bool {
+		size := int64(len(entry.Data))
+		stats.Entries++
+		stats.Bytes += size
+		if now.Before(entry.ExpiresAt) {
+			stats.Fresh++
+		} else {
+			stats.Stale++
+		}
+		age := c.age(entry, now)
+		for i := range stats.Ages {
+			if b := &stats.Ages[i]; b.MaxAge == 0 || age < b.MaxAge {
+				b.Entries++
+				b.Bytes += size
+				break
+			}
+		}
+		return true
+	})
+	return stats, err
+}
+
+// Matcher selects cache entries, for Purge.
+type Matcher func(key string, entry CacheEntry) bool
+
+// MatchUser selects entries fetched for a GitHub user: URLs with the username as a path segment or as
+// a search qualifier such as author:username, and API calls whose request body names the user.
+func MatchUser(username string) Matcher {
+	user := strings.ToLower(username)
+	quoted := []byte(`"` + user + `"`)
+	return func(_ string, entry CacheEntry) bool {
+		if u, err := url.Parse(entry.URL); err == nil {
+			for _, segment := range strings.Split(strings.ToLower(u.Path), "/") {
+				if segment == user {
+					return true
+				}
+			}
+			for _, values := range u.Query() {
+				for _, v := range values {
+					for _, term := range strings.Fields(strings.ToLower(v)) {
+						if _, qualified, ok := strings.Cut(term, ":"); ok && qualified == user {
+							return true
+						}
+					}
+				}
+			}
+		}
+		return bytes.Contains(bytes.ToLower(entry.Request), quoted)
+	}
+}
+
+// MatchURL selects entries whose URL matches pattern, where * matches any run of characters, including slashes.
+func MatchURL(pattern string) Matcher {
+	re := regexp.MustCompile("^" + strings.ReplaceAll(regexp.QuoteMeta(pattern), `\*`, ".*") + "$")
+	return func(_ string, entry CacheEntry) bool {
+		return re.MatchString(entry.URL)
+	}
+}
+
+// OlderThan selects entries stored more than age ago.
+func (c *OtterCache) OlderThan(age time.Duration) Matcher {
+	return func(_ string, entry CacheEntry) bool {
+		return c.age(entry, time.Now()) > age
+	}
+}
+
+// MatchAll selects entries selected by every matcher; with none, it selects everything.
+func MatchAll(matchers ...Matcher) Matcher {
+	return func(key string, entry CacheEntry) bool {
+		for _, m := range matchers {
+			if !m(key, entry) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// Purge removes the entries selected by match and returns how many it removed. Entries are not indexed,
+// so every purge reads the whole store. Other processes sharing the store stop serving removed entries
+// from memory within memoryRecheck.
+func (c *OtterCache) Purge(ctx context.Context, match Matcher) (int, error) {
+	var keys []string
+	if err := c.Range(ctx, func(key string, entry CacheEntry) bool {
+		if match(key, entry) {
+			keys = append(keys, key)
+		}
+		return true
+	}); err != nil {
+		return 0, err
+	}
+	for i, key := range keys {
+		c.cache.Invalidate(key)
+		if c.store == nil {
+			continue
+		}
+		if err := c.store.Delete(ctx, key); err != nil {
+			return i, fmt.Errorf("deleting %s: %w", key, err)
+		}
+	}
+	return len(keys), nil
+}
+
+// exportRecord is one line of an export: an entry and its key.
+type exportRecord struct {
+	Key string `json:"key"`
+	CacheEntry
+}
+
+// Export writes every entry to w as newline-delimited JSON, for Import on another machine.
+func (c *OtterCache) Export(ctx context.Context, w io.Writer) (int, error) {
+	enc := json.NewEncoder(w)
+	n := 0
+	var encErr error
+	err := c.Range(ctx, func(key string, entry CacheEntry) bool {
+		if encErr = enc.Encode(exportRecord{Key: key, CacheEntry: entry}); encErr != nil {
+			return false
+		}
+		n++
+		return true
+	})
+	return n, errors.Join(err, encErr)
+}
+
+// Import reads entries written by Export and stores them with their original expiry, skipping
+// entries too old to use. It returns how many entries were stored.
+func (c *OtterCache) Import(ctx context.Context, r io.Reader) (int, error) {
+	dec := json.NewDecoder(bufio.NewReader(r))
+	now := time.Now()
+	n := 0
+	for {
+		var rec exportRecord
+		err := dec.Decode(&rec)
+		if errors.Is(err, io.EOF) {
+			return n, nil
+		}
+		if err != nil {
+			return n, fmt.Errorf("decoding entry %d: %w", n+1, err)
+		}
+		if err := ctx.Err(); err != nil {
+			return n, err
+		}
+		if rec.Key == "" || rec.Key == countersKey || !now.Before(rec.retainUntil()) {
+			continue
+		}
+		if err := c.put(rec.Key, rec.CacheEntry); err != nil {
+			return n, err
+		}
+		n++
+	}
+}
@@ -0,0 +1,132 @@
+package httpcache
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func seededCache(t *testing.T) *OtterCache {
+	t.Helper()
+	c, err := NewOtterCache(context.Background(), t.TempDir(), time.Hour, slog.New(slog.DiscardHandler))
+	if err != nil {
+		t.Fatalf("NewOtterCache: %v", err)
+	}
+	t.Cleanup(func() { c.Close() }) //nolint:errcheck // test
+	for url, data := range map[string]string{
+		"https://api.github.com/users/alice":                       "alice",
+		"https://api.github.com/users/alice/events?per_page=100":   "events",
+		"https://api.github.com/search/issues?q=author%3Aalice":    "issues",
+		"https://api.github.com/users/bob":                         "bob",
+		"https://github.com/bob":                                   "profile",
+		"https://api.github.com/repos/carol/alice-notes/contents/": "repo",
+	} {
+		if err := c.Set(url, []byte(data), ""); err != nil {
+			t.Fatalf("Set: %v", err)
+		}
+	}
+	if err := c.SetAPICall("https://api.github.com/graphql", []byte(`{"variables":{"login":"Alice"}}`), []byte("gql")); err != nil {
+		t.Fatalf("SetAPICall: %v", err)
+	}
+	return c
+}
+
+func urls(t *testing.T, c *OtterCache) map[string]bool {
+	t.Helper()
+	seen := map[string]bool{}
+	if err := c.Range(context.Background(), func(_ string, e CacheEntry) bool {
+		seen[e.URL] = true
+		return true
+	}); err != nil {
+		t.Fatalf("Range: %v", err)
+	}
+	return seen
+}
+
+func TestPurge(t *testing.T) {
+	tests := []struct {
+		match func(c *OtterCache) Matcher
+		name  string
+		want  int // Entries removed of the seven seeded
+	}{
+		{name: "user", match: func(*OtterCache) Matcher { return MatchUser("ALICE") }, want: 4},
+		{name: "url pattern", match: func(*OtterCache) Matcher { return MatchURL("https://api.github.com/users/*") }, want: 3},
+		{name: "user and pattern", match: func(*OtterCache) Matcher {
+			return MatchAll(MatchUser("bob"), MatchURL("https://github.com/*"))
+		}, want: 1},
+		{name: "older than", match: func(c *OtterCache) Matcher { return c.OlderThan(time.Hour) }, want: 0},
+		{name: "all", match: func(*OtterCache) Matcher { return MatchAll() }, want: 7},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := seededCache(t)
+			n, err := c.Purge(context.Background(), tt.match(c))
+			if err != nil || n != tt.want {
+				t.Fatalf("Purge = %d, %v; want %d", n, err, tt.want)
+			}
+			if left := len(urls(t, c)); left != 7-tt.want {
+				t.Errorf("%d entries left, want %d", left, 7-tt.want)
+			}
+		})
+	}
+
+	c := seededCache(t)
+	if _, err := c.Purge(context.Background(), MatchUser("alice")); err != nil {
+		t.Fatalf("Purge: %v", err)
+	}
+	if _, _, found := c.Get("https://api.github.com/users/alice"); found {
+		t.Error("purged entry still served from memory")
+	}
+	left := urls(t, c)
+	if !left["https://api.github.com/users/bob"] || !left["https://api.github.com/repos/carol/alice-notes/contents/"] {
+		t.Errorf("purging alice removed others' entries; left %v", left)
+	}
+}
+
+func TestExportImport(t *testing.T) {
+	src := seededCache(t)
+	var buf bytes.Buffer
+	n, err := src.Export(context.Background(), &buf)
+	if err != nil || n != 7 {
+		t.Fatalf("Export = %d, %v; want 7", n, err)
+	}
+
+	dst, err := NewMemoryOnlyCache(time.Hour, slog.New(slog.DiscardHandler))
+	if err != nil {
+		t.Fatalf("NewMemoryOnlyCache: %v", err)
+	}
+	if n, err := dst.Import(context.Background(), &buf); err != nil || n != 7 {
+		t.Fatalf("Import = %d, %v; want 7", n, err)
+	}
+	if data, _, found := dst.Get("https://github.com/bob"); !found || string(data) != "profile" {
+		t.Errorf("Get after import = %q, %v", data, found)
+	}
+	if data, found := dst.APICall("https://api.github.com/graphql", []byte(`{"variables":{"login":"Alice"}}`)); !found || string(data) != "gql" {
+		t.Errorf("APICall after import = %q, %v", data, found)
+	}
+}
+
+func TestInspect(t *testing.T) {
+	c := seededCache(t)
+	c.Get("https://api.github.com/users/bob")
+	c.Get("https://api.github.com/users/dave")
+	if err := c.Close(); err != nil { // Saves this process's counters
+		t.Fatalf("Close: %v", err)
+	}
+
+	stats, err := c.Inspect(context.Background())
+	if err != nil {
+		t.Fatalf("Inspect: %v", err)
+	}
+	if stats.Entries != 7 || stats.Fresh != 7 || stats.Stale != 0 || stats.Bytes != 34 {
+		t.Errorf("Inspect = %+v, want 7 fresh entries of 34 bytes", stats)
+	}
+	if stats.Hits != 1 || stats.Misses != 1 || stats.HitRate() != 0.5 {
+		t.Errorf("hits %d, misses %d, rate %v; want 1, 1, 0.5", stats.Hits, stats.Misses, stats.HitRate())
+	}
+	if stats.Ages[0].Entries != 7 {
+		t.Errorf("age histogram %+v, want everything under an hour", stats.Ages)
+	}
+}
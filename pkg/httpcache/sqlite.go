@@ -0,0 +1,101 @@
+//go:build cgo && sqlite
+
+package httpcache
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	_ "github.com/mattn/go-sqlite3" // Registers the sqlite3 driver
+)
+
+// SQLiteStorage stores entries in an embedded SQLite database. Write-ahead logging lets several
+// processes read and write the same database concurrently. The driver needs cgo, so SQLite support
+// is only built with -tags sqlite.
+type SQLiteStorage struct {
+	db *sql.DB
+}
+
+func openSQLite(path string) (Storage, error) {
+	return NewSQLiteStorage(path)
+}
+
+// NewSQLiteStorage creates or opens a SQLite database at path.
+func NewSQLiteStorage(path string) (*SQLiteStorage, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return nil, fmt.Errorf("creating cache directory: %w", err)
+	}
+	db, err := sql.Open("sqlite3", "file:"+path+"?_journal_mode=WAL&_busy_timeout=10000&_synchronous=NORMAL")
+	if err != nil {
+		return nil, fmt.Errorf("opening %s: %w", path, err)
+	}
+	const schema = `CREATE TABLE IF NOT EXISTS httpcache (key TEXT PRIMARY KEY, entry BLOB NOT NULL)`
+	if _, err := db.ExecContext(context.Background(), schema); err != nil {
+		return nil, errors.Join(fmt.Errorf("creating schema in %s: %w", path, err), db.Close())
+	}
+	return &SQLiteStorage{db: db}, nil
+}
+
+// Get returns the entry stored under key.
+func (s *SQLiteStorage) Get(ctx context.Context, key string) (CacheEntry, bool, error) {
+	var data []byte
+	err := s.db.QueryRowContext(ctx, `SELECT entry FROM httpcache WHERE key = ?`, key).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return CacheEntry{}, false, nil
+	}
+	if err != nil {
+		return CacheEntry{}, false, err
+	}
+	entry, err := decodeEntry(data)
+	return entry, err == nil, err
+}
+
+// Set stores entry under key.
+func (s *SQLiteStorage) Set(ctx context.Context, key string, entry CacheEntry) error {
+	data, err := encodeEntry(entry)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.ExecContext(ctx, `INSERT INTO httpcache (key, entry) VALUES (?, ?)
+		ON CONFLICT (key) DO UPDATE SET entry = excluded.entry`, key, data)
+	return err
+}
+
+// Delete removes the entry stored under key.
+func (s *SQLiteStorage) Delete(ctx context.Context, key string) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM httpcache WHERE key = ?`, key)
+	return err
+}
+
+// Range calls fn for each stored entry until fn returns false.
+func (s *SQLiteStorage) Range(ctx context.Context, fn func(key string, entry CacheEntry) bool) error {
+	rows, err := s.db.QueryContext(ctx, `SELECT key, entry FROM httpcache ORDER BY key`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close() //nolint:errcheck // read-only
+	for rows.Next() {
+		var key string
+		var data []byte
+		if err := rows.Scan(&key, &data); err != nil {
+			return err
+		}
+		entry, err := decodeEntry(data)
+		if err != nil {
+			return fmt.Errorf("key %s: %w", key, err)
+		}
+		if !fn(key, entry) {
+			return nil
+		}
+	}
+	return rows.Err()
+}
+
+// Close closes the database.
+func (s *SQLiteStorage) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,10 @@
+//go:build !(cgo && sqlite)
+
+package httpcache
+
+import "errors"
+
+// openSQLite fails in builds without the cgo SQLite driver.
+func openSQLite(string) (Storage, error) {
+	return nil, errors.New("SQLite storage needs a cgo build with -tags sqlite")
+}
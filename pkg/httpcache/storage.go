@@ -0,0 +1,66 @@
+package httpcache
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// ErrUnknownStorage is returned by OpenStorage for a spec it does not recognize.
+var ErrUnknownStorage = errors.New("unknown cache storage")
+
+// Storage persists cache entries beneath OtterCache's in-memory layer, one entry at a time.
+// Implementations must be safe for concurrent use, and should tolerate other processes sharing the same store.
+type Storage interface {
+	// Get returns the entry stored under key, reporting false if there is none.
+	Get(ctx context.Context, key string) (CacheEntry, bool, error)
+	// Set stores entry under key, replacing any previous entry.
+	Set(ctx context.Context, key string, entry CacheEntry) error
+	// Delete removes the entry stored under key, if any.
+	Delete(ctx context.Context, key string) error
+	// Range calls fn for each stored entry until fn returns false.
+	Range(ctx context.Context, fn func(key string, entry CacheEntry) bool) error
+	// Close releases the store.
+	Close() error
+}
+
+// OpenStorage opens the store described by spec:
+//
+//	bolt:/path/to/httpcache.db     embedded bbolt database (also any plain path)
+//	sqlite:/path/to/httpcache.db   embedded SQLite database in WAL mode (built with -tags sqlite)
+//	redis://host:6379/0            Redis-compatible server (rediss:// for TLS)
+func OpenStorage(spec string) (Storage, error) {
+	switch {
+	case strings.HasPrefix(spec, "redis://"), strings.HasPrefix(spec, "rediss://"):
+		return NewRedisStorage(spec)
+	case strings.HasPrefix(spec, "sqlite:"):
+		return openSQLite(strings.TrimPrefix(spec, "sqlite:"))
+	case strings.HasPrefix(spec, "bolt:"):
+		return NewBoltStorage(strings.TrimPrefix(spec, "bolt:"))
+	case spec != "" && !strings.Contains(spec, "://"):
+		return NewBoltStorage(filepath.Clean(spec))
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownStorage, spec)
+	}
+}
+
+// encodeEntry serializes an entry for storage, in the gob encoding the cache has always persisted.
+func encodeEntry(entry CacheEntry) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(entry); err != nil {
+		return nil, fmt.Errorf("encoding cache entry: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func decodeEntry(data []byte) (CacheEntry, error) {
+	var entry CacheEntry
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&entry); err != nil {
+		return CacheEntry{}, fmt.Errorf("decoding cache entry: %w", err)
+	}
+	return entry, nil
+}
@@ -0,0 +1,256 @@
+package httpcache
+
+import (
+	"context"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func testStorages(t *testing.T) map[string]func(t *testing.T) Storage {
+	t.Helper()
+	storages := map[string]func(t *testing.T) Storage{
+		"bolt": func(t *testing.T) Storage {
+			t.Helper()
+			s, err := OpenStorage(filepath.Join(t.TempDir(), "httpcache.db"))
+			if err != nil {
+				t.Fatalf("OpenStorage: %v", err)
+			}
+			return s
+		},
+		"sqlite": func(t *testing.T) Storage {
+			t.Helper()
+			s, err := OpenStorage("sqlite:" + filepath.Join(t.TempDir(), "httpcache.db"))
+			if err != nil {
+				t.Skipf("SQLite unavailable: %v", err)
+			}
+			return s
+		},
+	}
+	// A Redis-compatible server is only available where the environment provides one
+	if url := os.Getenv("GUTZ_TEST_REDIS_URL"); url != "" {
+		storages["redis"] = func(t *testing.T) Storage {
+			t.Helper()
+			s, err := OpenStorage(url)
+			if err != nil {
+				t.Fatalf("OpenStorage: %v", err)
+			}
+			return s
+		}
+	}
+	return storages
+}
+
+func TestStorage(t *testing.T) {
+	for name, open := range testStorages(t) {
+		t.Run(name, func(t *testing.T) {
+			store := open(t)
+			defer store.Close() //nolint:errcheck // test
+			ctx := context.Background()
+			key := cacheKey(t.Name(), nil)
+
+			if _, found, err := store.Get(ctx, key); found || err != nil {
+				t.Fatalf("Get on empty store = %v, %v", found, err)
+			}
+			want := CacheEntry{ExpiresAt: time.Now().Add(time.Hour).Round(0), ETag: `"v1"`, Data: []byte("body")}
+			if err := store.Set(ctx, key, want); err != nil {
+				t.Fatalf("Set: %v", err)
+			}
+			got, found, err := store.Get(ctx, key)
+			if !found || err != nil || !got.ExpiresAt.Equal(want.ExpiresAt) || got.ETag != want.ETag || string(got.Data) != "body" {
+				t.Fatalf("Get = %+v, %v, %v; want %+v", got, found, err, want)
+			}
+
+			var keys []string
+			if err := store.Range(ctx, func(k string, _ CacheEntry) bool {
+				keys = append(keys, k)
+				return true
+			}); err != nil || len(keys) != 1 || keys[0] != key {
+				t.Errorf("Range saw %q, %v; want [%s]", keys, err, key)
+			}
+
+			if err := store.Delete(ctx, key); err != nil {
+				t.Fatalf("Delete: %v", err)
+			}
+			if _, found, _ := store.Get(ctx, key); found {
+				t.Error("entry survived Delete")
+			}
+		})
+	}
+}
+
+// TestBoltConcurrentWriters writes from many goroutines through two stores on one file, as two
+// processes sharing a cache directory would, and checks that no entry is lost.
+func TestBoltConcurrentWriters(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "httpcache.db")
+	var stores []*BoltStorage
+	for range 2 {
+		s, err := NewBoltStorage(path)
+		if err != nil {
+			t.Fatalf("NewBoltStorage: %v", err)
+		}
+		defer s.Close() //nolint:errcheck // test
+		stores = append(stores, s)
+	}
+
+	const writers, writes = 8, 20
+	ctx := context.Background()
+	var wg sync.WaitGroup
+	errs := make(chan error, len(stores)*writers*writes)
+	for i, s := range stores {
+		for w := range writers {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for n := range writes {
+					key := fmt.Sprintf("%d/%d/%d", i, w, n)
+					if err := s.Set(ctx, key, CacheEntry{ExpiresAt: time.Now().Add(time.Hour), Data: []byte(key)}); err != nil {
+						errs <- err
+					}
+				}
+			}()
+		}
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Errorf("Set: %v", err)
+	}
+
+	count := 0
+	if err := stores[0].Range(ctx, func(key string, entry CacheEntry) bool {
+		if string(entry.Data) != key {
+			t.Errorf("entry %s = %q", key, entry.Data)
+		}
+		count++
+		return true
+	}); err != nil {
+		t.Fatalf("Range: %v", err)
+	}
+	if want := len(stores) * writers * writes; count != want {
+		t.Errorf("stored %d entries, want %d", count, want)
+	}
+
+	// A canceled context gives up waiting for the database instead of blocking
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := stores[1].Set(canceled, "late", CacheEntry{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Set with a canceled context = %v, want context.Canceled", err)
+	}
+}
+
+func TestOtterCachePersistsIncrementally(t *testing.T) {
+	dir := t.TempDir()
+	logger := slog.New(slog.DiscardHandler)
+	ctx := context.Background()
+
+	// Two caches open on the same directory, as two CLI processes would be
+	first, err := NewOtterCache(ctx, dir, time.Hour, logger)
+	if err != nil {
+		t.Fatalf("NewOtterCache: %v", err)
+	}
+	second, err := NewOtterCache(ctx, dir, time.Hour, logger)
+	if err != nil {
+		t.Fatalf("NewOtterCache: %v", err)
+	}
+	if err := first.Set("https://api.github.com/users/a", []byte("a"), ""); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if err := second.Set("https://api.github.com/users/b", []byte("b"), ""); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	for _, c := range []*OtterCache{first, second} {
+		for _, user := range []string{"a", "b"} {
+			if data, _, found := c.Get("https://api.github.com/users/" + user); !found || string(data) != user {
+				t.Errorf("Get(%s) = %q, %v; want each process to see the other's writes", user, data, found)
+			}
+		}
+	}
+	if err := errors.Join(first.Close(), second.Close()); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "otter-cache.gob")); !errors.Is(err, os.ErrNotExist) {
+		t.Error("closing wrote a gob snapshot")
+	}
+}
+
+func TestOtterCacheRechecksStorage(t *testing.T) {
+	defer func(d time.Duration) { memoryRecheck = d }(memoryRecheck)
+	memoryRecheck = 50 * time.Millisecond
+	dir := t.TempDir()
+	logger := slog.New(slog.DiscardHandler)
+	ctx := context.Background()
+
+	// Two replicas sharing a store
+	first, err := NewOtterCache(ctx, dir, time.Hour, logger)
+	if err != nil {
+		t.Fatalf("NewOtterCache: %v", err)
+	}
+	defer first.Close() //nolint:errcheck // test
+	second, err := NewOtterCache(ctx, dir, time.Hour, logger)
+	if err != nil {
+		t.Fatalf("NewOtterCache: %v", err)
+	}
+	defer second.Close() //nolint:errcheck // test
+
+	const url = "https://api.github.com/users/alice"
+	if err := first.Set(url, []byte("alice"), ""); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if _, _, found := first.Get(url); !found {
+		t.Fatal("Get missed an entry just set")
+	}
+	if n, err := second.Purge(ctx, MatchUser("alice")); n != 1 || err != nil {
+		t.Fatalf("Purge = %d, %v; want 1 entry", n, err)
+	}
+	time.Sleep(2 * memoryRecheck)
+	if data, _, found := first.Get(url); found {
+		t.Errorf("Get = %q after another replica purged it, want a miss once memory is rechecked", data)
+	}
+}
+
+func TestOtterCacheImportsLegacyFile(t *testing.T) {
+	dir := t.TempDir()
+	legacy := filepath.Join(dir, "otter-cache.gob")
+	url := "https://api.github.com/users/legacy"
+	entries := map[string]CacheEntry{
+		cacheKey(url, nil):            {ExpiresAt: time.Now().Add(time.Hour), Data: []byte("kept")},
+		cacheKey(url+"/expired", nil): {ExpiresAt: time.Now().Add(-time.Hour), Data: []byte("dropped")},
+	}
+	f, err := os.Create(legacy)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if err := errors.Join(gob.NewEncoder(f).Encode(entries), f.Close()); err != nil {
+		t.Fatalf("write legacy file: %v", err)
+	}
+
+	c, err := NewOtterCache(context.Background(), dir, time.Hour, slog.New(slog.DiscardHandler))
+	if err != nil {
+		t.Fatalf("NewOtterCache: %v", err)
+	}
+	defer c.Close() //nolint:errcheck // test
+	if data, _, found := c.Get(url); !found || string(data) != "kept" {
+		t.Errorf("Get = %q, %v; want the imported entry", data, found)
+	}
+	if _, err := os.Stat(legacy); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("legacy file still present after import: %v", err)
+	}
+	n := 0
+	if err := c.store.Range(context.Background(), func(string, CacheEntry) bool { n++; return true }); err != nil || n != 1 {
+		t.Errorf("store holds %d entries (%v), want only the unexpired one", n, err)
+	}
+}
+
+func TestOpenStorageRejectsUnknown(t *testing.T) {
+	if _, err := OpenStorage("memcached://localhost:11211"); !errors.Is(err, ErrUnknownStorage) {
+		t.Errorf("OpenStorage = %v, want ErrUnknownStorage", err)
+	}
+}
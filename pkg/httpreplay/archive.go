@@ -0,0 +1,346 @@
+// Package httpreplay records HTTP exchanges into a fixture archive and replays them without network access.
+package httpreplay
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNotRecorded is returned in replay mode when a request has no recorded response.
+var ErrNotRecorded = errors.New("request not found in fixture archive")
+
+// formatVersion is bumped whenever the on-disk archive layout changes incompatibly.
+const formatVersion = 1
+
+// Mode selects whether an Archive records live traffic or replays recorded traffic.
+type Mode int
+
+const (
+	// Record passes requests through to the network and stores every exchange.
+	Record Mode = iota
+	// Replay answers requests from the archive and never touches the network.
+	Replay
+)
+
+// redactedParams are query parameters whose values are scrubbed from recorded URLs.
+var redactedParams = []string{"key", "api_key", "access_token", "client_secret"}
+
+// Exchange is a single recorded request and its response.
+type Exchange struct {
+	Header      http.Header `json:"header,omitempty"`
+	Method      string      `json:"method"`
+	URL         string      `json:"url"`
+	RequestBody string      `json:"request_body,omitempty"`
+	Body        []byte      `json:"body"`
+	Status      int         `json:"status"`
+}
+
+// APICall is a recorded non-HTTP API call, such as a Gemini SDK request, keyed by its cache key.
+type APICall struct {
+	Key      string `json:"key"`
+	Response []byte `json:"response"`
+}
+
+// archiveFile is the serialized form of an Archive.
+type archiveFile struct {
+	RecordedAt time.Time  `json:"recorded_at,omitzero"`
+	Exchanges  []Exchange `json:"exchanges"`
+	APICalls   []APICall  `json:"api_calls,omitempty"`
+	Version    int        `json:"version"`
+}
+
+// Archive holds recorded exchanges. It is safe for concurrent use.
+type Archive struct {
+	recordedAt time.Time
+	byKey      map[string][]int // request key -> indexes into exchanges, in recording order
+	cursor     map[string]int   // request key -> next exchange to replay
+	apiCalls   map[string][]byte
+	exchanges  []Exchange
+	apiOrder   []string
+	mode       Mode
+	mu         sync.Mutex
+}
+
+// NewRecorder returns an empty archive in record mode.
+func NewRecorder() *Archive {
+	return &Archive{
+		recordedAt: time.Now().UTC().Truncate(time.Second),
+		mode:       Record,
+		byKey:      make(map[string][]int),
+		cursor:     make(map[string]int),
+		apiCalls:   make(map[string][]byte),
+	}
+}
+
+// Load reads an archive from path and returns it in replay mode.
+// Files ending in ".gz" are gzip-compressed.
+func Load(path string) (*Archive, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening fixture archive: %w", err)
+	}
+	defer func() {
+		_ = f.Close() //nolint:errcheck // read-only file, close error is not actionable
+	}()
+
+	var r io.Reader = f
+	if strings.HasSuffix(path, ".gz") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, fmt.Errorf("decompressing fixture archive: %w", err)
+		}
+		defer func() {
+			_ = gz.Close() //nolint:errcheck // read-only stream, close error is not actionable
+		}()
+		r = gz
+	}
+
+	var file archiveFile
+	if err := json.NewDecoder(r).Decode(&file); err != nil {
+		return nil, fmt.Errorf("decoding fixture archive: %w", err)
+	}
+	if file.Version != formatVersion {
+		return nil, fmt.Errorf("unsupported fixture archive version %d (want %d)", file.Version, formatVersion)
+	}
+
+	a := NewRecorder()
+	a.mode = Replay
+	a.recordedAt = file.RecordedAt
+	for _, ex := range file.Exchanges {
+		a.add(ex)
+	}
+	for _, call := range file.APICalls {
+		a.addAPICall(call.Key, call.Response)
+	}
+	return a, nil
+}
+
+// Save writes the archive to path, creating parent directories as needed.
+// Files ending in ".gz" are gzip-compressed.
+func (a *Archive) Save(path string) error {
+	a.mu.Lock()
+	file := archiveFile{
+		Version:    formatVersion,
+		RecordedAt: a.recordedAt,
+		Exchanges:  append([]Exchange{}, a.exchanges...),
+	}
+	for _, key := range a.apiOrder {
+		file.APICalls = append(file.APICalls, APICall{Key: key, Response: a.apiCalls[key]})
+	}
+	a.mu.Unlock()
+
+	var buf bytes.Buffer
+	var w io.Writer = &buf
+	var gz *gzip.Writer
+	if strings.HasSuffix(path, ".gz") {
+		gz = gzip.NewWriter(&buf)
+		w = gz
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(file); err != nil {
+		return fmt.Errorf("encoding fixture archive: %w", err)
+	}
+	if gz != nil {
+		if err := gz.Close(); err != nil {
+			return fmt.Errorf("compressing fixture archive: %w", err)
+		}
+	}
+
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0o750); err != nil {
+			return fmt.Errorf("creating fixture directory: %w", err)
+		}
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0o600); err != nil {
+		return fmt.Errorf("writing fixture archive: %w", err)
+	}
+	return nil
+}
+
+// Mode returns whether the archive is recording or replaying.
+func (a *Archive) Mode() Mode {
+	return a.mode
+}
+
+// RecordedAt returns when the archive was recorded, or the zero time for archives saved
+// before recording times were stored. Detectors use it as their clock so that time-relative
+// windows select the same activity on replay as they did when recording.
+func (a *Archive) RecordedAt() time.Time {
+	return a.recordedAt
+}
+
+// Len returns the number of recorded HTTP exchanges.
+func (a *Archive) Len() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return len(a.exchanges)
+}
+
+// Transport wraps next so that every round trip is recorded (record mode) or answered
+// from the archive (replay mode). In replay mode next is never called and may be nil.
+func (a *Archive) Transport(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &transport{archive: a, next: next}
+}
+
+// APICall returns the recorded response for a non-HTTP API call. In record mode it always
+// reports a miss so that the live API is called and its response captured.
+// It satisfies the cache interface used by the gemini package.
+func (a *Archive) APICall(key string, _ []byte) ([]byte, bool) {
+	if a.mode != Replay {
+		return nil, false
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	data, ok := a.apiCalls[key]
+	return data, ok
+}
+
+// SetAPICall stores the response for a non-HTTP API call. It is a no-op in replay mode.
+func (a *Archive) SetAPICall(key string, _ []byte, response []byte) error {
+	if a.mode == Replay {
+		return nil
+	}
+	a.addAPICall(key, response)
+	return nil
+}
+
+func (a *Archive) addAPICall(key string, response []byte) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if _, exists := a.apiCalls[key]; !exists {
+		a.apiOrder = append(a.apiOrder, key)
+	}
+	a.apiCalls[key] = response
+}
+
+func (a *Archive) add(ex Exchange) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	key := requestKey(ex.Method, ex.URL, []byte(ex.RequestBody))
+	a.byKey[key] = append(a.byKey[key], len(a.exchanges))
+	a.exchanges = append(a.exchanges, ex)
+}
+
+// next returns the next recorded exchange for key. Identical requests are replayed in
+// recording order (so retries see the same sequence of failures), repeating the last one.
+func (a *Archive) next(key string) (Exchange, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	indexes := a.byKey[key]
+	if len(indexes) == 0 {
+		return Exchange{}, false
+	}
+	i := min(a.cursor[key], len(indexes)-1)
+	a.cursor[key] = i + 1
+	return a.exchanges[indexes[i]], true
+}
+
+// transport is the http.RoundTripper returned by Archive.Transport.
+type transport struct {
+	archive *Archive
+	next    http.RoundTripper
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		reqBody, err = io.ReadAll(req.Body)
+		if closeErr := req.Body.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading request body: %w", err)
+		}
+		req.Body = io.NopCloser(bytes.NewReader(reqBody))
+	}
+	recordedURL := redactURL(req.URL)
+
+	if t.archive.mode == Replay {
+		ex, ok := t.archive.next(requestKey(req.Method, recordedURL, reqBody))
+		if !ok {
+			return nil, fmt.Errorf("%w: %s %s", ErrNotRecorded, req.Method, recordedURL)
+		}
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", ex.Status, http.StatusText(ex.Status)),
+			StatusCode:    ex.Status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        ex.Header.Clone(),
+			Body:          io.NopCloser(bytes.NewReader(ex.Body)),
+			ContentLength: int64(len(ex.Body)),
+			Request:       req,
+		}, nil
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	if closeErr := resp.Body.Close(); closeErr != nil && err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading response body: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	header := resp.Header.Clone()
+	header.Del("Set-Cookie")
+	t.archive.add(Exchange{
+		Method:      req.Method,
+		URL:         recordedURL,
+		RequestBody: string(reqBody),
+		Status:      resp.StatusCode,
+		Header:      header,
+		Body:        body,
+	})
+	return resp, nil
+}
+
+// requestKey identifies a request by method, redacted URL, and a digest of its body.
+func requestKey(method, rawURL string, body []byte) string {
+	if len(body) == 0 {
+		return method + " " + rawURL
+	}
+	sum := sha256.Sum256(body)
+	return method + " " + rawURL + " " + hex.EncodeToString(sum[:8])
+}
+
+// redactURL returns u as a string with credential-bearing query parameters scrubbed,
+// so that archives can be committed and replayed with different keys.
+func redactURL(u *url.URL) string {
+	q := u.Query()
+	changed := false
+	for _, p := range redactedParams {
+		if q.Has(p) {
+			q.Set(p, "REDACTED")
+			changed = true
+		}
+	}
+	if !changed {
+		return u.String()
+	}
+	clone := *u
+	clone.RawQuery = q.Encode()
+	return clone.String()
+}
@@ -0,0 +1,131 @@
+package httpreplay
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func get(t *testing.T, client *http.Client, url string) (int, string) {
+	t.Helper()
+	resp, err := client.Get(url) //nolint:noctx // test helper
+	if err != nil {
+		t.Fatalf("GET %s: %v", url, err)
+	}
+	defer func() {
+		_ = resp.Body.Close() //nolint:errcheck // test cleanup
+	}()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("reading body: %v", err)
+	}
+	return resp.StatusCode, string(body)
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	var flakyHits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/flaky" && flakyHits.Add(1) == 1 {
+			http.Error(w, "try again", http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintf(w, "%s %s", r.URL.Path, r.URL.Query().Get("q"))
+	}))
+
+	recorder := NewRecorder()
+	client := &http.Client{Transport: recorder.Transport(nil)}
+	get(t, client, server.URL+"/users/alice?q=1&key=secret")
+	get(t, client, server.URL+"/flaky")
+	get(t, client, server.URL+"/flaky")
+	server.Close()
+
+	for _, name := range []string{"fixture.json", "fixture.json.gz"} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), name)
+			if err := recorder.Save(path); err != nil {
+				t.Fatalf("Save: %v", err)
+			}
+			archive, err := Load(path)
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			if archive.Mode() != Replay || archive.Len() != 3 {
+				t.Fatalf("got mode %v with %d exchanges, want replay with 3", archive.Mode(), archive.Len())
+			}
+			if !archive.RecordedAt().Equal(recorder.RecordedAt()) || archive.RecordedAt().IsZero() {
+				t.Errorf("RecordedAt = %v, want %v", archive.RecordedAt(), recorder.RecordedAt())
+			}
+
+			client := &http.Client{Transport: archive.Transport(nil)}
+			// The API key differs from the recording but is redacted on both sides.
+			if status, body := get(t, client, server.URL+"/users/alice?q=1&key=other"); status != http.StatusOK || body != "/users/alice 1" {
+				t.Errorf("replayed %d %q, want 200 %q", status, body, "/users/alice 1")
+			}
+			// Identical requests replay in recording order, then repeat the last response.
+			for i, want := range []int{http.StatusServiceUnavailable, http.StatusOK, http.StatusOK} {
+				if status, _ := get(t, client, server.URL+"/flaky"); status != want {
+					t.Errorf("flaky request %d replayed status %d, want %d", i, status, want)
+				}
+			}
+
+			_, err = client.Get(server.URL + "/missing") //nolint:noctx,bodyclose // expected to fail
+			if !errors.Is(err, ErrNotRecorded) {
+				t.Errorf("unrecorded request error = %v, want ErrNotRecorded", err)
+			}
+		})
+	}
+}
+
+func TestRecordRedactsCredentials(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "s3cr3t"})
+		fmt.Fprint(w, "ok")
+	}))
+	defer server.Close()
+
+	recorder := NewRecorder()
+	client := &http.Client{Transport: recorder.Transport(nil)}
+	get(t, client, server.URL+"/geocode?address=Paris&key=s3cr3t")
+
+	path := filepath.Join(t.TempDir(), "fixture.json")
+	if err := recorder.Save(path); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	archive, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	ex := archive.exchanges[0]
+	if strings.Contains(ex.URL, "s3cr3t") || ex.Header.Get("Set-Cookie") != "" {
+		t.Errorf("recorded exchange leaks credentials: %+v", ex)
+	}
+}
+
+func TestAPICalls(t *testing.T) {
+	recorder := NewRecorder()
+	if _, found := recorder.APICall("genai:model:prompt", nil); found {
+		t.Error("recorder should always miss so the live API is called")
+	}
+	if err := recorder.SetAPICall("genai:model:prompt", nil, []byte(`{"detected_timezone":"Europe/Paris"}`)); err != nil {
+		t.Fatalf("SetAPICall: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "fixture.json")
+	if err := recorder.Save(path); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	archive, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	data, found := archive.APICall("genai:model:prompt", nil)
+	if !found || !strings.Contains(string(data), "Europe/Paris") {
+		t.Errorf("APICall = %q, %v; want recorded response", data, found)
+	}
+}
@@ -0,0 +1,595 @@
+// Package jobs runs bulk detections in the background on a bounded pool of workers, and keeps each
+// job and its results on disk so that a restarted server picks up where it left off.
+package jobs
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// MaxUsers caps the users in one job.
+	MaxUsers = 1000
+	// MaxPending caps the jobs queued or running at once.
+	MaxPending = 100
+	// TTL is how long a finished job and its results are kept.
+	TTL = 7 * 24 * time.Hour
+)
+
+var (
+	// ErrNotFound is returned for a job that does not exist or has expired.
+	ErrNotFound = errors.New("job not found")
+	// ErrBusy is returned when MaxPending jobs are already waiting.
+	ErrBusy = errors.New("too many pending jobs")
+)
+
+// Status is where a job is in its life.
+type Status string
+
+const (
+	// StatusQueued jobs wait for the workers to finish the jobs before them.
+	StatusQueued Status = "queued"
+	// StatusRunning jobs have users being detected.
+	StatusRunning Status = "running"
+	// StatusDone jobs have a result or failure for every user.
+	StatusDone Status = "done"
+)
+
+// Job is a batch of users to detect.
+type Job struct {
+	Created   time.Time `json:"created"`
+	Finished  time.Time `json:"finished,omitzero"`
+	ID        string    `json:"id"`
+	Key       string    `json:"key,omitempty"` // API key each user is charged to; empty charges nobody
+	Org       string    `json:"org,omitempty"` // Organization whose members were added to the job
+	Status    Status    `json:"status"`
+	Usernames []string  `json:"usernames"`
+	Done      int       `json:"done"`   // Users with a result or failure
+	Failed    int       `json:"failed"` // Users whose detection failed
+	Refresh   bool      `json:"refresh,omitempty"`
+}
+
+// Failure is written to a job's results in place of a user's detection when it fails. A DetectFunc
+// may return one to choose the message and code; other errors are recorded with their text.
+type Failure struct {
+	Username string `json:"username"`
+	Message  string `json:"error"`
+	Code     string `json:"code,omitempty"`
+}
+
+func (f *Failure) Error() string {
+	return f.Message
+}
+
+// DetectFunc detects one user of job and returns the JSON result.
+type DetectFunc func(ctx context.Context, job Job, username string) ([]byte, error)
+
+type job struct {
+	Job
+	finished map[string]bool // Lower-cased usernames with a result line
+	lines    [][]byte        // Result lines, when the manager has no directory
+	size     int64           // Length of the complete result lines in the results file
+}
+
+type task struct {
+	id       string
+	username string
+}
+
+// Manager queues jobs and runs them.
+type Manager struct {
+	detect  DetectFunc
+	logger  *slog.Logger
+	jobs    map[string]*job
+	queue   chan string // IDs of jobs waiting for the dispatcher
+	dir     string      // Empty keeps jobs in memory only
+	workers int
+	mu      sync.Mutex
+}
+
+// Open returns a manager that runs detect on up to workers users at once. Jobs are kept in dir,
+// which need not exist yet; unfinished ones are queued again, in the order they were created. An
+// empty dir gives a manager whose jobs are lost on restart.
+func Open(dir string, workers int, detect DetectFunc, logger *slog.Logger) (*Manager, error) {
+	m := &Manager{detect: detect, logger: logger, jobs: map[string]*job{}, dir: dir, workers: max(1, workers)}
+	if dir != "" {
+		if err := os.MkdirAll(dir, 0o750); err != nil {
+			return nil, fmt.Errorf("create jobs dir: %w", err)
+		}
+		if err := m.load(); err != nil {
+			return nil, err
+		}
+	}
+	m.prune()
+
+	var pending []*job
+	for _, j := range m.jobs {
+		if j.Status != StatusDone {
+			pending = append(pending, j)
+		}
+	}
+	sort.Slice(pending, func(a, b int) bool { return pending[a].Created.Before(pending[b].Created) })
+	m.queue = make(chan string, max(MaxPending, len(pending)))
+	for _, j := range pending {
+		m.queue <- j.ID
+	}
+	if len(pending) > 0 {
+		logger.Info("Resuming jobs", "count", len(pending))
+	}
+	return m, nil
+}
+
+func (m *Manager) metaPath(id string) string {
+	return filepath.Join(m.dir, id+".json")
+}
+
+func (m *Manager) resultsPath(id string) string {
+	return filepath.Join(m.dir, id+".ndjson")
+}
+
+// load reads every job in the directory, along with what its results file says is finished.
+func (m *Manager) load() error {
+	paths, err := filepath.Glob(filepath.Join(m.dir, "*.json"))
+	if err != nil {
+		return fmt.Errorf("list jobs: %w", err)
+	}
+	for _, path := range paths {
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("read job: %w", err)
+		}
+		j := &job{finished: map[string]bool{}}
+		if err := json.Unmarshal(raw, &j.Job); err != nil {
+			m.logger.Warn("Skipping unreadable job", "path", path, "error", err)
+			continue
+		}
+		if err := m.loadResults(j); err != nil {
+			return err
+		}
+		m.jobs[j.ID] = j
+	}
+	return nil
+}
+
+// loadResults counts the users j has results for. A line torn by a crash mid-append is cut off, so
+// that its user is detected again.
+func (m *Manager) loadResults(j *job) error {
+	path := m.resultsPath(j.ID)
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("open job results: %w", err)
+	}
+	defer f.Close() //nolint:errcheck // read-only
+
+	j.Done, j.Failed = 0, 0
+	r := bufio.NewReader(f)
+	for {
+		line, err := r.ReadBytes('\n')
+		if err != nil {
+			break
+		}
+		var rec Failure
+		if json.Unmarshal(line, &rec) != nil || rec.Username == "" {
+			break
+		}
+		j.record(rec, int64(len(line)))
+	}
+	if info, err := f.Stat(); err == nil && info.Size() > j.size {
+		m.logger.Warn("Truncating torn job results", "job_id", j.ID, "size", info.Size(), "kept", j.size)
+		if err := os.Truncate(path, j.size); err != nil {
+			return fmt.Errorf("truncate job results: %w", err)
+		}
+	}
+	return nil
+}
+
+// record counts a result line for the user in rec.
+func (j *job) record(rec Failure, size int64) {
+	j.finished[strings.ToLower(rec.Username)] = true
+	j.Done++
+	if rec.Message != "" {
+		j.Failed++
+	}
+	j.size += size
+}
+
+// save writes j's metadata through a temporary file, so readers never see a partial write.
+// The caller holds m.mu.
+func (m *Manager) save(j *job) error {
+	if m.dir == "" {
+		return nil
+	}
+	raw, err := json.Marshal(j.Job)
+	if err != nil {
+		return fmt.Errorf("encode job: %w", err)
+	}
+	if err := replaceFile(m.metaPath(j.ID), raw); err != nil {
+		return fmt.Errorf("save job: %w", err)
+	}
+	return nil
+}
+
+// prune forgets jobs that finished more than TTL ago. The caller holds m.mu, or owns m.
+func (m *Manager) prune() {
+	cutoff := time.Now().Add(-TTL)
+	for id, j := range m.jobs {
+		if j.Status != StatusDone || j.Finished.After(cutoff) {
+			continue
+		}
+		delete(m.jobs, id)
+		if m.dir == "" {
+			continue
+		}
+		for _, path := range []string{m.metaPath(id), m.resultsPath(id)} {
+			if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+				m.logger.Warn("Failed to remove expired job", "path", path, "error", err)
+			}
+		}
+	}
+}
+
+// Submit queues a job for usernames, dropping duplicates, and returns it with its ID. Only the
+// Usernames, Key, Org and Refresh fields of spec are used.
+func (m *Manager) Submit(spec Job) (Job, error) {
+	seen := map[string]bool{}
+	var usernames []string
+	for _, u := range spec.Usernames {
+		u = strings.TrimSpace(u)
+		if u == "" || seen[strings.ToLower(u)] {
+			continue
+		}
+		seen[strings.ToLower(u)] = true
+		usernames = append(usernames, u)
+	}
+	if len(usernames) == 0 {
+		return Job{}, errors.New("a job needs at least one user")
+	}
+	if len(usernames) > MaxUsers {
+		return Job{}, fmt.Errorf("a job holds at most %d users, got %d", MaxUsers, len(usernames))
+	}
+
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return Job{}, fmt.Errorf("generate job ID: %w", err)
+	}
+	j := &job{
+		Job: Job{
+			Created:   time.Now(),
+			ID:        hex.EncodeToString(buf),
+			Key:       spec.Key,
+			Org:       spec.Org,
+			Status:    StatusQueued,
+			Usernames: usernames,
+			Refresh:   spec.Refresh,
+		},
+		finished: map[string]bool{},
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.prune()
+	pending := 0
+	for _, other := range m.jobs {
+		if other.Status != StatusDone {
+			pending++
+		}
+	}
+	if pending >= MaxPending {
+		return Job{}, ErrBusy
+	}
+	if err := m.save(j); err != nil {
+		return Job{}, err
+	}
+	m.jobs[j.ID] = j
+	select {
+	case m.queue <- j.ID:
+	default:
+		delete(m.jobs, j.ID)
+		return Job{}, ErrBusy
+	}
+	return j.Job, nil
+}
+
+// Get returns the job with id.
+func (m *Manager) Get(id string) (Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	j, ok := m.jobs[id]
+	if !ok {
+		return Job{}, ErrNotFound
+	}
+	return j.Job, nil
+}
+
+// Results calls fn with the username and JSON line of each result of the job with id recorded so
+// far, in the order they finished, stopping at the first error fn returns.
+func (m *Manager) Results(id string, fn func(username string, line []byte) error) error {
+	m.mu.Lock()
+	j, ok := m.jobs[id]
+	if !ok {
+		m.mu.Unlock()
+		return ErrNotFound
+	}
+	size, lines := j.size, j.lines
+	m.mu.Unlock()
+
+	var r io.Reader
+	if m.dir == "" {
+		r = bytes.NewReader(bytes.Join(lines, nil))
+	} else {
+		f, err := os.Open(m.resultsPath(id))
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("open job results: %w", err)
+		}
+		defer f.Close() //nolint:errcheck // read-only
+		// Lines appended after the lock was released may be incomplete
+		r = io.LimitReader(f, size)
+	}
+
+	br := bufio.NewReader(r)
+	for {
+		line, err := br.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("read job results: %w", err)
+		}
+		var rec Failure
+		if err := json.Unmarshal(line, &rec); err != nil {
+			return fmt.Errorf("decode job results: %w", err)
+		}
+		if err := fn(rec.Username, line); err != nil {
+			return err
+		}
+	}
+}
+
+// Forget replaces the results of username in every job with an OPTED_OUT failure, for a user who
+// opted out after their detection was recorded.
+func (m *Manager) Forget(username string) error {
+	user := strings.ToLower(strings.TrimSpace(username))
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, j := range m.jobs {
+		if !j.finished[user] {
+			continue
+		}
+		var lines [][]byte
+		if m.dir == "" {
+			lines = j.lines
+		} else {
+			raw, err := os.ReadFile(m.resultsPath(j.ID))
+			if err != nil {
+				return fmt.Errorf("read job results: %w", err)
+			}
+			lines = bytes.SplitAfter(raw[:j.size], []byte("\n"))
+		}
+
+		var kept [][]byte
+		j.finished, j.Done, j.Failed, j.size = map[string]bool{}, 0, 0, 0
+		for _, l := range lines {
+			var rec Failure
+			if json.Unmarshal(l, &rec) != nil || rec.Username == "" {
+				continue
+			}
+			if strings.EqualFold(rec.Username, user) {
+				rec = Failure{Username: rec.Username, Message: "User has opted out", Code: "OPTED_OUT"}
+				raw, err := json.Marshal(rec)
+				if err != nil {
+					return fmt.Errorf("encode job failure: %w", err)
+				}
+				l = append(raw, '\n')
+			}
+			kept = append(kept, l)
+			j.record(rec, int64(len(l)))
+		}
+		if m.dir == "" {
+			j.lines = kept
+			continue
+		}
+		if err := replaceFile(m.resultsPath(j.ID), bytes.Join(kept, nil)); err != nil {
+			return fmt.Errorf("rewrite job results: %w", err)
+		}
+	}
+	return nil
+}
+
+// Run processes queued jobs, one after the other and each with up to the manager's number of workers,
+// until ctx is canceled. Users whose detection was interrupted are detected again by the next Run.
+func (m *Manager) Run(ctx context.Context) {
+	tasks := make(chan task)
+	var wg sync.WaitGroup
+	for range m.workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for t := range tasks {
+				m.process(ctx, t)
+			}
+		}()
+	}
+	defer wg.Wait()
+	defer close(tasks)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case id := <-m.queue:
+			if !m.dispatch(ctx, id, tasks) {
+				return
+			}
+		}
+	}
+}
+
+// dispatch hands the unfinished users of the job with id to the workers. It returns false if ctx was
+// canceled first.
+func (m *Manager) dispatch(ctx context.Context, id string, tasks chan<- task) bool {
+	m.mu.Lock()
+	j, ok := m.jobs[id]
+	if !ok {
+		m.mu.Unlock()
+		return true
+	}
+	var todo []string
+	for _, u := range j.Usernames {
+		if !j.finished[strings.ToLower(u)] {
+			todo = append(todo, u)
+		}
+	}
+	if len(todo) == 0 {
+		// Every user finished before a restart, but the job was not marked done
+		m.finish(j)
+		m.mu.Unlock()
+		return true
+	}
+	if j.Status != StatusRunning {
+		j.Status = StatusRunning
+		if err := m.save(j); err != nil {
+			m.logger.Error("Failed to save job", "job_id", id, "error", err)
+		}
+	}
+	m.mu.Unlock()
+
+	m.logger.Info("Job started", "job_id", id, "users", len(todo))
+	for _, u := range todo {
+		select {
+		case tasks <- task{id: id, username: u}:
+		case <-ctx.Done():
+			return false
+		}
+	}
+	return true
+}
+
+// process detects one user and records the outcome, unless the manager is stopping.
+func (m *Manager) process(ctx context.Context, t task) {
+	m.mu.Lock()
+	j, ok := m.jobs[t.id]
+	var spec Job
+	if ok {
+		spec = j.Job
+	}
+	m.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	data, err := m.detectOne(ctx, spec, t.username)
+	if ctx.Err() != nil {
+		return
+	}
+	var line []byte
+	if err == nil {
+		var buf bytes.Buffer
+		if err = json.Compact(&buf, data); err == nil {
+			line = buf.Bytes()
+		}
+	}
+	rec := Failure{Username: t.username}
+	if err != nil {
+		var f *Failure
+		if errors.As(err, &f) {
+			rec.Message, rec.Code = f.Message, f.Code
+		} else {
+			rec.Message = err.Error()
+		}
+		if line, err = json.Marshal(rec); err != nil {
+			m.logger.Error("Failed to encode job failure", "job_id", t.id, "username", t.username, "error", err)
+			return
+		}
+	}
+	m.append(t.id, rec, append(line, '\n'))
+}
+
+// detectOne runs the DetectFunc, converting a panic into an error so one bad user cannot take down
+// the rest of the job.
+func (m *Manager) detectOne(ctx context.Context, spec Job, username string) (data []byte, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			data, err = nil, fmt.Errorf("detection panicked: %v", r)
+		}
+	}()
+	return m.detect(ctx, spec, username)
+}
+
+// append records a result line of the job with id, and finishes the job with its last user.
+func (m *Manager) append(id string, rec Failure, line []byte) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	j, ok := m.jobs[id]
+	if !ok || j.finished[strings.ToLower(rec.Username)] {
+		return
+	}
+	if m.dir == "" {
+		j.lines = append(j.lines, line)
+	} else if err := appendFile(m.resultsPath(id), line); err != nil {
+		m.logger.Error("Failed to record job result", "job_id", id, "username", rec.Username, "error", err)
+		return
+	}
+	j.record(rec, int64(len(line)))
+	if j.Done == len(j.Usernames) {
+		m.finish(j)
+	}
+}
+
+// finish marks j done. The caller holds m.mu.
+func (m *Manager) finish(j *job) {
+	j.Status = StatusDone
+	j.Finished = time.Now()
+	if err := m.save(j); err != nil {
+		m.logger.Error("Failed to save job", "job_id", j.ID, "error", err)
+	}
+	m.logger.Info("Job finished", "job_id", j.ID, "users", len(j.Usernames), "failed", j.Failed)
+}
+
+// replaceFile writes data to path through a temporary file, so readers never see a partial write.
+func replaceFile(path string, data []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		_ = f.Close()           //nolint:errcheck // write error takes precedence
+		_ = os.Remove(f.Name()) //nolint:errcheck // best effort
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+func appendFile(path string, line []byte) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("open job results: %w", err)
+	}
+	if _, err := f.Write(line); err != nil {
+		_ = f.Close() //nolint:errcheck // write error takes precedence
+		return fmt.Errorf("write job results: %w", err)
+	}
+	return f.Close()
+}
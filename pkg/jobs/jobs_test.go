@@ -0,0 +1,217 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+var logger = slog.New(slog.DiscardHandler)
+
+// waitDone polls until the job with id is done.
+func waitDone(t *testing.T, m *Manager, id string) Job {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		j, err := m.Get(id)
+		if err != nil {
+			t.Fatalf("Get: %v", err)
+		}
+		if j.Status == StatusDone {
+			return j
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("job %s did not finish", id)
+	return Job{}
+}
+
+func results(t *testing.T, m *Manager, id string) map[string]string {
+	t.Helper()
+	got := map[string]string{}
+	if err := m.Results(id, func(username string, line []byte) error {
+		got[username] = strings.TrimSpace(string(line))
+		return nil
+	}); err != nil {
+		t.Fatalf("Results: %v", err)
+	}
+	return got
+}
+
+func TestRun(t *testing.T) {
+	detect := func(_ context.Context, _ Job, username string) ([]byte, error) {
+		switch username {
+		case "ghost":
+			return nil, &Failure{Message: "GitHub user not found", Code: "USER_NOT_FOUND"}
+		case "boom":
+			panic("bad data")
+		default:
+			return []byte(fmt.Sprintf("{\n  \"username\": %q\n}", username)), nil
+		}
+	}
+	m, err := Open("", 2, detect, logger)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go m.Run(ctx)
+
+	job, err := m.Submit(Job{Usernames: []string{"alice", " ghost", "Alice", "boom", ""}})
+	if err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+	if len(job.Usernames) != 3 {
+		t.Errorf("usernames = %v, want duplicates and blanks dropped", job.Usernames)
+	}
+	if job = waitDone(t, m, job.ID); job.Done != 3 || job.Failed != 2 {
+		t.Errorf("job = %+v, want 3 done and 2 failed", job)
+	}
+
+	got := results(t, m, job.ID)
+	want := map[string]string{
+		"alice": `{"username":"alice"}`,
+		"ghost": `{"username":"ghost","error":"GitHub user not found","code":"USER_NOT_FOUND"}`,
+		"boom":  `{"username":"boom","error":"detection panicked: bad data"}`,
+	}
+	for u, line := range want {
+		if got[u] != line {
+			t.Errorf("result for %s = %s, want %s", u, got[u], line)
+		}
+	}
+
+	if _, err := m.Get("missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get(missing) = %v, want ErrNotFound", err)
+	}
+	if _, err := m.Submit(Job{Usernames: []string{" "}}); err == nil {
+		t.Error("Submit of an empty job succeeded")
+	}
+}
+
+func TestResume(t *testing.T) {
+	dir := t.TempDir()
+	var mu sync.Mutex
+	calls := map[string]int{}
+	started := make(chan struct{})
+	detect := func(ctx context.Context, _ Job, username string) ([]byte, error) {
+		mu.Lock()
+		calls[username]++
+		mu.Unlock()
+		if username == "slow" {
+			close(started)
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+		return []byte(fmt.Sprintf(`{"username":%q}`, username)), nil
+	}
+
+	m, err := Open(dir, 1, detect, logger)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	job, err := m.Submit(Job{Usernames: []string{"alice", "slow"}, Org: "acme"})
+	if err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		m.Run(ctx)
+	}()
+	<-started
+	cancel() // Restart while slow is being detected
+	<-stopped
+
+	// A crash mid-append leaves a torn line behind
+	f, err := os.OpenFile(filepath.Join(dir, job.ID+".ndjson"), os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString(`{"username":"sl`); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	detect2 := func(_ context.Context, _ Job, username string) ([]byte, error) {
+		mu.Lock()
+		calls[username]++
+		mu.Unlock()
+		return []byte(fmt.Sprintf(`{"username":%q}`, username)), nil
+	}
+	m2, err := Open(dir, 1, detect2, logger)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	if j, err := m2.Get(job.ID); err != nil || j.Status != StatusRunning || j.Done != 1 || j.Org != "acme" {
+		t.Fatalf("reopened job = %+v, %v; want running with alice done", j, err)
+	}
+	ctx2, cancel2 := context.WithCancel(context.Background())
+	defer cancel2()
+	go m2.Run(ctx2)
+
+	if j := waitDone(t, m2, job.ID); j.Done != 2 || j.Failed != 0 {
+		t.Errorf("resumed job = %+v, want both users done", j)
+	}
+	if got := results(t, m2, job.ID); len(got) != 2 || got["slow"] != `{"username":"slow"}` {
+		t.Errorf("results = %v", got)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if calls["alice"] != 1 || calls["slow"] != 2 {
+		t.Errorf("calls = %v, want alice detected once and slow again after the restart", calls)
+	}
+}
+
+func TestForget(t *testing.T) {
+	for _, dir := range []string{"", t.TempDir()} {
+		detect := func(_ context.Context, _ Job, username string) ([]byte, error) {
+			return []byte(fmt.Sprintf(`{"username":%q,"timezone":"Europe/Berlin"}`, username)), nil
+		}
+		m, err := Open(dir, 2, detect, logger)
+		if err != nil {
+			t.Fatalf("Open: %v", err)
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		go m.Run(ctx)
+		job, err := m.Submit(Job{Usernames: []string{"Alice", "bob"}})
+		if err != nil {
+			t.Fatalf("Submit: %v", err)
+		}
+		waitDone(t, m, job.ID)
+		cancel()
+
+		if err := m.Forget("alice"); err != nil {
+			t.Fatalf("Forget: %v", err)
+		}
+		got := results(t, m, job.ID)
+		if strings.Contains(got["Alice"], "Europe/Berlin") || !strings.Contains(got["Alice"], "OPTED_OUT") {
+			t.Errorf("dir %q: result for Alice = %s, want an opted-out failure", dir, got["Alice"])
+		}
+		if !strings.Contains(got["bob"], "Europe/Berlin") {
+			t.Errorf("dir %q: result for bob = %s, want it kept", dir, got["bob"])
+		}
+		if j, _ := m.Get(job.ID); j.Done != 2 || j.Failed != 1 {
+			t.Errorf("dir %q: job = %+v, want 2 done and 1 failed", dir, j)
+		}
+		if dir == "" {
+			continue
+		}
+		m2, err := Open(dir, 1, detect, logger)
+		if err != nil {
+			t.Fatalf("reopen: %v", err)
+		}
+		if got := results(t, m2, job.ID); strings.Contains(got["Alice"], "Europe/Berlin") {
+			t.Errorf("reopened result for Alice = %s, want the opt-out to persist", got["Alice"])
+		}
+	}
+}
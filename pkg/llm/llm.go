@@ -0,0 +1,196 @@
+// Package llm provides pluggable language model backends for AI-assisted timezone detection.
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/codeGROOVE-dev/guTZ/pkg/gemini"
+	"github.com/codeGROOVE-dev/guTZ/pkg/httpreplay"
+	"github.com/codeGROOVE-dev/retry"
+)
+
+// DefaultTimeout bounds a single model request. Local models can take minutes to generate a full verdict.
+const DefaultTimeout = 5 * time.Minute
+
+// retryDelay is the initial backoff between attempts; tests shorten it.
+var retryDelay = 2 * time.Second
+
+// Backend names a supported provider.
+type Backend string
+
+// Supported backends.
+const (
+	BackendGemini Backend = "gemini" // Google Gemini via API key or Vertex AI
+	BackendOpenAI Backend = "openai" // Any OpenAI-compatible chat completions endpoint (OpenAI, vLLM, llama.cpp)
+	BackendOllama Backend = "ollama" // A local Ollama server
+	BackendNone   Backend = "none"   // Disable LLM analysis; no profile data leaves the machine
+)
+
+// Provider turns the evidence prompt built from gemini.UnifiedPrompt into a structured verdict.
+type Provider interface {
+	// CacheKey returns the key under which the verdict for prompt is cached.
+	// It must identify the backend and model so verdicts from different models don't collide.
+	CacheKey(prompt string) string
+	// Query returns the verdict for prompt, consulting cache first when it is non-nil.
+	Query(ctx context.Context, prompt string, cache gemini.Cache, logger gemini.Logger) (*gemini.Response, error)
+}
+
+// Config selects and configures a backend.
+type Config struct {
+	Backend    Backend
+	BaseURL    string // Endpoint root; defaults depend on the backend
+	Model      string
+	APIKey     string
+	GCPProject string        // Gemini only: project for Vertex AI when no API key is set
+	Timeout    time.Duration // Per-request timeout for HTTP backends; defaults to DefaultTimeout
+}
+
+// DoFunc performs an HTTP request, typically with retries.
+type DoFunc func(context.Context, *http.Request) (*http.Response, error)
+
+// ParseBackend validates a backend name. An empty name selects Gemini.
+func ParseBackend(name string) (Backend, error) {
+	switch b := Backend(name); b {
+	case "":
+		return BackendGemini, nil
+	case BackendGemini, BackendOpenAI, BackendOllama, BackendNone:
+		return b, nil
+	default:
+		return "", fmt.Errorf("unknown LLM backend %q (want gemini, openai, ollama, or none)", name)
+	}
+}
+
+// New creates the provider described by cfg, sending HTTP requests through transport
+// (http.DefaultTransport when nil). It returns a nil Provider for BackendNone.
+func New(cfg Config, transport http.RoundTripper) (Provider, error) {
+	backend, err := ParseBackend(string(cfg.Backend))
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+	do := newDoFunc(&http.Client{Transport: transport, Timeout: cfg.Timeout})
+	switch backend {
+	case BackendOpenAI:
+		return newOpenAI(cfg, do), nil
+	case BackendOllama:
+		return newOllama(cfg, do), nil
+	case BackendNone:
+		return nil, nil //nolint:nilnil // no provider is a valid configuration
+	default:
+		return &geminiProvider{client: gemini.NewClient(cfg.APIKey, cfg.Model, cfg.GCPProject)}, nil
+	}
+}
+
+// newDoFunc returns a DoFunc that retries network errors, 429s and 5xx responses with backoff.
+// Model servers answer 503 while a model loads, so retries are spaced out over roughly a minute.
+// The request body is rebuilt from GetBody before every attempt, since the previous attempt drained it.
+func newDoFunc(client *http.Client) DoFunc {
+	return func(ctx context.Context, req *http.Request) (*http.Response, error) {
+		var resp *http.Response
+		err := retry.Do(
+			func() error {
+				attempt := req.WithContext(ctx)
+				if req.GetBody != nil {
+					body, err := req.GetBody()
+					if err != nil {
+						return retry.Unrecoverable(fmt.Errorf("rewinding request body: %w", err))
+					}
+					attempt.Body = body
+				}
+
+				var err error
+				resp, err = client.Do(attempt) //nolint:bodyclose // Body closed on retry, returned open on success for caller
+				if err != nil {
+					if errors.Is(err, httpreplay.ErrNotRecorded) {
+						return retry.Unrecoverable(err)
+					}
+					return err
+				}
+				if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError {
+					body, err := io.ReadAll(io.LimitReader(resp.Body, 4<<10))
+					_ = resp.Body.Close() //nolint:errcheck // body already consumed
+					if err != nil {
+						return fmt.Errorf("HTTP %d", resp.StatusCode)
+					}
+					return fmt.Errorf("HTTP %d: %s", resp.StatusCode, body)
+				}
+				return nil
+			},
+			retry.Context(ctx),
+			retry.Attempts(5),
+			retry.Delay(retryDelay),
+			retry.MaxDelay(30*time.Second),
+			retry.DelayType(retry.CombineDelay(retry.BackOffDelay, retry.RandomDelay)),
+			retry.MaxJitter(time.Second),
+			retry.LastErrorOnly(true),
+		)
+		if err != nil {
+			return nil, err
+		}
+		return resp, nil
+	}
+}
+
+// geminiProvider adapts gemini.Client, which talks to Google through the genai SDK.
+type geminiProvider struct {
+	client *gemini.Client
+}
+
+func (p *geminiProvider) CacheKey(prompt string) string {
+	return p.client.CacheKey(prompt)
+}
+
+func (p *geminiProvider) Query(ctx context.Context, prompt string, cache gemini.Cache, logger gemini.Logger) (*gemini.Response, error) {
+	return p.client.CallWithSDK(ctx, prompt, cache, logger)
+}
+
+// systemPrompt tells models without native structured output what shape to answer in.
+func systemPrompt() string {
+	schema, err := json.Marshal(gemini.ResponseJSONSchema())
+	if err != nil {
+		// The schema is a static map of strings; this cannot fail
+		panic(err)
+	}
+	return "Respond with a single JSON object, and nothing else, matching this JSON Schema: " + string(schema)
+}
+
+// cachedQuery checks cache for a previous verdict, otherwise calls fetch and parses and caches its text.
+func cachedQuery(key, prompt string, cache gemini.Cache, logger gemini.Logger, fetch func() (string, error)) (*gemini.Response, error) {
+	if cache != nil {
+		if data, found := cache.APICall(key, []byte(prompt)); found {
+			var cached gemini.Response
+			if err := json.Unmarshal(data, &cached); err == nil && cached.DetectedTimezone != "" {
+				logger.Info("Using cached LLM response", "timezone", cached.DetectedTimezone, "confidence", cached.ConfidenceLevel)
+				return &cached, nil
+			}
+		}
+	}
+
+	text, err := fetch()
+	if err != nil {
+		return nil, err
+	}
+	logger.Debug("Raw LLM response", "response_text", text)
+
+	resp, err := gemini.ParseResponse(text, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	if cache != nil {
+		if data, err := json.Marshal(resp); err == nil {
+			if err := cache.SetAPICall(key, []byte(prompt), data); err != nil {
+				logger.Debug("Failed to cache LLM response", "error", err)
+			}
+		}
+	}
+	return resp, nil
+}
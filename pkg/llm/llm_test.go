@@ -0,0 +1,169 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const verdict = `{"detected_timezone":"Europe/Berlin","confidence_level":"high","detected_location":"Berlin, Germany",` +
+	`"latitude":52.52,"longitude":13.405,"detection_reasoning":"Evening activity\nmatches CET","suspicious_mismatch":false,"mismatch_reason":""}`
+
+// memCache is a minimal gemini.Cache for tests.
+type memCache map[string][]byte
+
+func (c memCache) APICall(key string, _ []byte) ([]byte, bool) {
+	data, ok := c[key]
+	return data, ok
+}
+
+func (c memCache) SetAPICall(key string, _, data []byte) error {
+	c[key] = data
+	return nil
+}
+
+func TestOpenAI(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if r.URL.Path != "/v1/chat/completions" {
+			t.Errorf("path = %s, want /v1/chat/completions", r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer sk-test" {
+			t.Errorf("Authorization = %q", got)
+		}
+		var req openAIRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("decoding request: %v", err)
+		}
+		if req.Model != "qwen2.5" || len(req.Messages) != 2 || req.Messages[1].Content != "evidence" {
+			t.Errorf("unexpected request: %+v", req)
+		}
+		resp := map[string]any{"choices": []any{map[string]any{"message": map[string]string{"role": "assistant", "content": verdict}}}}
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			t.Errorf("encoding response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	p, err := New(Config{Backend: BackendOpenAI, BaseURL: server.URL + "/v1/", Model: "qwen2.5", APIKey: "sk-test"}, nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	cache := memCache{}
+	logger := slog.New(slog.DiscardHandler)
+	for range 2 {
+		resp, err := p.Query(context.Background(), "evidence", cache, logger)
+		if err != nil {
+			t.Fatalf("Query: %v", err)
+		}
+		if resp.DetectedTimezone != "Europe/Berlin" || resp.DetectionReasoning != "Evening activity matches CET" {
+			t.Errorf("got %+v", resp)
+		}
+	}
+	if calls.Load() != 1 {
+		t.Errorf("endpoint called %d times, want 1 (second query should be cached)", calls.Load())
+	}
+}
+
+func TestOllama(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/chat" {
+			t.Errorf("path = %s, want /api/chat", r.URL.Path)
+		}
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Fatalf("reading request: %v", err)
+		}
+		if !strings.Contains(string(body), `"detected_timezone"`) || !strings.Contains(string(body), `"stream":false`) {
+			t.Errorf("request should carry the response schema and disable streaming: %s", body)
+		}
+		// Local models often wrap JSON in code fences despite instructions.
+		resp := map[string]any{"message": map[string]string{"role": "assistant", "content": "```json\n" + verdict + "\n```"}}
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			t.Errorf("encoding response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	p, err := New(Config{Backend: BackendOllama, BaseURL: server.URL}, nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	resp, err := p.Query(context.Background(), "evidence", nil, slog.New(slog.DiscardHandler))
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if resp.DetectedTimezone != "Europe/Berlin" || resp.Latitude != 52.52 {
+		t.Errorf("got %+v", resp)
+	}
+}
+
+func TestQueryError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "model not found", http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	p, err := New(Config{Backend: BackendOllama, BaseURL: server.URL}, nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if _, err := p.Query(context.Background(), "evidence", nil, slog.New(slog.DiscardHandler)); err == nil ||
+		!strings.Contains(err.Error(), "model not found") {
+		t.Errorf("Query error = %v, want HTTP 404 with body", err)
+	}
+}
+
+func TestQueryRetriesWithBody(t *testing.T) {
+	retryDelay = time.Millisecond
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ollamaRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Model == "" {
+			t.Errorf("attempt %d had no request body: %v", calls.Load()+1, err)
+		}
+		if calls.Add(1) == 1 {
+			http.Error(w, "model is loading", http.StatusServiceUnavailable)
+			return
+		}
+		if err := json.NewEncoder(w).Encode(map[string]any{"message": map[string]string{"role": "assistant", "content": verdict}}); err != nil {
+			t.Errorf("encoding response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	p, err := New(Config{Backend: BackendOllama, BaseURL: server.URL}, nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if _, err := p.Query(context.Background(), "evidence", nil, slog.New(slog.DiscardHandler)); err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if got := calls.Load(); got != 2 {
+		t.Errorf("server saw %d requests, want 2", got)
+	}
+}
+
+func TestNew(t *testing.T) {
+	if p, err := New(Config{Backend: BackendNone}, nil); err != nil || p != nil {
+		t.Errorf("New(none) = %v, %v; want nil provider", p, err)
+	}
+	if p, err := New(Config{}, nil); err != nil || p == nil {
+		t.Errorf("New(default) = %v, %v; want Gemini provider", p, err)
+	}
+	if _, err := New(Config{Backend: "watson"}, nil); err == nil {
+		t.Error("New with an unknown backend should fail")
+	}
+	if !strings.HasPrefix((&openAI{baseURL: "a", model: "m"}).CacheKey("p"), "openai:") {
+		t.Error("OpenAI cache keys should be namespaced by backend")
+	}
+}
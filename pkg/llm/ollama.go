@@ -0,0 +1,86 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/codeGROOVE-dev/guTZ/pkg/gemini"
+)
+
+const (
+	defaultOllamaURL   = "http://localhost:11434"
+	defaultOllamaModel = "llama3.1"
+)
+
+// ollama talks to Ollama's native /api/chat endpoint, which supports JSON Schema constrained output.
+type ollama struct {
+	do      DoFunc
+	baseURL string
+	model   string
+}
+
+func newOllama(cfg Config, do DoFunc) *ollama {
+	p := &ollama{do: do, baseURL: cfg.BaseURL, model: cfg.Model}
+	if p.baseURL == "" {
+		p.baseURL = defaultOllamaURL
+	}
+	if p.model == "" {
+		p.model = defaultOllamaModel
+	}
+	p.baseURL = strings.TrimSuffix(p.baseURL, "/")
+	return p
+}
+
+func (p *ollama) CacheKey(prompt string) string {
+	return fmt.Sprintf("ollama:%s:%s:%s", p.baseURL, p.model, prompt)
+}
+
+type ollamaRequest struct {
+	Format   map[string]any `json:"format"`
+	Options  map[string]any `json:"options"`
+	Model    string         `json:"model"`
+	Messages []chatMessage  `json:"messages"`
+	Stream   bool           `json:"stream"`
+}
+
+type ollamaResponse struct {
+	Message chatMessage `json:"message"`
+}
+
+func (p *ollama) Query(ctx context.Context, prompt string, cache gemini.Cache, logger gemini.Logger) (*gemini.Response, error) {
+	return cachedQuery(p.CacheKey(prompt), prompt, cache, logger, func() (string, error) {
+		logger.Info("Querying Ollama", "url", p.baseURL, "model", p.model)
+		body, err := json.Marshal(ollamaRequest{
+			Model: p.model,
+			Messages: []chatMessage{
+				{Role: "system", Content: systemPrompt()},
+				{Role: "user", Content: prompt},
+			},
+			Format:  gemini.ResponseJSONSchema(),
+			Options: map[string]any{"temperature": 0.1, "num_predict": 2500},
+		})
+		if err != nil {
+			return "", fmt.Errorf("marshaling request: %w", err)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/api/chat", bytes.NewReader(body))
+		if err != nil {
+			return "", fmt.Errorf("creating request: %w", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		var out ollamaResponse
+		if err := doJSON(p.do, req, &out); err != nil {
+			return "", fmt.Errorf("ollama chat: %w", err)
+		}
+		if out.Message.Content == "" {
+			return "", errors.New("empty response from Ollama")
+		}
+		return out.Message.Content, nil
+	})
+}
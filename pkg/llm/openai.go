@@ -0,0 +1,129 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/codeGROOVE-dev/guTZ/pkg/gemini"
+)
+
+const (
+	defaultOpenAIURL   = "https://api.openai.com/v1"
+	defaultOpenAIModel = "gpt-4o-mini"
+)
+
+// openAI talks to an OpenAI-compatible /chat/completions endpoint.
+// This covers OpenAI itself as well as self-hosted servers such as vLLM and llama.cpp.
+type openAI struct {
+	do      DoFunc
+	baseURL string
+	model   string
+	apiKey  string
+}
+
+func newOpenAI(cfg Config, do DoFunc) *openAI {
+	p := &openAI{do: do, baseURL: cfg.BaseURL, model: cfg.Model, apiKey: cfg.APIKey}
+	if p.baseURL == "" {
+		p.baseURL = defaultOpenAIURL
+	}
+	if p.model == "" {
+		p.model = defaultOpenAIModel
+	}
+	p.baseURL = strings.TrimSuffix(p.baseURL, "/")
+	return p
+}
+
+func (p *openAI) CacheKey(prompt string) string {
+	return fmt.Sprintf("openai:%s:%s:%s", p.baseURL, p.model, prompt)
+}
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type openAIRequest struct {
+	ResponseFormat map[string]any `json:"response_format"`
+	Model          string         `json:"model"`
+	Messages       []chatMessage  `json:"messages"`
+	Temperature    float64        `json:"temperature"`
+	MaxTokens      int            `json:"max_tokens"`
+}
+
+type openAIResponse struct {
+	Choices []struct {
+		Message chatMessage `json:"message"`
+	} `json:"choices"`
+}
+
+func (p *openAI) Query(ctx context.Context, prompt string, cache gemini.Cache, logger gemini.Logger) (*gemini.Response, error) {
+	return cachedQuery(p.CacheKey(prompt), prompt, cache, logger, func() (string, error) {
+		logger.Info("Querying OpenAI-compatible endpoint", "url", p.baseURL, "model", p.model)
+		body, err := json.Marshal(openAIRequest{
+			Model: p.model,
+			Messages: []chatMessage{
+				{Role: "system", Content: systemPrompt()},
+				{Role: "user", Content: prompt},
+			},
+			Temperature: 0.1,
+			MaxTokens:   2500,
+			ResponseFormat: map[string]any{
+				"type": "json_schema",
+				"json_schema": map[string]any{
+					"name":   "timezone_verdict",
+					"schema": gemini.ResponseJSONSchema(),
+				},
+			},
+		})
+		if err != nil {
+			return "", fmt.Errorf("marshaling request: %w", err)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/chat/completions", bytes.NewReader(body))
+		if err != nil {
+			return "", fmt.Errorf("creating request: %w", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if p.apiKey != "" {
+			req.Header.Set("Authorization", "Bearer "+p.apiKey)
+		}
+
+		var out openAIResponse
+		if err := doJSON(p.do, req, &out); err != nil {
+			return "", fmt.Errorf("openai chat completion: %w", err)
+		}
+		if len(out.Choices) == 0 || out.Choices[0].Message.Content == "" {
+			return "", errors.New("empty response from OpenAI-compatible endpoint")
+		}
+		return out.Choices[0].Message.Content, nil
+	})
+}
+
+// doJSON sends req and decodes a successful JSON response into out.
+func doJSON(do DoFunc, req *http.Request, out any) error {
+	resp, err := do(req.Context(), req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = resp.Body.Close() //nolint:errcheck // body already consumed
+	}()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+	if err != nil {
+		return fmt.Errorf("reading response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("decoding response: %w", err)
+	}
+	return nil
+}
@@ -29,14 +29,14 @@ func TestAsh2kSydneyLunchDetection(t *testing.T) {
 	// Test for UTC+10 (Sydney)
 	// NOTE: In this codebase, positive UTC offsets use positive offset values
 	// So UTC+10 uses offset = 10, not -10
-	offset := 10
+	offset := 10.0
 
 	// Detect lunch for Sydney timezone
 	lunchStart, lunchEnd, confidence := DetectLunchBreakNoonCentered(halfHourCounts, offset)
 
 	// Convert UTC lunch times to local Sydney time
-	lunchStartLocal := lunchStart + float64(offset)
-	lunchEndLocal := lunchEnd + float64(offset)
+	lunchStartLocal := lunchStart + offset
+	lunchEndLocal := lunchEnd + offset
 
 	// Normalize to 24-hour format
 	for lunchStartLocal < 0 {
@@ -41,7 +41,7 @@ func TestDlorencLunchSteepnessUniformity(t *testing.T) {
 	}
 
 	// Test for UTC-4 timezone (Eastern Time)
-	utcOffset := -4
+	utcOffset := -4.0
 
 	startUTC, endUTC, confidence := DetectLunchBreakNoonCentered(halfHourCounts, utcOffset)
 
@@ -63,14 +63,14 @@ func TestIdlePhysicistLunchDetection(t *testing.T) {
 	}
 
 	// Test for UTC-6 (Mountain Standard Time)
-	offset := -6
+	offset := -6.0
 
 	// Detect lunch for this timezone
 	lunchStart, lunchEnd, confidence := DetectLunchBreakNoonCentered(halfHourCounts, offset)
 
 	// Convert UTC lunch times to local Mountain Time
-	lunchStartLocal := lunchStart + float64(offset)
-	lunchEndLocal := lunchEnd + float64(offset)
+	lunchStartLocal := lunchStart + offset
+	lunchEndLocal := lunchEnd + offset
 
 	// Normalize to 24-hour format
 	if lunchStartLocal < 0 {
@@ -121,14 +121,14 @@ func TestIdlePhysicistLunchDetectionUTC6(t *testing.T) {
 	}
 
 	// Test for UTC-6 (Mountain Standard Time)
-	offset := -6
+	offset := -6.0
 
 	// Detect lunch for this timezone
 	lunchStart, lunchEnd, confidence := DetectLunchBreakNoonCentered(halfHourCounts, offset)
 
 	// Convert UTC lunch times to local time
-	lunchStartLocal := lunchStart + float64(offset)
-	lunchEndLocal := lunchEnd + float64(offset)
+	lunchStartLocal := lunchStart + offset
+	lunchEndLocal := lunchEnd + offset
 
 	// Normalize to 24-hour format
 	if lunchStartLocal < 0 {
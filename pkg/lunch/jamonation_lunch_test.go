@@ -23,11 +23,11 @@ func TestJamonationLunchDetection(t *testing.T) {
 	}
 
 	// Test for UTC-4 (Eastern Time - Toronto)
-	utcOffset := -4
+	utcOffset := -4.0
 	lunchStart, lunchEnd, confidence := DetectLunchBreakNoonCentered(halfHourCounts, utcOffset)
 
 	// Convert to local time for checking
-	lunchStartLocal := lunchStart + float64(utcOffset)
+	lunchStartLocal := lunchStart + utcOffset
 	if lunchStartLocal < 0 {
 		lunchStartLocal += 24
 	}
@@ -42,7 +42,7 @@ func TestJamonationLunchDetection(t *testing.T) {
 	t.Logf("\nActivity around noon UTC-4:")
 	for utc := 14.0; utc <= 18.0; utc += 0.5 {
 		if count, exists := halfHourCounts[utc]; exists {
-			local := utc + float64(utcOffset)
+			local := utc + utcOffset
 			t.Logf("  UTC %.1f (%.1f local): %d events", utc, local, count)
 		}
 	}
@@ -57,7 +57,7 @@ func TestJamonationLunchDetection(t *testing.T) {
 	// Also test UTC-8 (Pacific - where it's incorrectly placing them)
 	utcOffset = -8
 	lunchStart8, _, confidence8 := DetectLunchBreakNoonCentered(halfHourCounts, utcOffset)
-	lunchStartLocal8 := lunchStart8 + float64(utcOffset)
+	lunchStartLocal8 := lunchStart8 + utcOffset
 	if lunchStartLocal8 < 0 {
 		lunchStartLocal8 += 24
 	}
@@ -36,7 +36,7 @@ func TestJosebiroLunchDetection(t *testing.T) {
 
 	tests := []struct {
 		name          string
-		utcOffset     int
+		utcOffset     float64
 		wantStart     float64
 		wantEnd       float64
 		minConfidence float64
@@ -74,14 +74,14 @@ func TestJosebiroLunchDetection(t *testing.T) {
 
 				// Convert to local time for debugging
 				if lunchStart >= 0 {
-					localStart := lunchStart - float64(tt.utcOffset)
+					localStart := lunchStart - tt.utcOffset
 					for localStart < 0 {
 						localStart += 24
 					}
 					for localStart >= 24 {
 						localStart -= 24
 					}
-					localEnd := lunchEnd - float64(tt.utcOffset)
+					localEnd := lunchEnd - tt.utcOffset
 					for localEnd < 0 {
 						localEnd += 24
 					}
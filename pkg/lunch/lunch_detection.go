@@ -3,6 +3,8 @@ package lunch
 
 import (
 	"math"
+
+	"github.com/codeGROOVE-dev/guTZ/pkg/tzconvert"
 )
 
 // GlobalLunchPattern represents a detected lunch pattern in UTC time.
@@ -24,7 +26,7 @@ func extendLunchDuration(duration float64, nextCount int) float64 {
 
 // DetectLunchBreakNoonCentered looks for lunch breaks in the 10am-2:30pm window
 // SIMPLIFIED VERSION - just find ANY drop in activity.
-func DetectLunchBreakNoonCentered(halfHourCounts map[float64]int, utcOffset int) (lunchStart, lunchEnd, confidence float64) {
+func DetectLunchBreakNoonCentered(halfHourCounts map[float64]int, utcOffset float64) (lunchStart, lunchEnd, confidence float64) {
 	return detectLunchBreakNoonCentered(halfHourCounts, utcOffset)
 }
 
@@ -32,7 +34,7 @@ func DetectLunchBreakNoonCentered(halfHourCounts map[float64]int, utcOffset int)
 // SIMPLIFIED VERSION - just find ANY drop in activity.
 //
 //nolint:gocognit,revive,maintidx // Lunch pattern detection requires comprehensive temporal analysis
-func detectLunchBreakNoonCentered(halfHourCounts map[float64]int, utcOffset int) (lunchStart, lunchEnd, confidence float64) {
+func detectLunchBreakNoonCentered(halfHourCounts map[float64]int, utcOffset float64) (lunchStart, lunchEnd, confidence float64) {
 	// Debug output disabled to reduce clutter
 	// Enable with --verbose flag if needed
 
@@ -40,13 +42,7 @@ func detectLunchBreakNoonCentered(halfHourCounts map[float64]int, utcOffset int)
 	// fmt.Fprintf(os.Stderr, "Activity in lunch window (10am-2:30pm local):\n")
 	hasAnyData := false
 	for localHour := 10.0; localHour <= 14.5; localHour += 0.5 {
-		utcHour := localHour - float64(utcOffset)
-		for utcHour < 0 {
-			utcHour += 24
-		}
-		for utcHour >= 24 {
-			utcHour -= 24
-		}
+		utcHour := tzconvert.HalfHourBucket(tzconvert.LocalToUTC(localHour, utcOffset))
 
 		if _, exists := halfHourCounts[utcHour]; exists {
 			hasAnyData = true
@@ -72,15 +68,8 @@ func detectLunchBreakNoonCentered(halfHourCounts map[float64]int, utcOffset int)
 			// But for UTC+10, we want local - 10 to get UTC
 			// The correct formula when offset is negative for positive zones is:
 			// startUTC = startLocal + utcOffset (since offset is already negative)
-			startUTC := startLocal - float64(utcOffset)
-			// Normalize to 0-24 range
-			// IMPORTANT: Use modulo to handle day wraparound correctly
-			for startUTC < 0 {
-				startUTC += 24
-			}
-			for startUTC >= 24 {
-				startUTC -= 24
-			}
+			// Fractional offsets (e.g. UTC+5:45) snap to the half-hour bucket containing the start.
+			startUTC := tzconvert.HalfHourBucket(tzconvert.LocalToUTC(startLocal, utcOffset))
 
 			// Get activity before lunch
 			beforeUTC := startUTC - 0.5
@@ -382,13 +371,7 @@ func detectLunchBreakNoonCentered(halfHourCounts map[float64]int, utcOffset int)
 	}
 
 	// Convert back to UTC for return
-	startUTC := bestStart - float64(utcOffset)
-	for startUTC < 0 {
-		startUTC += 24
-	}
-	for startUTC >= 24 {
-		startUTC -= 24
-	}
+	startUTC := tzconvert.HalfHourBucket(tzconvert.LocalToUTC(bestStart, utcOffset))
 
 	endUTC := startUTC + bestDuration
 	if endUTC >= 24 {
@@ -34,14 +34,14 @@ func TestLunchDetectionTstromberg(t *testing.T) {
 	}
 
 	// Test with UTC-4 (EDT) offset
-	utcOffset := -4
+	utcOffset := -4.0
 
 	// The algorithm should detect lunch from 11:30-12:30 EDT
 
 	lunchStart, lunchEnd, confidence := DetectLunchBreakNoonCentered(halfHourData, utcOffset)
 
 	// Convert to local time for verification
-	lunchStartLocal := (lunchStart + float64(utcOffset) + 24)
+	lunchStartLocal := (lunchStart + utcOffset + 24)
 	for lunchStartLocal >= 24 {
 		lunchStartLocal -= 24
 	}
@@ -49,7 +49,7 @@ func TestLunchDetectionTstromberg(t *testing.T) {
 		lunchStartLocal += 24
 	}
 
-	lunchEndLocal := (lunchEnd + float64(utcOffset) + 24)
+	lunchEndLocal := (lunchEnd + utcOffset + 24)
 	for lunchEndLocal >= 24 {
 		lunchEndLocal -= 24
 	}
@@ -71,3 +71,23 @@ func TestLunchDetectionTstromberg(t *testing.T) {
 		t.Errorf("Expected reasonable confidence, got %.2f", confidence)
 	}
 }
+
+func TestLunchDetectionHalfHourOffset(t *testing.T) {
+	// A workday in India (UTC+5:30): 09:00-18:00 local with a quiet 12:30-13:30 lunch
+	halfHourData := make(map[float64]int)
+	for local := 9.0; local < 18.0; local += 0.5 {
+		utc := local - 5.5
+		if local >= 12.5 && local < 13.5 {
+			continue // No activity over lunch
+		}
+		halfHourData[utc] = 20
+	}
+
+	lunchStart, lunchEnd, confidence := DetectLunchBreakNoonCentered(halfHourData, 5.5)
+	if lunchStart != 7.0 || lunchEnd != 8.0 {
+		t.Errorf("lunch = %.1f-%.1f UTC, want 7.0-8.0 (12:30-13:30 IST)", lunchStart, lunchEnd)
+	}
+	if confidence < 0.5 {
+		t.Errorf("confidence = %.2f, want at least 0.5", confidence)
+	}
+}
@@ -35,14 +35,14 @@ func TestTstrombergFullHourLunch(t *testing.T) {
 	}
 
 	// Test for UTC-4 (Eastern Daylight Time)
-	offset := -4
+	offset := -4.0
 
 	// Detect lunch for this timezone
 	lunchStart, lunchEnd, confidence := DetectLunchBreakNoonCentered(halfHourCounts, offset)
 
 	// Convert UTC lunch times to local Eastern Time
-	lunchStartLocal := lunchStart + float64(offset)
-	lunchEndLocal := lunchEnd + float64(offset)
+	lunchStartLocal := lunchStart + offset
+	lunchEndLocal := lunchEnd + offset
 
 	// Normalize to 24-hour format
 	if lunchStartLocal < 0 {
@@ -0,0 +1,210 @@
+// Package optout keeps the users who asked not to be profiled, and the challenges they prove control
+// of their account with before joining.
+package optout
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ChallengeTTL is how long a user has to publish a challenge token.
+const ChallengeTTL = 24 * time.Hour
+
+// TokenPrefix starts every challenge token, so tokens are easy to spot in a gist or README.
+const TokenPrefix = "gutz-opt-out-"
+
+// ErrNoChallenge is returned when verifying a user who has no unexpired challenge.
+var ErrNoChallenge = errors.New("no pending opt-out challenge; request one first")
+
+// Entry records a user's opt-out.
+type Entry struct {
+	OptedOutAt time.Time `json:"opted_out_at"`
+	Username   string    `json:"username"`
+	Proof      string    `json:"proof"` // Where the challenge token was found
+}
+
+// Challenge is a token a user publishes on their account to prove they control it.
+type Challenge struct {
+	ExpiresAt time.Time `json:"expires_at"`
+	Token     string    `json:"token"`
+}
+
+// file is the registry's on-disk layout. Keys are lower-cased usernames.
+type file struct {
+	Users      map[string]Entry     `json:"users"`
+	Challenges map[string]Challenge `json:"challenges"`
+}
+
+// Registry is the set of opted-out users, persisted as a JSON file. Changes made by other processes
+// sharing the file are picked up on the next lookup.
+type Registry struct {
+	modTime time.Time // Of the file as last read or written, to spot changes by other processes
+	data    file
+	size    int64
+	path    string // Empty keeps the registry in memory only
+	mu      sync.Mutex
+}
+
+// Open loads the registry at path, which need not exist yet. An empty path gives a registry that
+// lives only in memory.
+func Open(path string) (*Registry, error) {
+	r := &Registry{path: path, data: file{Users: map[string]Entry{}, Challenges: map[string]Challenge{}}}
+	if path == "" {
+		return r, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return nil, fmt.Errorf("create opt-out dir: %w", err)
+	}
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func key(username string) string {
+	return strings.ToLower(strings.TrimSpace(username))
+}
+
+// reload rereads the file if another process changed it. The caller holds r.mu, or owns r.
+func (r *Registry) reload() error {
+	if r.path == "" {
+		return nil
+	}
+	info, err := os.Stat(r.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("stat opt-out registry: %w", err)
+	}
+	if info.ModTime().Equal(r.modTime) && info.Size() == r.size {
+		return nil
+	}
+	raw, err := os.ReadFile(r.path)
+	if err != nil {
+		return fmt.Errorf("read opt-out registry: %w", err)
+	}
+	var data file
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return fmt.Errorf("decode opt-out registry: %w", err)
+	}
+	if data.Users == nil {
+		data.Users = map[string]Entry{}
+	}
+	if data.Challenges == nil {
+		data.Challenges = map[string]Challenge{}
+	}
+	r.data = data
+	r.modTime, r.size = info.ModTime(), info.Size()
+	return nil
+}
+
+// save writes the registry through a temporary file, so readers never see a partial write.
+// The caller holds r.mu.
+func (r *Registry) save() error {
+	if r.path == "" {
+		return nil
+	}
+	raw, err := json.MarshalIndent(r.data, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode opt-out registry: %w", err)
+	}
+	f, err := os.CreateTemp(filepath.Dir(r.path), filepath.Base(r.path)+".*")
+	if err != nil {
+		return fmt.Errorf("write opt-out registry: %w", err)
+	}
+	if _, err := f.Write(raw); err != nil {
+		_ = f.Close()           //nolint:errcheck // write error takes precedence
+		_ = os.Remove(f.Name()) //nolint:errcheck // best effort
+		return fmt.Errorf("write opt-out registry: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("write opt-out registry: %w", err)
+	}
+	if err := os.Rename(f.Name(), r.path); err != nil {
+		return fmt.Errorf("replace opt-out registry: %w", err)
+	}
+	if info, err := os.Stat(r.path); err == nil {
+		r.modTime, r.size = info.ModTime(), info.Size()
+	}
+	return nil
+}
+
+// Contains reports whether username has opted out. If the file cannot be read, the last known state is used.
+func (r *Registry) Contains(username string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_ = r.reload() //nolint:errcheck // a torn read keeps the previous, still valid, state
+	_, ok := r.data.Users[key(username)]
+	return ok
+}
+
+// Entry returns the opt-out recorded for username.
+func (r *Registry) Entry(username string) (Entry, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_ = r.reload() //nolint:errcheck // a torn read keeps the previous, still valid, state
+	e, ok := r.data.Users[key(username)]
+	return e, ok
+}
+
+// Challenge returns username's pending challenge, issuing a new one if there is none or it expired.
+func (r *Registry) Challenge(username string) (Challenge, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.reload(); err != nil {
+		return Challenge{}, err
+	}
+	now := time.Now()
+	if c, ok := r.data.Challenges[key(username)]; ok && now.Before(c.ExpiresAt) {
+		return c, nil
+	}
+
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return Challenge{}, fmt.Errorf("generate token: %w", err)
+	}
+	c := Challenge{Token: TokenPrefix + hex.EncodeToString(buf), ExpiresAt: now.Add(ChallengeTTL)}
+	// Drop expired challenges while rewriting the file anyway
+	for k, old := range r.data.Challenges {
+		if !now.Before(old.ExpiresAt) {
+			delete(r.data.Challenges, k)
+		}
+	}
+	r.data.Challenges[key(username)] = c
+	return c, r.save()
+}
+
+// Pending returns username's unexpired challenge, or ErrNoChallenge.
+func (r *Registry) Pending(username string) (Challenge, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.reload(); err != nil {
+		return Challenge{}, err
+	}
+	c, ok := r.data.Challenges[key(username)]
+	if !ok || !time.Now().Before(c.ExpiresAt) {
+		return Challenge{}, ErrNoChallenge
+	}
+	return c, nil
+}
+
+// Add records that username opted out, with where their proof was found, and retires their challenge.
+func (r *Registry) Add(username, proof string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.reload(); err != nil {
+		return err
+	}
+	r.data.Users[key(username)] = Entry{Username: username, OptedOutAt: time.Now().UTC(), Proof: proof}
+	delete(r.data.Challenges, key(username))
+	return r.save()
+}
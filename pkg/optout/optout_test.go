@@ -0,0 +1,59 @@
+package optout
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRegistry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "optout.json")
+	reg, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+
+	if _, err := reg.Pending("alice"); !errors.Is(err, ErrNoChallenge) {
+		t.Errorf("Pending before a challenge = %v, want ErrNoChallenge", err)
+	}
+	c, err := reg.Challenge("Alice")
+	if err != nil || !strings.HasPrefix(c.Token, TokenPrefix) {
+		t.Fatalf("Challenge = %+v, %v", c, err)
+	}
+	if again, err := reg.Challenge("alice"); err != nil || again.Token != c.Token {
+		t.Errorf("second Challenge = %+v, %v; want the pending token %s", again, err, c.Token)
+	}
+
+	// Another process sharing the file sees the challenge and records the opt-out
+	other, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if pending, err := other.Pending("ALICE"); err != nil || pending.Token != c.Token {
+		t.Fatalf("Pending from another registry = %+v, %v", pending, err)
+	}
+	if err := other.Add("alice", "https://gist.github.com/alice/1"); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+
+	if !reg.Contains("Alice") || reg.Contains("bob") {
+		t.Error("Contains does not reflect the opt-out recorded by the other registry")
+	}
+	if e, ok := reg.Entry("alice"); !ok || e.Proof != "https://gist.github.com/alice/1" || e.OptedOutAt.IsZero() {
+		t.Errorf("Entry = %+v, %v", e, ok)
+	}
+	if _, err := reg.Pending("alice"); !errors.Is(err, ErrNoChallenge) {
+		t.Errorf("challenge survived the opt-out: %v", err)
+	}
+}
+
+func TestMemoryRegistry(t *testing.T) {
+	reg, err := Open("")
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if err := reg.Add("alice", "readme"); err != nil || !reg.Contains("alice") {
+		t.Errorf("Add = %v, Contains = %v", err, reg.Contains("alice"))
+	}
+}
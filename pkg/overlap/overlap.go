@@ -0,0 +1,324 @@
+// Package overlap combines timezone detection results for several people into a shared
+// half-hour UTC grid to find the least painful meeting windows.
+package overlap
+
+import (
+	"math"
+	"sort"
+
+	"github.com/codeGROOVE-dev/guTZ/pkg/gutz"
+	"github.com/codeGROOVE-dev/guTZ/pkg/tzconvert"
+)
+
+// SlotsPerDay is the number of half-hour slots in the planning grid.
+const SlotsPerDay = 48
+
+// Status describes what a person is likely doing during a half-hour slot.
+type Status int
+
+// Status values, ordered from most to least convenient for a meeting.
+const (
+	Active Status = iota // Within detected active hours
+	Off                  // Awake but outside active hours
+	Lunch                // On a detected lunch break
+	Asleep               // Within a detected rest period
+)
+
+// String returns the lowercase name of the status.
+func (s Status) String() string {
+	switch s {
+	case Active:
+		return "active"
+	case Off:
+		return "off"
+	case Lunch:
+		return "lunch"
+	case Asleep:
+		return "asleep"
+	default:
+		return "unknown"
+	}
+}
+
+// availability is how much a slot with this status contributes to a team score (0-1).
+func (s Status) availability() float64 {
+	switch s {
+	case Active:
+		return 1.0
+	case Off:
+		return 0.4
+	case Lunch:
+		return 0.2
+	default:
+		return 0
+	}
+}
+
+// pain is how much a meeting during a slot with this status costs the person.
+func (s Status) pain() float64 {
+	switch s {
+	case Active:
+		return 0
+	case Off:
+		return 1
+	case Lunch:
+		return 2
+	default:
+		return 4
+	}
+}
+
+// Member is one person's schedule projected onto the UTC grid.
+type Member struct {
+	Username  string              `json:"username"`
+	Timezone  string              `json:"timezone"`
+	Slots     [SlotsPerDay]Status `json:"-"`
+	Offset    float64             `json:"offset"`
+	PainScore float64             `json:"pain_score"`
+}
+
+// Slot summarizes everyone's status during one half-hour UTC slot.
+type Slot struct {
+	Active   []string `json:"active,omitempty"`
+	Off      []string `json:"off,omitempty"`
+	Lunch    []string `json:"lunch,omitempty"`
+	Asleep   []string `json:"asleep,omitempty"`
+	StartUTC float64  `json:"start_utc"`
+	Score    float64  `json:"score"`
+}
+
+// Window is a candidate meeting window made of consecutive slots.
+type Window struct {
+	Asleep   []string `json:"asleep,omitempty"`
+	AtLunch  []string `json:"at_lunch,omitempty"`
+	OffHours []string `json:"off_hours,omitempty"`
+	StartUTC float64  `json:"start_utc"`
+	EndUTC   float64  `json:"end_utc"`
+	Score    float64  `json:"score"`
+}
+
+// Plan is the combined schedule for a team.
+type Plan struct {
+	Members []Member `json:"members"`
+	Slots   []Slot   `json:"slots"`
+	Windows []Window `json:"windows"`
+}
+
+// Options controls how meeting windows are chosen.
+type Options struct {
+	// WindowSlots is the meeting length in half-hour slots (default 2, i.e. one hour).
+	WindowSlots int
+	// MaxWindows is the maximum number of non-overlapping windows to report (default 3).
+	MaxWindows int
+}
+
+// NewMember projects a detection result onto the UTC grid.
+// Sleep takes precedence over lunch, and lunch over active hours.
+// If no active hours were detected, 9:00-17:00 local time is assumed.
+func NewMember(result *gutz.Result) Member {
+	m := Member{
+		Username: result.Username,
+		Timezone: result.Timezone,
+		Offset:   detectedOffset(result),
+	}
+
+	activeStart, activeEnd := result.ActiveHoursUTC.Start, result.ActiveHoursUTC.End
+	if activeStart == 0 && activeEnd == 0 {
+		activeStart = tzconvert.LocalToUTC(9, m.Offset)
+		activeEnd = tzconvert.LocalToUTC(17, m.Offset)
+	}
+
+	for i := range SlotsPerDay {
+		hour := slotHour(i)
+		status := Off
+		if inRange(hour, activeStart, activeEnd) {
+			status = Active
+		}
+		if result.LunchHoursUTC.Confidence > 0 && inRange(hour, result.LunchHoursUTC.Start, result.LunchHoursUTC.End) {
+			status = Lunch
+		}
+		for _, r := range result.SleepRangesLocal {
+			start := tzconvert.LocalToUTC(r.Start, m.Offset)
+			end := tzconvert.LocalToUTC(r.End, m.Offset)
+			if inRange(hour, start, end) {
+				status = Asleep
+				break
+			}
+		}
+		m.Slots[i] = status
+	}
+	return m
+}
+
+// detectedOffset returns the UTC offset the detector used to produce the result's local times.
+// Named zones are ambiguous across DST (America/New_York may have been detected at -5 but is -4 today),
+// so the offset is read back from a UTC/local pair before falling back to the zone's current offset.
+func detectedOffset(result *gutz.Result) float64 {
+	var utc, local float64
+	switch {
+	case result.ActiveHoursUTC != (gutz.ActiveHours{}) && result.ActiveHoursLocal != (gutz.ActiveHours{}):
+		utc, local = result.ActiveHoursUTC.Start, result.ActiveHoursLocal.Start
+	case result.LunchHoursUTC.Confidence > 0 && result.LunchHoursLocal.Confidence > 0:
+		utc, local = result.LunchHoursUTC.Start, result.LunchHoursLocal.Start
+	default:
+		return tzconvert.ParseTimezoneOffset(result.Timezone)
+	}
+	offset := math.Mod(local-utc+48, 24)
+	if offset > 14 {
+		offset -= 24 // UTC offsets run from -12 to +14
+	}
+	return math.Round(offset*4) / 4 // Offsets are whole quarter hours (e.g. +5:45)
+}
+
+// Build combines detection results into a plan with the best meeting windows.
+func Build(results []*gutz.Result, opts Options) *Plan {
+	if opts.WindowSlots <= 0 {
+		opts.WindowSlots = 2
+	}
+	if opts.MaxWindows <= 0 {
+		opts.MaxWindows = 3
+	}
+
+	plan := &Plan{Slots: make([]Slot, SlotsPerDay)}
+	for _, r := range results {
+		if r == nil {
+			continue
+		}
+		plan.Members = append(plan.Members, NewMember(r))
+	}
+
+	for i := range SlotsPerDay {
+		slot := Slot{StartUTC: slotHour(i)}
+		total := 0.0
+		for j := range plan.Members {
+			m := &plan.Members[j]
+			switch m.Slots[i] {
+			case Active:
+				slot.Active = append(slot.Active, m.Username)
+			case Off:
+				slot.Off = append(slot.Off, m.Username)
+			case Lunch:
+				slot.Lunch = append(slot.Lunch, m.Username)
+			case Asleep:
+				slot.Asleep = append(slot.Asleep, m.Username)
+			}
+			total += m.Slots[i].availability()
+		}
+		if len(plan.Members) > 0 {
+			slot.Score = total / float64(len(plan.Members))
+		}
+		plan.Slots[i] = slot
+	}
+
+	plan.Windows = bestWindows(plan, opts)
+	for j := range plan.Members {
+		plan.Members[j].PainScore = painScore(&plan.Members[j], plan.Windows, opts.WindowSlots)
+	}
+	return plan
+}
+
+// bestWindows ranks every window start by mean slot score and returns the top non-overlapping ones.
+func bestWindows(plan *Plan, opts Options) []Window {
+	if len(plan.Members) == 0 {
+		return nil
+	}
+
+	type scored struct {
+		start int
+		score float64
+	}
+	starts := make([]scored, 0, SlotsPerDay)
+	for i := range SlotsPerDay {
+		sum := 0.0
+		for k := range opts.WindowSlots {
+			sum += plan.Slots[(i+k)%SlotsPerDay].Score
+		}
+		starts = append(starts, scored{start: i, score: sum / float64(opts.WindowSlots)})
+	}
+	sort.SliceStable(starts, func(a, b int) bool {
+		return starts[a].score > starts[b].score
+	})
+
+	var used [SlotsPerDay]bool
+	var windows []Window
+	for _, s := range starts {
+		if len(windows) >= opts.MaxWindows || s.score <= 0 {
+			break
+		}
+		overlaps := false
+		for k := range opts.WindowSlots {
+			if used[(s.start+k)%SlotsPerDay] {
+				overlaps = true
+				break
+			}
+		}
+		if overlaps {
+			continue
+		}
+		for k := range opts.WindowSlots {
+			used[(s.start+k)%SlotsPerDay] = true
+		}
+		windows = append(windows, newWindow(plan, s.start, opts.WindowSlots, s.score))
+	}
+	return windows
+}
+
+// newWindow builds a window, listing anyone who is asleep, at lunch, or off-hours for any part of it.
+func newWindow(plan *Plan, start, length int, score float64) Window {
+	w := Window{
+		StartUTC: slotHour(start),
+		EndUTC:   slotHour((start + length) % SlotsPerDay),
+		Score:    math.Round(score*1000) / 1000,
+	}
+	for j := range plan.Members {
+		m := &plan.Members[j]
+		worst := Active
+		for k := range length {
+			if st := m.Slots[(start+k)%SlotsPerDay]; st > worst {
+				worst = st
+			}
+		}
+		switch worst {
+		case Asleep:
+			w.Asleep = append(w.Asleep, m.Username)
+		case Lunch:
+			w.AtLunch = append(w.AtLunch, m.Username)
+		case Off:
+			w.OffHours = append(w.OffHours, m.Username)
+		default:
+		}
+	}
+	return w
+}
+
+// painScore averages how inconvenient the recommended windows are for a member:
+// 0 means every window falls in their active hours, 4 means they would be asleep for all of them.
+func painScore(m *Member, windows []Window, length int) float64 {
+	if len(windows) == 0 {
+		return 0
+	}
+	total := 0.0
+	for _, w := range windows {
+		start := int(w.StartUTC * 2)
+		for k := range length {
+			total += m.Slots[(start+k)%SlotsPerDay].pain()
+		}
+	}
+	return math.Round(total/float64(len(windows)*length)*100) / 100
+}
+
+// slotHour returns the UTC hour at which slot i starts.
+func slotHour(i int) float64 {
+	return float64(i) * 0.5
+}
+
+// inRange reports whether hour lies in [start, end), handling ranges that wrap past midnight.
+func inRange(hour, start, end float64) bool {
+	if start == end {
+		return false
+	}
+	if start < end {
+		return hour >= start && hour < end
+	}
+	return hour >= start || hour < end
+}
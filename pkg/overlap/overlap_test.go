@@ -0,0 +1,171 @@
+package overlap
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/codeGROOVE-dev/guTZ/pkg/gutz"
+)
+
+// result builds a detection result with active hours, lunch, and sleep given in local time.
+func result(username, tz string, offset, activeStart, activeEnd, lunchStart, sleepStart, sleepEnd float64) *gutz.Result {
+	toUTC := func(h float64) float64 {
+		utc := h - offset
+		for utc < 0 {
+			utc += 24
+		}
+		for utc >= 24 {
+			utc -= 24
+		}
+		return utc
+	}
+	r := &gutz.Result{
+		Username: username,
+		Timezone: tz,
+		SleepRangesLocal: []gutz.SleepRange{
+			{Start: sleepStart, End: sleepEnd},
+		},
+	}
+	r.ActiveHoursUTC = gutz.ActiveHours{Start: toUTC(activeStart), End: toUTC(activeEnd)}
+	r.ActiveHoursLocal = gutz.ActiveHours{Start: activeStart, End: activeEnd}
+	r.LunchHoursUTC = gutz.LunchBreak{Start: toUTC(lunchStart), End: toUTC(lunchStart + 1), Confidence: 0.8}
+	r.LunchHoursLocal = gutz.LunchBreak{Start: lunchStart, End: lunchStart + 1, Confidence: 0.8}
+	return r
+}
+
+func TestNewMember(t *testing.T) {
+	m := NewMember(result("alice", "UTC-5", -5, 9, 17, 12, 23, 7))
+
+	tests := []struct {
+		hourUTC float64
+		want    Status
+	}{
+		{14, Active},   // 9:00 local
+		{17, Lunch},    // 12:00 local
+		{21.5, Active}, // 16:30 local
+		{23, Off},      // 18:00 local
+		{5, Asleep},    // 0:00 local
+		{12.5, Off},    // 7:30 local
+	}
+	for _, tt := range tests {
+		if got := m.Slots[int(tt.hourUTC*2)]; got != tt.want {
+			t.Errorf("slot %.1f UTC = %s, want %s", tt.hourUTC, got, tt.want)
+		}
+	}
+}
+
+func TestNewMemberUsesDetectedOffset(t *testing.T) {
+	tests := []struct {
+		name   string
+		result *gutz.Result
+		offset float64
+		asleep float64 // a UTC hour that must be asleep at the detected offset
+	}{
+		{
+			// Detected in winter at EST; New York's current offset may be -4, which would shift sleep by an hour
+			name:   "IANA zone detected at standard time",
+			result: result("nyc", "America/New_York", -5, 9, 17, 12, 23, 7),
+			offset: -5,
+			asleep: 11.5, // 6:30 EST, awake already at EDT
+		},
+		{
+			name:   "half-hour offset",
+			result: result("blr", "Asia/Kolkata", 5.5, 9, 18, 13, 23, 7),
+			offset: 5.5,
+			asleep: 1, // 6:30 IST
+		},
+		{
+			name:   "no hours falls back to the zone",
+			result: &gutz.Result{Username: "utc", Timezone: "UTC+3"},
+			offset: 3,
+			asleep: -1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMember(tt.result)
+			if m.Offset != tt.offset {
+				t.Errorf("Offset = %v, want %v", m.Offset, tt.offset)
+			}
+			if tt.asleep >= 0 {
+				if got := m.Slots[int(tt.asleep*2)]; got != Asleep {
+					t.Errorf("slot %.1f UTC = %s, want %s", tt.asleep, got, Asleep)
+				}
+			}
+		})
+	}
+}
+
+func TestBuildFindsSharedHours(t *testing.T) {
+	plan := Build([]*gutz.Result{
+		result("nyc", "UTC-5", -5, 9, 17, 12, 23, 7),
+		result("london", "UTC+0", 0, 9, 17, 12, 23, 7),
+	}, Options{})
+
+	if len(plan.Windows) != 3 {
+		t.Fatalf("got %d windows, want 3", len(plan.Windows))
+	}
+	// The only shared active hours are 14:00-17:00 UTC.
+	for _, w := range plan.Windows {
+		if w.StartUTC < 14 || w.EndUTC > 17 {
+			t.Errorf("window %.1f-%.1f UTC falls outside shared hours", w.StartUTC, w.EndUTC)
+		}
+		if w.Score != 1 || len(w.Asleep) > 0 || len(w.AtLunch) > 0 {
+			t.Errorf("window %.1f-%.1f UTC should be fully available: %+v", w.StartUTC, w.EndUTC, w)
+		}
+	}
+	for _, m := range plan.Members {
+		if m.PainScore != 0 {
+			t.Errorf("%s pain score = %.2f, want 0", m.Username, m.PainScore)
+		}
+	}
+
+	// At 12:00 UTC London is at lunch; at 10:00 UTC New York is asleep.
+	if slot := plan.Slots[24]; !slices.Contains(slot.Lunch, "london") {
+		t.Errorf("slot 12:00 UTC = %+v, want london at lunch", slot)
+	}
+	if slot := plan.Slots[20]; !slices.Contains(slot.Asleep, "nyc") || !slices.Contains(slot.Active, "london") {
+		t.Errorf("slot 10:00 UTC = %+v, want nyc asleep and london active", slot)
+	}
+}
+
+func TestBuildReportsPain(t *testing.T) {
+	plan := Build([]*gutz.Result{
+		result("sf", "UTC-8", -8, 9, 17, 12, 23, 7),
+		result("berlin", "UTC+1", 1, 9, 17, 12, 23, 7),
+	}, Options{WindowSlots: 2, MaxWindows: 1})
+
+	if len(plan.Windows) != 1 {
+		t.Fatalf("got %d windows, want 1", len(plan.Windows))
+	}
+	// No shared active hours exist, so someone has to give something up.
+	total := 0.0
+	for _, m := range plan.Members {
+		total += m.PainScore
+	}
+	if total == 0 {
+		t.Errorf("expected a non-zero pain score for SF/Berlin, got %+v", plan.Members)
+	}
+	if w := plan.Windows[0]; len(w.Asleep) > 0 {
+		t.Errorf("best window should avoid sleep when possible: %+v", w)
+	}
+}
+
+func TestInRange(t *testing.T) {
+	tests := []struct {
+		hour, start, end float64
+		want             bool
+	}{
+		{10, 9, 17, true},
+		{17, 9, 17, false},
+		{23.5, 22, 6, true},
+		{3, 22, 6, true},
+		{12, 22, 6, false},
+		{5, 5, 5, false},
+	}
+	for _, tt := range tests {
+		if got := inRange(tt.hour, tt.start, tt.end); got != tt.want {
+			t.Errorf("inRange(%v, %v, %v) = %v, want %v", tt.hour, tt.start, tt.end, got, tt.want)
+		}
+	}
+}
@@ -37,7 +37,7 @@ func TestBinacsUTC8SleepDetection(t *testing.T) {
 
 	tests := []struct {
 		name          string
-		offset        int
+		offset        float64
 		expectedSleep []float64 // Expected UTC hours for sleep
 		description   string
 	}{
@@ -61,13 +61,13 @@ func TestBinacsUTC8SleepDetection(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			// Add debug output to understand activity pattern
-			t.Logf("Testing with offset %+d", tt.offset)
+			t.Logf("Testing with offset %+g", tt.offset)
 			t.Logf("Total activity: 14 events")
 			t.Logf("Active buckets: 7.0(3), 9.0(3), 10.5(1), 11.0(1), 12.5(3), 15.0(1), 16.0(1), 18.5(1)")
 
 			sleepBuckets := DetectSleepPeriodsWithOffset(halfHourCounts, tt.offset)
 
-			t.Logf("Detected sleep buckets for offset %+d: %v", tt.offset, sleepBuckets)
+			t.Logf("Detected sleep buckets for offset %+g: %v", tt.offset, sleepBuckets)
 			t.Logf("Description: %s", tt.description)
 
 			// Check if we found any sleep
@@ -77,7 +77,7 @@ func TestBinacsUTC8SleepDetection(t *testing.T) {
 					t.Logf("No sleep detected for UTC+8 - acceptable for sparse data with nighttime activity")
 					return
 				}
-				t.Errorf("No sleep detected for offset %+d", tt.offset)
+				t.Errorf("No sleep detected for offset %+g", tt.offset)
 				return
 			}
 
@@ -109,8 +109,8 @@ func TestBinacsUTC8SleepDetection(t *testing.T) {
 			// Log the local time equivalents
 			t.Logf("Sleep period: %.1f-%.1f UTC (%.1f-%.1f local)",
 				minBucket, maxBucket,
-				normalizeHour(minBucket+float64(tt.offset)),
-				normalizeHour(maxBucket+float64(tt.offset)))
+				normalizeHour(minBucket+tt.offset),
+				normalizeHour(maxBucket+tt.offset))
 		})
 	}
 }
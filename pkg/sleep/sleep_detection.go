@@ -3,6 +3,8 @@ package sleep
 
 import (
 	"sort"
+
+	"github.com/codeGROOVE-dev/guTZ/pkg/tzconvert"
 )
 
 // DetectSleepPeriodsWithHalfHours identifies sleep periods using 30-minute resolution data.
@@ -19,7 +21,7 @@ func DetectSleepPeriodsWithHalfHours(halfHourCounts map[float64]int) []float64 {
 // The offset parameter adjusts the search to look for sleep at appropriate local times.
 //
 //nolint:gocognit,revive,maintidx // Complex sleep detection algorithm
-func DetectSleepPeriodsWithOffset(halfHourCounts map[float64]int, timezoneOffset int) []float64 {
+func DetectSleepPeriodsWithOffset(halfHourCounts map[float64]int, timezoneOffset float64) []float64 {
 	// Find all potential rest periods
 	type restPeriod struct {
 		buckets []float64
@@ -32,14 +34,8 @@ func DetectSleepPeriodsWithOffset(halfHourCounts map[float64]int, timezoneOffset
 	// Calculate the UTC hour that corresponds to 21:00 (9pm) in the target timezone
 	// For example, if timezoneOffset is +8 (China), 21:00 local = 13:00 UTC
 	localNightStart := 21.0 // 9pm local time
-	utcNightStart := localNightStart - float64(timezoneOffset)
-	// Normalize to 0-24 range
-	for utcNightStart < 0 {
-		utcNightStart += 24
-	}
-	for utcNightStart >= 24 {
-		utcNightStart -= 24
-	}
+	// Fractional offsets (e.g. UTC+5:45) snap to the enclosing half-hour bucket
+	utcNightStart := tzconvert.HalfHourBucket(tzconvert.LocalToUTC(localNightStart, timezoneOffset))
 
 	// Start search at the UTC time that corresponds to 9pm local
 	// This gives preference to nighttime sleep periods in the target timezone
@@ -254,20 +250,13 @@ func DetectSleepPeriodsWithOffset(halfHourCounts map[float64]int, timezoneOffset
 // calculateNighttimeScore returns a score indicating how much a period overlaps with typical nighttime hours.
 // Nighttime is considered 21:00-09:00 LOCAL time. Higher scores mean more nighttime overlap.
 // Periods during work hours (9:00-17:00 local) get heavily penalized.
-func calculateNighttimeScore(buckets []float64, timezoneOffset int) float64 {
+func calculateNighttimeScore(buckets []float64, timezoneOffset float64) float64 {
 	nighttimeCount := 0
 	worktimeCount := 0
 
 	for _, bucket := range buckets {
 		// Convert UTC bucket to local time
-		localHour := bucket + float64(timezoneOffset)
-		// Normalize to 0-24 range
-		for localHour >= 24.0 {
-			localHour -= 24.0
-		}
-		for localHour < 0.0 {
-			localHour += 24.0
-		}
+		localHour := tzconvert.UTCToLocal(bucket, timezoneOffset)
 
 		// Hours 21:00-23:30 and 00:00-08:30 LOCAL are considered nighttime
 		if localHour >= 21.0 || localHour < 9.0 {
@@ -2,7 +2,6 @@ package social
 
 import (
 	"context"
-	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -31,7 +30,7 @@ type BlueSkyProfile struct {
 }
 
 // extractBlueSky extracts content from a BlueSky profile.
-func extractBlueSky(ctx context.Context, blueSkyURL string, logger *slog.Logger) *Content {
+func extractBlueSky(ctx context.Context, client *http.Client, blueSkyURL string, logger *slog.Logger) *Content {
 	// Extract handle from URL
 	handle := extractBlueSkyHandle(blueSkyURL)
 	if handle == "" {
@@ -40,7 +39,7 @@ func extractBlueSky(ctx context.Context, blueSkyURL string, logger *slog.Logger)
 	}
 
 	// Try the public API endpoint
-	profile, err := fetchBlueSkyProfile(ctx, handle, logger)
+	profile, err := fetchBlueSkyProfile(ctx, client, handle, logger)
 	if err != nil {
 		logger.Debug("failed to fetch BlueSky profile", "handle", handle, "error", err)
 		// Return basic structure
@@ -121,7 +120,7 @@ func extractBlueSkyHandle(blueSkyURL string) string {
 }
 
 // fetchBlueSkyProfile fetches a BlueSky profile using the public API.
-func fetchBlueSkyProfile(ctx context.Context, handle string, logger *slog.Logger) (*BlueSkyProfile, error) {
+func fetchBlueSkyProfile(ctx context.Context, client *http.Client, handle string, logger *slog.Logger) (*BlueSkyProfile, error) {
 	// Try the public API endpoint
 	// Note: BlueSky's API is evolving, this endpoint might change
 	apiURL := fmt.Sprintf("https://public.api.bsky.app/xrpc/app.bsky.actor.getProfile?actor=%s", handle)
@@ -134,14 +133,7 @@ func fetchBlueSkyProfile(ctx context.Context, handle string, logger *slog.Logger
 	req.Header.Set("Accept", "application/json")
 	req.Header.Set("User-Agent", "guTZ/1.0")
 
-	client := &http.Client{
-		Timeout: 10 * time.Second,
-		Transport: &http.Transport{
-			TLSClientConfig: &tls.Config{
-				InsecureSkipVerify: true, //nolint:gosec // Explicitly requested to ignore SSL errors for personal websites
-			},
-		},
-	}
+	client = httpClient(client)
 
 	// Use retry logic with exponential backoff and jitter
 	var resp *http.Response
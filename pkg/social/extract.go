@@ -23,6 +23,13 @@ import (
 // The map key is the type (e.g., "mastodon", "twitter", "website", "linkedin")
 // The map value is the URL or identifier.
 func Extract(ctx context.Context, data map[string]string, logger *slog.Logger) []Content {
+	return ExtractWithClient(ctx, nil, data, logger)
+}
+
+// ExtractWithClient is like Extract but sends every request through client.
+// A nil client uses a default client with a 10-second timeout.
+// Twitter profiles are only scraped when client is nil, as the scraper manages its own connections.
+func ExtractWithClient(ctx context.Context, client *http.Client, data map[string]string, logger *slog.Logger) []Content {
 	if logger == nil {
 		logger = slog.Default()
 	}
@@ -60,18 +67,18 @@ func Extract(ctx context.Context, data map[string]string, logger *slog.Logger) [
 
 			switch strings.ToLower(k) {
 			case "mastodon":
-				content = extractMastodon(ctx, u, logger)
+				content = extractMastodon(ctx, client, u, logger)
 			case "twitter", "x":
-				content = extractTwitter(ctx, u, logger)
+				content = extractTwitter(ctx, client, u, logger)
 			case "bluesky", "bsky":
-				content = extractBlueSky(ctx, u, logger)
+				content = extractBlueSky(ctx, client, u, logger)
 			case "website", "blog", "homepage":
-				content = extractWebsite(ctx, u, logger)
+				content = extractWebsite(ctx, client, u, logger)
 			case "linkedin":
 				content = extractLinkedIn(ctx, u, logger)
 			default:
 				// For unknown types, try to extract as a generic website
-				content = extractWebsite(ctx, u, logger)
+				content = extractWebsite(ctx, client, u, logger)
 				if content != nil {
 					content.Kind = k // Preserve the original kind
 				}
@@ -90,11 +97,11 @@ func Extract(ctx context.Context, data map[string]string, logger *slog.Logger) [
 }
 
 // extractMastodon extracts content from a Mastodon profile.
-func extractMastodon(ctx context.Context, mastodonURL string, logger *slog.Logger) *Content {
+func extractMastodon(ctx context.Context, client *http.Client, mastodonURL string, logger *slog.Logger) *Content {
 	// First try API, then fall back to HTML scraping
-	profileData := fetchMastodonProfileViaAPI(ctx, mastodonURL, logger)
+	profileData := fetchMastodonProfileViaAPI(ctx, client, mastodonURL, logger)
 	if profileData == nil {
-		profileData = fetchMastodonProfile(ctx, mastodonURL, logger)
+		profileData = fetchMastodonProfile(ctx, client, mastodonURL, logger)
 	}
 
 	if profileData == nil {
@@ -243,9 +250,9 @@ func extractLinkedIn(ctx context.Context, linkedinURL string, logger *slog.Logge
 }
 
 // extractWebsite extracts content from a generic website.
-func extractWebsite(ctx context.Context, websiteURL string, logger *slog.Logger) *Content {
+func extractWebsite(ctx context.Context, client *http.Client, websiteURL string, logger *slog.Logger) *Content {
 	// Fetch website content
-	htmlContent := fetchWebsiteContent(ctx, websiteURL, logger)
+	htmlContent := fetchWebsiteContent(ctx, client, websiteURL, logger)
 	if htmlContent == "" {
 		return nil
 	}
@@ -283,8 +290,23 @@ func extractWebsite(ctx context.Context, websiteURL string, logger *slog.Logger)
 	return content
 }
 
+// httpClient returns client, or a default client with a short timeout if client is nil.
+func httpClient(client *http.Client) *http.Client {
+	if client != nil {
+		return client
+	}
+	return &http.Client{
+		Timeout: 10 * time.Second,
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{
+				InsecureSkipVerify: true, //nolint:gosec // Explicitly requested to ignore SSL errors for personal websites
+			},
+		},
+	}
+}
+
 // fetchWebsiteContent fetches the content of a website.
-func fetchWebsiteContent(ctx context.Context, websiteURL string, logger *slog.Logger) string {
+func fetchWebsiteContent(ctx context.Context, client *http.Client, websiteURL string, logger *slog.Logger) string {
 	if websiteURL == "" {
 		return ""
 	}
@@ -339,14 +361,7 @@ func fetchWebsiteContent(ctx context.Context, websiteURL string, logger *slog.Lo
 
 	req.Header.Set("User-Agent", "GitHub-Timezone-Detector/1.0")
 
-	client := &http.Client{
-		Timeout: 10 * time.Second,
-		Transport: &http.Transport{
-			TLSClientConfig: &tls.Config{
-				InsecureSkipVerify: true, //nolint:gosec // Explicitly requested to ignore SSL errors for personal websites
-			},
-		},
-	}
+	client = httpClient(client)
 
 	// Use retry logic with minimal attempts for personal websites
 	var resp *http.Response
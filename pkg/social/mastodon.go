@@ -2,7 +2,6 @@ package social
 
 import (
 	"context"
-	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"html"
@@ -51,12 +50,12 @@ type MastodonField struct {
 }
 
 // fetchMastodonProfileViaAPI fetches profile data using the Mastodon API.
-func fetchMastodonProfileViaAPI(ctx context.Context, mastodonURL string, logger *slog.Logger) *MastodonProfileData { //nolint:gocognit,revive,maintidx // Complex function with necessary error handling and retries
+func fetchMastodonProfileViaAPI(ctx context.Context, client *http.Client, mastodonURL string, logger *slog.Logger) *MastodonProfileData { //nolint:gocognit,revive,maintidx // Complex function with necessary error handling and retries
 	// Parse the Mastodon URL to extract hostname and username
 	parsedURL, err := url.Parse(mastodonURL)
 	if err != nil {
 		logger.Debug("failed to parse Mastodon URL", "url", mastodonURL, "error", err)
-		return fetchMastodonProfile(ctx, mastodonURL, logger) // Fallback to HTML scraping
+		return fetchMastodonProfile(ctx, client, mastodonURL, logger) // Fallback to HTML scraping
 	}
 
 	hostname := parsedURL.Host
@@ -86,7 +85,7 @@ func fetchMastodonProfileViaAPI(ctx context.Context, mastodonURL string, logger
 
 	if username == "" {
 		logger.Debug("could not extract username from Mastodon URL", "url", mastodonURL)
-		return fetchMastodonProfile(ctx, mastodonURL, logger) // Fallback to HTML scraping
+		return fetchMastodonProfile(ctx, client, mastodonURL, logger) // Fallback to HTML scraping
 	}
 
 	// Construct the API URL
@@ -97,20 +96,13 @@ func fetchMastodonProfileViaAPI(ctx context.Context, mastodonURL string, logger
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, http.NoBody)
 	if err != nil {
 		logger.Debug("failed to create API request", "url", apiURL, "error", err)
-		return fetchMastodonProfile(ctx, mastodonURL, logger) // Fallback
+		return fetchMastodonProfile(ctx, client, mastodonURL, logger) // Fallback
 	}
 
 	req.Header.Set("Accept", "application/json")
 	req.Header.Set("User-Agent", "GitHub-Timezone-Detector/1.0")
 
-	client := &http.Client{
-		Timeout: 10 * time.Second,
-		Transport: &http.Transport{
-			TLSClientConfig: &tls.Config{
-				InsecureSkipVerify: true, //nolint:gosec // Explicitly requested to ignore SSL errors for personal websites
-			},
-		},
-	}
+	client = httpClient(client)
 
 	// Use retry logic with exponential backoff and jitter
 	var resp *http.Response
@@ -147,7 +139,7 @@ func fetchMastodonProfileViaAPI(ctx context.Context, mastodonURL string, logger
 	)
 	if err != nil {
 		logger.Debug("failed to fetch Mastodon API after retries", "url", apiURL, "error", err)
-		return fetchMastodonProfile(ctx, mastodonURL, logger) // Fallback
+		return fetchMastodonProfile(ctx, client, mastodonURL, logger) // Fallback
 	}
 	defer func() {
 		if err := resp.Body.Close(); err != nil {
@@ -157,19 +149,19 @@ func fetchMastodonProfileViaAPI(ctx context.Context, mastodonURL string, logger
 
 	if resp.StatusCode != http.StatusOK {
 		logger.Debug("Mastodon API returned non-200 status", "status", resp.StatusCode, "url", apiURL)
-		return fetchMastodonProfile(ctx, mastodonURL, logger) // Fallback
+		return fetchMastodonProfile(ctx, client, mastodonURL, logger) // Fallback
 	}
 
 	body, err := io.ReadAll(io.LimitReader(resp.Body, 1024*1024)) // 1MB limit
 	if err != nil {
 		logger.Debug("failed to read API response", "error", err)
-		return fetchMastodonProfile(ctx, mastodonURL, logger) // Fallback
+		return fetchMastodonProfile(ctx, client, mastodonURL, logger) // Fallback
 	}
 
 	var account MastodonAccount
 	if err := json.Unmarshal(body, &account); err != nil {
 		logger.Debug("failed to parse Mastodon API response", "error", err)
-		return fetchMastodonProfile(ctx, mastodonURL, logger) // Fallback
+		return fetchMastodonProfile(ctx, client, mastodonURL, logger) // Fallback
 	}
 
 	// Convert to our profile data structure
@@ -267,7 +259,7 @@ func fetchMastodonProfileViaAPI(ctx context.Context, mastodonURL string, logger
 }
 
 // fetchMastodonProfile fetches comprehensive info from a Mastodon profile via HTML scraping.
-func fetchMastodonProfile(ctx context.Context, mastodonURL string, logger *slog.Logger) *MastodonProfileData { //nolint:gocognit,revive,maintidx // Complex function with necessary error handling and retries
+func fetchMastodonProfile(ctx context.Context, client *http.Client, mastodonURL string, logger *slog.Logger) *MastodonProfileData { //nolint:gocognit,revive,maintidx // Complex function with necessary error handling and retries
 	// Mastodon profiles often have metadata in the HTML
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, mastodonURL, http.NoBody)
 	if err != nil {
@@ -277,14 +269,7 @@ func fetchMastodonProfile(ctx context.Context, mastodonURL string, logger *slog.
 
 	req.Header.Set("User-Agent", "GitHub-Timezone-Detector/1.0")
 
-	client := &http.Client{
-		Timeout: 10 * time.Second,
-		Transport: &http.Transport{
-			TLSClientConfig: &tls.Config{
-				InsecureSkipVerify: true, //nolint:gosec // Explicitly requested to ignore SSL errors for personal websites
-			},
-		},
-	}
+	client = httpClient(client)
 
 	// Use retry logic with exponential backoff and jitter
 	var resp *http.Response
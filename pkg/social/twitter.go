@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"net/http"
 	"strconv"
 	"strings"
 
@@ -11,7 +12,7 @@ import (
 )
 
 // extractTwitter extracts content from a Twitter/X profile using the scraper.
-func extractTwitter(ctx context.Context, twitterURL string, logger *slog.Logger) *Content {
+func extractTwitter(ctx context.Context, client *http.Client, twitterURL string, logger *slog.Logger) *Content {
 	// Extract username from URL
 	username := extractTwitterUsername(twitterURL)
 	if username == "" {
@@ -19,6 +20,11 @@ func extractTwitter(ctx context.Context, twitterURL string, logger *slog.Logger)
 		return extractTwitterBasic(ctx, twitterURL, logger) // Fall back to basic extraction
 	}
 
+	// The scraper can't use a caller-supplied client, so don't let it bypass one
+	if client != nil {
+		return extractTwitterBasic(ctx, twitterURL, logger)
+	}
+
 	// Create scraper instance
 	scraper := twitterscraper.New()
 	// Try to get profile
@@ -4,11 +4,13 @@ package timezone
 import (
 	"fmt"
 	"math"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/codeGROOVE-dev/guTZ/pkg/lunch"
+	"github.com/codeGROOVE-dev/guTZ/pkg/tzconvert"
 )
 
 // GlobalLunchPattern represents the best lunch pattern found globally in UTC.
@@ -33,11 +35,47 @@ func calculateEasternLunchBonus(dropRatio, lunchLocalStart float64) (bonus float
 	return 0, ""
 }
 
-// EvaluateCandidates evaluates multiple timezone offsets to find the best candidates.
-//
+// fractionalOffsets lists the non-whole-hour UTC offsets in real-world use, such as
+// Newfoundland (-3.5), Iran (+3.5), India (+5.5), Nepal (+5.75) and Adelaide (+9.5).
+var fractionalOffsets = []float64{-9.5, -3.5, -2.5, 3.5, 4.5, 5.5, 5.75, 6.5, 8.75, 9.5, 10.5, 12.75, 13.75}
+
+// candidateOffsets returns every whole-hour offset from minOffset to maxOffset plus the
+// fractional offsets in that range, in ascending order.
+func candidateOffsets(minOffset, maxOffset int) []float64 {
+	offsets := make([]float64, 0, maxOffset-minOffset+1+len(fractionalOffsets))
+	for offset := minOffset; offset <= maxOffset; offset++ {
+		offsets = append(offsets, float64(offset))
+	}
+	for _, offset := range fractionalOffsets {
+		if offset >= float64(minOffset) && offset <= float64(maxOffset) {
+			offsets = append(offsets, offset)
+		}
+	}
+	sort.Float64s(offsets)
+	return offsets
+}
+
+// localHourCounts re-keys activity by local hour for the given offset.
+// Whole-hour offsets shift the hourly counts directly. Fractional offsets sum the two
+// half-hour buckets that make up each local hour, since local hours no longer line up
+// with UTC hours (a 45-minute offset snaps to the enclosing half-hour bucket).
+func localHourCounts(hourCounts map[int]int, halfHourCounts map[float64]int, offset float64) map[int]int {
+	local := make(map[int]int, 24)
+	whole := offset == math.Trunc(offset)
+	for localHour := range 24 {
+		if whole {
+			local[localHour] = hourCounts[(localHour-int(offset)+48)%24]
+			continue
+		}
+		bucket := tzconvert.HalfHourBucket(tzconvert.LocalToUTC(float64(localHour), offset))
+		local[localHour] = halfHourCounts[bucket] + halfHourCounts[math.Mod(bucket+0.5, 24)]
+	}
+	return local
+}
+
 // findBestWorkStartForTimezone finds the most likely work start time for a given timezone
 // It looks for activity periods that, when converted to local time, look like morning work hours.
-func findBestWorkStartForTimezone(halfHourCounts map[float64]int, testOffset int, fallbackStart float64) float64 {
+func findBestWorkStartForTimezone(halfHourCounts map[float64]int, testOffset float64, fallbackStart float64) float64 {
 	// Find all continuous activity periods (at least 3 events in consecutive buckets)
 	type period struct {
 		startUTC float64
@@ -69,7 +107,7 @@ func findBestWorkStartForTimezone(halfHourCounts map[float64]int, testOffset int
 	bestStart := fallbackStart
 
 	for _, p := range periods {
-		localStart := math.Mod(p.startUTC+float64(testOffset)+24, 24)
+		localStart := tzconvert.UTCToLocal(p.startUTC, testOffset)
 		score := 0.0
 
 		// Strong preference for morning starts (7am-11am)
@@ -101,30 +139,36 @@ func findBestWorkStartForTimezone(halfHourCounts map[float64]int, testOffset int
 	return bestStart
 }
 
+// EvaluateCandidates evaluates multiple timezone offsets to find the best candidates.
+//
 //nolint:gocognit,nestif,revive,maintidx // Timezone evaluation requires comprehensive multi-factor analysis
 func EvaluateCandidates(username string, hourCounts map[int]int, halfHourCounts map[float64]int, totalActivity int, quietHours []int, midQuiet float64, activeStart float64, bestGlobalLunch GlobalLunchPattern, profileTimezone string, newestActivity time.Time) []Candidate {
 	var candidates []Candidate // Store timezone candidates for Gemini
 
 	// Evaluate multiple timezone offsets to find the best candidates
 	// CRITICAL: Always test both American AND European timezones
-	// Always test ALL possible UTC offsets from -12 to +14
+	// Always test ALL possible UTC offsets from -12 to +14, plus the half-hour and
+	// 45-minute offsets in use (India, Nepal, Newfoundland, Iran, central Australia)
 	// This ensures --force-offset works for any valid timezone
 	minOffset := -12
 	maxOffset := 14
 
-	for testOffset := minOffset; testOffset <= maxOffset; testOffset++ {
+	for _, testOffset := range candidateOffsets(minOffset, maxOffset) {
+		// Activity by local hour for this offset
+		localCounts := localHourCounts(hourCounts, halfHourCounts, testOffset)
+
 		// Calculate metrics for this offset
 		// 1. Lunch timing analysis
 		testLunchStart, testLunchEnd, testLunchConf := lunch.DetectLunchBreakNoonCentered(halfHourCounts, testOffset)
 
-		lunchLocalStart := math.Mod(testLunchStart+float64(testOffset)+24, 24)
+		lunchLocalStart := tzconvert.UTCToLocal(testLunchStart, testOffset)
 
 		// Find the best work period for this specific timezone
 		// This is crucial for users with multiple activity periods (morning work + evening coding)
 		testActiveStartUTC := findBestWorkStartForTimezone(halfHourCounts, testOffset, activeStart)
 
 		// Work start was changed from the global active start
-		testWorkStart := math.Mod(testActiveStartUTC+float64(testOffset)+24, 24)
+		testWorkStart := tzconvert.UTCToLocal(testActiveStartUTC, testOffset)
 		firstActivityLocal := testWorkStart
 
 		// Lunch is only reasonable if:
@@ -153,7 +197,7 @@ func EvaluateCandidates(username string, hourCounts map[int]int, halfHourCounts
 
 		// 2. Sleep timing analysis
 		// Calculate what local sleep time would be for this offset
-		sleepLocalMid := math.Mod(midQuiet+float64(testOffset)+24, 24)
+		sleepLocalMid := tzconvert.UTCToLocal(midQuiet, testOffset)
 		// Sleep is reasonable if mid-sleep is between 10pm and 10am (allowing for various sleep schedules)
 		// Note: midQuiet might include all quiet hours, not just nighttime sleep
 		sleepReasonable := (sleepLocalMid >= 0 && sleepLocalMid <= 10) || sleepLocalMid >= 22
@@ -169,8 +213,7 @@ func EvaluateCandidates(username string, hourCounts map[int]int, halfHourCounts
 		// For UTC-5: 7pm local = 19:00 local = 19 - (-5) = 24 = 0 UTC
 		eveningActivity := 0
 		for localHour := 19; localHour <= 23; localHour++ {
-			utcHour := (localHour - testOffset + 24) % 24
-			eveningActivity += hourCounts[utcHour]
+			eveningActivity += localCounts[localHour]
 		}
 
 		// 5. European timezone validation - Europeans should have morning activity by 10am latest
@@ -179,8 +222,7 @@ func EvaluateCandidates(username string, hourCounts map[int]int, halfHourCounts
 			// Check for activity between 8am-10am local time
 			morningActivity := 0
 			for localHour := 8; localHour <= 10; localHour++ {
-				utcHour := (localHour - testOffset + 24) % 24
-				morningActivity += hourCounts[utcHour]
+				morningActivity += localCounts[localHour]
 			}
 			// If no morning activity, this is likely not a real European timezone
 			if morningActivity == 0 {
@@ -209,7 +251,7 @@ func EvaluateCandidates(username string, hourCounts map[int]int, halfHourCounts
 				adjustments = append(adjustments, fmt.Sprintf("+12 (perfect sleep 1-4am, mid=%.1f)", sleepLocalMid))
 				// Bonus for early sleep (9-11pm start) - Pacific pattern
 				if sleepStartUTC >= 0 {
-					sleepStartLocal := float64((sleepStartUTC + testOffset + 24) % 24)
+					sleepStartLocal := tzconvert.UTCToLocal(float64(sleepStartUTC), testOffset)
 					if sleepStartLocal >= 21 && sleepStartLocal <= 23 {
 						testConfidence += 3 // Early sleep bonus (Pacific indicator)
 						adjustments = append(adjustments, fmt.Sprintf("+3 (early sleep bonus, start=%.0fpm)", sleepStartLocal-12))
@@ -220,7 +262,7 @@ func EvaluateCandidates(username string, hourCounts map[int]int, halfHourCounts
 				adjustments = append(adjustments, fmt.Sprintf("+10 (good sleep, mid=%.1f)", sleepLocalMid))
 				// Still give bonus for early sleep
 				if sleepStartUTC >= 0 {
-					sleepStartLocal := float64((sleepStartUTC + testOffset + 24) % 24)
+					sleepStartLocal := tzconvert.UTCToLocal(float64(sleepStartUTC), testOffset)
 					if sleepStartLocal >= 21 && sleepStartLocal <= 23 {
 						testConfidence += 2 // Early sleep bonus
 						adjustments = append(adjustments, fmt.Sprintf("+2 (early sleep, start=%.0fpm)", sleepStartLocal-12))
@@ -260,7 +302,7 @@ func EvaluateCandidates(username string, hourCounts map[int]int, halfHourCounts
 				lunchScore = 15 // Perfect noon timing gets maximum bonus
 				// Add global lunch context bonus if applicable
 				if bestGlobalLunch.Confidence > 0 {
-					globalLunchLocalTime := math.Mod(bestGlobalLunch.StartUTC+float64(testOffset)+24, 24)
+					globalLunchLocalTime := tzconvert.UTCToLocal(bestGlobalLunch.StartUTC, testOffset)
 					if math.Abs(globalLunchLocalTime-12.0) < 0.5 { // Within 30 min of noon
 						lunchScore += 5 // Additional bonus for matching global pattern
 						adjustments = append(adjustments, fmt.Sprintf("+%.0f (perfect noon lunch matching global pattern)", lunchScore))
@@ -314,8 +356,7 @@ func EvaluateCandidates(username string, hourCounts map[int]int, halfHourCounts
 			// Calculate last activity hour for this timezone
 			activeEndLocal := 0.0
 			for hour := 23; hour >= 0; hour-- {
-				utcHour := (hour - testOffset + 24) % 24
-				if hourCounts[utcHour] > 0 {
+				if localCounts[hour] > 0 {
 					activeEndLocal = float64(hour)
 					break
 				}
@@ -357,8 +398,7 @@ func EvaluateCandidates(username string, hourCounts map[int]int, halfHourCounts
 				// Check if this person has good afternoon productivity to offset early start
 				afternoonProductivity := 0
 				for localHour := 13; localHour <= 16; localHour++ {
-					utcHour := (localHour - testOffset + 24) % 24
-					afternoonProductivity += hourCounts[utcHour]
+					afternoonProductivity += localCounts[localHour]
 				}
 				if afternoonProductivity > 40 {
 					// If they have good afternoon productivity (40+ events 1-4pm), reduce penalty
@@ -374,8 +414,7 @@ func EvaluateCandidates(username string, hourCounts map[int]int, halfHourCounts
 				// Check afternoon productivity for additional leniency
 				afternoonProductivity := 0
 				for localHour := 13; localHour <= 16; localHour++ {
-					utcHour := (localHour - testOffset + 24) % 24
-					afternoonProductivity += hourCounts[utcHour]
+					afternoonProductivity += localCounts[localHour]
 				}
 				if afternoonProductivity > 40 {
 					// Very lenient for good afternoon productivity
@@ -423,8 +462,7 @@ func EvaluateCandidates(username string, hourCounts map[int]int, halfHourCounts
 			// Also check 5-6pm activity to detect misclassification
 			lateAfternoonActivity := 0
 			for localHour := 17; localHour <= 18; localHour++ {
-				utcHour := (localHour - testOffset + 24) % 24
-				lateAfternoonActivity += hourCounts[utcHour]
+				lateAfternoonActivity += localCounts[localHour]
 			}
 			lateAfternoonRatio := float64(lateAfternoonActivity) / float64(totalActivity)
 
@@ -457,15 +495,13 @@ func EvaluateCandidates(username string, hourCounts map[int]int, halfHourCounts
 		// Calculate early morning activity (midnight-6am local)
 		earlyMorningActivity := 0
 		for localHour := range 6 {
-			utcHour := (localHour - testOffset + 24) % 24
-			earlyMorningActivity += hourCounts[utcHour]
+			earlyMorningActivity += localCounts[localHour]
 		}
 
 		// Also calculate afternoon activity (noon-6pm) for comparison
 		noonToSixActivity := 0
 		for localHour := 12; localHour <= 17; localHour++ {
-			utcHour := (localHour - testOffset + 24) % 24
-			noonToSixActivity += hourCounts[utcHour]
+			noonToSixActivity += localCounts[localHour]
 		}
 
 		if totalActivity > 0 {
@@ -494,8 +530,7 @@ func EvaluateCandidates(username string, hourCounts map[int]int, halfHourCounts
 		// Check activity during expected work hours (9am-5pm local) for this timezone
 		workHoursActivity := 0
 		for localHour := 9; localHour <= 17; localHour++ {
-			utcHour := (localHour - testOffset + 24) % 24
-			workHoursActivity += hourCounts[utcHour]
+			workHoursActivity += localCounts[localHour]
 		}
 		if workHoursActivity > 0 && totalActivity > 0 {
 			workRatio := float64(workHoursActivity) / float64(totalActivity)
@@ -511,8 +546,8 @@ func EvaluateCandidates(username string, hourCounts map[int]int, halfHourCounts
 		// Find the hour with maximum activity and check if it occurs during ideal work hours (10am-4pm local)
 		maxActivity := 0
 		maxActivityHour := -1
-		for hour, count := range hourCounts {
-			if count > maxActivity {
+		for hour := range 24 { // Ascending so ties resolve to the earliest hour
+			if count := hourCounts[hour]; count > maxActivity {
 				maxActivity = count
 				maxActivityHour = hour
 			}
@@ -522,7 +557,7 @@ func EvaluateCandidates(username string, hourCounts map[int]int, halfHourCounts
 		peakReasonable := false
 		if maxActivityHour >= 0 {
 			// Convert peak activity UTC hour to local time for this timezone
-			peakLocalHour := (maxActivityHour + testOffset + 24) % 24
+			peakLocalHour := int(tzconvert.UTCToLocal(float64(maxActivityHour), testOffset))
 
 			// Peak is reasonable if it's between 9am-4pm (work) or 6-9pm (OSS hobbyist)
 			// Morning productivity (9am-12pm) is very common and healthy
@@ -585,8 +620,7 @@ func EvaluateCandidates(username string, hourCounts map[int]int, halfHourCounts
 		dayActivity := 0 // 8am to midnight local
 		dayHours := 0
 
-		for hour, count := range hourCounts {
-			localHour := (hour + testOffset + 24) % 24
+		for localHour, count := range localCounts {
 			if localHour >= 0 && localHour < 8 {
 				nightActivity += count
 				if count > 0 {
@@ -624,18 +658,11 @@ func EvaluateCandidates(username string, hourCounts map[int]int, halfHourCounts
 		getActivityInRange := func(startLocalHour, endLocalHour int, includeHalfHour bool) int {
 			activity := 0
 			for localHour := startLocalHour; localHour <= endLocalHour; localHour++ {
-				utcHour := (localHour - testOffset + 24) % 24
-				activity += hourCounts[utcHour]
+				activity += localCounts[localHour]
 
 				// Include half-hour bucket if requested and this is the end hour
 				if includeHalfHour && localHour == endLocalHour {
-					utcHalfHour := float64(localHour-1) + 0.5 - float64(testOffset)
-					for utcHalfHour >= 24 {
-						utcHalfHour -= 24
-					}
-					for utcHalfHour < 0 {
-						utcHalfHour += 24
-					}
+					utcHalfHour := tzconvert.HalfHourBucket(tzconvert.LocalToUTC(float64(localHour-1)+0.5, testOffset))
 					activity += halfHourCounts[utcHalfHour]
 				}
 			}
@@ -791,14 +818,7 @@ func EvaluateCandidates(username string, hourCounts map[int]int, halfHourCounts
 			// High morning productivity (9-11am) typical of East Coast
 			morningActivity := 0
 			for localHour := 9.0; localHour <= 11.0; localHour += 0.5 {
-				utcHour := localHour - float64(testOffset)
-				for utcHour < 0 {
-					utcHour += 24
-				}
-				for utcHour >= 24 {
-					utcHour -= 24
-				}
-				morningActivity += halfHourCounts[utcHour]
+				morningActivity += halfHourCounts[tzconvert.LocalToUTC(localHour, testOffset)]
 			}
 			// If strong morning activity, add small bonus
 			if morningActivity > 50 {
@@ -936,7 +956,7 @@ func EvaluateCandidates(username string, hourCounts map[int]int, halfHourCounts
 			if lunchLocalStart < 11.0 {
 				if testOffset >= 10 && testOffset <= 11 {
 					testConfidence -= 2 // Reduced penalty for Pacific timezones
-					adjustments = append(adjustments, fmt.Sprintf("-2 (early lunch at %.1f for UTC+%d)", lunchLocalStart, testOffset))
+					adjustments = append(adjustments, fmt.Sprintf("-2 (early lunch at %.1f for %s)", lunchLocalStart, tzconvert.FormatOffset(testOffset)))
 				} else {
 					testConfidence -= 5 // Full penalty for other timezones
 					adjustments = append(adjustments, fmt.Sprintf("-5 (lunch before 11am at %.1f)", lunchLocalStart))
@@ -1002,7 +1022,7 @@ func EvaluateCandidates(username string, hourCounts map[int]int, halfHourCounts
 			}
 
 			// Check for European commute pattern (quiet hour at 17-18 local)
-			commuteHourUTC := 17 - testOffset // 17:00 local in UTC
+			commuteHourUTC := 17 - int(testOffset) // 17:00 local in UTC
 			if commuteHourUTC >= 0 && commuteHourUTC < 24 {
 				nextHourUTC := (commuteHourUTC + 1) % 24
 				if hourCounts[commuteHourUTC] < 5 && hourCounts[nextHourUTC] > 10 {
@@ -1012,7 +1032,7 @@ func EvaluateCandidates(username string, hourCounts map[int]int, halfHourCounts
 			}
 
 			// Check for UK tea time pattern (15:00-16:00 local)
-			teaTimeUTC := 15 - testOffset
+			teaTimeUTC := 15 - int(testOffset)
 			if teaTimeUTC >= 0 && teaTimeUTC < 24 {
 				beforeTeaUTC := (teaTimeUTC - 1 + 24) % 24
 				afterTeaUTC := (teaTimeUTC + 1) % 24
@@ -1025,7 +1045,7 @@ func EvaluateCandidates(username string, hourCounts map[int]int, halfHourCounts
 			testConfidence += 2.0 // Small boost for Central/Eastern European developers
 			adjustments = append(adjustments, "+2 (Central/Eastern Europe population boost)")
 			// Check for European commute pattern
-			commuteHourUTC := 17 - testOffset // 17:00 local in UTC
+			commuteHourUTC := 17 - int(testOffset) // 17:00 local in UTC
 			if commuteHourUTC >= 0 && commuteHourUTC < 24 {
 				nextHourUTC := (commuteHourUTC + 1) % 24
 				if hourCounts[commuteHourUTC] < 5 && hourCounts[nextHourUTC] > 10 {
@@ -1090,8 +1110,8 @@ func EvaluateCandidates(username string, hourCounts map[int]int, halfHourCounts
 		// We'll let Gemini and other factors determine the final choice
 		if true { // Always add candidate
 			candidate := Candidate{
-				Timezone:            fmt.Sprintf("UTC%+d", testOffset),
-				Offset:              float64(testOffset),
+				Timezone:            tzconvert.FormatOffset(testOffset),
+				Offset:              testOffset,
 				Confidence:          testConfidence,
 				EveningActivity:     eveningActivity,
 				LunchReasonable:     lunchReasonable,
@@ -1124,10 +1144,9 @@ func EvaluateCandidates(username string, hourCounts map[int]int, halfHourCounts
 	return candidates
 }
 
-// parseUTCOffsetString converts a UTC offset string to its integer offset.
+// parseUTCOffsetString converts a UTC offset string to its offset in hours.
 // Handles formats like "UTC", "UTC-5", "UTC+8", "UTC+5.5", "UTC-2.5", etc.
-// Returns the offset rounded to the nearest integer hour for comparison.
-func parseUTCOffsetString(tz string) int {
+func parseUTCOffsetString(tz string) float64 {
 	// Handle plain UTC
 	if tz == "UTC" {
 		return 0
@@ -1137,20 +1156,20 @@ func parseUTCOffsetString(tz string) int {
 	if strings.HasPrefix(tz, "UTC+") {
 		offsetStr := strings.TrimPrefix(tz, "UTC+")
 		if offset, err := strconv.ParseFloat(offsetStr, 64); err == nil {
-			return int(math.Round(offset))
+			return offset
 		}
 	}
 	if strings.HasPrefix(tz, "UTC-") {
 		offsetStr := strings.TrimPrefix(tz, "UTC-")
 		if offset, err := strconv.ParseFloat(offsetStr, 64); err == nil {
-			return -int(math.Round(offset))
+			return -offset
 		}
 	}
 	if strings.HasPrefix(tz, "UTC") && len(tz) > 3 {
 		// Handle formats like UTC-5.5 or UTC5 (without explicit + or -)
 		offsetStr := strings.TrimPrefix(tz, "UTC")
 		if offset, err := strconv.ParseFloat(offsetStr, 64); err == nil {
-			return int(math.Round(offset))
+			return offset
 		}
 	}
 
@@ -281,3 +281,35 @@ func containsSubstring(s, substr string) bool {
 	}
 	return false
 }
+
+// TestEvaluateCandidatesHalfHourOffset checks that a UTC+5:30 workday is scored as UTC+5.5
+// rather than the neighboring whole hours.
+func TestEvaluateCandidatesHalfHourOffset(t *testing.T) {
+	events := workdayEvents(t, "Asia/Kolkata")
+	candidates := evaluateSeason("test", events, halfHourHistogram(events))
+	if len(candidates) == 0 {
+		t.Fatal("no candidates")
+	}
+	if top := candidates[0]; top.Offset != 5.5 || top.Timezone != "UTC+5.5" {
+		t.Errorf("top candidate = %s (%v), want UTC+5.5", top.Timezone, top.Offset)
+	}
+}
+
+func TestCandidateOffsetsIncludesFractional(t *testing.T) {
+	offsets := candidateOffsets(-12, 14)
+	if len(offsets) != 27+len(fractionalOffsets) {
+		t.Fatalf("got %d offsets, want %d", len(offsets), 27+len(fractionalOffsets))
+	}
+	seen := make(map[float64]bool, len(offsets))
+	for i, o := range offsets {
+		if i > 0 && o <= offsets[i-1] {
+			t.Errorf("offsets not ascending at %d: %v after %v", i, o, offsets[i-1])
+		}
+		seen[o] = true
+	}
+	for _, want := range []float64{-3.5, 3.5, 5.5, 5.75, 9.5} {
+		if !seen[want] {
+			t.Errorf("offset %v not evaluated", want)
+		}
+	}
+}
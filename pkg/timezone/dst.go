@@ -0,0 +1,369 @@
+package timezone
+
+import (
+	"math"
+	"time"
+
+	"github.com/codeGROOVE-dev/guTZ/pkg/lunch"
+	"github.com/codeGROOVE-dev/guTZ/pkg/sleep"
+)
+
+// DSTRegime names a daylight saving schedule.
+type DSTRegime string
+
+// Daylight saving regimes distinguishable from activity alone.
+const (
+	DSTUnknown  DSTRegime = "unknown"  // Not enough seasonal data, or the seasons disagree in a way no regime explains
+	DSTNone     DSTRegime = "none"     // Same offset all year (e.g. America/Phoenix, Africa/Abidjan)
+	DSTUS       DSTRegime = "us"       // North American rules: second Sunday of March to first Sunday of November
+	DSTEU       DSTRegime = "eu"       // European rules: last Sunday of March to last Sunday of October
+	DSTSouthern DSTRegime = "southern" // Southern hemisphere: clocks go forward around October and back around April
+)
+
+// minSeasonEvents is the fewest events a season needs before its pattern is trusted.
+const minSeasonEvents = 30
+
+// SeasonalAnalysis compares activity in the northern winter and summer to infer daylight saving behavior.
+type SeasonalAnalysis struct {
+	Regime         DSTRegime   `json:"regime"`
+	SuggestedZones []string    `json:"suggested_zones,omitempty"`
+	Winter         []Candidate `json:"-"`               // Candidates scored on northern winter activity only
+	Summer         []Candidate `json:"-"`               // Candidates scored on northern summer activity only
+	WinterOffset   float64     `json:"winter_offset"`   // Top candidate offset for November to early March
+	SummerOffset   float64     `json:"summer_offset"`   // Top candidate offset for April to September
+	StandardOffset float64     `json:"standard_offset"` // Offset outside daylight saving time
+	Shift          float64     `json:"shift"`           // Hours the daily routine moves in UTC from winter to summer
+	Confidence     float64     `json:"confidence"`
+	WinterEvents   int         `json:"winter_events"`
+	SummerEvents   int         `json:"summer_events"`
+	ObservesDST    bool        `json:"observes_dst"`
+}
+
+// season reports which side of the year t falls in. The windows avoid every regime's transition weeks
+// (US, EU, Australia, New Zealand, Chile), so each zone keeps one offset throughout a window.
+func season(t time.Time) (winter, summer bool) {
+	t = t.UTC()
+	m, d := t.Month(), t.Day()
+	switch {
+	case m == time.November && d >= 8, m == time.December, m == time.January, m == time.February, m == time.March && d <= 7:
+		return true, false
+	case m == time.April && d >= 8, m >= time.May && m <= time.August, m == time.September && d <= 23:
+		return false, true
+	default:
+		return false, false
+	}
+}
+
+// EvaluateSeasons splits timestamps into northern winter and summer, scores timezone candidates for each,
+// and infers whether and how the user observes daylight saving time.
+func EvaluateSeasons(username string, timestamps []time.Time) *SeasonalAnalysis {
+	var winter, summer []time.Time
+	for _, t := range timestamps {
+		switch w, s := season(t); {
+		case w:
+			winter = append(winter, t)
+		case s:
+			summer = append(summer, t)
+		default:
+		}
+	}
+
+	analysis := &SeasonalAnalysis{
+		Regime:       DSTUnknown,
+		WinterEvents: len(winter),
+		SummerEvents: len(summer),
+	}
+	if len(winter) < minSeasonEvents || len(summer) < minSeasonEvents {
+		return analysis
+	}
+
+	winterCounts, summerCounts := halfHourHistogram(winter), halfHourHistogram(summer)
+	analysis.Winter = evaluateSeason(username, winter, winterCounts)
+	analysis.Summer = evaluateSeason(username, summer, summerCounts)
+	if len(analysis.Winter) == 0 || len(analysis.Summer) == 0 {
+		return analysis
+	}
+	analysis.WinterOffset = analysis.Winter[0].Offset
+	analysis.SummerOffset = analysis.Summer[0].Offset
+
+	// Absolute offsets scored on a few months of data wobble by an hour; the shift between
+	// the two seasons' routines is measured directly, which is far steadier
+	analysis.Shift = seasonalShift(winterCounts, summerCounts)
+	analysis.Confidence = math.Min(1, float64(min(len(winter), len(summer)))/150)
+
+	if analysis.Shift != 0 && math.Abs(analysis.Shift) != 1 {
+		return analysis
+	}
+
+	// Each season's evidence alone can favor a neighboring offset; the winter offset that
+	// best explains both seasons once the shift is applied is the one to trust
+	winterOffset := jointOffset(winterCounts, summerCounts, analysis.Winter, analysis.Summer, analysis.Shift)
+	switch analysis.Shift {
+	case 0:
+		analysis.Regime = DSTNone
+		analysis.StandardOffset = winterOffset
+	case 1:
+		analysis.ObservesDST = true
+		analysis.StandardOffset = winterOffset
+		analysis.Regime = northernRegime(analysis.StandardOffset)
+	default:
+		// Southern hemisphere clocks are forward during the northern winter
+		analysis.ObservesDST = true
+		analysis.StandardOffset = winterOffset - 1
+		analysis.Regime = DSTSouthern
+	}
+	analysis.SuggestedZones = ZonesFor(analysis.Regime, analysis.StandardOffset)
+	return analysis
+}
+
+// jointOffset returns the winter offset that best explains both seasons, given that the summer offset is
+// the winter offset plus shift. Offsets are ranked by how well the two seasons, each converted to local time,
+// line up with a typical working day. The candidate scorer's population priors can pull a season's top
+// candidate an hour towards a busier neighbor, so its combined confidence only breaks near-ties.
+func jointOffset(winterCounts, summerCounts map[float64]int, winter, summer []Candidate, shift float64) float64 {
+	summerScore := make(map[float64]float64, len(summer))
+	for _, c := range summer {
+		summerScore[c.Offset] = c.Confidence
+	}
+	w, s := normalize(winterCounts), normalize(summerCounts)
+
+	best, bestFit, bestScore := winter[0].Offset, math.Inf(-1), math.Inf(-1)
+	for _, c := range winter {
+		score, ok := summerScore[c.Offset+shift]
+		if !ok {
+			continue
+		}
+		score += c.Confidence
+		fit := workdayFit(w, c.Offset) + workdayFit(s, c.Offset+shift)
+		if fit > bestFit+workdayFitTolerance || (fit > bestFit-workdayFitTolerance && score > bestScore) {
+			best, bestFit, bestScore = c.Offset, fit, score
+		}
+	}
+	return best
+}
+
+// workdayFitTolerance is the fit difference below which two offsets are considered equally plausible.
+const workdayFitTolerance = 0.01
+
+// workdayTemplate is the relative activity of a typical developer in each local half hour: nothing overnight,
+// a ramp-up from 8:00, full activity 9:00-17:00 with a noon lunch dip, and a quieter evening.
+var workdayTemplate = func() [48]float64 {
+	var t [48]float64
+	for i := range t {
+		h := float64(i) / 2
+		switch {
+		case h >= 12 && h < 13:
+			t[i] = 0.4
+		case h >= 9 && h < 17:
+			t[i] = 1
+		case h >= 8 && h < 9, h >= 17 && h < 18:
+			t[i] = 0.5
+		case h >= 18 && h < 23:
+			t[i] = 0.3
+		default:
+		}
+	}
+	return t
+}()
+
+// workdayFit scores how well a normalized UTC histogram matches workdayTemplate at the given offset.
+// Offsets that fall between half-hour buckets (e.g. UTC+5:45) interpolate the template, so they never
+// tie with their whole-hour neighbor.
+func workdayFit(utc [48]float64, offset float64) float64 {
+	shift := math.Floor(offset * 2)
+	frac := offset*2 - shift
+	var dot, sumSq float64
+	for i, v := range utc {
+		lo := ((i+int(shift))%48 + 48) % 48
+		t := (1-frac)*workdayTemplate[lo] + frac*workdayTemplate[(lo+1)%48]
+		dot += v * t
+		sumSq += t * t
+	}
+	if sumSq == 0 {
+		return 0
+	}
+	return dot / math.Sqrt(sumSq)
+}
+
+// northernRegime picks US or EU rules from the standard offset: the two regimes don't share any offsets in practice.
+func northernRegime(standardOffset float64) DSTRegime {
+	switch {
+	case standardOffset >= -10 && standardOffset <= -3:
+		return DSTUS
+	case standardOffset >= -1 && standardOffset <= 2:
+		return DSTEU
+	default:
+		return DSTUnknown
+	}
+}
+
+// Representative zones by regime and standard offset, most populous first.
+var regimeZones = map[DSTRegime]map[float64][]string{
+	DSTNone: {
+		-10:  {"Pacific/Honolulu"},
+		-7:   {"America/Phoenix"},
+		-6:   {"America/Mexico_City", "America/Regina", "America/Costa_Rica"},
+		-5:   {"America/Bogota", "America/Lima", "America/Panama"},
+		-4:   {"America/Caracas", "America/La_Paz", "America/Puerto_Rico"},
+		-3:   {"America/Sao_Paulo", "America/Argentina/Buenos_Aires", "America/Montevideo"},
+		0:    {"Africa/Abidjan", "Atlantic/Reykjavik", "Africa/Accra"},
+		1:    {"Africa/Lagos", "Africa/Algiers"},
+		2:    {"Africa/Johannesburg", "Africa/Maputo", "Africa/Harare"},
+		3:    {"Europe/Moscow", "Europe/Istanbul", "Africa/Nairobi", "Asia/Riyadh"},
+		3.5:  {"Asia/Tehran"},
+		4:    {"Asia/Dubai", "Asia/Baku"},
+		4.5:  {"Asia/Kabul"},
+		5:    {"Asia/Karachi", "Asia/Tashkent"},
+		5.5:  {"Asia/Kolkata", "Asia/Colombo"},
+		5.75: {"Asia/Kathmandu"},
+		6:    {"Asia/Dhaka", "Asia/Bishkek"},
+		6.5:  {"Asia/Yangon"},
+		7:    {"Asia/Bangkok", "Asia/Jakarta", "Asia/Ho_Chi_Minh"},
+		8:    {"Asia/Shanghai", "Asia/Singapore", "Asia/Taipei", "Australia/Perth"},
+		9:    {"Asia/Tokyo", "Asia/Seoul"},
+		9.5:  {"Australia/Darwin"},
+		10:   {"Australia/Brisbane", "Pacific/Port_Moresby"},
+	},
+	DSTUS: {
+		-10:  {"America/Adak"},
+		-9:   {"America/Anchorage"},
+		-8:   {"America/Los_Angeles", "America/Vancouver", "America/Tijuana"},
+		-7:   {"America/Denver", "America/Edmonton", "America/Boise"},
+		-6:   {"America/Chicago", "America/Winnipeg"},
+		-5:   {"America/New_York", "America/Toronto"},
+		-4:   {"America/Halifax"},
+		-3.5: {"America/St_Johns"},
+	},
+	DSTEU: {
+		-1: {"Atlantic/Azores"},
+		0:  {"Europe/London", "Europe/Dublin", "Europe/Lisbon"},
+		1:  {"Europe/Berlin", "Europe/Paris", "Europe/Madrid", "Europe/Rome", "Europe/Amsterdam"},
+		2:  {"Europe/Kyiv", "Europe/Athens", "Europe/Helsinki", "Europe/Bucharest"},
+	},
+	DSTSouthern: {
+		-4:  {"America/Santiago"},
+		9.5: {"Australia/Adelaide"},
+		10:  {"Australia/Sydney", "Australia/Melbourne", "Australia/Hobart"},
+		12:  {"Pacific/Auckland"},
+	},
+}
+
+// ZonesFor lists representative IANA zones following regime at standardOffset, most populous first.
+func ZonesFor(regime DSTRegime, standardOffset float64) []string {
+	return regimeZones[regime][standardOffset]
+}
+
+// halfHourHistogram counts timestamps in 30-minute UTC buckets (0.0, 0.5, ... 23.5).
+func halfHourHistogram(timestamps []time.Time) map[float64]int {
+	counts := make(map[float64]int)
+	for _, t := range timestamps {
+		t = t.UTC()
+		bucket := float64(t.Hour())
+		if t.Minute() >= 30 {
+			bucket += 0.5
+		}
+		counts[bucket]++
+	}
+	return counts
+}
+
+// evaluateSeason scores every offset against one season's activity, deriving the sleep, work-start,
+// and lunch inputs that EvaluateCandidates expects from that season alone.
+func evaluateSeason(username string, timestamps []time.Time, halfHourCounts map[float64]int) []Candidate {
+	hourCounts := make(map[int]int)
+	var newest time.Time
+	for _, t := range timestamps {
+		hourCounts[t.UTC().Hour()]++
+		if t.After(newest) {
+			newest = t
+		}
+	}
+
+	quietBuckets := sleep.DetectSleepPeriodsWithHalfHours(halfHourCounts)
+	quietSet := make(map[int]bool)
+	var quietHours []int
+	for _, b := range quietBuckets {
+		if h := int(b); !quietSet[h] {
+			quietSet[h] = true
+			quietHours = append(quietHours, h)
+		}
+	}
+	midQuiet := circularMean(quietBuckets)
+
+	// Work starts at the first busy bucket after the quiet period ends, which may wrap past midnight
+	activeStart := math.Mod(midQuiet+6, 24)
+	quiet := make(map[float64]bool, len(quietBuckets))
+	for _, b := range quietBuckets {
+		quiet[b] = true
+	}
+	for _, b := range quietBuckets {
+		if next := math.Mod(b+0.5, 24); !quiet[next] {
+			activeStart = next
+			break
+		}
+	}
+
+	globalLunch := lunch.FindBestGlobalLunchPattern(halfHourCounts)
+	return EvaluateCandidates(username, hourCounts, halfHourCounts, len(timestamps), quietHours, midQuiet, activeStart,
+		GlobalLunchPattern{
+			StartUTC:    globalLunch.StartUTC,
+			EndUTC:      globalLunch.EndUTC,
+			Confidence:  globalLunch.Confidence,
+			DropPercent: globalLunch.DropPercent,
+		}, "", newest)
+}
+
+// circularMean averages hours of the day, treating 23.5 and 0.5 as neighbors. It returns 2.5 (a typical
+// European sleep midpoint in UTC, matching the aggregate analysis default) when hours is empty.
+func circularMean(hours []float64) float64 {
+	if len(hours) == 0 {
+		return 2.5
+	}
+	var x, y float64
+	for _, h := range hours {
+		angle := h / 24 * 2 * math.Pi
+		x += math.Cos(angle)
+		y += math.Sin(angle)
+	}
+	mean := math.Atan2(y, x) / (2 * math.Pi) * 24
+	return math.Mod(mean+24, 24)
+}
+
+// seasonalShift finds how many hours the summer routine sits ahead of the winter routine in local terms,
+// i.e. the summer offset minus the winter offset, by aligning the two normalized histograms.
+// A northern DST observer starts work an hour earlier in UTC during summer, giving +1.
+func seasonalShift(winter, summer map[float64]int) float64 {
+	w, s := normalize(winter), normalize(summer)
+	bestShift, bestScore := 0, -1.0
+	// Prefer no shift on ties, then smaller shifts, by trying them in order of size
+	for _, k := range []int{0, 2, -2, 1, -1, 3, -3, 4, -4} {
+		score := 0.0
+		for i := range 48 {
+			score += s[i] * w[(i+k+48)%48]
+		}
+		if score > bestScore+1e-9 {
+			bestShift, bestScore = k, score
+		}
+	}
+	return float64(bestShift) / 2
+}
+
+// normalize turns half-hour counts into a 48-bucket distribution with unit length.
+func normalize(counts map[float64]int) [48]float64 {
+	var v [48]float64
+	var sumSq float64
+	for bucket, c := range counts {
+		i := int(bucket * 2)
+		if i >= 0 && i < 48 {
+			v[i] = float64(c)
+			sumSq += float64(c * c)
+		}
+	}
+	if sumSq > 0 {
+		norm := math.Sqrt(sumSq)
+		for i := range v {
+			v[i] /= norm
+		}
+	}
+	return v
+}
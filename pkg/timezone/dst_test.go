@@ -0,0 +1,137 @@
+package timezone
+
+import (
+	"testing"
+	"time"
+)
+
+// workdayEvents simulates a year of activity in zone: busy weekday working hours from 9:00 to 17:00
+// with a quieter noon lunch, a little activity around the edges of the day and in the evening,
+// and nothing between 23:00 and 8:00.
+func workdayEvents(t *testing.T, zone string) []time.Time {
+	t.Helper()
+	loc, err := time.LoadLocation(zone)
+	if err != nil {
+		t.Fatalf("LoadLocation(%q): %v", zone, err)
+	}
+	perHalfHour := func(minutes int) int {
+		switch {
+		case minutes >= 12*60 && minutes < 13*60:
+			return 1
+		case minutes >= 9*60 && minutes < 17*60:
+			return 4
+		case minutes >= 8*60 && minutes < 18*60, minutes >= 20*60 && minutes < 23*60:
+			return 1
+		default:
+			return 0
+		}
+	}
+
+	var events []time.Time
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, loc)
+	for day := range 366 {
+		date := start.AddDate(0, 0, day)
+		if date.Weekday() == time.Saturday || date.Weekday() == time.Sunday {
+			continue
+		}
+		for minutes := 0; minutes < 24*60; minutes += 30 {
+			for i := range perHalfHour(minutes) {
+				m := minutes + i*7
+				events = append(events, time.Date(date.Year(), date.Month(), date.Day(), m/60, m%60, 0, 0, loc))
+			}
+		}
+	}
+	return events
+}
+
+func TestEvaluateSeasons(t *testing.T) {
+	tests := []struct {
+		zone     string
+		regime   DSTRegime
+		standard float64
+	}{
+		{"America/Los_Angeles", DSTUS, -8},
+		{"America/Denver", DSTUS, -7},
+		{"America/Phoenix", DSTNone, -7},
+		{"America/Chicago", DSTUS, -6},
+		{"America/New_York", DSTUS, -5},
+		{"America/Sao_Paulo", DSTNone, -3},
+		{"Europe/London", DSTEU, 0},
+		{"Africa/Abidjan", DSTNone, 0},
+		{"Europe/Berlin", DSTEU, 1},
+		{"Australia/Sydney", DSTSouthern, 10},
+		{"Asia/Tokyo", DSTNone, 9},
+		{"Asia/Kolkata", DSTNone, 5.5},
+		{"Asia/Kathmandu", DSTNone, 5.75},
+		{"Asia/Tehran", DSTNone, 3.5},
+		{"Australia/Darwin", DSTNone, 9.5},
+		{"America/St_Johns", DSTUS, -3.5},
+		{"Australia/Adelaide", DSTSouthern, 9.5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.zone, func(t *testing.T) {
+			got := EvaluateSeasons("test", workdayEvents(t, tt.zone))
+			if got.Regime != tt.regime {
+				t.Errorf("Regime = %q, want %q (shift %v, winter %v, summer %v)",
+					got.Regime, tt.regime, got.Shift, got.WinterOffset, got.SummerOffset)
+			}
+			if got.ObservesDST != (tt.regime != DSTNone) {
+				t.Errorf("ObservesDST = %v for regime %q", got.ObservesDST, tt.regime)
+			}
+			if got.StandardOffset != tt.standard {
+				t.Errorf("StandardOffset = %v, want %v (winter %v, summer %v)",
+					got.StandardOffset, tt.standard, got.WinterOffset, got.SummerOffset)
+			}
+			if len(got.SuggestedZones) == 0 || got.SuggestedZones[0] != tt.zone {
+				t.Errorf("SuggestedZones = %v, want %s first", got.SuggestedZones, tt.zone)
+			}
+		})
+	}
+}
+
+func TestEvaluateSeasonsNeedsBothSeasons(t *testing.T) {
+	var summerOnly []time.Time
+	for _, e := range workdayEvents(t, "America/Denver") {
+		if e.Month() >= time.May && e.Month() <= time.August {
+			summerOnly = append(summerOnly, e)
+		}
+	}
+	got := EvaluateSeasons("test", summerOnly)
+	if got.Regime != DSTUnknown || got.WinterEvents != 0 || got.SummerEvents == 0 {
+		t.Errorf("got regime %q with %d winter / %d summer events, want unknown with summer events only",
+			got.Regime, got.WinterEvents, got.SummerEvents)
+	}
+}
+
+// TestRegimeZones checks the zone table against the tz database, so a rule change upstream is noticed.
+func TestRegimeZones(t *testing.T) {
+	jan := time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC)
+	jul := time.Date(2025, 7, 15, 12, 0, 0, 0, time.UTC)
+	for regime, byOffset := range regimeZones {
+		for standard, zones := range byOffset {
+			for _, zone := range zones {
+				loc, err := time.LoadLocation(zone)
+				if err != nil {
+					t.Errorf("%s: %v", zone, err)
+					continue
+				}
+				_, janOff := jan.In(loc).Zone()
+				_, julOff := jul.In(loc).Zone()
+				winter, summer := float64(janOff)/3600, float64(julOff)/3600
+
+				wantWinter, wantSummer := standard, standard
+				switch regime {
+				case DSTUS, DSTEU:
+					wantSummer++
+				case DSTSouthern:
+					wantWinter++
+				default:
+				}
+				if winter != wantWinter || summer != wantSummer {
+					t.Errorf("%s (%s, standard %v): January %v / July %v, want %v / %v",
+						zone, regime, standard, winter, summer, wantWinter, wantSummer)
+				}
+			}
+		}
+	}
+}
@@ -4,6 +4,7 @@
 package tzconvert
 
 import (
+	"fmt"
 	"math"
 	"time"
 )
@@ -15,14 +16,14 @@ import (
 // Parameters:
 //   - utcHour: Hour in UTC (0-24, can include fractional hours like 15.5 for 15:30)
 //   - utcOffset: The timezone offset from UTC (negative for west, positive for east)
-//     Examples: -4 for EDT, -7 for PDT, 0 for GMT, 8 for CST (China)
+//     Examples: -4 for EDT, -7 for PDT, 0 for GMT, 8 for CST (China), 5.5 for IST, 5.75 for NPT
 //
 // Returns: Local hour (0-24), properly wrapped for day boundaries.
-func UTCToLocal(utcHour float64, utcOffset int) float64 {
+func UTCToLocal(utcHour float64, utcOffset float64) float64 {
 	// Add the offset to convert from UTC to local
 	// For UTC-4: UTC 15:00 + (-4) = 11:00 local
 	// For UTC+8: UTC 02:00 + 8 = 10:00 local
-	localHour := utcHour + float64(utcOffset)
+	localHour := utcHour + utcOffset
 
 	// Wrap around 24-hour clock
 	return math.Mod(localHour+24, 24)
@@ -37,11 +38,11 @@ func UTCToLocal(utcHour float64, utcOffset int) float64 {
 //   - utcOffset: The timezone offset from UTC (negative for west, positive for east)
 //
 // Returns: UTC hour (0-24), properly wrapped for day boundaries.
-func LocalToUTC(localHour float64, utcOffset int) float64 {
+func LocalToUTC(localHour float64, utcOffset float64) float64 {
 	// Subtract the offset to convert from local to UTC
 	// For UTC-4: Local 11:00 - (-4) = 15:00 UTC
 	// For UTC+8: Local 10:00 - 8 = 02:00 UTC
-	utcHour := localHour - float64(utcOffset)
+	utcHour := localHour - utcOffset
 
 	// Wrap around 24-hour clock
 	return math.Mod(utcHour+24, 24)
@@ -55,7 +56,7 @@ func LocalToUTC(localHour float64, utcOffset int) float64 {
 //   - utcOffset: The timezone offset from UTC
 //
 // Returns: Start and end hours in local time.
-func ConvertRangeUTCToLocal(startUTC, endUTC float64, utcOffset int) (localStart, localEnd float64) {
+func ConvertRangeUTCToLocal(startUTC, endUTC float64, utcOffset float64) (localStart, localEnd float64) {
 	return UTCToLocal(startUTC, utcOffset), UTCToLocal(endUTC, utcOffset)
 }
 
@@ -67,20 +68,43 @@ func ConvertRangeUTCToLocal(startUTC, endUTC float64, utcOffset int) (localStart
 //   - utcOffset: The timezone offset from UTC
 //
 // Returns: Start and end hours in UTC.
-func ConvertRangeLocalToUTC(startLocal, endLocal float64, utcOffset int) (utcStart, utcEnd float64) {
+func ConvertRangeLocalToUTC(startLocal, endLocal float64, utcOffset float64) (utcStart, utcEnd float64) {
 	return LocalToUTC(startLocal, utcOffset), LocalToUTC(endLocal, utcOffset)
 }
 
+// HalfHourBucket snaps an hour to the start of the 30-minute bucket containing it.
+// Activity is counted in half-hour UTC buckets (0, 0.5, ... 23.5), so hours derived
+// from 45-minute offsets such as UTC+5:45 must be snapped before lookup.
+// Example: HalfHourBucket(4.25) returns 4.0, HalfHourBucket(-0.25) returns 23.5.
+func HalfHourBucket(hour float64) float64 {
+	bucket := math.Floor(hour*2) / 2
+	return math.Mod(math.Mod(bucket, 24)+24, 24)
+}
+
+// FormatOffset renders a UTC offset in the "UTC±N" form used for activity-only timezones.
+// Whole hours print as integers and fractional offsets keep their decimal part.
+// Examples: FormatOffset(-4) returns "UTC-4", FormatOffset(0) returns "UTC+0",
+// FormatOffset(5.5) returns "UTC+5.5", FormatOffset(5.75) returns "UTC+5.75".
+func FormatOffset(utcOffset float64) string {
+	if utcOffset == math.Trunc(utcOffset) {
+		return fmt.Sprintf("UTC%+d", int(utcOffset))
+	}
+	return fmt.Sprintf("UTC%+g", utcOffset)
+}
+
 // ParseTimezoneOffset extracts the numeric offset from a timezone string.
 // For IANA timezones, it uses Go's time package to get the current offset.
 // Examples:
 //   - "UTC-4" returns -4
 //   - "UTC+8" returns 8
+//   - "UTC+5:30" and "UTC+5.5" return 5.5
+//   - "UTC+5:45" returns 5.75
 //   - "UTC" returns 0
 //   - "America/New_York" returns -4 or -5 depending on DST
+//   - "Asia/Kolkata" returns 5.5
 //   - "Pacific/Auckland" returns 12 or 13 depending on DST
 //   - Invalid input returns 0
-func ParseTimezoneOffset(timezone string) int {
+func ParseTimezoneOffset(timezone string) float64 {
 	// First try UTC format
 	if len(timezone) >= 3 && timezone[:3] == "UTC" {
 		if len(timezone) == 3 {
@@ -94,7 +118,7 @@ func ParseTimezoneOffset(timezone string) int {
 		}
 
 		// Handle the sign
-		sign := 1
+		sign := 1.0
 		switch offsetStr[0] {
 		case '-':
 			sign = -1
@@ -105,13 +129,23 @@ func ParseTimezoneOffset(timezone string) int {
 			// No sign means positive offset
 		}
 
-		// Parse the number
-		offset := 0
-		for _, ch := range offsetStr {
-			if ch < '0' || ch > '9' {
-				break
+		// Parse whole hours, then an optional ":MM" or ".5"/".75" fraction
+		hours, rest := leadingDigits(offsetStr)
+		offset := float64(hours)
+		if len(rest) > 1 {
+			switch rest[0] {
+			case ':':
+				minutes, _ := leadingDigits(rest[1:])
+				offset += float64(minutes) / 60
+			case '.':
+				digits := rest[1:]
+				frac, tail := leadingDigits(digits)
+				if n := len(digits) - len(tail); n > 0 {
+					offset += float64(frac) / math.Pow(10, float64(n))
+				}
+			default:
+				// Trailing text after the hours is ignored
 			}
-			offset = offset*10 + int(ch-'0')
 		}
 
 		return sign * offset
@@ -125,5 +159,15 @@ func ParseTimezoneOffset(timezone string) int {
 
 	// Get current offset
 	_, offset := time.Now().In(loc).Zone()
-	return offset / 3600 // Convert seconds to hours
+	return float64(offset) / 3600 // Convert seconds to hours
+}
+
+// leadingDigits parses the decimal digits at the start of s and returns the value and the remainder.
+func leadingDigits(s string) (value int, rest string) {
+	i := 0
+	for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+		value = value*10 + int(s[i]-'0')
+		i++
+	}
+	return value, s[i:]
 }
@@ -9,7 +9,7 @@ func TestUTCToLocal(t *testing.T) {
 	tests := []struct {
 		name      string
 		utcHour   float64
-		utcOffset int
+		utcOffset float64
 		want      float64
 	}{
 		// Eastern Time (UTC-4)
@@ -28,6 +28,12 @@ func TestUTCToLocal(t *testing.T) {
 
 		// GMT (UTC+0)
 		{"GMT no change", 12.0, 0, 12.0},
+
+		// Fractional offsets
+		{"IST 3:30 UTC to 9am local", 3.5, 5.5, 9.0},
+		{"NPT 3:15 UTC to 9am local", 3.25, 5.75, 9.0},
+		{"NST 12:30 UTC to 9am local", 12.5, -3.5, 9.0},
+		{"ACST wrap past midnight", 15.0, 9.5, 0.5},
 	}
 
 	for _, tt := range tests {
@@ -45,7 +51,7 @@ func TestLocalToUTC(t *testing.T) {
 	tests := []struct {
 		name      string
 		localHour float64
-		utcOffset int
+		utcOffset float64
 		want      float64
 	}{
 		// Eastern Time (UTC-4)
@@ -64,6 +70,11 @@ func TestLocalToUTC(t *testing.T) {
 
 		// GMT (UTC+0)
 		{"GMT no change", 12.0, 0, 12.0},
+
+		// Fractional offsets
+		{"IST 9am local to 3:30 UTC", 9.0, 5.5, 3.5},
+		{"NPT 9am local to 3:15 UTC", 9.0, 5.75, 3.25},
+		{"NST 9am local to 12:30 UTC", 9.0, -3.5, 12.5},
 	}
 
 	for _, tt := range tests {
@@ -80,7 +91,7 @@ func TestLocalToUTC(t *testing.T) {
 func TestRoundTrip(t *testing.T) {
 	// Test that converting UTC->Local->UTC gives back the original
 	hours := []float64{0, 6, 12, 18, 23.5}
-	offsets := []int{-11, -7, -4, 0, 3, 8, 12}
+	offsets := []float64{-11, -7, -4, -3.5, 0, 3, 5.5, 5.75, 8, 9.5, 12}
 
 	for _, hour := range hours {
 		for _, offset := range offsets {
@@ -97,7 +108,7 @@ func TestRoundTrip(t *testing.T) {
 func TestParseTimezoneOffset(t *testing.T) {
 	tests := []struct {
 		timezone string
-		want     int
+		want     float64
 	}{
 		{"UTC-4", -4},
 		{"UTC-7", -7},
@@ -106,6 +117,14 @@ func TestParseTimezoneOffset(t *testing.T) {
 		{"UTC", 0},
 		{"UTC-10", -10},
 		{"UTC+12", 12},
+		{"UTC+5:30", 5.5},
+		{"UTC+5.5", 5.5},
+		{"UTC+5:45", 5.75},
+		{"UTC+5.75", 5.75},
+		{"UTC-3:30", -3.5},
+		{"UTC-9.5", -9.5},
+		{"Asia/Kolkata", 5.5},    // IANA timezone without DST
+		{"Asia/Kathmandu", 5.75}, // 45-minute IANA offset
 		{"America/New_York", -4}, // IANA timezone (currently EDT)
 		{"", 0},                  // Empty string
 	}
@@ -120,3 +139,48 @@ func TestParseTimezoneOffset(t *testing.T) {
 		})
 	}
 }
+
+func TestHalfHourBucket(t *testing.T) {
+	tests := []struct {
+		hour float64
+		want float64
+	}{
+		{0, 0},
+		{3.5, 3.5},
+		{4.25, 4},
+		{4.75, 4.5},
+		{23.75, 23.5},
+		{-0.25, 23.5},
+		{24.25, 0},
+	}
+
+	for _, tt := range tests {
+		if got := HalfHourBucket(tt.hour); got != tt.want {
+			t.Errorf("HalfHourBucket(%v) = %v, want %v", tt.hour, got, tt.want)
+		}
+	}
+}
+
+func TestFormatOffset(t *testing.T) {
+	tests := []struct {
+		offset float64
+		want   string
+	}{
+		{-4, "UTC-4"},
+		{0, "UTC+0"},
+		{8, "UTC+8"},
+		{5.5, "UTC+5.5"},
+		{5.75, "UTC+5.75"},
+		{-3.5, "UTC-3.5"},
+	}
+
+	for _, tt := range tests {
+		got := FormatOffset(tt.offset)
+		if got != tt.want {
+			t.Errorf("FormatOffset(%v) = %q, want %q", tt.offset, got, tt.want)
+		}
+		if back := ParseTimezoneOffset(got); back != tt.offset {
+			t.Errorf("ParseTimezoneOffset(%q) = %v, want %v", got, back, tt.offset)
+		}
+	}
+}